@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"slices"
 	"strconv"
@@ -1074,56 +1075,69 @@ func (svc *albyOAuthService) GetLSPInfo(ctx context.Context, lspIdentifier, netw
 		return nil, fmt.Errorf("lsp info endpoint returned non-success code: %s", string(body))
 	}
 
-	type lsps1LSPInfo struct {
-		MinRequiredChannelConfirmations uint64   `json:"min_required_channel_confirmations"`
-		MinFundingConfirmsWithinBlocks  uint64   `json:"min_funding_confirms_within_blocks"`
-		MaxChannelExpiryBlocks          uint64   `json:"max_channel_expiry_blocks"`
-		URIs                            []string `json:"uris"`
+	return ParseLSPS1Info(body)
+}
+
+func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network string, lspChannelRequest *LSPChannelRequest) (*LSPChannelResponse, error) {
+	token, err := svc.fetchUserToken(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to fetch user token")
+		return nil, err
 	}
 
-	lsps1LspInfo := &lsps1LSPInfo{}
-	err = json.Unmarshal(body, lsps1LspInfo)
+	var client *http.Client
+	if token != nil {
+		client = svc.oauthConf.Client(ctx, token)
+	} else {
+		client = &http.Client{}
+	}
+	client.Timeout = 30 * time.Second
+
+	payloadBytes, err := json.Marshal(lspChannelRequest)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to decode API response")
 		return nil, err
 	}
+	bodyReader := bytes.NewReader(payloadBytes)
 
-	httpUris := utils.Filter(lsps1LspInfo.URIs, func(uri string) bool {
-		return !strings.Contains(uri, ".onion")
-	})
-	if len(httpUris) == 0 {
-		logger.Logger.WithField("uris", lsps1LspInfo.URIs).WithError(err).Error("Couldn't find HTTP URI")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/internal/lsp/%s/%s/v1/create_order", albyOAuthAPIURL, lsp, network), bodyReader)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to create lsp order request")
 		return nil, err
 	}
-	uri := httpUris[0]
 
-	// make sure it's a valid IPv4 URI
-	regex := regexp.MustCompile(`^([0-9a-f]+)@([0-9]+\.[0-9]+\.[0-9]+\.[0-9]+):([0-9]+)$`)
-	parts := regex.FindStringSubmatch(uri)
-	logger.Logger.WithField("parts", parts).Info("Split URI")
-	if parts == nil || len(parts) != 4 {
-		logger.Logger.WithField("parts", parts).Error("Unsupported URI")
-		return nil, errors.New("could not decode LSP URI")
+	setDefaultRequestHeaders(req)
+
+	res, err := client.Do(req)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to request lsp order")
+		return nil, err
 	}
 
-	port, err := strconv.Atoi(parts[3])
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		logger.Logger.WithField("port", parts[3]).WithError(err).Error("Failed to decode port number")
+		logger.Logger.WithError(err).Error("Failed to read response body")
+		return nil, errors.New("failed to read response body")
+	}
 
+	if res.StatusCode >= 300 {
+		logger.Logger.WithFields(logrus.Fields{
+			"body":        string(body),
+			"status_code": res.StatusCode,
+		}).Error("lsp create order endpoint returned non-success code")
+		return nil, fmt.Errorf("lsp create order endpoint returned non-success code: %s", string(body))
+	}
+
+	channelResponse := &LSPChannelResponse{}
+	err = json.Unmarshal(body, channelResponse)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to decode API response")
 		return nil, err
 	}
 
-	return &LSPInfo{
-		Pubkey:                          parts[1],
-		Address:                         parts[2],
-		Port:                            uint16(port),
-		MaxChannelExpiryBlocks:          lsps1LspInfo.MaxChannelExpiryBlocks,
-		MinRequiredChannelConfirmations: lsps1LspInfo.MinRequiredChannelConfirmations,
-		MinFundingConfirmsWithinBlocks:  lsps1LspInfo.MinFundingConfirmsWithinBlocks,
-	}, nil
+	return channelResponse, nil
 }
 
-func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network string, lspChannelRequest *LSPChannelRequest) (*LSPChannelResponse, error) {
+func (svc *albyOAuthService) GetLSPOrder(ctx context.Context, lsp, network, orderId string) (*LSPChannelResponse, error) {
 	token, err := svc.fetchUserToken(ctx)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to fetch user token")
@@ -1138,15 +1152,9 @@ func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network st
 	}
 	client.Timeout = 30 * time.Second
 
-	payloadBytes, err := json.Marshal(lspChannelRequest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/internal/lsp/%s/%s/v1/get_order?order_id=%s", albyOAuthAPIURL, lsp, network, url.QueryEscape(orderId)), nil)
 	if err != nil {
-		return nil, err
-	}
-	bodyReader := bytes.NewReader(payloadBytes)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/internal/lsp/%s/%s/v1/create_order", albyOAuthAPIURL, lsp, network), bodyReader)
-	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to create lsp order request")
+		logger.Logger.WithError(err).Error("Failed to create lsp get order request")
 		return nil, err
 	}
 
@@ -1154,7 +1162,7 @@ func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network st
 
 	res, err := client.Do(req)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to request lsp order")
+		logger.Logger.WithError(err).Error("Failed to request lsp order status")
 		return nil, err
 	}
 
@@ -1168,8 +1176,8 @@ func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network st
 		logger.Logger.WithFields(logrus.Fields{
 			"body":        string(body),
 			"status_code": res.StatusCode,
-		}).Error("lsp create order endpoint returned non-success code")
-		return nil, fmt.Errorf("lsp create order endpoint returned non-success code: %s", string(body))
+		}).Error("lsp get order endpoint returned non-success code")
+		return nil, fmt.Errorf("lsp get order endpoint returned non-success code: %s", string(body))
 	}
 
 	channelResponse := &LSPChannelResponse{}
@@ -1182,6 +1190,71 @@ func (svc *albyOAuthService) CreateLSPOrder(ctx context.Context, lsp, network st
 	return channelResponse, nil
 }
 
+// ParseLSPS1Info decodes an LSPS1 get_info response and extracts the
+// LSP's IPv4 node URI
+func ParseLSPS1Info(body []byte) (*LSPInfo, error) {
+	type lsps1LSPInfo struct {
+		MinRequiredChannelConfirmations uint64   `json:"min_required_channel_confirmations"`
+		MinFundingConfirmsWithinBlocks  uint64   `json:"min_funding_confirms_within_blocks"`
+		MaxChannelExpiryBlocks          uint64   `json:"max_channel_expiry_blocks"`
+		URIs                            []string `json:"uris"`
+		// amounts are encoded as strings in LSPS1
+		MinInitialLSPBalanceSat json.Number `json:"min_initial_lsp_balance_sat"`
+		MaxInitialLSPBalanceSat json.Number `json:"max_initial_lsp_balance_sat"`
+	}
+
+	lsps1LspInfo := &lsps1LSPInfo{}
+	err := json.Unmarshal(body, lsps1LspInfo)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to decode API response")
+		return nil, err
+	}
+
+	httpUris := utils.Filter(lsps1LspInfo.URIs, func(uri string) bool {
+		return !strings.Contains(uri, ".onion")
+	})
+	if len(httpUris) == 0 {
+		logger.Logger.WithField("uris", lsps1LspInfo.URIs).Error("Couldn't find HTTP URI")
+		return nil, errors.New("could not find LSP HTTP URI")
+	}
+	uri := httpUris[0]
+
+	// make sure it's a valid IPv4 URI
+	regex := regexp.MustCompile(`^([0-9a-f]+)@([0-9]+\.[0-9]+\.[0-9]+\.[0-9]+):([0-9]+)$`)
+	parts := regex.FindStringSubmatch(uri)
+	logger.Logger.WithField("parts", parts).Info("Split URI")
+	if parts == nil || len(parts) != 4 {
+		logger.Logger.WithField("parts", parts).Error("Unsupported URI")
+		return nil, errors.New("could not decode LSP URI")
+	}
+
+	port, err := strconv.Atoi(parts[3])
+	if err != nil {
+		logger.Logger.WithField("port", parts[3]).WithError(err).Error("Failed to decode port number")
+
+		return nil, err
+	}
+
+	return &LSPInfo{
+		Pubkey:                          parts[1],
+		Address:                         parts[2],
+		Port:                            uint16(port),
+		MaxChannelExpiryBlocks:          lsps1LspInfo.MaxChannelExpiryBlocks,
+		MinRequiredChannelConfirmations: lsps1LspInfo.MinRequiredChannelConfirmations,
+		MinFundingConfirmsWithinBlocks:  lsps1LspInfo.MinFundingConfirmsWithinBlocks,
+		MinInitialLSPBalanceSat:         parseLSPS1Amount(lsps1LspInfo.MinInitialLSPBalanceSat),
+		MaxInitialLSPBalanceSat:         parseLSPS1Amount(lsps1LspInfo.MaxInitialLSPBalanceSat),
+	}, nil
+}
+
+func parseLSPS1Amount(amount json.Number) uint64 {
+	value, err := strconv.ParseUint(amount.String(), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
 func (svc *albyOAuthService) RequestAutoChannel(ctx context.Context, lnClient lnclient.LNClient, isPublic bool) (*AutoChannelResponse, error) {
 	nodeInfo, err := lnClient.GetInfo(ctx)
 	if err != nil {
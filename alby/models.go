@@ -18,6 +18,7 @@ type AlbyOAuthService interface {
 	GetLSPChannelOffer(ctx context.Context) (*LSPChannelOffer, error)
 	GetLSPInfo(ctx context.Context, lsp, network string) (*LSPInfo, error)
 	CreateLSPOrder(ctx context.Context, lsp, network string, lspChannelRequest *LSPChannelRequest) (*LSPChannelResponse, error)
+	GetLSPOrder(ctx context.Context, lsp, network, orderId string) (*LSPChannelResponse, error)
 	GetAuthUrl() string
 	GetUserIdentifier() (string, error)
 	GetLightningAddress() (string, error)
@@ -141,6 +142,7 @@ type ErrorResponse struct {
 }
 
 type LSPChannelPaymentBolt11 struct {
+	State       string `json:"state"`
 	Invoice     string `json:"invoice"`
 	FeeTotalSat string `json:"fee_total_sat"`
 }
@@ -150,8 +152,21 @@ type LSPChannelPayment struct {
 	// TODO: add onchain
 }
 
+type LSPChannelInfo struct {
+	FundingOutpoint string `json:"funding_outpoint"`
+	FundedAt        string `json:"funded_at"`
+	ExpiresAt       string `json:"expires_at"`
+}
+
 type LSPChannelResponse struct {
-	Payment *LSPChannelPayment `json:"payment"`
+	OrderId                      string             `json:"order_id"`
+	OrderState                   string             `json:"order_state"`
+	LSPBalanceSat                string             `json:"lsp_balance_sat"`
+	RequiredChannelConfirmations uint64             `json:"required_channel_confirmations"`
+	FundingConfirmsWithinBlocks  uint64             `json:"funding_confirms_within_blocks"`
+	ChannelExpiryBlocks          uint64             `json:"channel_expiry_blocks"`
+	Payment                      *LSPChannelPayment `json:"payment"`
+	Channel                      *LSPChannelInfo    `json:"channel"`
 }
 
 type LSPChannelRequest struct {
@@ -173,4 +188,7 @@ type LSPInfo struct {
 	MaxChannelExpiryBlocks          uint64
 	MinRequiredChannelConfirmations uint64
 	MinFundingConfirmsWithinBlocks  uint64
+	// channel size limits of the LSP. 0 if not provided
+	MinInitialLSPBalanceSat uint64
+	MaxInitialLSPBalanceSat uint64
 }
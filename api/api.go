@@ -20,14 +20,18 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/approvals"
 	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/channels"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/db/queries"
 	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/labels"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nip47/archive"
 	permissions "github.com/getAlby/hub/nip47/permissions"
 	"github.com/getAlby/hub/service"
 	"github.com/getAlby/hub/service/keys"
@@ -45,6 +49,9 @@ type api struct {
 	keys             keys.Keys
 	albyOAuthSvc     alby.AlbyOAuthService
 	albySvc          alby.AlbyService
+	approvalsSvc     approvals.ApprovalsService
+	archiveSvc       archive.RequestArchiveService
+	labelsSvc        labels.LabelsService
 	startupError     error
 	startupErrorTime time.Time
 	eventPublisher   events.EventPublisher
@@ -60,6 +67,9 @@ func NewAPI(svc service.Service, gormDB *gorm.DB, config config.Config, keys key
 		keys:           keys,
 		albySvc:        albySvc,
 		albyOAuthSvc:   albyOAuthSvc,
+		approvalsSvc:   approvals.NewApprovalsService(gormDB, config, eventPublisher),
+		archiveSvc:     archive.NewRequestArchiveService(gormDB, config, keys),
+		labelsSvc:      labels.NewLabelsService(gormDB),
 		eventPublisher: eventPublisher,
 	}
 }
@@ -132,13 +142,17 @@ func (api *api) CreateApp(createAppRequest *CreateAppRequest) (*CreateAppRespons
 		}
 	}
 
+	responseBody.PairingUri = getPairingUri(app, relayUrl, pairingSecretKey, lightningAddress)
+
+	return responseBody, nil
+}
+
+func getPairingUri(app *db.App, relayUrl string, pairingSecretKey string, lightningAddress string) string {
 	var lud16 string
 	if lightningAddress != "" && !app.Isolated {
 		lud16 = fmt.Sprintf("&lud16=%s", lightningAddress)
 	}
-	responseBody.PairingUri = fmt.Sprintf("nostr+walletconnect://%s?relay=%s&secret=%s%s", *app.WalletPubkey, relayUrl, pairingSecretKey, lud16)
-
-	return responseBody, nil
+	return fmt.Sprintf("nostr+walletconnect://%s?relay=%s&secret=%s%s", *app.WalletPubkey, relayUrl, pairingSecretKey, lud16)
 }
 
 func (api *api) UpdateApp(userApp *db.App, updateAppRequest *UpdateAppRequest) error {
@@ -178,6 +192,27 @@ func (api *api) UpdateApp(userApp *db.App, updateAppRequest *UpdateAppRequest) e
 			}
 		}
 
+		if updateAppRequest.ApprovalThresholdSat != nil {
+			err := tx.Model(&db.App{}).Where("id", userApp.ID).Update("approval_threshold_sat", *updateAppRequest.ApprovalThresholdSat).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if updateAppRequest.ApproveOverBudget != nil {
+			err := tx.Model(&db.App{}).Where("id", userApp.ID).Update("approve_over_budget", *updateAppRequest.ApproveOverBudget).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if updateAppRequest.ArchiveRequests != nil {
+			err := tx.Model(&db.App{}).Where("id", userApp.ID).Update("archive_requests", *updateAppRequest.ArchiveRequests).Error
+			if err != nil {
+				return err
+			}
+		}
+
 		// Update the app metadata
 		if updateAppRequest.Metadata != nil {
 			var metadataBytes []byte
@@ -373,28 +408,35 @@ func (api *api) GetApp(dbApp *db.App) *App {
 	}
 
 	response := App{
-		ID:                 dbApp.ID,
-		Name:               dbApp.Name,
-		Description:        dbApp.Description,
-		CreatedAt:          dbApp.CreatedAt,
-		UpdatedAt:          dbApp.UpdatedAt,
-		AppPubkey:          dbApp.AppPubkey,
-		ExpiresAt:          expiresAt,
-		MaxAmountSat:       maxAmount,
-		Scopes:             requestMethods,
-		BudgetUsage:        budgetUsage,
-		BudgetRenewal:      paySpecificPermission.BudgetRenewal,
-		Isolated:           dbApp.Isolated,
-		Metadata:           metadata,
-		WalletPubkey:       walletPubkey,
-		UniqueWalletPubkey: uniqueWalletPubkey,
-		LastUsedAt:         dbApp.LastUsedAt,
+		ID:                   dbApp.ID,
+		Name:                 dbApp.Name,
+		Description:          dbApp.Description,
+		CreatedAt:            dbApp.CreatedAt,
+		UpdatedAt:            dbApp.UpdatedAt,
+		AppPubkey:            dbApp.AppPubkey,
+		ExpiresAt:            expiresAt,
+		MaxAmountSat:         maxAmount,
+		Scopes:               requestMethods,
+		BudgetUsage:          budgetUsage,
+		BudgetRenewal:        paySpecificPermission.BudgetRenewal,
+		Isolated:             dbApp.Isolated,
+		Metadata:             metadata,
+		WalletPubkey:         walletPubkey,
+		UniqueWalletPubkey:   uniqueWalletPubkey,
+		LastUsedAt:           dbApp.LastUsedAt,
+		ApprovalThresholdSat: dbApp.ApprovalThresholdSat,
+		ApproveOverBudget:    dbApp.ApproveOverBudget,
+		ArchiveRequests:      dbApp.ArchiveRequests,
 	}
 
 	if dbApp.Isolated {
 		response.Balance = queries.GetIsolatedBalance(api.db, dbApp.ID)
 	}
 
+	if apps.HasPreviousKeys(dbApp) {
+		response.PreviousKeysExpireAt = dbApp.PreviousKeysExpireAt
+	}
+
 	return &response
 }
 
@@ -480,22 +522,29 @@ func (api *api) ListApps(limit uint64, offset uint64, filters ListAppsFilters, o
 			uniqueWalletPubkey = true
 		}
 		apiApp := App{
-			ID:                 dbApp.ID,
-			Name:               dbApp.Name,
-			Description:        dbApp.Description,
-			CreatedAt:          dbApp.CreatedAt,
-			UpdatedAt:          dbApp.UpdatedAt,
-			AppPubkey:          dbApp.AppPubkey,
-			Isolated:           dbApp.Isolated,
-			WalletPubkey:       walletPubkey,
-			UniqueWalletPubkey: uniqueWalletPubkey,
-			LastUsedAt:         dbApp.LastUsedAt,
+			ID:                   dbApp.ID,
+			Name:                 dbApp.Name,
+			Description:          dbApp.Description,
+			CreatedAt:            dbApp.CreatedAt,
+			UpdatedAt:            dbApp.UpdatedAt,
+			AppPubkey:            dbApp.AppPubkey,
+			Isolated:             dbApp.Isolated,
+			WalletPubkey:         walletPubkey,
+			UniqueWalletPubkey:   uniqueWalletPubkey,
+			LastUsedAt:           dbApp.LastUsedAt,
+			ApprovalThresholdSat: dbApp.ApprovalThresholdSat,
+			ApproveOverBudget:    dbApp.ApproveOverBudget,
+			ArchiveRequests:      dbApp.ArchiveRequests,
 		}
 
 		if dbApp.Isolated {
 			apiApp.Balance = queries.GetIsolatedBalance(api.db, dbApp.ID)
 		}
 
+		if apps.HasPreviousKeys(&dbApp) {
+			apiApp.PreviousKeysExpireAt = dbApp.PreviousKeysExpireAt
+		}
+
 		for _, appPermission := range permissionsMap[dbApp.ID] {
 			apiApp.Scopes = append(apiApp.Scopes, appPermission.Scope)
 			apiApp.ExpiresAt = appPermission.ExpiresAt
@@ -525,7 +574,7 @@ func (api *api) ListApps(limit uint64, offset uint64, filters ListAppsFilters, o
 	}, nil
 }
 
-func (api *api) ListChannels(ctx context.Context) ([]Channel, error) {
+func (api *api) ListChannels(ctx context.Context, filters LabelFilters) ([]Channel, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
@@ -534,8 +583,19 @@ func (api *api) ListChannels(ctx context.Context) ([]Channel, error) {
 		return nil, err
 	}
 
+	channelIds := []string{}
+	for _, channel := range channels {
+		channelIds = append(channelIds, channel.Id)
+	}
+	channelLabels := api.getLabels(constants.LABEL_ENTITY_TYPE_CHANNEL, channelIds)
+
 	apiChannels := []Channel{}
 	for _, channel := range channels {
+		channelLabel := findLabel(channelLabels, channel.Id)
+		if !labels.Matches(channelLabel, labels.Filters(filters)) {
+			continue
+		}
+
 		status := "offline"
 		if channel.Active {
 			status = "online"
@@ -563,6 +623,7 @@ func (api *api) ListChannels(ctx context.Context) ([]Channel, error) {
 			Error:                                    channel.Error,
 			IsOutbound:                               channel.IsOutbound,
 			Status:                                   status,
+			Label:                                    toApiLabel(channelLabel),
 		})
 	}
 
@@ -879,11 +940,33 @@ func (api *api) GetNodeStatus(ctx context.Context) (*lnclient.NodeStatus, error)
 	return api.svc.GetLNClient().GetNodeStatus(ctx)
 }
 
-func (api *api) ListPeers(ctx context.Context) ([]lnclient.PeerDetails, error) {
+func (api *api) ListPeers(ctx context.Context, filters LabelFilters) ([]Peer, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
-	return api.svc.GetLNClient().ListPeers(ctx)
+	peers, err := api.svc.GetLNClient().ListPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeIds := []string{}
+	for _, peer := range peers {
+		nodeIds = append(nodeIds, peer.NodeId)
+	}
+	peerLabels := api.getLabels(constants.LABEL_ENTITY_TYPE_PEER, nodeIds)
+
+	apiPeers := []Peer{}
+	for _, peer := range peers {
+		peerLabel := findLabel(peerLabels, peer.NodeId)
+		if !labels.Matches(peerLabel, labels.Filters(filters)) {
+			continue
+		}
+		apiPeers = append(apiPeers, Peer{
+			PeerDetails: peer,
+			Label:       toApiLabel(peerLabel),
+		})
+	}
+	return apiPeers, nil
 }
 
 func (api *api) ConnectPeer(ctx context.Context, connectPeerRequest *ConnectPeerRequest) error {
@@ -900,6 +983,27 @@ func (api *api) OpenChannel(ctx context.Context, openChannelRequest *OpenChannel
 	return api.svc.GetLNClient().OpenChannel(ctx, openChannelRequest)
 }
 
+func (api *api) OpenChannels(ctx context.Context, openChannelsRequest *OpenChannelsRequest) (*OpenChannelsResponse, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	if len(openChannelsRequest.Channels) == 0 {
+		return nil, errors.New("no channels to open")
+	}
+	for _, openChannelRequest := range openChannelsRequest.Channels {
+		if openChannelRequest.Pubkey == "" {
+			return nil, errors.New("missing pubkey")
+		}
+		if openChannelRequest.AmountSats <= 0 {
+			return nil, fmt.Errorf("invalid channel amount for %s", openChannelRequest.Pubkey)
+		}
+	}
+	logger.Logger.WithFields(logrus.Fields{
+		"channel_count": len(openChannelsRequest.Channels),
+	}).Info("Opening channels")
+	return api.svc.GetLNClient().OpenChannels(ctx, openChannelsRequest)
+}
+
 func (api *api) DisconnectPeer(ctx context.Context, peerId string) error {
 	if api.svc.GetLNClient() == nil {
 		return errors.New("LNClient not started")
@@ -910,20 +1014,139 @@ func (api *api) DisconnectPeer(ctx context.Context, peerId string) error {
 	return api.svc.GetLNClient().DisconnectPeer(ctx, peerId)
 }
 
-func (api *api) CloseChannel(ctx context.Context, peerId, channelId string, force bool) (*CloseChannelResponse, error) {
+func (api *api) CloseChannel(ctx context.Context, peerId, channelId string, closeChannelRequest *CloseChannelRequest) (*CloseChannelResponse, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
+	if closeChannelRequest.Force && (closeChannelRequest.FeeRate != nil || closeChannelRequest.Address != "" || closeChannelRequest.MaxFeeRate != nil) {
+		return nil, errors.New("fee rate, address and scheduling are only supported for cooperative closes")
+	}
 	logger.Logger.WithFields(logrus.Fields{
-		"peer_id":    peerId,
-		"channel_id": channelId,
-		"force":      force,
+		"peer_id":      peerId,
+		"channel_id":   channelId,
+		"force":        closeChannelRequest.Force,
+		"fee_rate":     closeChannelRequest.FeeRate,
+		"address":      closeChannelRequest.Address,
+		"max_fee_rate": closeChannelRequest.MaxFeeRate,
 	}).Info("Closing channel")
-	return api.svc.GetLNClient().CloseChannel(ctx, &lnclient.CloseChannelRequest{
+
+	lnClientCloseChannelRequest := &lnclient.CloseChannelRequest{
 		NodeId:    peerId,
 		ChannelId: channelId,
-		Force:     force,
-	})
+		Force:     closeChannelRequest.Force,
+		FeeRate:   closeChannelRequest.FeeRate,
+		Address:   closeChannelRequest.Address,
+	}
+
+	if closeChannelRequest.MaxFeeRate != nil {
+		pendingChannelClose, err := api.svc.GetChannelsService().ScheduleCloseChannel(lnClientCloseChannelRequest, *closeChannelRequest.MaxFeeRate)
+		if err != nil {
+			return nil, err
+		}
+		return &CloseChannelResponse{
+			PendingClose: toApiPendingChannelClose(pendingChannelClose),
+		}, nil
+	}
+
+	_, err := api.svc.GetChannelsService().CloseChannel(ctx, lnClientCloseChannelRequest)
+	if err != nil {
+		return nil, err
+	}
+	return &CloseChannelResponse{}, nil
+}
+
+func (api *api) GetWatchtowerStatus(ctx context.Context) (*WatchtowerStatusResponse, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	watchtowerStatus, err := api.svc.GetLNClient().GetWatchtowerStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	towers := []Watchtower{}
+	for _, tower := range watchtowerStatus.Towers {
+		towers = append(towers, Watchtower{
+			Pubkey:                 tower.Pubkey,
+			Addresses:              tower.Addresses,
+			ActiveSessionCandidate: tower.ActiveSessionCandidate,
+			NumSessions:            tower.NumSessions,
+			NumUsableSessions:      tower.NumUsableSessions,
+			NumBackups:             tower.NumBackups,
+			NumPendingBackups:      tower.NumPendingBackups,
+		})
+	}
+
+	return &WatchtowerStatusResponse{
+		Towers:               towers,
+		NumBackups:           watchtowerStatus.NumBackups,
+		NumPendingBackups:    watchtowerStatus.NumPendingBackups,
+		NumFailedBackups:     watchtowerStatus.NumFailedBackups,
+		NumSessionsAcquired:  watchtowerStatus.NumSessionsAcquired,
+		NumSessionsExhausted: watchtowerStatus.NumSessionsExhausted,
+	}, nil
+}
+
+func (api *api) AddWatchtower(ctx context.Context, addWatchtowerRequest *AddWatchtowerRequest) error {
+	if api.svc.GetLNClient() == nil {
+		return errors.New("LNClient not started")
+	}
+	if addWatchtowerRequest.Pubkey == "" || addWatchtowerRequest.Address == "" {
+		return errors.New("pubkey and address are required")
+	}
+	logger.Logger.WithFields(logrus.Fields{
+		"pubkey":  addWatchtowerRequest.Pubkey,
+		"address": addWatchtowerRequest.Address,
+	}).Info("Adding watchtower")
+	return api.svc.GetLNClient().AddWatchtower(ctx, addWatchtowerRequest)
+}
+
+func (api *api) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	if api.svc.GetLNClient() == nil {
+		return errors.New("LNClient not started")
+	}
+	logger.Logger.WithField("pubkey", pubkey).Info("Removing watchtower")
+	return api.svc.GetLNClient().RemoveWatchtower(ctx, pubkey)
+}
+
+func (api *api) ListPendingChannelCloses() ([]PendingChannelClose, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	pendingChannelCloses, err := api.svc.GetChannelsService().ListPendingChannelCloses()
+	if err != nil {
+		return nil, err
+	}
+
+	apiPendingChannelCloses := []PendingChannelClose{}
+	for _, pendingChannelClose := range pendingChannelCloses {
+		apiPendingChannelCloses = append(apiPendingChannelCloses, *toApiPendingChannelClose(&pendingChannelClose))
+	}
+	return apiPendingChannelCloses, nil
+}
+
+func (api *api) CancelPendingChannelClose(id uint) error {
+	if api.svc.GetLNClient() == nil {
+		return errors.New("LNClient not started")
+	}
+	logger.Logger.WithField("id", id).Info("Canceling pending channel close")
+	return api.svc.GetChannelsService().CancelPendingChannelClose(id)
+}
+
+func toApiPendingChannelClose(pendingChannelClose *channels.PendingChannelClose) *PendingChannelClose {
+	return &PendingChannelClose{
+		Id:         pendingChannelClose.ID,
+		NodeId:     pendingChannelClose.NodeId,
+		ChannelId:  pendingChannelClose.ChannelId,
+		MaxFeeRate: pendingChannelClose.MaxFeeRate,
+		FeeRate:    pendingChannelClose.FeeRate,
+		Address:    pendingChannelClose.Address,
+		State:      pendingChannelClose.State,
+		Attempts:   pendingChannelClose.Attempts,
+		Error:      pendingChannelClose.Error,
+		CreatedAt:  pendingChannelClose.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  pendingChannelClose.UpdatedAt.Format(time.RFC3339),
+	}
 }
 
 func (api *api) UpdateChannel(ctx context.Context, updateChannelRequest *UpdateChannelRequest) error {
@@ -1304,16 +1527,14 @@ func (api *api) GetWalletCapabilities(ctx context.Context) (*WalletCapabilitiesR
 		return nil, errors.New("LNClient not started")
 	}
 
-	methods := api.svc.GetLNClient().GetSupportedNIP47Methods()
-	notificationTypes := api.svc.GetLNClient().GetSupportedNIP47NotificationTypes()
+	methods := permissions.GetSupportedMethods(api.svc.GetLNClient())
+	notificationTypes := permissions.GetSupportedNotificationTypes(api.svc.GetLNClient())
 
 	scopes, err := permissions.RequestMethodsToScopes(methods)
 	if err != nil {
 		return nil, err
 	}
-	if len(notificationTypes) > 0 {
-		scopes = append(scopes, constants.NOTIFICATIONS_SCOPE)
-	}
+	scopes = append(scopes, permissions.NotificationTypesToScopes(notificationTypes)...)
 
 	return &WalletCapabilitiesResponse{
 		Methods:           methods,
@@ -1390,11 +1611,33 @@ func (api *api) SyncWallet() error {
 	api.svc.GetLNClient().UpdateLastWalletSyncRequest()
 	return nil
 }
-func (api *api) ListOnchainTransactions(ctx context.Context) ([]lnclient.OnchainTransaction, error) {
+func (api *api) ListOnchainTransactions(ctx context.Context, filters LabelFilters) ([]OnchainTransaction, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
-	return api.svc.GetLNClient().ListOnchainTransactions(ctx)
+	transactions, err := api.svc.GetLNClient().ListOnchainTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txIds := []string{}
+	for _, transaction := range transactions {
+		txIds = append(txIds, transaction.TxId)
+	}
+	transactionLabels := api.getLabels(constants.LABEL_ENTITY_TYPE_ONCHAIN_TRANSACTION, txIds)
+
+	apiTransactions := []OnchainTransaction{}
+	for _, transaction := range transactions {
+		transactionLabel := findLabel(transactionLabels, transaction.TxId)
+		if !labels.Matches(transactionLabel, labels.Filters(filters)) {
+			continue
+		}
+		apiTransactions = append(apiTransactions, OnchainTransaction{
+			OnchainTransaction: transaction,
+			Label:              toApiLabel(transactionLabel),
+		})
+	}
+	return apiTransactions, nil
 }
 
 func (api *api) GetLogOutput(ctx context.Context, logType string, getLogRequest *GetLogOutputRequest) (*GetLogOutputResponse, error) {
@@ -1480,6 +1723,18 @@ func (api *api) Health(ctx context.Context) (*HealthResponse, error) {
 		if len(offlineChannels) > 0 {
 			alarms = append(alarms, NewHealthAlarm(HealthAlarmKindChannelsOffline, nil))
 		}
+
+		// only alarm if watchtowers are supported and at least one has been configured
+		watchtowerStatus, err := lnClient.GetWatchtowerStatus(ctx)
+		if err == nil && len(watchtowerStatus.Towers) > 0 {
+			// no channel states can be backed up once the current sessions are used up
+			hasActiveTower := slices.ContainsFunc(watchtowerStatus.Towers, func(tower lnclient.Watchtower) bool {
+				return tower.ActiveSessionCandidate
+			})
+			if !hasActiveTower {
+				alarms = append(alarms, NewHealthAlarm(HealthAlarmKindWatchtowersInactive, nil))
+			}
+		}
 	}
 
 	return &HealthResponse{Alarms: alarms}, nil
@@ -1616,3 +1871,40 @@ func (api *api) GetForwards() (*GetForwardsResponse, error) {
 		NumForwards:                 uint64(numForwards),
 	}, nil
 }
+
+func (api *api) ListPendingPaymentApprovals() ([]PaymentApproval, error) {
+	paymentApprovals, err := api.approvalsSvc.ListPendingPaymentApprovals()
+	if err != nil {
+		return nil, err
+	}
+
+	apiPaymentApprovals := []PaymentApproval{}
+	for _, paymentApproval := range paymentApprovals {
+		apiPaymentApprovals = append(apiPaymentApprovals, PaymentApproval{
+			Id:             paymentApproval.ID,
+			AppId:          paymentApproval.AppId,
+			AppName:        paymentApproval.App.Name,
+			Method:         paymentApproval.Method,
+			PaymentHash:    paymentApproval.PaymentHash,
+			PaymentRequest: paymentApproval.PaymentRequest,
+			Destination:    paymentApproval.Destination,
+			Description:    paymentApproval.Description,
+			Amount:         paymentApproval.AmountMsat / 1000,
+			Reason:         paymentApproval.Reason,
+			State:          paymentApproval.State,
+			ExpiresAt:      paymentApproval.ExpiresAt.Format(time.RFC3339),
+			CreatedAt:      paymentApproval.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return apiPaymentApprovals, nil
+}
+
+func (api *api) ApprovePayment(id uint) error {
+	logger.Logger.WithField("id", id).Info("Approving payment")
+	return api.approvalsSvc.ApprovePayment(id)
+}
+
+func (api *api) DenyPayment(id uint) error {
+	logger.Logger.WithField("id", id).Info("Denying payment")
+	return api.approvalsSvc.DenyPayment(id)
+}
@@ -2,13 +2,18 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/service"
 	"github.com/getAlby/hub/tests/mocks"
 )
@@ -221,6 +226,120 @@ func TestExecuteCustomNodeCommand(t *testing.T) {
 	}
 }
 
+func TestOpenChannels(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	lnClient := mocks.NewMockLNClient(t)
+	svc := mocks.NewMockService(t)
+
+	openChannelsRequest := &OpenChannelsRequest{
+		Channels: []lnclient.OpenChannelRequest{
+			{Pubkey: "peer1", AmountSats: 100_000},
+			{Pubkey: "peer2", AmountSats: 200_000, Public: true},
+		},
+	}
+	lnResponse := &lnclient.OpenChannelsResponse{
+		FundingTxId: "txid",
+		Channels: []lnclient.OpenChannelStatus{
+			{Pubkey: "peer1", AmountSats: 100_000, State: constants.CHANNEL_OPEN_STATE_PENDING, FundingTxId: "txid", FundingTxVout: 0},
+			{Pubkey: "peer2", AmountSats: 200_000, State: constants.CHANNEL_OPEN_STATE_PENDING, FundingTxId: "txid", FundingTxVout: 1},
+		},
+	}
+
+	lnClient.On("OpenChannels", mock.Anything, openChannelsRequest).Return(lnResponse, nil)
+	svc.On("GetLNClient").Return(lnClient)
+
+	theAPI := instantiateAPIWithService(svc)
+
+	response, err := theAPI.OpenChannels(context.TODO(), openChannelsRequest)
+	require.NoError(t, err)
+	require.Equal(t, lnResponse, response)
+
+	_, err = theAPI.OpenChannels(context.TODO(), &OpenChannelsRequest{})
+	require.ErrorContains(t, err, "no channels to open")
+
+	_, err = theAPI.OpenChannels(context.TODO(), &OpenChannelsRequest{
+		Channels: []lnclient.OpenChannelRequest{{Pubkey: "peer1", AmountSats: 0}},
+	})
+	require.ErrorContains(t, err, "invalid channel amount")
+}
+
+func TestWatchtowers(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	lnClient := mocks.NewMockLNClient(t)
+	svc := mocks.NewMockService(t)
+	svc.On("GetLNClient").Return(lnClient)
+
+	addWatchtowerRequest := &AddWatchtowerRequest{Pubkey: "pubkey", Address: "127.0.0.1:9911"}
+	lnClient.On("AddWatchtower", mock.Anything, addWatchtowerRequest).Return(nil)
+	lnClient.On("GetWatchtowerStatus", mock.Anything).Return(&lnclient.WatchtowerStatus{
+		Towers: []lnclient.Watchtower{
+			{
+				Pubkey:                 "pubkey",
+				Addresses:              []string{"127.0.0.1:9911"},
+				ActiveSessionCandidate: true,
+				NumSessions:            2,
+				NumUsableSessions:      1,
+				NumBackups:             10,
+				NumPendingBackups:      1,
+			},
+		},
+		NumBackups:          10,
+		NumPendingBackups:   1,
+		NumSessionsAcquired: 2,
+	}, nil)
+	lnClient.On("RemoveWatchtower", mock.Anything, "pubkey").Return(nil)
+
+	theAPI := instantiateAPIWithService(svc)
+
+	err := theAPI.AddWatchtower(context.TODO(), addWatchtowerRequest)
+	require.NoError(t, err)
+
+	err = theAPI.AddWatchtower(context.TODO(), &AddWatchtowerRequest{Pubkey: "pubkey"})
+	require.EqualError(t, err, "pubkey and address are required")
+
+	watchtowerStatus, err := theAPI.GetWatchtowerStatus(context.TODO())
+	require.NoError(t, err)
+	require.Equal(t, &WatchtowerStatusResponse{
+		Towers: []Watchtower{
+			{
+				Pubkey:                 "pubkey",
+				Addresses:              []string{"127.0.0.1:9911"},
+				ActiveSessionCandidate: true,
+				NumSessions:            2,
+				NumUsableSessions:      1,
+				NumBackups:             10,
+				NumPendingBackups:      1,
+			},
+		},
+		NumBackups:          10,
+		NumPendingBackups:   1,
+		NumSessionsAcquired: 2,
+	}, watchtowerStatus)
+
+	err = theAPI.RemoveWatchtower(context.TODO(), "pubkey")
+	require.NoError(t, err)
+}
+
+func TestWatchtowers_Unsupported(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	lnClient := mocks.NewMockLNClient(t)
+	svc := mocks.NewMockService(t)
+	svc.On("GetLNClient").Return(lnClient)
+
+	lnClient.On("GetWatchtowerStatus", mock.Anything).Return(nil, errors.ErrUnsupported)
+	lnClient.On("AddWatchtower", mock.Anything, mock.Anything).Return(errors.ErrUnsupported)
+	lnClient.On("RemoveWatchtower", mock.Anything, mock.Anything).Return(errors.ErrUnsupported)
+
+	theAPI := instantiateAPIWithService(svc)
+
+	_, err := theAPI.GetWatchtowerStatus(context.TODO())
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	err = theAPI.AddWatchtower(context.TODO(), &AddWatchtowerRequest{Pubkey: "pubkey", Address: "127.0.0.1:9911"})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	err = theAPI.RemoveWatchtower(context.TODO(), "pubkey")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
 // instantiateAPIWithService is a helper function that returns a partially
 // constructed API instance. It is only suitable for the simplest of test cases.
 func instantiateAPIWithService(s service.Service) *api {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -11,15 +12,11 @@ import (
 	"os"
 	"path/filepath"
 
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"crypto/sha256"
-
+	"github.com/getAlby/hub/backups"
+	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/utils"
-	"golang.org/x/crypto/pbkdf2"
 )
 
 func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
@@ -38,10 +35,6 @@ func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
 		return errors.New("Please disable auto-unlock before using this feature")
 	}
 
-	if api.db.Dialector.Name() != "sqlite" {
-		return errors.New("Migration with non-sqlite backend is currently not supported")
-	}
-
 	workDir, err := filepath.Abs(api.cfg.GetEnv().Workdir)
 	if err != nil {
 		return fmt.Errorf("failed to get absolute workdir: %w", err)
@@ -75,15 +68,6 @@ func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
 		return errors.New("failed to remove oauth access token")
 	}
 
-	// Closing the database leaves the service in an inconsistent state,
-	// but that should not be a problem since the app is not expected
-	// to be used after its data is exported.
-	err = db.Stop(api.db)
-	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to stop database")
-		return fmt.Errorf("failed to close database: %w", err)
-	}
-
 	var filesToArchive []string
 
 	if lnStorageDir != "" {
@@ -101,7 +85,18 @@ func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
 		filesToArchive = append(filesToArchive, lnFiles...)
 	}
 
-	cw, err := encryptingWriter(w, unlockPassword)
+	if api.cfg.GetEnv().LNBackendType == config.LDKBackendType {
+		// include the latest static channel backup so the backup can be verified
+		channelsBackupFile, err := backups.LatestStaticChannelBackupFile(workDir)
+		if err != nil {
+			return fmt.Errorf("failed to find static channel backup: %w", err)
+		}
+		if channelsBackupFile != "" {
+			filesToArchive = append(filesToArchive, channelsBackupFile)
+		}
+	}
+
+	cw, err := backups.EncryptingWriter(w, unlockPassword)
 	if err != nil {
 		return fmt.Errorf("failed to create encrypted writer: %w", err)
 	}
@@ -109,6 +104,31 @@ func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
 	zw := zip.NewWriter(cw)
 	defer zw.Close()
 
+	databaseType := api.db.Dialector.Name()
+	err = backups.WriteManifest(zw, databaseType, api.cfg.GetEnv().LNBackendType, false, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to write manifest to zip: %w", err)
+	}
+
+	if databaseType == "postgres" {
+		// the database cannot be copied as a file, so export all tables instead
+		logger.Logger.Info("adding database export to zip")
+		err = backups.ExportDatabaseToZip(api.db, zw)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to export database")
+			return fmt.Errorf("failed to export database: %w", err)
+		}
+	}
+
+	// Closing the database leaves the service in an inconsistent state,
+	// but that should not be a problem since the app is not expected
+	// to be used after its data is exported.
+	err = db.Stop(api.db)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to stop database")
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
 	addFileToZip := func(fsPath, zipPath string) error {
 		inF, err := os.Open(fsPath)
 		if err != nil {
@@ -125,14 +145,16 @@ func (api *api) CreateBackup(unlockPassword string, w io.Writer) error {
 		return err
 	}
 
-	// Locate the main database file.
-	dbFilePath := api.cfg.GetEnv().DatabaseUri
-	// Add the database file to the archive.
-	logger.Logger.WithField("nwc.db", dbFilePath).Info("adding nwc db to zip")
-	err = addFileToZip(dbFilePath, "nwc.db")
-	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to zip nwc db")
-		return fmt.Errorf("failed to write nwc db file to zip: %w", err)
+	if databaseType == "sqlite" {
+		// Locate the main database file.
+		dbFilePath := api.cfg.GetEnv().DatabaseUri
+		// Add the database file to the archive.
+		logger.Logger.WithField("nwc.db", dbFilePath).Info("adding nwc db to zip")
+		err = addFileToZip(dbFilePath, "nwc.db")
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to zip nwc db")
+			return fmt.Errorf("failed to write nwc db file to zip: %w", err)
+		}
 	}
 
 	for _, fileToArchive := range filesToArchive {
@@ -168,11 +190,7 @@ func (api *api) RestoreBackup(unlockPassword string, r io.Reader) error {
 		return errors.New("cannot restore backup when database path is a file URI")
 	}
 
-	if api.db.Dialector.Name() != "sqlite" {
-		return errors.New("migration to non-sqlite backend is currently not supported")
-	}
-
-	cr, err := decryptingReader(r, unlockPassword)
+	cr, err := backups.DecryptingReader(r, unlockPassword)
 	if err != nil {
 		return fmt.Errorf("failed to create decrypted reader: %w", err)
 	}
@@ -203,6 +221,14 @@ func (api *api) RestoreBackup(unlockPassword string, r io.Reader) error {
 		return fmt.Errorf("failed to create zip reader: %w", err)
 	}
 
+	manifest, err := backups.ReadManifest(zr)
+	if err != nil {
+		return fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	if manifest != nil && manifest.HotBackup && api.cfg.GetEnv().LNBackendType == config.LDKBackendType {
+		return errors.New("this backup does not contain the node storage and cannot be restored on an LDK node. Please recover your channels with the static channel backup instead")
+	}
+
 	extractZipEntry := func(zipFile *zip.File) error {
 		fsFilePath := filepath.Join(workDir, "restore", filepath.FromSlash(zipFile.Name))
 
@@ -231,6 +257,9 @@ func (api *api) RestoreBackup(unlockPassword string, r io.Reader) error {
 
 	logger.Logger.WithField("count", len(zr.File)).Info("Extracting files")
 	for _, f := range zr.File {
+		if f.Name == backups.ManifestFileName {
+			continue
+		}
 		logger.Logger.WithField("file", f.Name).Info("Extracting file")
 		if err = extractZipEntry(f); err != nil {
 			return fmt.Errorf("failed to extract zip entry: %w", err)
@@ -238,14 +267,26 @@ func (api *api) RestoreBackup(unlockPassword string, r io.Reader) error {
 	}
 	logger.Logger.WithField("count", len(zr.File)).Info("Extracted files")
 
+	// A SQLite backup restored into a Postgres hub is converted to a database export.
+	// The export is imported into the hub database on the next start, see backups.FinishDatabaseRestore
+	restoredDBPath := filepath.Join(workDir, "restore", "nwc.db")
+	if _, err := os.Stat(restoredDBPath); err == nil && api.db.Dialector.Name() == "postgres" {
+		err = convertRestoredSqliteDB(restoredDBPath, filepath.Join(workDir, "restore", backups.DatabaseExportDirectory))
+		if err != nil {
+			return fmt.Errorf("failed to convert restored database: %w", err)
+		}
+	}
+
 	go func() {
 		logger.Logger.Info("Backup restored. Shutting down Alby Hub...")
 		api.svc.Shutdown()
-		// ensure no -shm or -wal files exist as they will stop the restore
-		for _, filename := range []string{"nwc.db", "nwc.db-shm", "nwc.db-wal"} {
-			err = os.Remove(filepath.Join(workDir, filename))
-			if err != nil {
-				logger.Logger.WithError(err).WithField("filename", filename).Error("failed to remove old nwc db file before restore")
+		if api.db.Dialector.Name() == "sqlite" {
+			// ensure no -shm or -wal files exist as they will stop the restore
+			for _, filename := range []string{"nwc.db", "nwc.db-shm", "nwc.db-wal"} {
+				err = os.Remove(filepath.Join(workDir, filename))
+				if err != nil {
+					logger.Logger.WithError(err).WithField("filename", filename).Error("failed to remove old nwc db file before restore")
+				}
 			}
 		}
 
@@ -257,64 +298,70 @@ func (api *api) RestoreBackup(unlockPassword string, r io.Reader) error {
 	return nil
 }
 
-func encryptingWriter(w io.Writer, password string) (io.Writer, error) {
-	salt := make([]byte, 8)
-	if _, err := rand.Read(salt); err != nil {
-		return nil, fmt.Errorf("failed to generate salt: %w", err)
-	}
+func (api *api) VerifyBackup(unlockPassword string, r io.Reader) (*BackupVerificationReport, error) {
+	logger.Logger.Info("Verifying backup file")
 
-	encKey := pbkdf2.Key([]byte(password), salt, 4096, 32, sha256.New)
-	block, err := aes.NewCipher(encKey)
+	report, err := backups.VerifyBackup(r, unlockPassword)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+		return nil, err
 	}
 
-	iv := make([]byte, aes.BlockSize)
-	if _, err = rand.Read(iv); err != nil {
-		return nil, fmt.Errorf("failed to generate IV: %w", err)
-	}
+	logger.Logger.WithField("valid", report.Valid).WithField("errors", report.Errors).Info("Verified backup file")
+	return report, nil
+}
 
-	_, err = w.Write(salt)
+func convertRestoredSqliteDB(dbPath string, exportDir string) error {
+	restoredDB, err := db.NewDB(dbPath, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write salt: %w", err)
+		return err
 	}
 
-	_, err = w.Write(iv)
+	err = backups.ExportDatabaseToDir(restoredDB, exportDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write IV: %w", err)
+		db.Stop(restoredDB)
+		return err
 	}
 
-	stream := cipher.NewOFB(block, iv)
-	cw := &cipher.StreamWriter{
-		S: stream,
-		W: w,
+	err = db.Stop(restoredDB)
+	if err != nil {
+		return err
 	}
 
-	return cw, nil
+	for _, filename := range []string{"nwc.db", "nwc.db-shm", "nwc.db-wal"} {
+		err = os.Remove(filepath.Join(filepath.Dir(dbPath), filename))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
 }
 
-func decryptingReader(r io.Reader, password string) (io.Reader, error) {
-	salt := make([]byte, 8)
-	if _, err := io.ReadFull(r, salt); err != nil {
-		return nil, fmt.Errorf("failed to read salt: %w", err)
+func (api *api) ListAutoBackups(ctx context.Context) ([]AutoBackup, error) {
+	storedBackups, err := api.svc.GetBackupsService().ListBackups(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	iv := make([]byte, aes.BlockSize)
-	if _, err := io.ReadFull(r, iv); err != nil {
-		return nil, fmt.Errorf("failed to read IV: %w", err)
+	autoBackups := []AutoBackup{}
+	for _, storedBackup := range storedBackups {
+		autoBackups = append(autoBackups, toApiAutoBackup(&storedBackup))
 	}
+	return autoBackups, nil
+}
 
-	encKey := pbkdf2.Key([]byte(password), salt, 4096, 32, sha256.New)
-	block, err := aes.NewCipher(encKey)
+func (api *api) CreateAutoBackup(ctx context.Context) (*AutoBackup, error) {
+	storedBackup, err := api.svc.GetBackupsService().CreateBackup(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+		return nil, err
 	}
+	autoBackup := toApiAutoBackup(storedBackup)
+	return &autoBackup, nil
+}
 
-	stream := cipher.NewOFB(block, iv)
-	cr := &cipher.StreamReader{
-		S: stream,
-		R: r,
+func toApiAutoBackup(storedBackup *backups.StoredBackup) AutoBackup {
+	return AutoBackup{
+		Name:      storedBackup.Name,
+		Size:      storedBackup.Size,
+		CreatedAt: storedBackup.CreatedAt.Format(time.RFC3339),
 	}
-
-	return cr, nil
 }
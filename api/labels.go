@@ -0,0 +1,73 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/labels"
+	"github.com/getAlby/hub/logger"
+)
+
+func (api *api) SetLabel(entityType string, entityId string, setLabelRequest *SetLabelRequest) (*Label, error) {
+	dbLabel, err := api.labelsSvc.SetLabel(entityType, entityId, setLabelRequest.Label, setLabelRequest.Note, setLabelRequest.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return toApiLabel(dbLabel), nil
+}
+
+func (api *api) ListLabels(entityType string) ([]Label, error) {
+	dbLabels, err := api.labelsSvc.ListLabels(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	apiLabels := []Label{}
+	for _, dbLabel := range dbLabels {
+		apiLabels = append(apiLabels, *toApiLabel(&dbLabel))
+	}
+	return apiLabels, nil
+}
+
+// getLabels returns the labels of the given entities, or an empty map if they cannot be loaded
+// so that listing entities does not fail because of their labels
+func (api *api) getLabels(entityType string, entityIds []string) map[string]db.Label {
+	entityLabels, err := api.labelsSvc.GetLabels(entityType, entityIds)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("entity_type", entityType).Error("Failed to load labels")
+		return map[string]db.Label{}
+	}
+	return entityLabels
+}
+
+func (api *api) getTransactionLabels(transactionIds []uint) map[string]db.Label {
+	entityIds := []string{}
+	for _, id := range transactionIds {
+		entityIds = append(entityIds, strconv.FormatUint(uint64(id), 10))
+	}
+	return api.getLabels(constants.LABEL_ENTITY_TYPE_TRANSACTION, entityIds)
+}
+
+func toApiLabel(dbLabel *db.Label) *Label {
+	if dbLabel == nil {
+		return nil
+	}
+	return &Label{
+		EntityType: dbLabel.EntityType,
+		EntityId:   dbLabel.EntityId,
+		Label:      dbLabel.Label,
+		Note:       dbLabel.Note,
+		Tags:       labels.GetTags(dbLabel),
+		UpdatedAt:  dbLabel.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func findLabel(entityLabels map[string]db.Label, entityId string) *db.Label {
+	dbLabel, ok := entityLabels[entityId]
+	if !ok {
+		return nil
+	}
+	return &dbLabel
+}
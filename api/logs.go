@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/logger"
+)
+
+const (
+	defaultLogQueryLimit = 100
+	maxLogQueryLimit     = 1000
+)
+
+// QueryLogs searches the app log file including its rotated backups
+func (api *api) QueryLogs(ctx context.Context, limit uint64, filters LogFilters) (*QueryLogsResponse, error) {
+	logFilePath := logger.GetLogFilePath()
+	if logFilePath == "" {
+		return nil, errors.New("file log is disabled")
+	}
+
+	query, err := toLogQuery(filters)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = defaultLogQueryLimit
+	if limit > 0 {
+		query.Limit = int(min(limit, maxLogQueryLimit))
+	}
+
+	entries, truncated, err := logger.QueryLogFile(logFilePath, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &QueryLogsResponse{
+		Entries:   make([]LogEntry, 0, len(entries)),
+		Truncated: truncated,
+	}
+	for i := range entries {
+		response.Entries = append(response.Entries, toApiLogEntry(&entries[i]))
+	}
+	return response, nil
+}
+
+// TailLogs passes new log entries matching the filters to onEntry until the context
+// is cancelled or onEntry returns an error
+func (api *api) TailLogs(ctx context.Context, filters LogFilters, onEntry func(entry *LogEntry) error) error {
+	query, err := toLogQuery(filters)
+	if err != nil {
+		return err
+	}
+
+	entries, unsubscribe := logger.SubscribeLogs()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-entries:
+			if !query.Matches(entry) {
+				continue
+			}
+			apiEntry := toApiLogEntry(entry)
+			err := onEntry(&apiEntry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toLogQuery(filters LogFilters) (*logger.LogQuery, error) {
+	query := logger.NewLogQuery()
+	if filters.Level != "" {
+		level, err := logrus.ParseLevel(filters.Level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level: '%s'", filters.Level)
+		}
+		query.Level = level
+	}
+	query.From = filters.From
+	query.Until = filters.Until
+	query.Fields = filters.Fields
+	query.Search = filters.Search
+	return query, nil
+}
+
+func toApiLogEntry(entry *logger.LogEntry) LogEntry {
+	return LogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level,
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/getAlby/hub/alby"
 	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/lsp"
@@ -37,7 +41,8 @@ func (api *api) RequestLSPOrder(ctx context.Context, request *LSPOrderRequest) (
 	}
 
 	logger.Logger.Info("Requesting LSP info")
-	lspInfo, err := api.albyOAuthSvc.GetLSPInfo(ctx, request.LSPIdentifier, nodeInfo.Network)
+	lspClient := api.svc.GetLSPService().GetLSPS1Client(request.LSPIdentifier, nodeInfo.Network)
+	lspInfo, err := lspClient.GetInfo(ctx)
 
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to request LSP info")
@@ -57,30 +62,35 @@ func (api *api) RequestLSPOrder(ctx context.Context, request *LSPOrderRequest) (
 		return nil, err
 	}
 
-	invoice, fee, err := api.requestLSPS1Invoice(ctx, request, nodeInfo.Network, nodeInfo.Pubkey, lspInfo.MaxChannelExpiryBlocks, lspInfo.MinRequiredChannelConfirmations, lspInfo.MinFundingConfirmsWithinBlocks)
-	invoiceAmount := uint64(0)
-	incomingLiquidity := request.Amount
+	refundAddress, err := api.svc.GetLNClient().GetNewOnchainAddress(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to request onchain address")
+		return nil, err
+	}
 
+	order, err := api.createLSPS1Order(ctx, lspClient, lspInfo, request.LSPIdentifier, request.Amount, request.Public, nodeInfo.Network, nodeInfo.Pubkey, refundAddress)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to request invoice")
 		return nil, err
 	}
 
-	if invoice != "" {
-		paymentRequest, err := decodepay.Decodepay(invoice)
-		if err != nil {
-			logger.Logger.WithError(err).Error("Failed to decode bolt11 invoice")
-			return nil, err
-		}
+	order.Accepted = true
+	err = api.svc.GetLSPService().SaveOrder(order)
+	if err != nil {
+		// the order was created by the LSP, so the invoice can still be paid
+		logger.Logger.WithError(err).Error("Failed to save LSP order")
+	}
 
-		invoiceAmount = uint64(paymentRequest.MSatoshi / 1000)
+	invoiceAmount, err := decodeInvoiceAmount(order.Invoice)
+	if err != nil {
+		return nil, err
 	}
 
 	newChannelResponse := &LSPOrderResponse{
-		Invoice:           invoice,
-		Fee:               fee,
+		Invoice:           order.Invoice,
+		Fee:               order.Fee,
 		InvoiceAmount:     invoiceAmount,
-		IncomingLiquidity: incomingLiquidity,
+		IncomingLiquidity: request.Amount,
 		OutgoingLiquidity: uint64(0), // JIT channel no longer supported
 	}
 
@@ -91,18 +101,12 @@ func (api *api) RequestLSPOrder(ctx context.Context, request *LSPOrderRequest) (
 	return newChannelResponse, nil
 }
 
-func (api *api) requestLSPS1Invoice(ctx context.Context, request *LSPOrderRequest, network, pubkey string, channelExpiryBlocks uint64, minRequiredChannelConfirmations uint64, minFundingConfirmsWithinBlocks uint64) (invoice string, fee uint64, err error) {
-	refundAddress, err := api.svc.GetLNClient().GetNewOnchainAddress(ctx)
-	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to request onchain address")
-		return "", 0, err
-	}
-
+func (api *api) getRequiredChannelConfirmations(lspInfo *alby.LSPInfo, public bool) (uint64, error) {
 	var requiredChannelConfirmations uint64 = 0
 
 	backendType, err := api.cfg.Get("LNBackendType", "")
 	if err != nil {
-		return "", 0, errors.New("failed to get LN backend type")
+		return 0, errors.New("failed to get LN backend type")
 	}
 
 	if backendType != config.LDKBackendType {
@@ -111,50 +115,286 @@ func (api *api) requestLSPS1Invoice(ctx context.Context, request *LSPOrderReques
 	}
 
 	// Some LSPs (e.g. Olympus) require more min confirmations, as per the spec ours must be at least as many blocks
-	requiredChannelConfirmations = max(requiredChannelConfirmations, minRequiredChannelConfirmations)
+	requiredChannelConfirmations = max(requiredChannelConfirmations, lspInfo.MinRequiredChannelConfirmations)
 
-	if request.Public {
+	if public {
 		// as per BOLT-7 6 confirmations are required for the channel to be gossiped
 		// https://github.com/lightning/bolts/blob/master/07-routing-gossip.md#requirements
 		requiredChannelConfirmations = 6
 	}
+	return requiredChannelConfirmations, nil
+}
+
+func (api *api) createLSPS1Order(ctx context.Context, lspClient lsp.LSPS1Client, lspInfo *alby.LSPInfo, lspIdentifier string, amount uint64, public bool, network, pubkey, refundAddress string) (*db.LSPOrder, error) {
+	requiredChannelConfirmations, err := api.getRequiredChannelConfirmations(lspInfo, public)
+	if err != nil {
+		return nil, err
+	}
 
 	token := ""
-	if request.LSPIdentifier == "olympus" {
+	if lspIdentifier == "olympus" {
 		token = "AlbyHub/" + version.Tag
 	}
 
 	// set a non-empty token to notify LNServer that we support 0-conf
 	// (Pre-v1.17.2 does not support 0-conf)
-	if request.LSPIdentifier == "lnserver" {
+	if lspIdentifier == "lnserver" {
 		token = "AlbyHub/" + version.Tag
 	}
 
 	lsps1ChannelRequest := &alby.LSPChannelRequest{
 		PublicKey:                    pubkey,
-		LSPBalanceSat:                strconv.FormatUint(request.Amount, 10),
+		LSPBalanceSat:                strconv.FormatUint(amount, 10),
 		ClientBalanceSat:             "0",
 		RequiredChannelConfirmations: requiredChannelConfirmations,
-		FundingConfirmsWithinBlocks:  minFundingConfirmsWithinBlocks,
-		ChannelExpiryBlocks:          channelExpiryBlocks,
+		FundingConfirmsWithinBlocks:  lspInfo.MinFundingConfirmsWithinBlocks,
+		ChannelExpiryBlocks:          lspInfo.MaxChannelExpiryBlocks,
 		Token:                        token,
 		RefundOnchainAddress:         refundAddress,
-		AnnounceChannel:              request.Public,
+		AnnounceChannel:              public,
 	}
 
-	channelResponse, err := api.albyOAuthSvc.CreateLSPOrder(ctx, request.LSPIdentifier, network, lsps1ChannelRequest)
+	channelResponse, err := lspClient.CreateOrder(ctx, lsps1ChannelRequest)
 	if err != nil {
-		return "", 0, err
+		return nil, err
+	}
+	if channelResponse.Payment == nil {
+		return nil, errors.New("LSP order has no payment details")
 	}
 
-	invoice = channelResponse.Payment.Bolt11.Invoice
-	fee, err = strconv.ParseUint(channelResponse.Payment.Bolt11.FeeTotalSat, 10, 64)
+	fee, err := strconv.ParseUint(channelResponse.Payment.Bolt11.FeeTotalSat, 10, 64)
 	if err != nil {
 		logger.Logger.WithError(err).WithFields(logrus.Fields{
-			"lspIdentifier": request.LSPIdentifier,
+			"lspIdentifier": lspIdentifier,
 		}).Error("Failed to parse fee")
-		return "", 0, fmt.Errorf("failed to parse fee %v", err)
+		return nil, fmt.Errorf("failed to parse fee %v", err)
+	}
+
+	orderState := channelResponse.OrderState
+	if orderState == "" {
+		orderState = lsp.LSPS1_ORDER_STATE_CREATED
+	}
+	channelExpiryBlocks := channelResponse.ChannelExpiryBlocks
+	if channelExpiryBlocks == 0 {
+		channelExpiryBlocks = lsps1ChannelRequest.ChannelExpiryBlocks
 	}
 
-	return invoice, fee, nil
+	return &db.LSPOrder{
+		LSPIdentifier:                lspIdentifier,
+		Network:                      network,
+		OrderId:                      channelResponse.OrderId,
+		OrderState:                   orderState,
+		PaymentState:                 channelResponse.Payment.Bolt11.State,
+		LSPPubkey:                    lspInfo.Pubkey,
+		LSPAddress:                   lspInfo.Address,
+		LSPPort:                      lspInfo.Port,
+		Amount:                       amount,
+		Fee:                          fee,
+		Invoice:                      channelResponse.Payment.Bolt11.Invoice,
+		Public:                       public,
+		ChannelExpiryBlocks:          channelExpiryBlocks,
+		RequiredChannelConfirmations: requiredChannelConfirmations,
+		FundingConfirmsWithinBlocks:  lsps1ChannelRequest.FundingConfirmsWithinBlocks,
+	}, nil
+}
+
+// GetLSPQuotes compares the LSPs for a channel of the given size using their get_info
+// options. No order is created until the user requests a channel from the chosen LSP
+// with RequestLSPOrder, as LSPs keep every created order.
+func (api *api) GetLSPQuotes(ctx context.Context, request *LSPQuotesRequest) (*LSPQuotesResponse, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	if request.Amount == 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	nodeInfo, err := api.svc.GetLNClient().GetInfo(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to request own node info")
+		return nil, err
+	}
+
+	lsps, err := api.getQuotableLSPs(ctx, request, nodeInfo.Network)
+	if err != nil {
+		return nil, err
+	}
+	if len(lsps) == 0 {
+		return nil, errors.New("no LSPs available")
+	}
+
+	quotes := make([]LSPQuote, len(lsps))
+	var wg sync.WaitGroup
+	for i, registeredLSP := range lsps {
+		wg.Add(1)
+		go func(i int, registeredLSP db.LSP) {
+			defer wg.Done()
+			quotes[i] = api.getLSPQuote(ctx, &registeredLSP, request, nodeInfo.Network)
+		}(i, registeredLSP)
+	}
+	wg.Wait()
+
+	// failed quotes last
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Error == "" && quotes[j].Error != ""
+	})
+
+	return &LSPQuotesResponse{Quotes: quotes}, nil
+}
+
+// getQuotableLSPs returns the LSPs registered for the network, or the LSPS1 channel
+// partners suggested by Alby if none have been registered
+func (api *api) getQuotableLSPs(ctx context.Context, request *LSPQuotesRequest, network string) ([]db.LSP, error) {
+	lsps, err := api.svc.GetLSPService().ListNetworkLSPs(network)
+	if err != nil {
+		return nil, err
+	}
+	if len(lsps) > 0 {
+		return lsps, nil
+	}
+
+	suggestions, err := api.albySvc.GetChannelPeerSuggestions(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to fetch channel peer suggestions")
+		return nil, err
+	}
+
+	for _, suggestion := range suggestions {
+		if suggestion.Type != lsp.LSP_TYPE_LSPS1 || suggestion.Network != network || suggestion.Identifier == "" {
+			continue
+		}
+		if request.Public && !suggestion.PublicChannelsAllowed {
+			continue
+		}
+		if request.Amount < suggestion.MinimumChannelSize || (suggestion.MaximumChannelSize > 0 && request.Amount > suggestion.MaximumChannelSize) {
+			continue
+		}
+		lsps = append(lsps, db.LSP{
+			Name:       suggestion.Name,
+			Identifier: suggestion.Identifier,
+		})
+	}
+	return lsps, nil
+}
+
+func (api *api) getLSPQuote(ctx context.Context, registeredLSP *db.LSP, request *LSPQuotesRequest, network string) LSPQuote {
+	quote := LSPQuote{
+		LSPIdentifier: registeredLSP.Identifier,
+		LSPName:       registeredLSP.Name,
+	}
+
+	lspClient := lsp.NewLSPS1Client(registeredLSP, network, api.albyOAuthSvc)
+	lspInfo, err := lspClient.GetInfo(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("lspIdentifier", registeredLSP.Identifier).Error("Failed to request LSP info")
+		quote.Error = err.Error()
+		return quote
+	}
+
+	if request.Amount < lspInfo.MinInitialLSPBalanceSat || (lspInfo.MaxInitialLSPBalanceSat > 0 && request.Amount > lspInfo.MaxInitialLSPBalanceSat) {
+		quote.Error = fmt.Sprintf("channel size must be between %d and %d sats", lspInfo.MinInitialLSPBalanceSat, lspInfo.MaxInitialLSPBalanceSat)
+		return quote
+	}
+
+	requiredChannelConfirmations, err := api.getRequiredChannelConfirmations(lspInfo, request.Public)
+	if err != nil {
+		quote.Error = err.Error()
+		return quote
+	}
+
+	quote.MinChannelSize = lspInfo.MinInitialLSPBalanceSat
+	quote.MaxChannelSize = lspInfo.MaxInitialLSPBalanceSat
+	quote.LeaseDurationBlocks = lspInfo.MaxChannelExpiryBlocks
+	quote.MinConfirmations = requiredChannelConfirmations
+	quote.FundingConfirmsWithinBlocks = lspInfo.MinFundingConfirmsWithinBlocks
+	return quote
+}
+
+func (api *api) ListLSPOrders() ([]LSPOrder, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	orders, err := api.svc.GetLSPService().ListOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	apiOrders := []LSPOrder{}
+	for _, order := range orders {
+		apiOrders = append(apiOrders, LSPOrder{
+			Id:                           order.ID,
+			LSPIdentifier:                order.LSPIdentifier,
+			OrderId:                      order.OrderId,
+			OrderState:                   order.OrderState,
+			PaymentState:                 order.PaymentState,
+			Amount:                       order.Amount,
+			Fee:                          order.Fee,
+			Public:                       order.Public,
+			ChannelExpiryBlocks:          order.ChannelExpiryBlocks,
+			RequiredChannelConfirmations: order.RequiredChannelConfirmations,
+			FundingOutpoint:              order.FundingOutpoint,
+			CreatedAt:                    order.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:                    order.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return apiOrders, nil
+}
+
+func (api *api) ListLSPs() ([]LSP, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	lsps, err := api.svc.GetLSPService().ListLSPs()
+	if err != nil {
+		return nil, err
+	}
+
+	apiLSPs := []LSP{}
+	for _, registeredLSP := range lsps {
+		apiLSPs = append(apiLSPs, toApiLSP(&registeredLSP))
+	}
+	return apiLSPs, nil
+}
+
+func (api *api) AddLSP(request *AddLSPRequest) (*LSP, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	registeredLSP, err := api.svc.GetLSPService().AddLSP(request.Name, request.Identifier, request.Network, request.Pubkey, request.Url)
+	if err != nil {
+		return nil, err
+	}
+	apiLSP := toApiLSP(registeredLSP)
+	return &apiLSP, nil
+}
+
+func (api *api) DeleteLSP(id uint) error {
+	if api.svc.GetLNClient() == nil {
+		return errors.New("LNClient not started")
+	}
+	return api.svc.GetLSPService().DeleteLSP(id)
+}
+
+func toApiLSP(registeredLSP *db.LSP) LSP {
+	return LSP{
+		Id:         registeredLSP.ID,
+		Name:       registeredLSP.Name,
+		Identifier: registeredLSP.Identifier,
+		Network:    registeredLSP.Network,
+		Pubkey:     registeredLSP.Pubkey,
+		Url:        registeredLSP.Url,
+		CreatedAt:  registeredLSP.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func decodeInvoiceAmount(invoice string) (uint64, error) {
+	if invoice == "" {
+		return 0, nil
+	}
+	paymentRequest, err := decodepay.Decodepay(invoice)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to decode bolt11 invoice")
+		return 0, err
+	}
+	return uint64(paymentRequest.MSatoshi / 1000), nil
 }
@@ -2,12 +2,16 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"time"
 
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/backups"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/retention"
 	"github.com/getAlby/hub/swaps"
 )
 
@@ -16,11 +20,14 @@ type API interface {
 	UpdateApp(app *db.App, updateAppRequest *UpdateAppRequest) error
 	Transfer(ctx context.Context, fromAppId *uint, toAppId *uint, amountMsat uint64) error
 	DeleteApp(app *db.App) error
+	RotateAppKeys(app *db.App, rotateAppKeysRequest *RotateAppKeysRequest) (*RotateAppKeysResponse, error)
+	RotateAllAppKeys(rotateAppKeysRequest *RotateAppKeysRequest) (*RotateAllAppKeysResponse, error)
+	RotateNostrKey(rotateNostrKeyRequest *RotateNostrKeyRequest) (*RotateNostrKeyResponse, error)
 	GetApp(app *db.App) *App
 	ListApps(limit uint64, offset uint64, filters ListAppsFilters, orderBy string) (*ListAppsResponse, error)
 	CreateLightningAddress(ctx context.Context, createLightningAddressRequest *CreateLightningAddressRequest) error
 	DeleteLightningAddress(ctx context.Context, appId uint) error
-	ListChannels(ctx context.Context) ([]Channel, error)
+	ListChannels(ctx context.Context, filters LabelFilters) ([]Channel, error)
 	GetChannelPeerSuggestions(ctx context.Context) ([]alby.ChannelPeerSuggestion, error)
 	GetLSPChannelOffer(ctx context.Context) (*alby.LSPChannelOffer, error)
 	ResetRouter(key string) error
@@ -29,12 +36,18 @@ type API interface {
 	Stop() error
 	GetNodeConnectionInfo(ctx context.Context) (*lnclient.NodeConnectionInfo, error)
 	GetNodeStatus(ctx context.Context) (*lnclient.NodeStatus, error)
-	ListPeers(ctx context.Context) ([]lnclient.PeerDetails, error)
+	ListPeers(ctx context.Context, filters LabelFilters) ([]Peer, error)
 	ConnectPeer(ctx context.Context, connectPeerRequest *ConnectPeerRequest) error
 	DisconnectPeer(ctx context.Context, peerId string) error
 	OpenChannel(ctx context.Context, openChannelRequest *OpenChannelRequest) (*OpenChannelResponse, error)
+	OpenChannels(ctx context.Context, openChannelsRequest *OpenChannelsRequest) (*OpenChannelsResponse, error)
 	RebalanceChannel(ctx context.Context, rebalanceChannelRequest *RebalanceChannelRequest) (*RebalanceChannelResponse, error)
-	CloseChannel(ctx context.Context, peerId, channelId string, force bool) (*CloseChannelResponse, error)
+	CloseChannel(ctx context.Context, peerId, channelId string, closeChannelRequest *CloseChannelRequest) (*CloseChannelResponse, error)
+	GetWatchtowerStatus(ctx context.Context) (*WatchtowerStatusResponse, error)
+	AddWatchtower(ctx context.Context, addWatchtowerRequest *AddWatchtowerRequest) error
+	RemoveWatchtower(ctx context.Context, pubkey string) error
+	ListPendingChannelCloses() ([]PendingChannelClose, error)
+	CancelPendingChannelClose(id uint) error
 	UpdateChannel(ctx context.Context, updateChannelRequest *UpdateChannelRequest) error
 	MakeOffer(ctx context.Context, description string) (string, error)
 	GetNewOnchainAddress(ctx context.Context) (string, error)
@@ -42,8 +55,9 @@ type API interface {
 	SignMessage(ctx context.Context, message string) (*SignMessageResponse, error)
 	RedeemOnchainFunds(ctx context.Context, toAddress string, amount uint64, feeRate *uint64, sendAll bool) (*RedeemOnchainFundsResponse, error)
 	GetBalances(ctx context.Context) (*BalancesResponse, error)
-	ListTransactions(ctx context.Context, appId *uint, limit uint64, offset uint64) (*ListTransactionsResponse, error)
-	ListOnchainTransactions(ctx context.Context) ([]lnclient.OnchainTransaction, error)
+	ListTransactions(ctx context.Context, appId *uint, limit uint64, offset uint64, filters ListTransactionsFilters) (*ListTransactionsResponse, error)
+	ListOnchainTransactions(ctx context.Context, filters LabelFilters) ([]OnchainTransaction, error)
+	ListHoldInvoices(ctx context.Context) ([]HoldInvoice, error)
 	SendPayment(ctx context.Context, invoice string, amountMsat *uint64, metadata map[string]interface{}) (*SendPaymentResponse, error)
 	CreateInvoice(ctx context.Context, amount uint64, description string) (*MakeInvoiceResponse, error)
 	LookupInvoice(ctx context.Context, paymentHash string) (*LookupInvoiceResponse, error)
@@ -58,9 +72,30 @@ type API interface {
 	GetNetworkGraph(ctx context.Context, nodeIds []string) (NetworkGraphResponse, error)
 	SyncWallet() error
 	GetLogOutput(ctx context.Context, logType string, getLogRequest *GetLogOutputRequest) (*GetLogOutputResponse, error)
+	QueryLogs(ctx context.Context, limit uint64, filters LogFilters) (*QueryLogsResponse, error)
+	TailLogs(ctx context.Context, filters LogFilters, onEntry func(entry *LogEntry) error) error
 	RequestLSPOrder(ctx context.Context, request *LSPOrderRequest) (*LSPOrderResponse, error)
+	ListLSPs() ([]LSP, error)
+	AddLSP(request *AddLSPRequest) (*LSP, error)
+	DeleteLSP(id uint) error
+	GetLSPQuotes(ctx context.Context, request *LSPQuotesRequest) (*LSPQuotesResponse, error)
+	ListLSPOrders() ([]LSPOrder, error)
+	ListPendingPaymentApprovals() ([]PaymentApproval, error)
+	SetLabel(entityType string, entityId string, setLabelRequest *SetLabelRequest) (*Label, error)
+	ListLabels(entityType string) ([]Label, error)
+	ParseWalletAuthUri(uri string) (*WalletAuthRequest, error)
+	AcceptWalletAuthRequest(request *AcceptWalletAuthRequest) (*CreateAppResponse, error)
+	GetArchivedRequest(nostrId string) (*ArchivedRequest, error)
+	ReplayArchivedRequest(ctx context.Context, nostrId string) (*ReplayArchivedRequestResponse, error)
+	ApprovePayment(id uint) error
+	DenyPayment(id uint) error
 	CreateBackup(unlockPassword string, w io.Writer) error
 	RestoreBackup(unlockPassword string, r io.Reader) error
+	VerifyBackup(unlockPassword string, r io.Reader) (*BackupVerificationReport, error)
+	ListAutoBackups(ctx context.Context) ([]AutoBackup, error)
+	CreateAutoBackup(ctx context.Context) (*AutoBackup, error)
+	GetRetention() *RetentionResponse
+	PruneData(ctx context.Context) (*RetentionReport, error)
 	MigrateNodeStorage(ctx context.Context, to string) error
 	GetWalletCapabilities(ctx context.Context) (*WalletCapabilitiesResponse, error)
 	Health(ctx context.Context) (*HealthResponse, error)
@@ -84,23 +119,28 @@ type API interface {
 }
 
 type App struct {
-	ID                 uint       `json:"id"`
-	Name               string     `json:"name"`
-	Description        string     `json:"description"`
-	AppPubkey          string     `json:"appPubkey"`
-	CreatedAt          time.Time  `json:"createdAt"`
-	UpdatedAt          time.Time  `json:"updatedAt"`
-	LastUsedAt         *time.Time `json:"lastUsedAt"`
-	ExpiresAt          *time.Time `json:"expiresAt"`
-	Scopes             []string   `json:"scopes"`
-	MaxAmountSat       uint64     `json:"maxAmount"`
-	BudgetUsage        uint64     `json:"budgetUsage"`
-	BudgetRenewal      string     `json:"budgetRenewal"`
-	Isolated           bool       `json:"isolated"`
-	WalletPubkey       string     `json:"walletPubkey"`
-	UniqueWalletPubkey bool       `json:"uniqueWalletPubkey"`
-	Balance            int64      `json:"balance"`
-	Metadata           Metadata   `json:"metadata,omitempty"`
+	ID                   uint       `json:"id"`
+	Name                 string     `json:"name"`
+	Description          string     `json:"description"`
+	AppPubkey            string     `json:"appPubkey"`
+	CreatedAt            time.Time  `json:"createdAt"`
+	UpdatedAt            time.Time  `json:"updatedAt"`
+	LastUsedAt           *time.Time `json:"lastUsedAt"`
+	ExpiresAt            *time.Time `json:"expiresAt"`
+	Scopes               []string   `json:"scopes"`
+	MaxAmountSat         uint64     `json:"maxAmount"`
+	BudgetUsage          uint64     `json:"budgetUsage"`
+	BudgetRenewal        string     `json:"budgetRenewal"`
+	Isolated             bool       `json:"isolated"`
+	WalletPubkey         string     `json:"walletPubkey"`
+	UniqueWalletPubkey   bool       `json:"uniqueWalletPubkey"`
+	Balance              int64      `json:"balance"`
+	Metadata             Metadata   `json:"metadata,omitempty"`
+	ApprovalThresholdSat uint64     `json:"approvalThreshold"`
+	ApproveOverBudget    bool       `json:"approveOverBudget"`
+	ArchiveRequests      bool       `json:"archiveRequests"`
+	// set while the connection replaced by a key rotation still works
+	PreviousKeysExpireAt *time.Time `json:"previousKeysExpireAt"`
 }
 
 type ListAppsFilters struct {
@@ -123,6 +163,10 @@ type UpdateAppRequest struct {
 	Scopes        []string `json:"scopes"`
 	Metadata      Metadata `json:"metadata,omitempty"`
 	Isolated      bool     `json:"isolated"`
+	// optional, existing values are kept if not set
+	ApprovalThresholdSat *uint64 `json:"approvalThreshold,omitempty"`
+	ApproveOverBudget    *bool   `json:"approveOverBudget,omitempty"`
+	ArchiveRequests      *bool   `json:"archiveRequests,omitempty"`
 }
 
 type TransferRequest struct {
@@ -144,6 +188,31 @@ type CreateAppRequest struct {
 	UnlockPassword string   `json:"unlockPassword"`
 }
 
+type ParseWalletAuthUriRequest struct {
+	Uri string `json:"uri"`
+}
+
+type WalletAuthRequest struct {
+	AppPubkey         string     `json:"appPubkey"`
+	Relays            []string   `json:"relays"`
+	Name              string     `json:"name"`
+	Icon              string     `json:"icon"`
+	ReturnTo          string     `json:"returnTo"`
+	RequestMethods    []string   `json:"requestMethods"`
+	NotificationTypes []string   `json:"notificationTypes"`
+	Scopes            []string   `json:"scopes"`
+	MaxAmountSat      uint64     `json:"maxAmount"`
+	BudgetRenewal     string     `json:"budgetRenewal"`
+	ExpiresAt         *time.Time `json:"expiresAt"`
+	Isolated          bool       `json:"isolated"`
+	Metadata          Metadata   `json:"metadata,omitempty"`
+}
+
+type AcceptWalletAuthRequest struct {
+	Uri            string `json:"uri"`
+	UnlockPassword string `json:"unlockPassword"`
+}
+
 type CreateLightningAddressRequest struct {
 	Address string `json:"address"`
 	AppId   uint   `json:"appId"`
@@ -260,6 +329,42 @@ type CreateAppResponse struct {
 	ReturnTo      string `json:"returnTo"`
 }
 
+type RotateAppKeysRequest struct {
+	// optional, how long the replaced connection keeps working (72 hours if not set)
+	GracePeriodHours *uint `json:"gracePeriodHours,omitempty"`
+}
+
+type RotateAppKeysResponse struct {
+	Id                   uint      `json:"id"`
+	Name                 string    `json:"name"`
+	PairingUri           string    `json:"pairingUri"`
+	PairingSecret        string    `json:"pairingSecretKey"`
+	Pubkey               string    `json:"pairingPublicKey"`
+	WalletPubkey         string    `json:"walletPubkey"`
+	PreviousKeysExpireAt time.Time `json:"previousKeysExpireAt"`
+}
+
+type RotateAllAppKeysResponse struct {
+	Apps []RotateAppKeysResponse `json:"apps"`
+	// names of apps which cannot be rotated and need to be reconnected instead
+	Skipped []string `json:"skipped"`
+}
+
+type RotateNostrKeyRequest struct {
+	UnlockPassword string `json:"unlockPassword"`
+	// optional, how long legacy connections using the previous key keep working (72 hours if not set)
+	GracePeriodHours *uint `json:"gracePeriodHours,omitempty"`
+}
+
+type RotateNostrKeyResponse struct {
+	Pubkey               string    `json:"pubkey"`
+	PreviousPubkey       string    `json:"previousPubkey"`
+	PreviousKeyExpiresAt time.Time `json:"previousKeyExpiresAt"`
+	// legacy app connections use the hub key as wallet key and have to be
+	// updated with the new key or have their app keys rotated before the previous key expires
+	LegacyAppCount int64 `json:"legacyAppCount"`
+}
+
 type User struct {
 	Email string `json:"email"`
 }
@@ -317,9 +422,59 @@ type AutoUnlockRequest struct {
 type ConnectPeerRequest = lnclient.ConnectPeerRequest
 type OpenChannelRequest = lnclient.OpenChannelRequest
 type OpenChannelResponse = lnclient.OpenChannelResponse
-type CloseChannelResponse = lnclient.CloseChannelResponse
+type OpenChannelsRequest = lnclient.OpenChannelsRequest
+type OpenChannelsResponse = lnclient.OpenChannelsResponse
 type UpdateChannelRequest = lnclient.UpdateChannelRequest
 
+type CloseChannelRequest struct {
+	Force bool
+	// fee rate in sat/vB for cooperative closes
+	FeeRate *uint64
+	// on-chain address or xpub to send our channel balance to
+	Address string
+	// if set, the close is scheduled until the mempool fee rate (sat/vB) is at or below this value
+	MaxFeeRate *uint64
+}
+
+type CloseChannelResponse struct {
+	PendingClose *PendingChannelClose `json:"pendingClose,omitempty"`
+}
+
+type PendingChannelClose struct {
+	Id         uint    `json:"id"`
+	NodeId     string  `json:"nodeId"`
+	ChannelId  string  `json:"channelId"`
+	MaxFeeRate uint64  `json:"maxFeeRate"`
+	FeeRate    *uint64 `json:"feeRate"`
+	Address    string  `json:"address"`
+	State      string  `json:"state"`
+	Attempts   int     `json:"attempts"`
+	Error      string  `json:"error"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type AddWatchtowerRequest = lnclient.AddWatchtowerRequest
+
+type Watchtower struct {
+	Pubkey                 string   `json:"pubkey"`
+	Addresses              []string `json:"addresses"`
+	ActiveSessionCandidate bool     `json:"activeSessionCandidate"`
+	NumSessions            uint32   `json:"numSessions"`
+	NumUsableSessions      uint32   `json:"numUsableSessions"`
+	NumBackups             uint32   `json:"numBackups"`
+	NumPendingBackups      uint32   `json:"numPendingBackups"`
+}
+
+type WatchtowerStatusResponse struct {
+	Towers               []Watchtower `json:"towers"`
+	NumBackups           uint32       `json:"numBackups"`
+	NumPendingBackups    uint32       `json:"numPendingBackups"`
+	NumFailedBackups     uint32       `json:"numFailedBackups"`
+	NumSessionsAcquired  uint32       `json:"numSessionsAcquired"`
+	NumSessionsExhausted uint32       `json:"numSessionsExhausted"`
+}
+
 type RebalanceChannelRequest struct {
 	ReceiveThroughNodePubkey string `json:"receiveThroughNodePubkey"`
 	AmountSat                uint64 `json:"amountSat"`
@@ -346,13 +501,45 @@ type SendPaymentResponse = Transaction
 type MakeInvoiceResponse = Transaction
 type LookupInvoiceResponse = Transaction
 
+type ListTransactionsFilters struct {
+	// empty to start paging by creation time with nextCursor, omit for offset paging
+	Cursor        *string           `json:"cursor"`
+	Type          string            `json:"type"`
+	States        []string          `json:"states"`
+	MinAmountMsat uint64            `json:"minAmountMsat"`
+	MaxAmountMsat uint64            `json:"maxAmountMsat"`
+	Metadata      map[string]string `json:"metadata"`
+	PayerName     string            `json:"payerName"`
+	Recipient     string            `json:"recipient"`
+	NostrEventId  string            `json:"nostrEventId"`
+	Tag           string            `json:"tag"`
+	Search        string            `json:"search"`
+	Label         string            `json:"label"`
+	LabelTag      string            `json:"labelTag"`
+}
+
+// HoldInvoice is an accepted hold invoice which was not yet settled or canceled
+type HoldInvoice struct {
+	Transaction
+	SettleDeadline uint32 `json:"settleDeadline"`
+	// nil if auto-cancel is disabled
+	AutoCancelHeight *uint32 `json:"autoCancelHeight"`
+	// blocks until the hold invoice is auto-canceled, or until the settle deadline if auto-cancel is disabled
+	BlocksRemaining int64 `json:"blocksRemaining"`
+	// assuming 10 minute blocks
+	EstimatedSecondsRemaining int64   `json:"estimatedSecondsRemaining"`
+	ExpiryNotifiedAt          *string `json:"expiryNotifiedAt"`
+}
+
 type ListTransactionsResponse struct {
 	TotalCount   uint64        `json:"totalCount"`
 	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
 }
 
 // TODO: camelCase
 type Transaction struct {
+	Id              uint        `json:"id"`
 	Type            string      `json:"type"`
 	State           string      `json:"state"`
 	Invoice         string      `json:"invoice"`
@@ -369,6 +556,7 @@ type Transaction struct {
 	Metadata        Metadata    `json:"metadata,omitempty"`
 	Boostagram      *Boostagram `json:"boostagram,omitempty"`
 	FailureReason   string      `json:"failureReason"`
+	Label           *Label      `json:"label"`
 }
 
 type Metadata = map[string]interface{}
@@ -421,6 +609,27 @@ type GetLogOutputResponse struct {
 	Log string `json:"logs"`
 }
 
+type LogFilters struct {
+	Level  string            `json:"level"` // minimum level, e.g. "warning" also returns errors
+	From   *time.Time        `json:"from"`
+	Until  *time.Time        `json:"until"`
+	Fields map[string]string `json:"fields"` // e.g. {"paymentHash": "..."}
+	Search string            `json:"search"`
+}
+
+type LogEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields"`
+}
+
+type QueryLogsResponse struct {
+	Entries []LogEntry `json:"entries"`
+	// older matching entries exist
+	Truncated bool `json:"truncated"`
+}
+
 type SignMessageRequest struct {
 	Message string `json:"message"`
 }
@@ -456,6 +665,22 @@ type BasicRestoreWailsRequest struct {
 	UnlockPassword string `json:"unlockPassword"`
 }
 
+type BackupVerificationReport = backups.VerificationReport
+
+type AutoBackup struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type RetentionPolicy = retention.Policy
+type RetentionReport = retention.Report
+
+type RetentionResponse struct {
+	Policy     RetentionPolicy  `json:"policy"`
+	LastReport *RetentionReport `json:"lastReport"`
+}
+
 type NetworkGraphResponse = lnclient.NetworkGraphResponse
 
 type LSPOrderRequest struct {
@@ -473,6 +698,104 @@ type LSPOrderResponse struct {
 	OutgoingLiquidity uint64 `json:"outgoingLiquidity"`
 }
 
+type PaymentApproval struct {
+	Id             uint   `json:"id"`
+	AppId          uint   `json:"appId"`
+	AppName        string `json:"appName"`
+	Method         string `json:"method"`
+	PaymentHash    string `json:"paymentHash"`
+	PaymentRequest string `json:"paymentRequest"`
+	Destination    string `json:"destination"`
+	Description    string `json:"description"`
+	Amount         uint64 `json:"amount"`
+	Reason         string `json:"reason"`
+	State          string `json:"state"`
+	ExpiresAt      string `json:"expiresAt"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type ArchivedResponse struct {
+	NostrId   string          `json:"nostrId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+type ArchivedRequest struct {
+	AppId     uint               `json:"appId"`
+	NostrId   string             `json:"nostrId"`
+	Method    string             `json:"method"`
+	Payload   json.RawMessage    `json:"payload"`
+	CreatedAt string             `json:"createdAt"`
+	Responses []ArchivedResponse `json:"responses"`
+}
+
+type ReplayArchivedRequestResponse struct {
+	Method            string             `json:"method"`
+	PermissionGranted bool               `json:"permissionGranted"`
+	PermissionCode    string             `json:"permissionCode,omitempty"`
+	PermissionMessage string             `json:"permissionMessage,omitempty"`
+	Executed          bool               `json:"executed"`
+	Responses         []*models.Response `json:"responses"`
+}
+
+type LSP struct {
+	Id         uint   `json:"id"`
+	Name       string `json:"name"`
+	Identifier string `json:"identifier"`
+	Network    string `json:"network"`
+	Pubkey     string `json:"pubkey"`
+	Url        string `json:"url"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type AddLSPRequest struct {
+	Name       string `json:"name"`
+	Identifier string `json:"identifier"`
+	// empty to use the LSP on every network
+	Network string `json:"network"`
+	// node pubkey of the LSP, trusted for 0-conf channels
+	Pubkey string `json:"pubkey"`
+	Url    string `json:"url"`
+}
+
+type LSPQuotesRequest struct {
+	Amount uint64 `json:"amount"`
+	Public bool   `json:"public"`
+}
+
+// LSPQuote describes the channel an LSP would open. The fee is only known once
+// an order is requested from the LSP
+type LSPQuote struct {
+	LSPIdentifier               string `json:"lspIdentifier"`
+	LSPName                     string `json:"lspName"`
+	MinChannelSize              uint64 `json:"minChannelSize"`
+	MaxChannelSize              uint64 `json:"maxChannelSize"`
+	LeaseDurationBlocks         uint64 `json:"leaseDurationBlocks"`
+	MinConfirmations            uint64 `json:"minConfirmations"`
+	FundingConfirmsWithinBlocks uint64 `json:"fundingConfirmsWithinBlocks"`
+	Error                       string `json:"error,omitempty"`
+}
+
+type LSPQuotesResponse struct {
+	Quotes []LSPQuote `json:"quotes"`
+}
+
+type LSPOrder struct {
+	Id                           uint   `json:"id"`
+	LSPIdentifier                string `json:"lspIdentifier"`
+	OrderId                      string `json:"orderId"`
+	OrderState                   string `json:"orderState"`
+	PaymentState                 string `json:"paymentState"`
+	Amount                       uint64 `json:"amount"`
+	Fee                          uint64 `json:"fee"`
+	Public                       bool   `json:"public"`
+	ChannelExpiryBlocks          uint64 `json:"channelExpiryBlocks"`
+	RequiredChannelConfirmations uint64 `json:"requiredChannelConfirmations"`
+	FundingOutpoint              string `json:"fundingOutpoint"`
+	CreatedAt                    string `json:"createdAt"`
+	UpdatedAt                    string `json:"updatedAt"`
+}
+
 type WalletCapabilitiesResponse struct {
 	Scopes            []string `json:"scopes"`
 	Methods           []string `json:"methods"`
@@ -499,6 +822,7 @@ type Channel struct {
 	Error                                    *string     `json:"error"`
 	Status                                   string      `json:"status"`
 	IsOutbound                               bool        `json:"isOutbound"`
+	Label                                    *Label      `json:"label"`
 }
 
 type MigrateNodeStorageRequest struct {
@@ -508,11 +832,12 @@ type MigrateNodeStorageRequest struct {
 type HealthAlarmKind string
 
 const (
-	HealthAlarmKindAlbyService       HealthAlarmKind = "alby_service"
-	HealthAlarmKindNodeNotReady      HealthAlarmKind = "node_not_ready"
-	HealthAlarmKindChannelsOffline   HealthAlarmKind = "channels_offline"
-	HealthAlarmKindNostrRelayOffline HealthAlarmKind = "nostr_relay_offline"
-	HealthAlarmKindVssNoSubscription HealthAlarmKind = "vss_no_subscription"
+	HealthAlarmKindAlbyService         HealthAlarmKind = "alby_service"
+	HealthAlarmKindNodeNotReady        HealthAlarmKind = "node_not_ready"
+	HealthAlarmKindChannelsOffline     HealthAlarmKind = "channels_offline"
+	HealthAlarmKindNostrRelayOffline   HealthAlarmKind = "nostr_relay_offline"
+	HealthAlarmKindVssNoSubscription   HealthAlarmKind = "vss_no_subscription"
+	HealthAlarmKindWatchtowersInactive HealthAlarmKind = "watchtowers_inactive"
 )
 
 type HealthAlarm struct {
@@ -555,3 +880,33 @@ type GetForwardsResponse struct {
 	TotalFeeEarnedMsat          uint64 `json:"totalFeeEarnedMsat"`
 	NumForwards                 uint64 `json:"numForwards"`
 }
+
+type Peer struct {
+	lnclient.PeerDetails
+	Label *Label `json:"label"`
+}
+
+type OnchainTransaction struct {
+	lnclient.OnchainTransaction
+	Label *Label `json:"label"`
+}
+
+type Label struct {
+	EntityType string   `json:"entityType"`
+	EntityId   string   `json:"entityId"`
+	Label      string   `json:"label"`
+	Note       string   `json:"note"`
+	Tags       []string `json:"tags"`
+	UpdatedAt  string   `json:"updatedAt"`
+}
+
+type SetLabelRequest struct {
+	Label string   `json:"label"`
+	Note  string   `json:"note"`
+	Tags  []string `json:"tags"`
+}
+
+type LabelFilters struct {
+	Label string `json:"label"` // case-insensitive match on the label or note
+	Tag   string `json:"tag"`
+}
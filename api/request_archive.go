@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/getAlby/hub/nip47"
+)
+
+func (api *api) GetArchivedRequest(nostrId string) (*ArchivedRequest, error) {
+	archivedRequest, err := api.archiveSvc.GetArchivedRequest(nostrId)
+	if err != nil {
+		return nil, err
+	}
+
+	apiArchivedRequest := &ArchivedRequest{
+		AppId:     archivedRequest.AppId,
+		NostrId:   archivedRequest.NostrId,
+		Method:    archivedRequest.Method,
+		Payload:   json.RawMessage(archivedRequest.Payload),
+		CreatedAt: archivedRequest.CreatedAt.Format(time.RFC3339),
+		Responses: []ArchivedResponse{},
+	}
+	for _, archivedResponse := range archivedRequest.Responses {
+		apiArchivedRequest.Responses = append(apiArchivedRequest.Responses, ArchivedResponse{
+			NostrId:   archivedResponse.NostrId,
+			Payload:   json.RawMessage(archivedResponse.Payload),
+			CreatedAt: archivedResponse.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return apiArchivedRequest, nil
+}
+
+func (api *api) ReplayArchivedRequest(ctx context.Context, nostrId string) (*ReplayArchivedRequestResponse, error) {
+	if api.svc.GetLNClient() == nil {
+		return nil, errors.New("LNClient not started")
+	}
+
+	nip47Svc := nip47.NewNip47Service(api.db, api.cfg, api.keys, api.eventPublisher, api.albyOAuthSvc)
+	replayResult, err := nip47Svc.ReplayArchivedRequest(ctx, nostrId, api.svc.GetLNClient())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayArchivedRequestResponse{
+		Method:            replayResult.Method,
+		PermissionGranted: replayResult.PermissionGranted,
+		PermissionCode:    replayResult.PermissionCode,
+		PermissionMessage: replayResult.PermissionMessage,
+		Executed:          replayResult.Executed,
+		Responses:         replayResult.Responses,
+	}, nil
+}
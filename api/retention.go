@@ -0,0 +1,17 @@
+package api
+
+import (
+	"context"
+)
+
+func (api *api) GetRetention() *RetentionResponse {
+	retentionService := api.svc.GetRetentionService()
+	return &RetentionResponse{
+		Policy:     retentionService.GetPolicy(),
+		LastReport: retentionService.GetLastReport(),
+	}
+}
+
+func (api *api) PruneData(ctx context.Context) (*RetentionReport, error) {
+	return api.svc.GetRetentionService().Prune(ctx)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+)
+
+func (api *api) RotateAppKeys(userApp *db.App, rotateAppKeysRequest *RotateAppKeysRequest) (*RotateAppKeysResponse, error) {
+	if userApp.Name == alby.ALBY_ACCOUNT_APP_NAME {
+		// the connection secret is held by Alby
+		return nil, fmt.Errorf("%s keys cannot be rotated, reconnect your Alby Account instead", alby.ALBY_ACCOUNT_APP_NAME)
+	}
+
+	lightningAddress, err := api.albyOAuthSvc.GetLightningAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return api.rotateAppKeys(userApp, getKeyRotationGracePeriod(rotateAppKeysRequest.GracePeriodHours), lightningAddress)
+}
+
+func (api *api) RotateAllAppKeys(rotateAppKeysRequest *RotateAppKeysRequest) (*RotateAllAppKeysResponse, error) {
+	lightningAddress, err := api.albyOAuthSvc.GetLightningAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	dbApps := []db.App{}
+	err = api.db.Order("id").Find(&dbApps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	gracePeriod := getKeyRotationGracePeriod(rotateAppKeysRequest.GracePeriodHours)
+	response := &RotateAllAppKeysResponse{
+		Apps:    []RotateAppKeysResponse{},
+		Skipped: []string{},
+	}
+	for _, dbApp := range dbApps {
+		if dbApp.Name == alby.ALBY_ACCOUNT_APP_NAME {
+			response.Skipped = append(response.Skipped, dbApp.Name)
+			continue
+		}
+
+		rotatedApp, err := api.rotateAppKeys(&dbApp, gracePeriod, lightningAddress)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id":        dbApp.ID,
+				"rotated_count": len(response.Apps),
+			}).Error("Failed to rotate app keys")
+			return nil, fmt.Errorf("failed to rotate keys of app %s: %w", dbApp.Name, err)
+		}
+		response.Apps = append(response.Apps, *rotatedApp)
+	}
+
+	return response, nil
+}
+
+func (api *api) rotateAppKeys(dbApp *db.App, gracePeriod time.Duration, lightningAddress string) (*RotateAppKeysResponse, error) {
+	pairingSecretKey, err := api.appsSvc.RotateAppKeys(dbApp, gracePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotateAppKeysResponse{
+		Id:                   dbApp.ID,
+		Name:                 dbApp.Name,
+		PairingUri:           getPairingUri(dbApp, api.cfg.GetRelayUrl(), pairingSecretKey, lightningAddress),
+		PairingSecret:        pairingSecretKey,
+		Pubkey:               dbApp.AppPubkey,
+		WalletPubkey:         *dbApp.WalletPubkey,
+		PreviousKeysExpireAt: *dbApp.PreviousKeysExpireAt,
+	}, nil
+}
+
+// RotateNostrKey replaces the hub Nostr key, which legacy app connections use as wallet key.
+// Requests to the previous key are served until the grace period ends,
+// and the key cannot be rotated again before then.
+func (api *api) RotateNostrKey(rotateNostrKeyRequest *RotateNostrKeyRequest) (*RotateNostrKeyResponse, error) {
+	if !api.cfg.CheckUnlockPassword(rotateNostrKeyRequest.UnlockPassword) {
+		return nil, errors.New("incorrect password")
+	}
+
+	// only the key replaced by the last rotation is served, so rotating again would
+	// cut off legacy apps which did not update their connection yet
+	if api.keys.GetPreviousNostrSigner() != nil {
+		return nil, fmt.Errorf("the previous hub nostr key is served until %s, rotate the key again after it expired", api.keys.GetPreviousNostrKeyExpiresAt().Format(time.RFC3339))
+	}
+
+	previousPubkey := api.keys.GetNostrPublicKey()
+	err := api.keys.RotateNostrKey(api.cfg, rotateNostrKeyRequest.UnlockPassword, getKeyRotationGracePeriod(rotateNostrKeyRequest.GracePeriodHours))
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to rotate nostr key")
+		return nil, err
+	}
+
+	var legacyAppCount int64
+	err = api.db.Model(&db.App{}).Where("wallet_pubkey IS NULL").Count(&legacyAppCount).Error
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"pubkey":           api.keys.GetNostrPublicKey(),
+		"previous_pubkey":  previousPubkey,
+		"legacy_app_count": legacyAppCount,
+	}).Info("Rotated nostr key")
+
+	api.eventPublisher.Publish(&events.Event{
+		Event: "nwc_nostr_key_rotated",
+	})
+
+	return &RotateNostrKeyResponse{
+		Pubkey:               api.keys.GetNostrPublicKey(),
+		PreviousPubkey:       previousPubkey,
+		PreviousKeyExpiresAt: *api.keys.GetPreviousNostrKeyExpiresAt(),
+		LegacyAppCount:       legacyAppCount,
+	}, nil
+}
+
+func getKeyRotationGracePeriod(gracePeriodHours *uint) time.Duration {
+	if gracePeriodHours == nil {
+		return apps.DefaultKeyRotationGracePeriod
+	}
+	return time.Duration(*gracePeriodHours) * time.Hour
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/nostr/signer"
+	"github.com/getAlby/hub/tests/mocks"
+)
+
+func TestRotateNostrKey_PreviousKeyNotExpired(t *testing.T) {
+	cfg := mocks.NewMockConfig(t)
+	cfg.On("CheckUnlockPassword", "123").Return(true)
+	previousNostrSigner, err := signer.NewKeySigner(nostr.GeneratePrivateKey())
+	require.NoError(t, err)
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	keys := mocks.NewMockKeys(t)
+	keys.On("GetPreviousNostrSigner").Return(previousNostrSigner)
+	keys.On("GetPreviousNostrKeyExpiresAt").Return(&expiresAt)
+	theAPI := &api{cfg: cfg, keys: keys}
+
+	response, err := theAPI.RotateNostrKey(&RotateNostrKeyRequest{UnlockPassword: "123"})
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "the previous hub nostr key is served until 2030-01-02T03:04:05Z, rotate the key again after it expired")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,10 +32,12 @@ func (api *api) LookupInvoice(ctx context.Context, paymentHash string) (*LookupI
 	if err != nil {
 		return nil, err
 	}
-	return toApiTransaction(transaction), nil
+	apiTransaction := toApiTransaction(transaction)
+	apiTransaction.Label = toApiLabel(findLabel(api.getTransactionLabels([]uint{transaction.ID}), strconv.FormatUint(uint64(transaction.ID), 10)))
+	return apiTransaction, nil
 }
 
-func (api *api) ListTransactions(ctx context.Context, appId *uint, limit uint64, offset uint64) (*ListTransactionsResponse, error) {
+func (api *api) ListTransactions(ctx context.Context, appId *uint, limit uint64, offset uint64, filters ListTransactionsFilters) (*ListTransactionsResponse, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
@@ -44,27 +47,115 @@ func (api *api) ListTransactions(ctx context.Context, appId *uint, limit uint64,
 		forceFilterByAppId = true
 	}
 
-	transactions, totalCount, err := api.svc.GetTransactionsService().ListTransactions(ctx, 0, 0, limit, offset, true, false, nil, api.svc.GetLNClient(), appId, forceFilterByAppId)
+	var transactionType *string
+	if filters.Type != "" {
+		transactionType = &filters.Type
+	}
+
+	states := []string{}
+	for _, state := range filters.States {
+		states = append(states, strings.ToUpper(state))
+	}
+
+	dbTransactions, totalCount, err := api.svc.GetTransactionsService().ListTransactions(ctx, 0, 0, limit, offset, true, false, transactionType, api.svc.GetLNClient(), appId, forceFilterByAppId, transactions.ListTransactionsFilters{
+		Cursor:        filters.Cursor,
+		States:        states,
+		MinAmountMsat: filters.MinAmountMsat,
+		MaxAmountMsat: filters.MaxAmountMsat,
+		Metadata:      filters.Metadata,
+		PayerName:     filters.PayerName,
+		Recipient:     filters.Recipient,
+		NostrEventId:  filters.NostrEventId,
+		Tag:           filters.Tag,
+		Search:        filters.Search,
+		Label:         filters.Label,
+		LabelTag:      filters.LabelTag,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	transactionIds := []uint{}
+	for _, transaction := range dbTransactions {
+		transactionIds = append(transactionIds, transaction.ID)
+	}
+	transactionLabels := api.getTransactionLabels(transactionIds)
+
 	apiTransactions := []Transaction{}
-	for _, transaction := range transactions {
-		apiTransactions = append(apiTransactions, *toApiTransaction(&transaction))
+	for _, transaction := range dbTransactions {
+		apiTransaction := toApiTransaction(&transaction)
+		apiTransaction.Label = toApiLabel(findLabel(transactionLabels, strconv.FormatUint(uint64(transaction.ID), 10)))
+		apiTransactions = append(apiTransactions, *apiTransaction)
 	}
 
-	return &ListTransactionsResponse{
+	response := &ListTransactionsResponse{
 		Transactions: apiTransactions,
 		TotalCount:   totalCount,
-	}, nil
+	}
+	if filters.Cursor != nil && limit > 0 && uint64(len(dbTransactions)) == limit {
+		response.NextCursor = transactions.NewTransactionsCursor(&dbTransactions[len(dbTransactions)-1])
+	}
+	return response, nil
+}
+
+func (api *api) ListHoldInvoices(ctx context.Context) ([]HoldInvoice, error) {
+	lnClient := api.svc.GetLNClient()
+	if lnClient == nil {
+		return nil, errors.New("LNClient not started")
+	}
+
+	info, err := lnClient.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dbTransactions, err := api.svc.GetTransactionsService().ListAcceptedHoldInvoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := transactions.HoldInvoicePolicy{
+		AutoCancelBlocks:    api.cfg.GetEnv().HoldInvoiceAutoCancelBlocks,
+		ExpiryWarningBlocks: api.cfg.GetEnv().HoldInvoiceExpiryWarningBlocks,
+	}
+
+	holdInvoices := []HoldInvoice{}
+	for _, transaction := range dbTransactions {
+		if transaction.SettleDeadline == nil {
+			continue
+		}
+
+		autoCancelHeight := policy.GetAutoCancelHeight(&transaction)
+		expiryHeight := *transaction.SettleDeadline
+		if autoCancelHeight != nil {
+			expiryHeight = *autoCancelHeight
+		}
+		blocksRemaining := max(int64(expiryHeight)-int64(info.BlockHeight), 0)
+
+		var expiryNotifiedAt *string
+		if transaction.HoldExpiryNotifiedAt != nil {
+			expiryNotifiedAtValue := transaction.HoldExpiryNotifiedAt.Format(time.RFC3339)
+			expiryNotifiedAt = &expiryNotifiedAtValue
+		}
+
+		holdInvoices = append(holdInvoices, HoldInvoice{
+			Transaction:               *toApiTransaction(&transaction),
+			SettleDeadline:            *transaction.SettleDeadline,
+			AutoCancelHeight:          autoCancelHeight,
+			BlocksRemaining:           blocksRemaining,
+			EstimatedSecondsRemaining: blocksRemaining * 10 * 60,
+			ExpiryNotifiedAt:          expiryNotifiedAt,
+		})
+	}
+
+	return holdInvoices, nil
 }
 
 func (api *api) SendPayment(ctx context.Context, invoice string, amountMsat *uint64, metadata map[string]interface{}) (*SendPaymentResponse, error) {
 	if api.svc.GetLNClient() == nil {
 		return nil, errors.New("LNClient not started")
 	}
-	transaction, err := api.svc.GetTransactionsService().SendPaymentSync(invoice, amountMsat, metadata, api.svc.GetLNClient(), nil, nil)
+	transaction, err := api.svc.GetTransactionsService().SendPaymentSync(ctx, invoice, amountMsat, metadata, api.svc.GetLNClient(), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +199,7 @@ func toApiTransaction(transaction *transactions.Transaction) *Transaction {
 	}
 
 	return &Transaction{
+		Id:              transaction.ID,
 		Type:            transaction.Type,
 		State:           strings.ToLower(transaction.State),
 		Invoice:         transaction.PaymentRequest,
@@ -150,7 +242,7 @@ func (api *api) Transfer(ctx context.Context, fromAppId *uint, toAppId *uint, am
 		return err
 	}
 
-	_, err = api.svc.GetTransactionsService().SendPaymentSync(transaction.PaymentRequest, nil, nil, api.svc.GetLNClient(), fromAppId, nil)
+	_, err = api.svc.GetTransactionsService().SendPaymentSync(ctx, transaction.PaymentRequest, nil, nil, api.svc.GetLNClient(), fromAppId, nil)
 	return err
 }
 
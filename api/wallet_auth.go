@@ -0,0 +1,83 @@
+package api
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nip47"
+)
+
+func (api *api) ParseWalletAuthUri(uri string) (*WalletAuthRequest, error) {
+	walletAuthRequest, err := nip47.ParseWalletAuthUri(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletAuthRequest{
+		AppPubkey:         walletAuthRequest.AppPubkey,
+		Relays:            walletAuthRequest.Relays,
+		Name:              walletAuthRequest.Name,
+		Icon:              walletAuthRequest.Icon,
+		ReturnTo:          walletAuthRequest.ReturnTo,
+		RequestMethods:    walletAuthRequest.RequestMethods,
+		NotificationTypes: walletAuthRequest.NotificationTypes,
+		Scopes:            walletAuthRequest.Scopes,
+		MaxAmountSat:      walletAuthRequest.MaxAmountSat,
+		BudgetRenewal:     walletAuthRequest.BudgetRenewal,
+		ExpiresAt:         walletAuthRequest.ExpiresAt,
+		Isolated:          walletAuthRequest.Isolated,
+		Metadata:          walletAuthRequest.Metadata,
+	}, nil
+}
+
+// AcceptWalletAuthRequest creates the connection requested by the app.
+// The app receives the wallet pubkey through the NIP-47 info event, which
+// is tagged with the app pubkey and published to the hub's relay and the
+// relays requested by the app.
+func (api *api) AcceptWalletAuthRequest(request *AcceptWalletAuthRequest) (*CreateAppResponse, error) {
+	walletAuthRequest, err := nip47.ParseWalletAuthUri(request.Uri)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := ""
+	if walletAuthRequest.ExpiresAt != nil {
+		expiresAt = walletAuthRequest.ExpiresAt.Format(time.RFC3339)
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"app_pubkey": walletAuthRequest.AppPubkey,
+		"name":       walletAuthRequest.Name,
+		"scopes":     walletAuthRequest.Scopes,
+		"relays":     walletAuthRequest.Relays,
+	}).Info("Accepting wallet auth request")
+
+	createAppResponse, err := api.CreateApp(&CreateAppRequest{
+		Name:           walletAuthRequest.Name,
+		Pubkey:         walletAuthRequest.AppPubkey,
+		MaxAmountSat:   walletAuthRequest.MaxAmountSat,
+		BudgetRenewal:  walletAuthRequest.BudgetRenewal,
+		ExpiresAt:      expiresAt,
+		Scopes:         walletAuthRequest.Scopes,
+		ReturnTo:       walletAuthRequest.ReturnTo,
+		Isolated:       walletAuthRequest.Isolated,
+		Metadata:       walletAuthRequest.Metadata,
+		UnlockPassword: request.UnlockPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	api.eventPublisher.Publish(&events.Event{
+		Event: "nwc_wallet_auth_accepted",
+		Properties: map[string]interface{}{
+			"id":     createAppResponse.Id,
+			"relays": walletAuthRequest.Relays,
+		},
+	})
+
+	return createAppResponse, nil
+}
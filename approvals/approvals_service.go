@@ -0,0 +1,271 @@
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+)
+
+type approvalsService struct {
+	db             *gorm.DB
+	cfg            config.Config
+	eventPublisher events.EventPublisher
+}
+
+// ApprovalsService holds back NIP-47 payments until the owner approves or denies them.
+// Decisions are stored in the database and published as events, so approvals can be
+// made from any instance of the service (e.g. the HTTP API while the payment is
+// waiting in the NWC handler)
+type ApprovalsService interface {
+	RequestPaymentApproval(paymentApproval *db.PaymentApproval) error
+	// FindPaymentApproval returns the unexpired approval of a payment the app requested
+	// before, or nil. Keysend payments are found by destination and amount
+	FindPaymentApproval(appId uint, paymentHash string, destination string, amountMsat uint64) (*db.PaymentApproval, error)
+	// WaitForDecision returns the state of the approval once it was decided or expired,
+	// or the pending state if it was not decided within the timeout
+	WaitForDecision(ctx context.Context, id uint, timeout time.Duration) (string, error)
+	// ClaimApprovedPayment marks the approval as used by the given request so the
+	// approved payment can only be attempted once
+	ClaimApprovedPayment(id uint, requestEventId uint) error
+	ApprovePayment(id uint) error
+	DenyPayment(id uint) error
+	ListPendingPaymentApprovals() ([]db.PaymentApproval, error)
+}
+
+const paymentApprovalTimeout = 10 * time.Minute
+
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+func NewApprovalsService(db *gorm.DB, cfg config.Config, eventPublisher events.EventPublisher) ApprovalsService {
+	return &approvalsService{
+		db:             db,
+		cfg:            cfg,
+		eventPublisher: eventPublisher,
+	}
+}
+
+func (svc *approvalsService) RequestPaymentApproval(paymentApproval *db.PaymentApproval) error {
+	paymentApproval.State = constants.PAYMENT_APPROVAL_STATE_PENDING
+	paymentApproval.ExpiresAt = time.Now().Add(paymentApprovalTimeout)
+
+	if err := svc.db.Omit("App").Create(paymentApproval).Error; err != nil {
+		logger.Logger.WithError(err).Error("Failed to save payment approval")
+		return err
+	}
+
+	var app db.App
+	if err := svc.db.Limit(1).Find(&app, paymentApproval.AppId).Error; err != nil {
+		return err
+	}
+
+	properties := map[string]interface{}{
+		"id":          paymentApproval.ID,
+		"app_id":      paymentApproval.AppId,
+		"app_name":    app.Name,
+		"method":      paymentApproval.Method,
+		"amount":      paymentApproval.AmountMsat / 1000,
+		"description": paymentApproval.Description,
+		"destination": paymentApproval.Destination,
+		"reason":      paymentApproval.Reason,
+		"expires_at":  paymentApproval.ExpiresAt.Unix(),
+	}
+
+	logger.Logger.WithFields(properties).Info("Payment requires approval")
+
+	svc.eventPublisher.Publish(&events.Event{
+		Event:      "nwc_payment_approval_required",
+		Properties: properties,
+	})
+
+	if webhookUrl := svc.cfg.GetEnv().PaymentApprovalWebhookUrl; webhookUrl != "" {
+		go svc.sendWebhook(webhookUrl, properties)
+	}
+
+	return nil
+}
+
+func (svc *approvalsService) FindPaymentApproval(appId uint, paymentHash string, destination string, amountMsat uint64) (*db.PaymentApproval, error) {
+	query := svc.db.Where("app_id = ? AND state IN ? AND expires_at > ?", appId, []string{
+		constants.PAYMENT_APPROVAL_STATE_PENDING,
+		constants.PAYMENT_APPROVAL_STATE_APPROVED,
+		constants.PAYMENT_APPROVAL_STATE_DENIED,
+	}, time.Now())
+	if paymentHash != "" {
+		query = query.Where("payment_hash = ?", paymentHash)
+	} else {
+		query = query.Where("destination = ? AND amount_msat = ?", destination, amountMsat)
+	}
+
+	var paymentApprovals []db.PaymentApproval
+	if err := query.Order("id DESC").Limit(1).Find(&paymentApprovals).Error; err != nil {
+		return nil, err
+	}
+	if len(paymentApprovals) == 0 {
+		return nil, nil
+	}
+	return &paymentApprovals[0], nil
+}
+
+func (svc *approvalsService) WaitForDecision(ctx context.Context, id uint, timeout time.Duration) (string, error) {
+	decidedChannel := make(chan string, 1)
+	paymentApprovalDecidedConsumer := newPaymentApprovalDecidedConsumer(id, decidedChannel)
+
+	// subscribe before reading the state so no decision is missed
+	svc.eventPublisher.RegisterSubscriber(paymentApprovalDecidedConsumer)
+	defer svc.eventPublisher.RemoveSubscriber(paymentApprovalDecidedConsumer)
+
+	var paymentApproval db.PaymentApproval
+	if err := svc.db.Limit(1).Find(&paymentApproval, id).Error; err != nil {
+		return "", err
+	}
+	if paymentApproval.ID == 0 {
+		return "", errors.New("payment approval not found")
+	}
+	if paymentApproval.State != constants.PAYMENT_APPROVAL_STATE_PENDING {
+		return paymentApproval.State, nil
+	}
+
+	expiresIn := time.Until(paymentApproval.ExpiresAt)
+	if expiresIn <= timeout {
+		timeout = max(expiresIn, 0)
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case state := <-decidedChannel:
+		return state, nil
+	case <-timer.C:
+	}
+
+	if time.Now().Before(paymentApproval.ExpiresAt) {
+		return constants.PAYMENT_APPROVAL_STATE_PENDING, nil
+	}
+
+	result := svc.db.Model(&db.PaymentApproval{}).
+		Where("id = ? AND state = ?", id, constants.PAYMENT_APPROVAL_STATE_PENDING).
+		Update("state", constants.PAYMENT_APPROVAL_STATE_EXPIRED)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		// decided at the same time it expired, read the decision
+		if err := svc.db.Limit(1).Find(&paymentApproval, id).Error; err != nil {
+			return "", err
+		}
+		return paymentApproval.State, nil
+	}
+	return constants.PAYMENT_APPROVAL_STATE_EXPIRED, nil
+}
+
+func (svc *approvalsService) ClaimApprovedPayment(id uint, requestEventId uint) error {
+	result := svc.db.Model(&db.PaymentApproval{}).
+		Where("id = ? AND state = ?", id, constants.PAYMENT_APPROVAL_STATE_APPROVED).
+		Updates(map[string]interface{}{
+			"state":            constants.PAYMENT_APPROVAL_STATE_USED,
+			"request_event_id": requestEventId,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("the approved payment was already attempted")
+	}
+	return nil
+}
+
+func (svc *approvalsService) ApprovePayment(id uint) error {
+	return svc.decide(id, constants.PAYMENT_APPROVAL_STATE_APPROVED)
+}
+
+func (svc *approvalsService) DenyPayment(id uint) error {
+	return svc.decide(id, constants.PAYMENT_APPROVAL_STATE_DENIED)
+}
+
+func (svc *approvalsService) decide(id uint, state string) error {
+	result := svc.db.Model(&db.PaymentApproval{}).
+		Where("id = ? AND state = ? AND expires_at > ?", id, constants.PAYMENT_APPROVAL_STATE_PENDING, time.Now()).
+		Update("state", state)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("pending payment approval not found")
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"id":    id,
+		"state": state,
+	}).Info("Payment approval decided")
+
+	svc.eventPublisher.Publish(&events.Event{
+		Event: "nwc_payment_approval_decided",
+		Properties: map[string]interface{}{
+			"id":    id,
+			"state": state,
+		},
+	})
+	return nil
+}
+
+func (svc *approvalsService) ListPendingPaymentApprovals() ([]db.PaymentApproval, error) {
+	var paymentApprovals []db.PaymentApproval
+	err := svc.db.
+		Preload("App").
+		Where("state = ? AND expires_at > ?", constants.PAYMENT_APPROVAL_STATE_PENDING, time.Now()).
+		Order("created_at ASC").
+		Find(&paymentApprovals).Error
+	if err != nil {
+		return nil, err
+	}
+	return paymentApprovals, nil
+}
+
+func (svc *approvalsService) sendWebhook(webhookUrl string, properties map[string]interface{}) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":      "payment_approval_required",
+		"properties": properties,
+	})
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to serialize payment approval webhook")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookUrl, bytes.NewReader(payload))
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to create payment approval webhook request")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := webhookClient.Do(req)
+	if err == nil {
+		res.Body.Close()
+		if res.StatusCode >= 300 {
+			err = fmt.Errorf("unexpected status code %d", res.StatusCode)
+		}
+	}
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to send payment approval webhook")
+	}
+}
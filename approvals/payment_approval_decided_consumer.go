@@ -0,0 +1,35 @@
+package approvals
+
+import (
+	"context"
+
+	"github.com/getAlby/hub/events"
+)
+
+type paymentApprovalDecidedConsumer struct {
+	id             uint
+	decidedChannel chan<- string
+}
+
+func newPaymentApprovalDecidedConsumer(id uint, decidedChannel chan<- string) *paymentApprovalDecidedConsumer {
+	return &paymentApprovalDecidedConsumer{
+		id:             id,
+		decidedChannel: decidedChannel,
+	}
+}
+
+func (consumer *paymentApprovalDecidedConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	if event.Event != "nwc_payment_approval_decided" {
+		return
+	}
+	properties, ok := event.Properties.(map[string]interface{})
+	if !ok || properties["id"] != consumer.id {
+		return
+	}
+	state, _ := properties["state"].(string)
+	// the waiter may already have stopped listening
+	select {
+	case consumer.decidedChannel <- state:
+	default:
+	}
+}
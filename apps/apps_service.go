@@ -26,6 +26,8 @@ type AppsService interface {
 	GetAppByPubkey(pubkey string) *db.App
 	GetAppById(id uint) *db.App
 	SetAppMetadata(appId uint, metadata map[string]interface{}) error
+	RotateAppKeys(app *db.App, gracePeriod time.Duration) (string, error)
+	RetirePreviousAppKeys(app *db.App) error
 }
 
 type appsService struct {
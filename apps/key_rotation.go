@@ -0,0 +1,185 @@
+package apps
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
+	"github.com/getAlby/hub/service/keys"
+)
+
+// DefaultKeyRotationGracePeriod is how long the replaced keys of an app are still
+// served after a key rotation, to give the user time to update the connection
+const DefaultKeyRotationGracePeriod = 72 * time.Hour
+
+// GetAppWalletSigner returns the signer of the wallet key the app currently uses
+func GetAppWalletSigner(keys keys.Keys, app *db.App) (signer.Signer, error) {
+	if app.WalletPubkey == nil {
+		// legacy app using the shared wallet key
+		return keys.GetNostrSigner(), nil
+	}
+	walletPrivKey, err := keys.GetAppWalletKeyGeneration(app.ID, app.WalletKeyGeneration)
+	if err != nil {
+		return nil, err
+	}
+	return signer.NewKeySigner(walletPrivKey)
+}
+
+// GetHubNostrSigner returns the signer of the hub Nostr key with the given pubkey,
+// which is the current key or the key replaced by the last hub key rotation.
+// Returns nil if the pubkey is not a hub key.
+func GetHubNostrSigner(keys keys.Keys, pubkey string) signer.Signer {
+	if pubkey == keys.GetNostrPublicKey() {
+		return keys.GetNostrSigner()
+	}
+	previousNostrSigner := keys.GetPreviousNostrSigner()
+	if previousNostrSigner != nil && pubkey == previousNostrSigner.GetPublicKey() {
+		return previousNostrSigner
+	}
+	return nil
+}
+
+// GetPreviousAppWalletSigner returns the signer of the wallet key replaced by the last key rotation
+func GetPreviousAppWalletSigner(keys keys.Keys, app *db.App) (signer.Signer, error) {
+	if app.PreviousWalletPubkey == "" {
+		return nil, errors.New("app has no previous wallet key")
+	}
+	if hubNostrSigner := GetHubNostrSigner(keys, app.PreviousWalletPubkey); hubNostrSigner != nil {
+		// the app was a legacy app before the rotation
+		return hubNostrSigner, nil
+	}
+	if app.WalletKeyGeneration == 0 {
+		return nil, errors.New("app has no previous wallet key generation")
+	}
+
+	walletPrivKey, err := keys.GetAppWalletKeyGeneration(app.ID, app.WalletKeyGeneration-1)
+	if err != nil {
+		return nil, err
+	}
+	walletSigner, err := signer.NewKeySigner(walletPrivKey)
+	if err != nil {
+		return nil, err
+	}
+	if walletSigner.GetPublicKey() != app.PreviousWalletPubkey {
+		return nil, errors.New("previous wallet key does not match the previous wallet pubkey")
+	}
+	return walletSigner, nil
+}
+
+// HasPreviousKeys returns true if the keys replaced by the last rotation are still served
+func HasPreviousKeys(app *db.App) bool {
+	return app.PreviousWalletPubkey != "" && app.PreviousKeysExpireAt != nil && app.PreviousKeysExpireAt.After(time.Now())
+}
+
+// RotateAppKeys issues a new connection secret and wallet key for the app.
+// The previous keys are served until the grace period ends.
+// Returns the new connection secret.
+func (svc *appsService) RotateAppKeys(app *db.App, gracePeriod time.Duration) (string, error) {
+	// only the keys replaced by the last rotation are served
+	err := svc.RetirePreviousAppKeys(app)
+	if err != nil {
+		return "", err
+	}
+
+	previousAppPubkey := app.AppPubkey
+	previousWalletPubkey := svc.keys.GetNostrPublicKey()
+	generation := uint(0)
+	if app.WalletPubkey != nil {
+		previousWalletPubkey = *app.WalletPubkey
+		generation = app.WalletKeyGeneration + 1
+	}
+
+	walletPrivKey, err := svc.keys.GetAppWalletKeyGeneration(app.ID, generation)
+	if err != nil {
+		return "", fmt.Errorf("error generating wallet child private key: %w", err)
+	}
+	walletPubkey, err := nostr.GetPublicKey(walletPrivKey)
+	if err != nil {
+		return "", fmt.Errorf("error generating wallet child public key: %w", err)
+	}
+
+	pairingSecretKey := nostr.GeneratePrivateKey()
+	pairingPublicKey, err := nostr.GetPublicKey(pairingSecretKey)
+	if err != nil {
+		return "", fmt.Errorf("error generating pairing public key: %w", err)
+	}
+
+	previousKeysExpireAt := time.Now().Add(gracePeriod)
+	updates := map[string]interface{}{
+		"app_pubkey":              pairingPublicKey,
+		"wallet_pubkey":           walletPubkey,
+		"wallet_key_generation":   generation,
+		"previous_app_pubkey":     previousAppPubkey,
+		"previous_wallet_pubkey":  previousWalletPubkey,
+		"previous_keys_expire_at": &previousKeysExpireAt,
+	}
+	err = svc.db.Model(app).Updates(updates).Error
+	if err != nil {
+		logger.Logger.WithError(err).WithField("app_id", app.ID).Error("Failed to save rotated app keys")
+		return "", err
+	}
+	app.PreviousAppPubkey = previousAppPubkey
+	app.PreviousWalletPubkey = previousWalletPubkey
+	app.PreviousKeysExpireAt = &previousKeysExpireAt
+	app.AppPubkey = pairingPublicKey
+	app.WalletPubkey = &walletPubkey
+	app.WalletKeyGeneration = generation
+
+	logger.Logger.WithFields(logrus.Fields{
+		"app_id":                  app.ID,
+		"wallet_pubkey":           walletPubkey,
+		"previous_wallet_pubkey":  previousWalletPubkey,
+		"previous_keys_expire_at": previousKeysExpireAt,
+	}).Info("Rotated app keys")
+
+	svc.eventPublisher.Publish(&events.Event{
+		Event: "nwc_app_keys_rotated",
+		Properties: map[string]interface{}{
+			"name": app.Name,
+			"id":   app.ID,
+		},
+	})
+
+	return pairingSecretKey, nil
+}
+
+// RetirePreviousAppKeys stops serving the keys replaced by the last key rotation
+func (svc *appsService) RetirePreviousAppKeys(app *db.App) error {
+	if app.PreviousWalletPubkey == "" {
+		return nil
+	}
+	previousWalletPubkey := app.PreviousWalletPubkey
+
+	// the keys may have been rotated again in the meantime
+	err := svc.db.Model(&db.App{}).
+		Where("id = ? AND previous_wallet_pubkey = ?", app.ID, previousWalletPubkey).
+		Updates(map[string]interface{}{
+			"previous_app_pubkey":     "",
+			"previous_wallet_pubkey":  "",
+			"previous_keys_expire_at": nil,
+		}).Error
+	if err != nil {
+		logger.Logger.WithError(err).WithField("app_id", app.ID).Error("Failed to retire previous app keys")
+		return err
+	}
+	app.PreviousAppPubkey = ""
+	app.PreviousWalletPubkey = ""
+	app.PreviousKeysExpireAt = nil
+
+	svc.eventPublisher.Publish(&events.Event{
+		Event: "nwc_app_keys_retired",
+		Properties: map[string]interface{}{
+			"id":            app.ID,
+			"wallet_pubkey": previousWalletPubkey,
+		},
+	})
+
+	return nil
+}
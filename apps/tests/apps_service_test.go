@@ -2,11 +2,14 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/tests"
+	"github.com/nbd-wtf/go-nostr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -56,3 +59,66 @@ func TestHandleCreateApp_IsolatedUnsupportedBackendType(t *testing.T) {
 	require.Error(t, err)
 	assert.Equal(t, "sub-wallets are currently not supported on your node backend. Try LDK or LND", err.Error())
 }
+
+func TestRotateAppKeys(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	eventConsumer := tests.NewMockEventConsumer()
+	svc.EventPublisher.RegisterSubscriber(eventConsumer)
+
+	app, _, err := tests.CreateApp(svc)
+	require.NoError(t, err)
+	originalAppPubkey := app.AppPubkey
+	originalWalletPubkey := *app.WalletPubkey
+
+	pairingSecretKey, err := svc.AppsService.RotateAppKeys(app, time.Hour)
+	require.NoError(t, err)
+	pairingPublicKey, err := nostr.GetPublicKey(pairingSecretKey)
+	require.NoError(t, err)
+
+	rotatedApp := svc.AppsService.GetAppById(app.ID)
+	require.NotNil(t, rotatedApp)
+	assert.Equal(t, pairingPublicKey, rotatedApp.AppPubkey)
+	assert.Equal(t, uint(1), rotatedApp.WalletKeyGeneration)
+	assert.Equal(t, originalAppPubkey, rotatedApp.PreviousAppPubkey)
+	assert.Equal(t, originalWalletPubkey, rotatedApp.PreviousWalletPubkey)
+	assert.True(t, apps.HasPreviousKeys(rotatedApp))
+
+	walletSigner, err := apps.GetAppWalletSigner(svc.Keys, rotatedApp)
+	require.NoError(t, err)
+	walletPubkey := walletSigner.GetPublicKey()
+	assert.Equal(t, *rotatedApp.WalletPubkey, walletPubkey)
+
+	previousWalletSigner, err := apps.GetPreviousAppWalletSigner(svc.Keys, rotatedApp)
+	require.NoError(t, err)
+	assert.Equal(t, originalWalletPubkey, previousWalletSigner.GetPublicKey())
+
+	// rotating again retires the keys replaced by the first rotation
+	_, err = svc.AppsService.RotateAppKeys(rotatedApp, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), rotatedApp.WalletKeyGeneration)
+	assert.Equal(t, walletPubkey, rotatedApp.PreviousWalletPubkey)
+
+	eventNames := []string{}
+	var retiredEvent *events.Event
+	for _, event := range eventConsumer.GetConsumedEvents() {
+		eventNames = append(eventNames, event.Event)
+		if event.Event == "nwc_app_keys_retired" {
+			retiredEvent = event
+		}
+	}
+	assert.ElementsMatch(t, []string{"nwc_app_created", "nwc_app_keys_rotated", "nwc_app_keys_retired", "nwc_app_keys_rotated"}, eventNames)
+	require.NotNil(t, retiredEvent)
+	assert.Equal(t, originalWalletPubkey, retiredEvent.Properties.(map[string]interface{})["wallet_pubkey"])
+
+	err = svc.AppsService.RetirePreviousAppKeys(rotatedApp)
+	require.NoError(t, err)
+	retiredApp := svc.AppsService.GetAppById(app.ID)
+	require.NotNil(t, retiredApp)
+	assert.Empty(t, retiredApp.PreviousAppPubkey)
+	assert.Empty(t, retiredApp.PreviousWalletPubkey)
+	assert.Nil(t, retiredApp.PreviousKeysExpireAt)
+	assert.False(t, apps.HasPreviousKeys(retiredApp))
+}
@@ -0,0 +1,387 @@
+package backups
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/version"
+)
+
+// BackupsService periodically takes encrypted hot backups of the hub database
+// and the latest static channel backup, and uploads them to a local directory
+// or an S3-compatible bucket. Unlike the manual backup it does not stop the node.
+//
+// Backups are encrypted with the unlock password and are zip files in the same
+// format as manual backups, but they do not contain the node storage. They are
+// marked as hot backups in the manifest and RestoreBackup refuses them on LDK nodes:
+// the channels can only be recovered by closing them with the static channel backup.
+type BackupsService interface {
+	events.EventSubscriber
+	Start(ctx context.Context, encryptionKey string)
+	CreateBackup(ctx context.Context) (*StoredBackup, error)
+	ListBackups(ctx context.Context) ([]StoredBackup, error)
+}
+
+// Manifest is stored as manifest.json in every backup
+type Manifest struct {
+	Version       int       `json:"version"`
+	HubVersion    string    `json:"hub_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Database      string    `json:"database"`
+	LNBackendType string    `json:"ln_backend_type"`
+	// hot backups only contain the database and the static channel backup, not the node storage
+	HotBackup bool `json:"hot_backup,omitempty"`
+}
+
+const ManifestVersion = 1
+
+const ManifestFileName = "manifest.json"
+const StaticChannelBackupFileName = "static_channel_backup.json"
+
+// directory in the work directory where LDK saves every static channel backup it publishes
+const StaticChannelBackupsDirectory = "ldk/static_channel_backups"
+
+const backupCheckInterval = 10 * time.Minute
+
+type backupsService struct {
+	db       *gorm.DB
+	cfg      config.Config
+	store    Store
+	storeErr error
+
+	// only one backup is taken at a time
+	backupMutex sync.Mutex
+
+	mu                   sync.Mutex
+	encryptionKey        string
+	latestChannelsBackup *events.StaticChannelsBackupEvent
+}
+
+func NewBackupsService(db *gorm.DB, cfg config.Config) BackupsService {
+	store, err := newStore(cfg.GetEnv())
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to configure backup store")
+	}
+
+	return &backupsService{
+		db:       db,
+		cfg:      cfg,
+		store:    store,
+		storeErr: err,
+	}
+}
+
+func newStore(env *config.AppConfig) (Store, error) {
+	if env.BackupS3Bucket != "" {
+		return NewS3Store(S3Config{
+			Endpoint:        env.BackupS3Endpoint,
+			Bucket:          env.BackupS3Bucket,
+			Region:          env.BackupS3Region,
+			Prefix:          env.BackupS3Prefix,
+			AccessKeyId:     env.BackupS3AccessKeyId,
+			SecretAccessKey: env.BackupS3SecretAccessKey,
+		})
+	}
+
+	dir := env.BackupDir
+	if dir == "" {
+		dir = filepath.Join(env.Workdir, "backups")
+	}
+	return NewLocalStore(dir), nil
+}
+
+func (svc *backupsService) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	if event.Event != "nwc_backup_channels" {
+		return
+	}
+	channelsBackup, ok := event.Properties.(*events.StaticChannelsBackupEvent)
+	if !ok {
+		logger.Logger.WithField("event", event).Error("Failed to cast static channels backup event")
+		return
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.latestChannelsBackup = channelsBackup
+}
+
+// Start runs the backup scheduler until ctx is cancelled (when the hub is stopped or locked).
+// The encryption key is only kept in memory while the hub is running.
+func (svc *backupsService) Start(ctx context.Context, encryptionKey string) {
+	svc.mu.Lock()
+	svc.encryptionKey = encryptionKey
+	svc.mu.Unlock()
+
+	interval := time.Duration(svc.cfg.GetEnv().BackupIntervalHours) * time.Hour
+	if interval == 0 {
+		logger.Logger.Info("Scheduled backups are disabled")
+	}
+
+	go func() {
+		ticker := time.NewTicker(backupCheckInterval)
+		defer ticker.Stop()
+		for {
+			if interval > 0 {
+				svc.createBackupIfDue(ctx, interval)
+			}
+
+			select {
+			case <-ctx.Done():
+				svc.mu.Lock()
+				svc.encryptionKey = ""
+				svc.mu.Unlock()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (svc *backupsService) createBackupIfDue(ctx context.Context, interval time.Duration) {
+	backups, err := svc.ListBackups(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to list backups")
+		return
+	}
+	if len(backups) > 0 && time.Since(backups[0].CreatedAt) < interval {
+		return
+	}
+
+	_, err = svc.CreateBackup(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to create scheduled backup")
+	}
+}
+
+func (svc *backupsService) ListBackups(ctx context.Context) ([]StoredBackup, error) {
+	if svc.store == nil {
+		return nil, svc.storeErr
+	}
+	return svc.store.List(ctx)
+}
+
+func (svc *backupsService) CreateBackup(ctx context.Context) (*StoredBackup, error) {
+	if svc.store == nil {
+		return nil, svc.storeErr
+	}
+
+	svc.mu.Lock()
+	encryptionKey := svc.encryptionKey
+	svc.mu.Unlock()
+	if encryptionKey == "" {
+		return nil, errors.New("backups can only be created while the hub is unlocked")
+	}
+
+	svc.backupMutex.Lock()
+	defer svc.backupMutex.Unlock()
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	name := backupFileName(createdAt)
+
+	tmpFile, err := os.CreateTemp(svc.cfg.GetEnv().Workdir, "albyhub-backup-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	err = svc.writeBackup(tmpFile, encryptionKey, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := tmpFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tmpFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.store.Put(ctx, name, tmpFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"name": name,
+		"size": size,
+	}).Info("Created backup")
+
+	err = svc.rotateBackups(ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to remove old backups")
+	}
+
+	return &StoredBackup{
+		Name:      name,
+		Size:      size,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+func (svc *backupsService) writeBackup(w io.Writer, encryptionKey string, createdAt time.Time) error {
+	ew, err := EncryptingWriter(w, encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to create encrypted writer: %w", err)
+	}
+
+	zw := zip.NewWriter(ew)
+
+	databaseType := svc.db.Dialector.Name()
+	err = WriteManifest(zw, databaseType, svc.cfg.GetEnv().LNBackendType, true, createdAt)
+	if err != nil {
+		return err
+	}
+
+	switch databaseType {
+	case "sqlite":
+		err = writeSqliteSnapshot(svc.db, zw, svc.cfg.GetEnv().Workdir)
+	case "postgres":
+		err = ExportDatabaseToZip(svc.db, zw)
+	default:
+		err = fmt.Errorf("unsupported database: %s", databaseType)
+	}
+	if err != nil {
+		return err
+	}
+
+	channelsBackup, err := svc.getStaticChannelsBackup()
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to read static channel backup")
+	}
+	if channelsBackup != nil {
+		err = writeZipFile(zw, StaticChannelBackupFileName, channelsBackup)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return nil
+}
+
+// getStaticChannelsBackup returns the latest static channel backup published by the node,
+// or the latest one saved to disk if the node has not published one since the hub started
+func (svc *backupsService) getStaticChannelsBackup() ([]byte, error) {
+	svc.mu.Lock()
+	latestChannelsBackup := svc.latestChannelsBackup
+	svc.mu.Unlock()
+
+	if latestChannelsBackup != nil {
+		return json.Marshal(latestChannelsBackup)
+	}
+
+	backupFile, err := LatestStaticChannelBackupFile(svc.cfg.GetEnv().Workdir)
+	if err != nil || backupFile == "" {
+		return nil, err
+	}
+	return os.ReadFile(backupFile)
+}
+
+// LatestStaticChannelBackupFile returns the path of the latest static channel backup
+// saved by LDK, or an empty string if there is none
+func LatestStaticChannelBackupFile(workDir string) (string, error) {
+	backupFiles, err := filepath.Glob(filepath.Join(workDir, filepath.FromSlash(StaticChannelBackupsDirectory), "*.json"))
+	if err != nil {
+		return "", err
+	}
+	if len(backupFiles) == 0 {
+		return "", nil
+	}
+	// file names are timestamps
+	sort.Strings(backupFiles)
+	return backupFiles[len(backupFiles)-1], nil
+}
+
+func (svc *backupsService) rotateBackups(ctx context.Context) error {
+	retentionCount := int(svc.cfg.GetEnv().BackupRetentionCount)
+	if retentionCount == 0 {
+		return nil
+	}
+
+	backups, err := svc.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(backups) <= retentionCount {
+		return nil
+	}
+
+	for _, backup := range backups[retentionCount:] {
+		err = svc.store.Delete(ctx, backup.Name)
+		if err != nil {
+			return err
+		}
+		logger.Logger.WithField("name", backup.Name).Info("Removed old backup")
+	}
+	return nil
+}
+
+// WriteManifest adds the manifest describing the backup to the zip
+func WriteManifest(zw *zip.Writer, databaseType string, lnBackendType string, hotBackup bool, createdAt time.Time) error {
+	manifestBytes, err := json.Marshal(Manifest{
+		Version:       ManifestVersion,
+		HubVersion:    version.Tag,
+		CreatedAt:     createdAt,
+		Database:      databaseType,
+		LNBackendType: lnBackendType,
+		HotBackup:     hotBackup,
+	})
+	if err != nil {
+		return err
+	}
+	return writeZipFile(zw, ManifestFileName, manifestBytes)
+}
+
+// ReadManifest returns the manifest of a backup, or nil if the backup was created
+// by a hub which did not write a manifest
+func ReadManifest(zr *zip.Reader) (*Manifest, error) {
+	f, err := zr.Open(ManifestFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	err = json.NewDecoder(f).Decode(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, content []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+	_, err = fw.Write(content)
+	if err != nil {
+		return fmt.Errorf("failed to write zip entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package backups
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestCreateBackup(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	backupDir := t.TempDir()
+	svc.Cfg.GetEnv().Workdir = t.TempDir()
+	svc.Cfg.GetEnv().BackupDir = backupDir
+	svc.Cfg.GetEnv().BackupRetentionCount = 2
+
+	backupsService := NewBackupsService(svc.DB, svc.Cfg)
+
+	_, err = backupsService.CreateBackup(context.Background())
+	assert.ErrorContains(t, err, "unlocked")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backupsService.Start(ctx, "password")
+
+	backupsService.ConsumeEvent(ctx, &events.Event{
+		Event: "nwc_backup_channels",
+		Properties: &events.StaticChannelsBackupEvent{
+			NodeID: "node1",
+		},
+	}, nil)
+
+	// existing backups beyond the retention count are removed
+	for _, days := range []int{2, 3} {
+		name := backupFileName(time.Now().AddDate(0, 0, -days))
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, name), []byte{}, 0600))
+	}
+
+	backup, err := backupsService.CreateBackup(ctx)
+	require.NoError(t, err)
+
+	backups, err := backupsService.ListBackups(ctx)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, backup.Name, backups[0].Name)
+	assert.Equal(t, backup.Size, backups[0].Size)
+
+	backupFile, err := os.Open(filepath.Join(backupDir, backup.Name))
+	require.NoError(t, err)
+	defer backupFile.Close()
+	r, err := DecryptingReader(backupFile, "password")
+	require.NoError(t, err)
+	zipBytes, err := io.ReadAll(r)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	require.NoError(t, err)
+
+	files := map[string]*zip.File{}
+	for _, file := range zr.File {
+		files[file.Name] = file
+	}
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(readZipFile(t, files[ManifestFileName]), &manifest))
+	assert.Equal(t, ManifestVersion, manifest.Version)
+	assert.True(t, manifest.HotBackup)
+	assert.Equal(t, svc.DB.Dialector.Name(), manifest.Database)
+
+	var channelsBackup events.StaticChannelsBackupEvent
+	require.NoError(t, json.Unmarshal(readZipFile(t, files[StaticChannelBackupFileName]), &channelsBackup))
+	assert.Equal(t, "node1", channelsBackup.NodeID)
+
+	if svc.DB.Dialector.Name() == "postgres" {
+		assert.Contains(t, files, DatabaseExportDirectory+"/apps.jsonl")
+		assert.NotContains(t, files, DatabaseExportDirectory+"/transaction_search.jsonl")
+	} else {
+		assert.Contains(t, files, "nwc.db")
+	}
+
+	// the encryption key is forgotten when the hub is stopped
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, err := backupsService.CreateBackup(context.Background())
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func readZipFile(t *testing.T, file *zip.File) []byte {
+	require.NotNil(t, file)
+	r, err := file.Open()
+	require.NoError(t, err)
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return content
+}
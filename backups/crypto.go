@@ -0,0 +1,77 @@
+package backups
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// EncryptingWriter encrypts everything written to it with a key derived from the password.
+// The salt and IV are written to w first.
+func EncryptingWriter(w io.Writer, password string) (io.Writer, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	encKey := pbkdf2.Key([]byte(password), salt, 4096, 32, sha256.New)
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err = rand.Read(iv); err != nil {
+		return nil, fmt.Errorf("failed to generate IV: %w", err)
+	}
+
+	_, err = w.Write(salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write salt: %w", err)
+	}
+
+	_, err = w.Write(iv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write IV: %w", err)
+	}
+
+	stream := cipher.NewOFB(block, iv)
+	cw := &cipher.StreamWriter{
+		S: stream,
+		W: w,
+	}
+
+	return cw, nil
+}
+
+// DecryptingReader decrypts data written by EncryptingWriter
+func DecryptingReader(r io.Reader, password string) (io.Reader, error) {
+	salt := make([]byte, 8)
+	if _, err := io.ReadFull(r, salt); err != nil {
+		return nil, fmt.Errorf("failed to read salt: %w", err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(r, iv); err != nil {
+		return nil, fmt.Errorf("failed to read IV: %w", err)
+	}
+
+	encKey := pbkdf2.Key([]byte(password), salt, 4096, 32, sha256.New)
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	stream := cipher.NewOFB(block, iv)
+	cr := &cipher.StreamReader{
+		S: stream,
+		R: r,
+	}
+
+	return cr, nil
+}
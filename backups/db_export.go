@@ -0,0 +1,381 @@
+package backups
+
+import (
+	"archive/zip"
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/transactions/metadataindex"
+)
+
+// DatabaseExportDirectory contains a database agnostic export of the hub database
+// with one <table>.jsonl file per table. Each line is a JSON object keyed by column name.
+// Postgres backups use it instead of nwc.db, and it can be imported into SQLite or Postgres.
+const DatabaseExportDirectory = "db_export"
+
+const migrationsFileName = "migrations.jsonl"
+
+// derivedTables are rebuilt from the transactions on import, see metadataindex.Reindex
+var derivedTables = []string{
+	"transaction_metadata_entries",
+	"transaction_search",
+}
+
+type exportedTable struct {
+	name  string
+	model interface{}
+	// optional condition to skip orphaned rows which would violate foreign keys on import
+	where string
+}
+
+// exportedTables are ordered so that referenced tables are imported before the tables referencing them
+var exportedTables = []exportedTable{
+	{name: "user_configs", model: &db.UserConfig{}},
+	{name: "apps", model: &db.App{}},
+	{name: "app_permissions", model: &db.AppPermission{}},
+	{name: "request_events", model: &db.RequestEvent{}, where: "app_id IS NULL OR app_id IN (SELECT id FROM apps)"},
+	{name: "response_events", model: &db.ResponseEvent{}, where: "request_id IN (SELECT id FROM request_events)"},
+	{name: "transactions", model: &db.Transaction{}},
+	{name: "swaps", model: &db.Swap{}},
+	{name: "forwards", model: &db.Forward{}},
+	{name: "pending_channel_closes", model: &db.PendingChannelClose{}},
+	{name: "lsps", model: &db.LSP{}},
+	{name: "lsp_orders", model: &db.LSPOrder{}},
+	{name: "payment_approvals", model: &db.PaymentApproval{}},
+	{name: "request_archives", model: &db.RequestArchive{}},
+	{name: "response_archives", model: &db.ResponseArchive{}},
+	{name: "nip47_notifications", model: &db.Nip47Notification{}},
+	{name: "labels", model: &db.Label{}},
+	{name: "onchain_notified_transactions", model: &db.OnchainNotifiedTransaction{}},
+}
+
+const exportBatchSize = 1000
+
+// rows are inserted in smaller batches to stay below the postgres limit of 65535 parameters per statement
+const importBatchSize = 100
+
+// ExportDatabaseToZip writes a database export into the DatabaseExportDirectory of the zip
+func ExportDatabaseToZip(gormDB *gorm.DB, zw *zip.Writer) error {
+	return exportDatabase(gormDB, func(name string) (io.Writer, error) {
+		return zw.Create(DatabaseExportDirectory + "/" + name)
+	})
+}
+
+// ExportDatabaseToDir writes a database export into dir
+func ExportDatabaseToDir(gormDB *gorm.DB, dir string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	files := []*os.File{}
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	err = exportDatabase(gormDB, func(name string) (io.Writer, error) {
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+		return file, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	files = nil
+	return nil
+}
+
+func exportDatabase(gormDB *gorm.DB, createFile func(name string) (io.Writer, error)) error {
+	var opts []*sql.TxOptions
+	if gormDB.Dialector.Name() == "postgres" {
+		// all tables are read from the same snapshot so the export is consistent
+		opts = append(opts, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	}
+
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		w, err := createFile(migrationsFileName)
+		if err != nil {
+			return err
+		}
+		migrationIds, err := listMigrationIds(tx)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		for _, migrationId := range migrationIds {
+			err = encoder.Encode(map[string]string{"id": migrationId})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, table := range exportedTables {
+			w, err := createFile(table.name + ".jsonl")
+			if err != nil {
+				return err
+			}
+			count, err := exportTable(tx, table, w)
+			if err != nil {
+				return fmt.Errorf("failed to export table %s: %w", table.name, err)
+			}
+			logger.Logger.WithFields(logrus.Fields{
+				"table": table.name,
+				"count": count,
+			}).Debug("Exported table")
+		}
+		return nil
+	}, opts...)
+}
+
+func exportTable(tx *gorm.DB, table exportedTable, w io.Writer) (int, error) {
+	tableSchema, err := parseSchema(tx, table.model)
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(w)
+	count := 0
+	lastId := uint(0)
+	for {
+		records := reflect.New(reflect.SliceOf(tableSchema.ModelType))
+		query := tx.Table(table.name).Where("id > ?", lastId)
+		if table.where != "" {
+			query = query.Where(table.where)
+		}
+		err := query.Order("id").Limit(exportBatchSize).Find(records.Interface()).Error
+		if err != nil {
+			return count, err
+		}
+
+		records = records.Elem()
+		for i := 0; i < records.Len(); i++ {
+			record := records.Index(i)
+			row := map[string]interface{}{}
+			for _, field := range tableSchema.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				row[field.DBName] = record.FieldByIndex(field.StructField.Index).Interface()
+			}
+			err = encoder.Encode(row)
+			if err != nil {
+				return count, err
+			}
+			lastId = uint(record.FieldByName("ID").Uint())
+			count++
+		}
+
+		if records.Len() < exportBatchSize {
+			return count, nil
+		}
+	}
+}
+
+// ImportDatabase replaces all data in the hub database with the database export in dir.
+// The hub database must already be migrated, and must not be older than the exported one.
+func ImportDatabase(gormDB *gorm.DB, dir string) error {
+	exportedMigrationIds := []string{}
+	err := readJsonLines(filepath.Join(dir, migrationsFileName), func(line []byte) error {
+		var migration struct {
+			Id string `json:"id"`
+		}
+		err := json.Unmarshal(line, &migration)
+		if err != nil {
+			return err
+		}
+		exportedMigrationIds = append(exportedMigrationIds, migration.Id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read exported migrations: %w", err)
+	}
+
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		migrationIds, err := listMigrationIds(tx)
+		if err != nil {
+			return err
+		}
+		for _, migrationId := range exportedMigrationIds {
+			if !slices.Contains(migrationIds, migrationId) {
+				return fmt.Errorf("the backup was created by a newer version of Alby Hub (unknown migration %s)", migrationId)
+			}
+		}
+
+		for _, table := range slices.Backward(exportedTables) {
+			err = tx.Exec("DELETE FROM " + table.name).Error
+			if err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", table.name, err)
+			}
+		}
+		for _, table := range derivedTables {
+			err = tx.Exec("DELETE FROM " + table).Error
+			if err != nil {
+				return fmt.Errorf("failed to clear table %s: %w", table, err)
+			}
+		}
+
+		for _, table := range exportedTables {
+			count, err := importTable(tx, table, filepath.Join(dir, table.name+".jsonl"))
+			if err != nil {
+				return fmt.Errorf("failed to import table %s: %w", table.name, err)
+			}
+			logger.Logger.WithFields(logrus.Fields{
+				"table": table.name,
+				"count": count,
+			}).Info("Imported table")
+		}
+
+		err = metadataindex.Reindex(tx)
+		if err != nil {
+			return fmt.Errorf("failed to rebuild transaction metadata index: %w", err)
+		}
+
+		if tx.Dialector.Name() == "postgres" {
+			for _, table := range exportedTables {
+				err = tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)", table.name, table.name)).Error
+				if err != nil {
+					return fmt.Errorf("failed to reset sequence of %s: %w", table.name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func importTable(tx *gorm.DB, table exportedTable, path string) (int, error) {
+	tableSchema, err := parseSchema(tx, table.model)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	records := reflect.MakeSlice(reflect.SliceOf(tableSchema.ModelType), 0, importBatchSize)
+	flush := func() error {
+		if records.Len() == 0 {
+			return nil
+		}
+		err := tx.Table(table.name).Omit(clause.Associations).Create(records.Interface()).Error
+		if err != nil {
+			return err
+		}
+		count += records.Len()
+		records = records.Slice(0, 0)
+		return nil
+	}
+
+	err = readJsonLines(path, func(line []byte) error {
+		row := map[string]json.RawMessage{}
+		err := json.Unmarshal(line, &row)
+		if err != nil {
+			return err
+		}
+
+		record := reflect.New(tableSchema.ModelType).Elem()
+		for _, field := range tableSchema.Fields {
+			value, ok := row[field.DBName]
+			// keep NULL columns NULL instead of storing JSON null
+			if field.DBName == "" || !ok || string(value) == "null" {
+				continue
+			}
+			err = json.Unmarshal(value, record.FieldByIndex(field.StructField.Index).Addr().Interface())
+			if err != nil {
+				return fmt.Errorf("invalid value for column %s: %w", field.DBName, err)
+			}
+		}
+		records = reflect.Append(records, record)
+
+		if records.Len() >= importBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	err = flush()
+	return count, err
+}
+
+// FinishDatabaseRestore imports a database export restored from a backup, see api.RestoreBackup.
+// It has to run once the hub database is opened and migrated.
+func FinishDatabaseRestore(gormDB *gorm.DB, workDir string) error {
+	exportDir := filepath.Join(workDir, DatabaseExportDirectory)
+	if _, err := os.Stat(exportDir); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	logger.Logger.WithField("dir", exportDir).Info("Database export found. Importing restored database")
+	err := ImportDatabase(gormDB, exportDir)
+	if err != nil {
+		return err
+	}
+	logger.Logger.Info("Imported restored database")
+
+	return os.RemoveAll(exportDir)
+}
+
+func listMigrationIds(tx *gorm.DB) ([]string, error) {
+	var migrationIds []string
+	err := tx.Raw("SELECT id FROM migrations ORDER BY id").Scan(&migrationIds).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return migrationIds, nil
+}
+
+var schemaCache = &sync.Map{}
+
+func parseSchema(tx *gorm.DB, model interface{}) (*schema.Schema, error) {
+	return schema.Parse(model, schemaCache, tx.NamingStrategy)
+}
+
+func readJsonLines(path string, handleLine func(line []byte) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// archived payloads can be large
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		err = handleLine(scanner.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
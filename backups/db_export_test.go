@@ -0,0 +1,129 @@
+package backups
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/transactions/metadataindex"
+)
+
+func TestExportedTables(t *testing.T) {
+	for _, table := range db.Tables {
+		exported := slices.ContainsFunc(exportedTables, func(exportedTable exportedTable) bool {
+			return exportedTable.name == table
+		})
+		assert.True(t, exported || slices.Contains(derivedTables, table) || table == "migrations", "table %s is not exported", table)
+	}
+}
+
+func TestExportImportDatabase(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	require.NoError(t, err)
+
+	transaction := db.Transaction{
+		AppId:       &app.ID,
+		Type:        constants.TRANSACTION_TYPE_INCOMING,
+		State:       constants.TRANSACTION_STATE_SETTLED,
+		PaymentHash: "hash1",
+		AmountMsat:  1000,
+		Metadata:    datatypes.JSON(`{"payer_data":{"name":"Satoshi"}}`),
+	}
+	require.NoError(t, svc.DB.Create(&transaction).Error)
+	require.NoError(t, svc.DB.Create(&db.Label{
+		EntityType: constants.LABEL_ENTITY_TYPE_TRANSACTION,
+		EntityId:   "1",
+		Label:      "rent",
+		Tags:       datatypes.JSON(`["home"]`),
+	}).Error)
+
+	exportDir := t.TempDir()
+	require.NoError(t, ExportDatabaseToDir(svc.DB, exportDir))
+
+	// import into a new SQLite database
+	targetDB, err := db.NewDB(filepath.Join(t.TempDir(), "nwc.db"), false)
+	require.NoError(t, err)
+	defer db.Stop(targetDB)
+
+	require.NoError(t, ImportDatabase(targetDB, exportDir))
+	assertImported(t, targetDB, app, &transaction)
+
+	// importing into an existing database replaces its data
+	_, _, err = tests.CreateApp(svc)
+	require.NoError(t, err)
+	require.NoError(t, ImportDatabase(svc.DB, exportDir))
+	assertImported(t, svc.DB, app, &transaction)
+
+	// new rows continue after the imported ids
+	newApp, _, err := tests.CreateApp(svc)
+	require.NoError(t, err)
+	assert.Greater(t, newApp.ID, app.ID)
+}
+
+func assertImported(t *testing.T, gormDB *gorm.DB, app *db.App, transaction *db.Transaction) {
+	var apps []db.App
+	require.NoError(t, gormDB.Find(&apps).Error)
+	require.Len(t, apps, 1)
+	assert.Equal(t, app.ID, apps[0].ID)
+	assert.Equal(t, app.AppPubkey, apps[0].AppPubkey)
+
+	var appPermissionsCount int64
+	require.NoError(t, gormDB.Model(&db.AppPermission{}).Where("app_id = ?", app.ID).Count(&appPermissionsCount).Error)
+	assert.Equal(t, int64(1), appPermissionsCount)
+
+	var importedTransaction db.Transaction
+	require.NoError(t, gormDB.First(&importedTransaction, transaction.ID).Error)
+	assert.Equal(t, transaction.PaymentHash, importedTransaction.PaymentHash)
+	assert.Equal(t, transaction.AmountMsat, importedTransaction.AmountMsat)
+	assert.Equal(t, app.ID, *importedTransaction.AppId)
+	assert.JSONEq(t, string(transaction.Metadata), string(importedTransaction.Metadata))
+	assert.Nil(t, importedTransaction.Preimage)
+	assert.Nil(t, importedTransaction.Boostagram)
+	assert.WithinDuration(t, transaction.CreatedAt, importedTransaction.CreatedAt, time.Millisecond)
+
+	var label db.Label
+	require.NoError(t, gormDB.First(&label).Error)
+	assert.Equal(t, "rent", label.Label)
+	assert.JSONEq(t, `["home"]`, string(label.Tags))
+
+	var mnemonicCount int64
+	require.NoError(t, gormDB.Model(&db.UserConfig{}).Where("key = ?", "Mnemonic").Count(&mnemonicCount).Error)
+	assert.Equal(t, int64(1), mnemonicCount)
+
+	// the metadata index is rebuilt
+	var payerName string
+	require.NoError(t, gormDB.Raw("SELECT value FROM transaction_metadata_entries WHERE transaction_id = ? AND key = ?", transaction.ID, metadataindex.PAYER_NAME_KEY).Scan(&payerName).Error)
+	assert.Equal(t, "Satoshi", payerName)
+}
+
+func TestImportDatabase_NewerVersion(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	exportDir := t.TempDir()
+	require.NoError(t, ExportDatabaseToDir(svc.DB, exportDir))
+
+	migrationsFile, err := os.OpenFile(filepath.Join(exportDir, migrationsFileName), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = migrationsFile.WriteString(`{"id":"999999999999_from_the_future"}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, migrationsFile.Close())
+
+	err = ImportDatabase(svc.DB, exportDir)
+	assert.ErrorContains(t, err, "newer version")
+}
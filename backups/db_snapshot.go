@@ -0,0 +1,43 @@
+package backups
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+// writeSqliteSnapshot adds a consistent copy of the live SQLite database to the zip as nwc.db.
+// VACUUM INTO takes a read transaction, so the hub can keep writing while the backup is taken.
+func writeSqliteSnapshot(gormDB *gorm.DB, zw *zip.Writer, tmpDir string) error {
+	snapshotDir, err := os.MkdirTemp(tmpDir, "albyhub-snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	defer os.RemoveAll(snapshotDir)
+
+	snapshotPath := filepath.Join(snapshotDir, "nwc.db")
+	err = gormDB.Exec("VACUUM INTO ?", snapshotPath).Error
+	if err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	snapshotFile, err := os.Open(snapshotPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database snapshot: %w", err)
+	}
+	defer snapshotFile.Close()
+
+	fw, err := zw.Create("nwc.db")
+	if err != nil {
+		return fmt.Errorf("failed to create zip entry: %w", err)
+	}
+	_, err = io.Copy(fw, snapshotFile)
+	if err != nil {
+		return fmt.Errorf("failed to write database snapshot to zip: %w", err)
+	}
+	return nil
+}
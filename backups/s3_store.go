@@ -0,0 +1,298 @@
+package backups
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible backup store (AWS S3, MinIO, Backblaze B2, ...)
+type S3Config struct {
+	// e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000
+	Endpoint        string
+	Bucket          string
+	Region          string
+	Prefix          string
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+// s3Store talks to the S3 REST API directly using path-style requests
+// signed with AWS Signature Version 4
+type s3Store struct {
+	endpoint   *url.URL
+	cfg        S3Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("S3 endpoint and bucket are required")
+	}
+	if cfg.AccessKeyId == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("S3 access key id and secret access key are required")
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid S3 endpoint scheme: %s", endpoint.Scheme)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Prefix != "" && !strings.HasSuffix(cfg.Prefix, "/") {
+		cfg.Prefix += "/"
+	}
+
+	return &s3Store{
+		endpoint: endpoint,
+		cfg:      cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Minute,
+		},
+		now: time.Now,
+	}, nil
+}
+
+func (store *s3Store) Put(ctx context.Context, name string, r io.ReadSeeker) error {
+	if err := validateBackupFileName(name); err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return fmt.Errorf("failed to hash backup: %w", err)
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	req, err := store.newRequest(ctx, http.MethodPut, store.cfg.Prefix+name, nil, io.NopCloser(r), hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := store.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload backup: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (store *s3Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := validateBackupFileName(name); err != nil {
+		return nil, err
+	}
+
+	req, err := store.newRequest(ctx, http.MethodGet, store.cfg.Prefix+name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := store.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download backup: %w", err)
+	}
+	return resp.Body, nil
+}
+
+func (store *s3Store) Delete(ctx context.Context, name string) error {
+	if err := validateBackupFileName(name); err != nil {
+		return err
+	}
+
+	req, err := store.newRequest(ctx, http.MethodDelete, store.cfg.Prefix+name, nil, nil, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	resp, err := store.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete backup: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (store *s3Store) List(ctx context.Context) ([]StoredBackup, error) {
+	backups := []StoredBackup{}
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", store.cfg.Prefix+backupFilePrefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		req, err := store.newRequest(ctx, http.MethodGet, "", query, nil, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := store.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups: %w", err)
+		}
+		var result s3ListBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup list: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, store.cfg.Prefix)
+			createdAt, ok := parseBackupFileName(name)
+			if !ok {
+				continue
+			}
+			backups = append(backups, StoredBackup{
+				Name:      name,
+				Size:      object.Size,
+				CreatedAt: createdAt,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		continuationToken = result.NextContinuationToken
+	}
+	sortBackups(backups)
+
+	return backups, nil
+}
+
+func (store *s3Store) do(req *http.Request) (*http.Response, error) {
+	resp, err := store.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
+
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (store *s3Store) newRequest(ctx context.Context, method string, key string, query url.Values, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	requestUrl := *store.endpoint
+	requestUrl.Path = store.endpoint.Path + "/" + store.cfg.Bucket + "/" + key
+	requestUrl.RawPath = s3EscapePath(requestUrl.Path)
+	requestUrl.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(req, payloadHash, store.cfg.Region, store.cfg.AccessKeyId, store.cfg.SecretAccessKey, store.now())
+	return req, nil
+}
+
+// signS3Request adds an AWS Signature Version 4 Authorization header to the request
+func signS3Request(req *http.Request, payloadHash string, region string, accessKeyId string, secretAccessKey string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalRequestHash[:])
+
+	signingKey := hmacSha256([]byte("AWS4"+secretAccessKey), date)
+	signingKey = hmacSha256(signingKey, region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKeyId, scope, signedHeaders, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape percent-encodes everything except unreserved characters as required by SigV4
+func s3Escape(value string) string {
+	var sb strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
@@ -0,0 +1,150 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StoredBackup describes a backup file in a Store
+type StoredBackup struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Store is a destination for encrypted backup files
+type Store interface {
+	Put(ctx context.Context, name string, r io.ReadSeeker) error
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	Delete(ctx context.Context, name string) error
+	// List returns the backups in the store, newest first
+	List(ctx context.Context) ([]StoredBackup, error)
+}
+
+const backupFilePrefix = "albyhub-backup-"
+const backupFileExtension = ".bkp"
+const backupFileTimeFormat = "20060102T150405Z"
+
+func backupFileName(createdAt time.Time) string {
+	return backupFilePrefix + createdAt.UTC().Format(backupFileTimeFormat) + backupFileExtension
+}
+
+func parseBackupFileName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, backupFileExtension) {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(backupFileTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, backupFilePrefix), backupFileExtension))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
+
+func sortBackups(backups []StoredBackup) {
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+}
+
+func validateBackupFileName(name string) error {
+	if _, ok := parseBackupFileName(name); !ok {
+		return fmt.Errorf("invalid backup file name: %s", name)
+	}
+	return nil
+}
+
+type localStore struct {
+	dir string
+}
+
+// NewLocalStore stores backups in a directory on the local filesystem
+func NewLocalStore(dir string) Store {
+	return &localStore{
+		dir: dir,
+	}
+}
+
+func (store *localStore) Put(ctx context.Context, name string, r io.ReadSeeker) error {
+	if err := validateBackupFileName(name); err != nil {
+		return err
+	}
+
+	err := os.MkdirAll(store.dir, 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// write to a temporary file first so that an interrupted backup
+	// never shows up as a complete one
+	tmpFile, err := os.CreateTemp(store.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = io.Copy(tmpFile, r)
+	if err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	return os.Rename(tmpFile.Name(), filepath.Join(store.dir, name))
+}
+
+func (store *localStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	if err := validateBackupFileName(name); err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(store.dir, name))
+}
+
+func (store *localStore) Delete(ctx context.Context, name string) error {
+	if err := validateBackupFileName(name); err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(store.dir, name))
+}
+
+func (store *localStore) List(ctx context.Context) ([]StoredBackup, error) {
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []StoredBackup{}, nil
+		}
+		return nil, err
+	}
+
+	backups := []StoredBackup{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		createdAt, ok := parseBackupFileName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, StoredBackup{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sortBackups(backups)
+
+	return backups, nil
+}
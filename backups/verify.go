@@ -0,0 +1,360 @@
+package backups
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/db/migrations"
+	"github.com/getAlby/hub/events"
+)
+
+// VerificationReport describes the contents of a backup checked by VerifyBackup
+type VerificationReport struct {
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+
+	// only set for backups created by hubs which write a manifest
+	Manifest *Manifest `json:"manifest,omitempty"`
+	// "sqlite" if the backup contains nwc.db, "export" if it contains a database export
+	DatabaseFormat string `json:"databaseFormat"`
+	FileCount      int    `json:"fileCount"`
+	// number of files from the node storage directory
+	NodeStorageFileCount int `json:"nodeStorageFileCount"`
+
+	LatestMigration string `json:"latestMigration"`
+	// migrations missing from this version of the hub, i.e. the backup was created by a newer version
+	UnknownMigrations []string `json:"unknownMigrations"`
+	// migrations which will be applied when the backup is restored
+	PendingMigrationCount int `json:"pendingMigrationCount"`
+
+	MnemonicDecryptable       bool `json:"mnemonicDecryptable"`
+	NostrSecretKeyDecryptable bool `json:"nostrSecretKeyDecryptable"`
+
+	AppCount         int64 `json:"appCount"`
+	TransactionCount int64 `json:"transactionCount"`
+	// number of channels in the static channel backup, if the backup contains one.
+	// Backups of LND nodes do not contain a channel backup.
+	ChannelCount *int `json:"channelCount,omitempty"`
+}
+
+type backupUserConfig struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// backupDatabase gives read-only access to the database in a backup
+type backupDatabase struct {
+	migrationIds     []string
+	userConfigs      []backupUserConfig
+	appCount         int64
+	transactionCount int64
+}
+
+// VerifyBackup decrypts a backup into a temporary directory outside of the hub work directory
+// and checks that it can be restored: the zip is valid, the database can be opened,
+// its migrations are known and the wallet keys can be decrypted with the unlock password.
+// An error is only returned if the backup cannot be decrypted or read at all.
+func VerifyBackup(r io.Reader, unlockPassword string) (*VerificationReport, error) {
+	tmpDir, err := os.MkdirTemp("", "albyhub-verify-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dr, err := DecryptingReader(r, unlockPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decrypted reader: %w", err)
+	}
+
+	zipFile, err := os.Create(filepath.Join(tmpDir, "backup.zip"))
+	if err != nil {
+		return nil, err
+	}
+	defer zipFile.Close()
+
+	zipSize, err := io.Copy(zipFile, dr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup: %w", err)
+	}
+
+	zr, err := zip.NewReader(zipFile, zipSize)
+	if err != nil {
+		// decrypting with the wrong password does not fail, but results in garbage
+		return nil, errors.New("failed to read backup: wrong unlock password or corrupted backup file")
+	}
+
+	report := &VerificationReport{
+		Errors:            []string{},
+		UnknownMigrations: []string{},
+		FileCount:         len(zr.File),
+	}
+	addError := func(format string, args ...interface{}) {
+		report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+	}
+
+	extractDir := filepath.Join(tmpDir, "extract")
+	files := map[string]*zip.File{}
+	for _, file := range zr.File {
+		if !filepath.IsLocal(filepath.FromSlash(file.Name)) {
+			addError("invalid file path: %s", file.Name)
+			continue
+		}
+		files[file.Name] = file
+
+		// extracting every file verifies its checksum
+		err = extractZipFile(file, filepath.Join(extractDir, filepath.FromSlash(file.Name)))
+		if err != nil {
+			addError("corrupted file %s: %v", file.Name, err)
+			continue
+		}
+
+		if !isBackupMetadataFile(file.Name) {
+			report.NodeStorageFileCount++
+		}
+	}
+
+	if _, ok := files[ManifestFileName]; ok {
+		manifestBytes, err := os.ReadFile(filepath.Join(extractDir, ManifestFileName))
+		if err == nil {
+			err = json.Unmarshal(manifestBytes, &report.Manifest)
+		}
+		if err != nil {
+			addError("invalid manifest: %v", err)
+		}
+	}
+
+	// backups of LND, Phoenixd and Cashu hubs never contain node storage
+	if report.NodeStorageFileCount == 0 && (report.Manifest == nil || !slices.Contains([]string{config.LNDBackendType, config.PhoenixBackendType, config.CashuBackendType}, report.Manifest.LNBackendType)) {
+		addError("backup does not contain the node storage, channels can only be recovered with the static channel backup")
+	}
+
+	// automatic backups contain the latest static channel backup,
+	// manual backups of LDK nodes contain the latest one saved by the node
+	channelsBackupFileName := ""
+	if _, ok := files[StaticChannelBackupFileName]; ok {
+		channelsBackupFileName = StaticChannelBackupFileName
+	} else {
+		for name := range files {
+			// file names are timestamps
+			if path.Dir(name) == StaticChannelBackupsDirectory && path.Ext(name) == ".json" && name > channelsBackupFileName {
+				channelsBackupFileName = name
+			}
+		}
+	}
+
+	if channelsBackupFileName != "" {
+		var channelsBackup events.StaticChannelsBackupEvent
+		channelsBackupBytes, err := os.ReadFile(filepath.Join(extractDir, filepath.FromSlash(channelsBackupFileName)))
+		if err == nil {
+			err = json.Unmarshal(channelsBackupBytes, &channelsBackup)
+		}
+		if err != nil {
+			addError("invalid static channel backup: %v", err)
+		} else {
+			channelCount := len(channelsBackup.Channels)
+			report.ChannelCount = &channelCount
+		}
+	}
+
+	var backupDB *backupDatabase
+	switch {
+	case files["nwc.db"] != nil:
+		report.DatabaseFormat = "sqlite"
+		backupDB, err = readSqliteBackupDatabase(filepath.Join(extractDir, "nwc.db"))
+	case files[DatabaseExportDirectory+"/"+migrationsFileName] != nil:
+		report.DatabaseFormat = "export"
+		backupDB, err = readExportBackupDatabase(filepath.Join(extractDir, DatabaseExportDirectory))
+	default:
+		err = errors.New("backup does not contain a database")
+	}
+	if err != nil {
+		addError("failed to read database: %v", err)
+	}
+
+	if backupDB != nil {
+		report.AppCount = backupDB.appCount
+		report.TransactionCount = backupDB.transactionCount
+
+		knownMigrationIds := migrations.MigrationIds()
+		for _, migrationId := range backupDB.migrationIds {
+			if !slices.Contains(knownMigrationIds, migrationId) {
+				report.UnknownMigrations = append(report.UnknownMigrations, migrationId)
+			}
+		}
+		for _, migrationId := range knownMigrationIds {
+			if !slices.Contains(backupDB.migrationIds, migrationId) {
+				report.PendingMigrationCount++
+			}
+		}
+		if len(backupDB.migrationIds) > 0 {
+			report.LatestMigration = slices.Max(backupDB.migrationIds)
+		}
+		if len(report.UnknownMigrations) > 0 {
+			addError("backup was created by a newer version of Alby Hub")
+		}
+
+		report.MnemonicDecryptable = checkUserConfigDecryptable(backupDB.userConfigs, "Mnemonic", unlockPassword)
+		if !report.MnemonicDecryptable {
+			addError("Mnemonic is missing or cannot be decrypted with the unlock password")
+		}
+		report.NostrSecretKeyDecryptable = checkUserConfigDecryptable(backupDB.userConfigs, "NostrSecretKey", unlockPassword)
+		if !report.NostrSecretKeyDecryptable {
+			addError("NostrSecretKey is missing or cannot be decrypted with the unlock password")
+		}
+	}
+
+	report.Valid = len(report.Errors) == 0
+	return report, nil
+}
+
+func isBackupMetadataFile(name string) bool {
+	return name == "nwc.db" ||
+		name == ManifestFileName ||
+		name == StaticChannelBackupFileName ||
+		path.Dir(name) == StaticChannelBackupsDirectory ||
+		strings.HasPrefix(name, DatabaseExportDirectory+"/")
+}
+
+func checkUserConfigDecryptable(userConfigs []backupUserConfig, key string, unlockPassword string) bool {
+	for _, userConfig := range userConfigs {
+		if userConfig.Key != key {
+			continue
+		}
+		if userConfig.Value == "" || !userConfig.Encrypted {
+			return false
+		}
+		value, err := config.AesGcmDecryptWithPassword(userConfig.Value, unlockPassword)
+		return err == nil && value != ""
+	}
+	return false
+}
+
+func readSqliteBackupDatabase(path string) (*backupDatabase, error) {
+	gormDB, err := db.NewReadOnlySqliteDB(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		sqlDB, err := gormDB.DB()
+		if err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	backupDB := &backupDatabase{}
+	backupDB.migrationIds, err = listMigrationIds(gormDB)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gormDB.Model(&db.UserConfig{}).Select("key, value, encrypted").Scan(&backupDB.userConfigs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user configs: %w", err)
+	}
+
+	for table, count := range map[string]*int64{
+		"apps":         &backupDB.appCount,
+		"transactions": &backupDB.transactionCount,
+	} {
+		err = gormDB.Table(table).Count(count).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+	}
+
+	return backupDB, nil
+}
+
+func readExportBackupDatabase(dir string) (*backupDatabase, error) {
+	backupDB := &backupDatabase{}
+
+	err := readJsonLines(filepath.Join(dir, migrationsFileName), func(line []byte) error {
+		var migration struct {
+			Id string `json:"id"`
+		}
+		err := json.Unmarshal(line, &migration)
+		if err != nil {
+			return err
+		}
+		backupDB.migrationIds = append(backupDB.migrationIds, migration.Id)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	err = readJsonLines(filepath.Join(dir, "user_configs.jsonl"), func(line []byte) error {
+		var userConfig backupUserConfig
+		err := json.Unmarshal(line, &userConfig)
+		if err != nil {
+			return err
+		}
+		backupDB.userConfigs = append(backupDB.userConfigs, userConfig)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user configs: %w", err)
+	}
+
+	for _, table := range exportedTables {
+		var count int64
+		err = readJsonLines(filepath.Join(dir, table.name+".jsonl"), func(line []byte) error {
+			if !json.Valid(line) {
+				return errors.New("invalid JSON")
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table.name, err)
+		}
+		switch table.name {
+		case "apps":
+			backupDB.appCount = count
+		case "transactions":
+			backupDB.transactionCount = count
+		}
+	}
+
+	return backupDB, nil
+}
+
+func extractZipFile(file *zip.File, destination string) error {
+	if strings.HasSuffix(file.Name, "/") {
+		return os.MkdirAll(destination, 0700)
+	}
+
+	err := os.MkdirAll(filepath.Dir(destination), 0700)
+	if err != nil {
+		return err
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = io.Copy(w, r)
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
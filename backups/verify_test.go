@@ -0,0 +1,170 @@
+package backups
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestVerifyBackup(t *testing.T) {
+	svc, err := tests.CreateTestServiceWithMnemonic(t, "limit reward expect search tissue call visa fit thank cream brave jump", "123")
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	_, _, err = tests.CreateApp(svc)
+	require.NoError(t, err)
+
+	backupDir := t.TempDir()
+	svc.Cfg.GetEnv().Workdir = t.TempDir()
+	svc.Cfg.GetEnv().BackupDir = backupDir
+	svc.Cfg.GetEnv().LNBackendType = "LDK"
+
+	backupsService := NewBackupsService(svc.DB, svc.Cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backupsService.Start(ctx, "123")
+	backupsService.ConsumeEvent(ctx, &events.Event{
+		Event: "nwc_backup_channels",
+		Properties: &events.StaticChannelsBackupEvent{
+			Channels: []events.ChannelBackup{{ChannelID: "channel1"}},
+		},
+	}, nil)
+
+	backup, err := backupsService.CreateBackup(ctx)
+	require.NoError(t, err)
+	backupBytes, err := os.ReadFile(filepath.Join(backupDir, backup.Name))
+	require.NoError(t, err)
+
+	report, err := VerifyBackup(bytes.NewReader(backupBytes), "123")
+	require.NoError(t, err)
+	// hot backups cannot be restored on an LDK node
+	assert.False(t, report.Valid)
+	assert.Equal(t, []string{"backup does not contain the node storage, channels can only be recovered with the static channel backup"}, report.Errors)
+	assert.Equal(t, 0, report.NodeStorageFileCount)
+	require.NotNil(t, report.Manifest)
+	assert.Equal(t, ManifestVersion, report.Manifest.Version)
+	assert.True(t, report.Manifest.HotBackup)
+	assert.True(t, report.MnemonicDecryptable)
+	assert.True(t, report.NostrSecretKeyDecryptable)
+	assert.Empty(t, report.UnknownMigrations)
+	assert.Equal(t, 0, report.PendingMigrationCount)
+	assert.NotEmpty(t, report.LatestMigration)
+	assert.Equal(t, int64(1), report.AppCount)
+	assert.Equal(t, int64(0), report.TransactionCount)
+	require.NotNil(t, report.ChannelCount)
+	assert.Equal(t, 1, *report.ChannelCount)
+	if svc.DB.Dialector.Name() == "postgres" {
+		assert.Equal(t, "export", report.DatabaseFormat)
+	} else {
+		assert.Equal(t, "sqlite", report.DatabaseFormat)
+	}
+
+	_, err = VerifyBackup(bytes.NewReader(backupBytes), "wrong password")
+	assert.ErrorContains(t, err, "wrong unlock password or corrupted backup file")
+}
+
+func TestVerifyBackup_DatabaseExport(t *testing.T) {
+	svc, err := tests.CreateTestServiceWithMnemonic(t, "limit reward expect search tissue call visa fit thank cream brave jump", "123")
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	_, _, err = tests.CreateApp(svc)
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	w, err := EncryptingWriter(&buffer, "123")
+	require.NoError(t, err)
+	zw := zip.NewWriter(w)
+	require.NoError(t, WriteManifest(zw, "postgres", "LDK", false, time.Now()))
+	require.NoError(t, ExportDatabaseToZip(svc.DB, zw))
+	require.NoError(t, writeZipFile(zw, "ldk/storage/ldk_node_data.sqlite", []byte("node data")))
+	require.NoError(t, zw.Close())
+
+	report, err := VerifyBackup(bytes.NewReader(buffer.Bytes()), "123")
+	require.NoError(t, err)
+	assert.True(t, report.Valid, report.Errors)
+	assert.Equal(t, "export", report.DatabaseFormat)
+	assert.Equal(t, int64(1), report.AppCount)
+	assert.Equal(t, 1, report.NodeStorageFileCount)
+	assert.True(t, report.MnemonicDecryptable)
+	assert.Nil(t, report.ChannelCount)
+}
+
+func TestVerifyBackup_LDKStaticChannelBackups(t *testing.T) {
+	svc, err := tests.CreateTestServiceWithMnemonic(t, "limit reward expect search tissue call visa fit thank cream brave jump", "123")
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	olderChannelsBackup, err := json.Marshal(&events.StaticChannelsBackupEvent{
+		Channels: []events.ChannelBackup{{ChannelID: "channel1"}},
+	})
+	require.NoError(t, err)
+	latestChannelsBackup, err := json.Marshal(&events.StaticChannelsBackupEvent{
+		Channels: []events.ChannelBackup{{ChannelID: "channel1"}, {ChannelID: "channel2"}},
+	})
+	require.NoError(t, err)
+
+	// manual backups contain the static channel backups saved by LDK instead of static_channel_backup.json
+	var buffer bytes.Buffer
+	w, err := EncryptingWriter(&buffer, "123")
+	require.NoError(t, err)
+	zw := zip.NewWriter(w)
+	require.NoError(t, WriteManifest(zw, "postgres", "LDK", false, time.Now()))
+	require.NoError(t, ExportDatabaseToZip(svc.DB, zw))
+	require.NoError(t, writeZipFile(zw, StaticChannelBackupsDirectory+"/2025-01-02T10-00-00.json", latestChannelsBackup))
+	require.NoError(t, writeZipFile(zw, StaticChannelBackupsDirectory+"/2025-01-01T10-00-00.json", olderChannelsBackup))
+	require.NoError(t, writeZipFile(zw, "ldk/storage/ldk_node_data.sqlite", []byte("node data")))
+	require.NoError(t, zw.Close())
+
+	report, err := VerifyBackup(bytes.NewReader(buffer.Bytes()), "123")
+	require.NoError(t, err)
+	assert.True(t, report.Valid, report.Errors)
+	assert.Equal(t, 1, report.NodeStorageFileCount)
+	require.NotNil(t, report.ChannelCount)
+	assert.Equal(t, 2, *report.ChannelCount)
+}
+
+func TestVerifyBackup_MissingDatabase(t *testing.T) {
+	var buffer bytes.Buffer
+	w, err := EncryptingWriter(&buffer, "123")
+	require.NoError(t, err)
+	zw := zip.NewWriter(w)
+	require.NoError(t, writeZipFile(zw, "../outside", []byte{}))
+	require.NoError(t, zw.Close())
+
+	report, err := VerifyBackup(bytes.NewReader(buffer.Bytes()), "123")
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Contains(t, report.Errors, "invalid file path: ../outside")
+	assert.Contains(t, report.Errors, "failed to read database: backup does not contain a database")
+}
+
+func TestVerifyBackup_LNDWithoutNodeStorage(t *testing.T) {
+	svc, err := tests.CreateTestServiceWithMnemonic(t, "limit reward expect search tissue call visa fit thank cream brave jump", "123")
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	var buffer bytes.Buffer
+	w, err := EncryptingWriter(&buffer, "123")
+	require.NoError(t, err)
+	zw := zip.NewWriter(w)
+	require.NoError(t, WriteManifest(zw, "postgres", "LND", false, time.Now()))
+	require.NoError(t, ExportDatabaseToZip(svc.DB, zw))
+	require.NoError(t, zw.Close())
+
+	report, err := VerifyBackup(bytes.NewReader(buffer.Bytes()), "123")
+	require.NoError(t, err)
+	assert.True(t, report.Valid, report.Errors)
+	assert.Equal(t, 0, report.NodeStorageFileCount)
+}
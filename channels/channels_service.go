@@ -0,0 +1,260 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/mempool"
+)
+
+type PendingChannelClose = db.PendingChannelClose
+
+type channelsService struct {
+	ctx         context.Context
+	db          *gorm.DB
+	cfg         config.Config
+	getLNClient func() lnclient.LNClient
+	mempool     *mempool.Client
+	esplora     *mempool.Client
+}
+
+type ChannelsService interface {
+	CloseChannel(ctx context.Context, closeChannelRequest *lnclient.CloseChannelRequest) (*lnclient.CloseChannelResponse, error)
+	ScheduleCloseChannel(closeChannelRequest *lnclient.CloseChannelRequest, maxFeeRate uint64) (*PendingChannelClose, error)
+	ListPendingChannelCloses() ([]PendingChannelClose, error)
+	CancelPendingChannelClose(id uint) error
+}
+
+const (
+	pendingChannelCloseCheckInterval = 10 * time.Minute
+	pendingChannelCloseMaxAttempts   = 10
+)
+
+// getLNClient is called on each use, as the LNClient is replaced when the node is restarted
+func NewChannelsService(ctx context.Context, db *gorm.DB, cfg config.Config, getLNClient func() lnclient.LNClient) ChannelsService {
+	svc := &channelsService{
+		ctx:         ctx,
+		db:          db,
+		cfg:         cfg,
+		getLNClient: getLNClient,
+		mempool:     mempool.NewClient(cfg),
+		esplora:     mempool.NewEsploraClient(cfg),
+	}
+
+	go svc.watchPendingChannelCloses()
+
+	return svc
+}
+
+func (svc *channelsService) CloseChannel(ctx context.Context, closeChannelRequest *lnclient.CloseChannelRequest) (*lnclient.CloseChannelResponse, error) {
+	lnClient := svc.getLNClient()
+	if lnClient == nil {
+		return nil, errors.New("LNClient not started")
+	}
+
+	if closeChannelRequest.Address == "" || !isXpub(closeChannelRequest.Address) {
+		return lnClient.CloseChannel(ctx, closeChannelRequest)
+	}
+
+	xpub := closeChannelRequest.Address
+	startIndex, err := mempool.GetXpubIndexStart(svc.cfg, xpub)
+	if err != nil {
+		return nil, err
+	}
+	address, index, err := svc.esplora.GetNextUnusedAddressFromXpub(ctx, svc.cfg.GetNetwork(), xpub, startIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive close address from xpub: %w", err)
+	}
+	// copy the request so the xpub is kept in pending close records
+	derivedRequest := *closeChannelRequest
+	derivedRequest.Address = address
+
+	closeChannelResponse, err := lnClient.CloseChannel(ctx, &derivedRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// the close transaction may not be broadcast yet, so do not hand out the address again
+	if err := mempool.SetXpubIndexStart(svc.cfg, xpub, index+1); err != nil {
+		logger.Logger.WithError(err).Error("Failed to update xpub index start for close address")
+	}
+	return closeChannelResponse, nil
+}
+
+func (svc *channelsService) ScheduleCloseChannel(closeChannelRequest *lnclient.CloseChannelRequest, maxFeeRate uint64) (*PendingChannelClose, error) {
+	if closeChannelRequest.Force {
+		return nil, errors.New("force closes cannot be scheduled")
+	}
+	if maxFeeRate == 0 {
+		return nil, errors.New("max fee rate must be greater than zero")
+	}
+	// otherwise the close would fail on every attempt
+	if (closeChannelRequest.FeeRate != nil || closeChannelRequest.Address != "") && !svc.supportsCloseFeeRateAndAddress() {
+		return nil, errors.New("closing fee rate and address are not supported by this node backend")
+	}
+
+	lnClient := svc.getLNClient()
+	if lnClient == nil {
+		return nil, errors.New("LNClient not started")
+	}
+	channels, err := lnClient.ListChannels(svc.ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, channel := range channels {
+		if channel.Id == closeChannelRequest.ChannelId && channel.RemotePubkey == closeChannelRequest.NodeId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("channel not found")
+	}
+
+	var existingCount int64
+	err = svc.db.Model(&db.PendingChannelClose{}).
+		Where("channel_id = ? AND state = ?", closeChannelRequest.ChannelId, constants.PENDING_CHANNEL_CLOSE_STATE_PENDING).
+		Count(&existingCount).Error
+	if err != nil {
+		return nil, err
+	}
+	if existingCount > 0 {
+		return nil, errors.New("a close is already scheduled for this channel")
+	}
+
+	pendingChannelClose := &db.PendingChannelClose{
+		NodeId:     closeChannelRequest.NodeId,
+		ChannelId:  closeChannelRequest.ChannelId,
+		MaxFeeRate: maxFeeRate,
+		FeeRate:    closeChannelRequest.FeeRate,
+		Address:    closeChannelRequest.Address,
+		State:      constants.PENDING_CHANNEL_CLOSE_STATE_PENDING,
+	}
+	if err := svc.db.Create(pendingChannelClose).Error; err != nil {
+		logger.Logger.WithError(err).Error("Failed to save pending channel close")
+		return nil, err
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"id":           pendingChannelClose.ID,
+		"channel_id":   pendingChannelClose.ChannelId,
+		"max_fee_rate": maxFeeRate,
+	}).Info("Scheduled channel close")
+
+	return pendingChannelClose, nil
+}
+
+func (svc *channelsService) ListPendingChannelCloses() ([]PendingChannelClose, error) {
+	var pendingChannelCloses []PendingChannelClose
+	if err := svc.db.Order("created_at DESC").Find(&pendingChannelCloses).Error; err != nil {
+		return nil, err
+	}
+	return pendingChannelCloses, nil
+}
+
+func (svc *channelsService) CancelPendingChannelClose(id uint) error {
+	result := svc.db.Model(&db.PendingChannelClose{}).
+		Where("id = ? AND state = ?", id, constants.PENDING_CHANNEL_CLOSE_STATE_PENDING).
+		Update("state", constants.PENDING_CHANNEL_CLOSE_STATE_CANCELED)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("pending channel close not found")
+	}
+	return nil
+}
+
+func (svc *channelsService) watchPendingChannelCloses() {
+	for {
+		select {
+		case <-time.After(pendingChannelCloseCheckInterval):
+			svc.processPendingChannelCloses()
+		case <-svc.ctx.Done():
+			logger.Logger.Info("Stopping pending channel close watcher")
+			return
+		}
+	}
+}
+
+func (svc *channelsService) processPendingChannelCloses() {
+	var pendingChannelCloses []db.PendingChannelClose
+	err := svc.db.Where("state = ?", constants.PENDING_CHANNEL_CLOSE_STATE_PENDING).Find(&pendingChannelCloses).Error
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to load pending channel closes")
+		return
+	}
+	if len(pendingChannelCloses) == 0 {
+		return
+	}
+
+	rates, err := svc.mempool.GetFeeRates(svc.ctx)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to fetch fee rates for pending channel closes")
+		return
+	}
+
+	for _, pendingChannelClose := range pendingChannelCloses {
+		if rates.HalfHourFee > pendingChannelClose.MaxFeeRate {
+			logger.Logger.WithFields(logrus.Fields{
+				"id":           pendingChannelClose.ID,
+				"fee_rate":     rates.HalfHourFee,
+				"max_fee_rate": pendingChannelClose.MaxFeeRate,
+			}).Debug("Fee rate too high for pending channel close")
+			continue
+		}
+
+		_, err := svc.CloseChannel(svc.ctx, &lnclient.CloseChannelRequest{
+			ChannelId: pendingChannelClose.ChannelId,
+			NodeId:    pendingChannelClose.NodeId,
+			FeeRate:   pendingChannelClose.FeeRate,
+			Address:   pendingChannelClose.Address,
+		})
+
+		updates := map[string]interface{}{
+			"attempts": pendingChannelClose.Attempts + 1,
+		}
+		if err != nil {
+			logger.Logger.WithError(err).WithField("id", pendingChannelClose.ID).Error("Failed to close channel for pending channel close")
+			updates["error"] = err.Error()
+			if pendingChannelClose.Attempts+1 >= pendingChannelCloseMaxAttempts {
+				updates["state"] = constants.PENDING_CHANNEL_CLOSE_STATE_FAILED
+			}
+		} else {
+			logger.Logger.WithFields(logrus.Fields{
+				"id":         pendingChannelClose.ID,
+				"channel_id": pendingChannelClose.ChannelId,
+				"fee_rate":   rates.HalfHourFee,
+			}).Info("Closing channel for pending channel close")
+			updates["error"] = ""
+			updates["state"] = constants.PENDING_CHANNEL_CLOSE_STATE_CLOSING
+		}
+
+		err = svc.db.Model(&db.PendingChannelClose{}).Where("id = ?", pendingChannelClose.ID).Updates(updates).Error
+		if err != nil {
+			logger.Logger.WithError(err).WithField("id", pendingChannelClose.ID).Error("Failed to update pending channel close")
+		}
+	}
+}
+
+// only LND lets the closing transaction fee rate and address be set
+func (svc *channelsService) supportsCloseFeeRateAndAddress() bool {
+	return svc.cfg.GetEnv().LNBackendType == config.LNDBackendType
+}
+
+func isXpub(value string) bool {
+	_, err := hdkeychain.NewKeyFromString(value)
+	return err == nil
+}
@@ -0,0 +1,148 @@
+package channels_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/channels"
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/mempool"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/tests/mocks"
+	"github.com/getAlby/hub/utils"
+)
+
+func TestScheduleCloseChannel(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	lnClient := mocks.NewMockLNClient(t)
+	lnClient.On("ListChannels", mock.Anything).Return([]lnclient.Channel{
+		{Id: "channel1", RemotePubkey: "peer1"},
+	}, nil)
+
+	svc.Cfg.GetEnv().LNBackendType = config.LNDBackendType
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channelsSvc := channels.NewChannelsService(ctx, svc.DB, svc.Cfg, func() lnclient.LNClient { return lnClient })
+
+	feeRate := uint64(3)
+	pendingChannelClose, err := channelsSvc.ScheduleCloseChannel(&lnclient.CloseChannelRequest{
+		ChannelId: "channel1",
+		NodeId:    "peer1",
+		FeeRate:   &feeRate,
+	}, 5)
+	require.NoError(t, err)
+	assert.Equal(t, constants.PENDING_CHANNEL_CLOSE_STATE_PENDING, pendingChannelClose.State)
+	assert.Equal(t, uint64(5), pendingChannelClose.MaxFeeRate)
+	assert.Equal(t, feeRate, *pendingChannelClose.FeeRate)
+
+	_, err = channelsSvc.ScheduleCloseChannel(&lnclient.CloseChannelRequest{
+		ChannelId: "channel1",
+		NodeId:    "peer1",
+	}, 5)
+	assert.EqualError(t, err, "a close is already scheduled for this channel")
+
+	_, err = channelsSvc.ScheduleCloseChannel(&lnclient.CloseChannelRequest{
+		ChannelId: "channel2",
+		NodeId:    "peer1",
+	}, 5)
+	assert.EqualError(t, err, "channel not found")
+
+	_, err = channelsSvc.ScheduleCloseChannel(&lnclient.CloseChannelRequest{
+		ChannelId: "channel1",
+		NodeId:    "peer1",
+		Force:     true,
+	}, 5)
+	assert.EqualError(t, err, "force closes cannot be scheduled")
+
+	err = channelsSvc.CancelPendingChannelClose(pendingChannelClose.ID)
+	require.NoError(t, err)
+
+	pendingChannelCloses, err := channelsSvc.ListPendingChannelCloses()
+	require.NoError(t, err)
+	require.Len(t, pendingChannelCloses, 1)
+	assert.Equal(t, constants.PENDING_CHANNEL_CLOSE_STATE_CANCELED, pendingChannelCloses[0].State)
+
+	err = channelsSvc.CancelPendingChannelClose(pendingChannelClose.ID)
+	assert.EqualError(t, err, "pending channel close not found")
+}
+
+func TestScheduleCloseChannel_UnsupportedBackend(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	svc.Cfg.GetEnv().LNBackendType = config.LDKBackendType
+	lnClient := mocks.NewMockLNClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channelsSvc := channels.NewChannelsService(ctx, svc.DB, svc.Cfg, func() lnclient.LNClient { return lnClient })
+
+	_, err = channelsSvc.ScheduleCloseChannel(&lnclient.CloseChannelRequest{
+		ChannelId: "channel1",
+		NodeId:    "peer1",
+		Address:   "bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh",
+	}, 5)
+	assert.EqualError(t, err, "closing fee rate and address are not supported by this node backend")
+}
+
+func TestCloseChannel_Xpub(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	seed := make([]byte, hdkeychain.RecommendedSeedLen)
+	masterKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	xpubKey, err := masterKey.Neuter()
+	require.NoError(t, err)
+	xpub := xpubKey.String()
+
+	// no address has received any transactions
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer esplora.Close()
+	svc.Cfg.GetEnv().LDKEsploraServer = esplora.URL
+
+	lnClient := mocks.NewMockLNClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	channelsSvc := channels.NewChannelsService(ctx, svc.DB, svc.Cfg, func() lnclient.LNClient { return lnClient })
+
+	// each close is sent to the next address, as the previous one may not be used on-chain yet
+	for i := uint32(0); i < 2; i++ {
+		expectedAddress, err := utils.DeriveAddressFromXpub(svc.Cfg.GetNetwork(), xpub, i)
+		require.NoError(t, err)
+		lnClient.On("CloseChannel", mock.Anything, &lnclient.CloseChannelRequest{
+			ChannelId: "channel1",
+			NodeId:    "peer1",
+			Address:   expectedAddress,
+		}).Return(&lnclient.CloseChannelResponse{}, nil).Once()
+
+		_, err = channelsSvc.CloseChannel(ctx, &lnclient.CloseChannelRequest{
+			ChannelId: "channel1",
+			NodeId:    "peer1",
+			Address:   xpub,
+		})
+		require.NoError(t, err)
+	}
+
+	indexStart, err := mempool.GetXpubIndexStart(svc.Cfg, xpub)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), indexStart)
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"slices"
 	"strconv"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -14,25 +16,33 @@ import (
 	"github.com/getAlby/hub/logger"
 )
 
-var expectedTables = []string{
-	"apps",
-	"app_permissions",
-	"request_events",
-	"response_events",
-	"transactions",
-	"swaps",
-	"user_configs",
-	"migrations",
-	"forwards",
-}
+var expectedTables = db.Tables
+
+const (
+	modeCopy   = "copy"
+	modeSync   = "sync"
+	modeVerify = "verify"
+)
 
 func main() {
-	var fromDSN, toDSN string
+	var fromDSN, toDSN, mode string
+	var batchSize int
+	var resume, verify bool
 
 	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
 
 	flag.StringVar(&fromDSN, "from", "", "source DSN")
 	flag.StringVar(&toDSN, "to", "", "destination DSN")
+	flag.StringVar(&mode, "mode", modeCopy, "copy: copy all rows into an empty destination, "+
+		"sync: copy rows changed since the last copy or sync, "+
+		"verify: only compare row counts and checksums")
+	// to avoid "extended protocol limited to 65535 parameters" errors on postgres
+	// see https://stackoverflow.com/questions/77372430/extended-protocol-limited-to-65535-parameters-golang-gorm
+	// the number of parameters is the number of records * columns, so 1000 is fine
+	// for tables with up to 65 columns
+	flag.IntVar(&batchSize, "batch-size", 1000, "number of rows copied per transaction")
+	flag.BoolVar(&resume, "resume", false, "continue an interrupted copy into a non-empty destination")
+	flag.BoolVar(&verify, "verify", true, "compare row counts and checksums after migrating")
 
 	flag.Parse()
 
@@ -41,6 +51,16 @@ func main() {
 		logger.Logger.Error("missing DSN")
 		os.Exit(1)
 	}
+	if mode != modeCopy && mode != modeSync && mode != modeVerify {
+		flag.Usage()
+		logger.Logger.WithField("mode", mode).Error("unknown mode")
+		os.Exit(1)
+	}
+	if batchSize <= 0 {
+		flag.Usage()
+		logger.Logger.WithField("batch_size", batchSize).Error("invalid batch size")
+		os.Exit(1)
+	}
 
 	stopDB := func(d *gorm.DB) {
 		if err := db.Stop(d); err != nil {
@@ -72,135 +92,154 @@ func main() {
 		os.Exit(1)
 	}
 
+	if mode != modeVerify {
+		err = prepareSource(fromDB)
+		if err != nil {
+			logger.Logger.WithError(err).Error("source database check failed. Migration will not proceed.")
+			os.Exit(1)
+		}
+
+		logger.Logger.WithField("mode", mode).Info("migrating...")
+		if mode == modeSync {
+			err = syncDB(fromDB, toDB, batchSize)
+		} else {
+			err = copyDB(fromDB, toDB, resume, batchSize)
+		}
+		if err != nil {
+			logger.Logger.WithError(err).Error("failed to migrate database")
+			os.Exit(1)
+		}
+		logger.Logger.Info("migration complete")
+	}
+
+	if mode == modeVerify || verify {
+		logger.Logger.Info("verifying...")
+		err = verifyDB(fromDB, toDB, batchSize)
+		if err != nil {
+			logger.Logger.WithError(err).Error("verification failed")
+			os.Exit(1)
+		}
+		logger.Logger.Info("verification complete")
+	}
+}
+
+func prepareSource(fromDB *gorm.DB) error {
 	// Check if VSS is enabled in the source database
 	var vssConfig db.UserConfig
 	result := fromDB.Where("key = ?", "LdkVssEnabled").First(&vssConfig)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			logger.Logger.Error("LdkVssEnabled config not found in source DB. Migration will not proceed.")
-		} else {
-			logger.Logger.WithError(result.Error).Error("failed to query LdkVssEnabled config from source DB")
+			return errors.New("LdkVssEnabled config not found in source DB")
 		}
-		os.Exit(1)
+		return fmt.Errorf("failed to query LdkVssEnabled config from source DB: %w", result.Error)
 	}
 
 	if vssConfig.Value != "true" {
-		logger.Logger.Error("VSS is not enabled in the source DB (LdkVssEnabled is not 'true'). Migration will not proceed.")
-		os.Exit(1)
+		return errors.New("VSS is not enabled in the source DB (LdkVssEnabled is not 'true')")
 	}
 	logger.Logger.Info("LdkVssEnabled check passed.")
 
 	// NOTE: we assume that excess request events have already been cleaned up due to the background task
 	// and only a maximum of ~1000 remain.
 	logger.Logger.Info("Deleting orphaned request events.")
-	err = fromDB.Exec("DELETE FROM request_events WHERE app_id NOT IN (SELECT id FROM apps);").Error
-
+	err := fromDB.Exec("DELETE FROM request_events WHERE app_id NOT IN (SELECT id FROM apps);").Error
 	if err != nil {
-		logger.Logger.WithError(err).Error("failed to delete orphaned request events")
-		os.Exit(1)
+		return fmt.Errorf("failed to delete orphaned request events: %w", err)
 	}
 
 	// NOTE: we assume that excess response events have already been cleaned up due to the background task
 	// and only a maximum of ~1000 remain.
 	logger.Logger.Info("Deleting orphaned response events.")
 	err = fromDB.Exec("DELETE FROM response_events WHERE request_id NOT IN (SELECT id FROM request_events);").Error
-
 	if err != nil {
-		logger.Logger.WithError(err).Error("failed to delete orphaned response events")
-		os.Exit(1)
+		return fmt.Errorf("failed to delete orphaned response events: %w", err)
 	}
 
-	logger.Logger.Info("migrating...")
-	err = migrateDB(fromDB, toDB)
-	if err != nil {
-		logger.Logger.WithError(err).Error("failed to migrate database")
-		os.Exit(1)
-	}
-
-	logger.Logger.Info("migration complete")
+	return nil
 }
 
-func migrateDB(from, to *gorm.DB) error {
-	tx := to.Begin()
-	defer tx.Rollback()
-
-	if err := tx.Error; err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+// copyDB copies all rows in batches. Every batch is committed on its own, so a
+// failed copy can be continued with resume.
+func copyDB(from, to *gorm.DB, resume bool, batchSize int) error {
+	if !resume {
+		for _, table := range migratedTables {
+			var count int64
+			err := to.Table(table.tableName()).Count(&count).Error
+			if err != nil {
+				return fmt.Errorf("failed to count rows of %s: %w", table.tableName(), err)
+			}
+			if count > 0 && table.seededByMigrations() {
+				err = to.Exec(fmt.Sprintf("DELETE FROM %s", table.tableName())).Error
+				if err != nil {
+					return fmt.Errorf("failed to delete seeded rows of %s: %w", table.tableName(), err)
+				}
+				continue
+			}
+			if count > 0 {
+				return fmt.Errorf("destination table %s is not empty: use -resume to continue an interrupted copy or -mode %s to update the destination", table.tableName(), modeSync)
+			}
+		}
 	}
 
-	// Table migration order matters: referenced tables must be migrated
-	// before referencing tables.
-
-	logger.Logger.Info("migrating apps...")
-	if err := migrateTable[db.App](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate apps: %w", err)
+	// rows created after the copy started are left for the next sync, so that
+	// referenced rows are always copied before the rows referencing them
+	maxIds := map[string]uint{}
+	for _, table := range migratedTables {
+		maxId, err := maxTableId(from, table.tableName())
+		if err != nil {
+			return err
+		}
+		maxIds[table.tableName()] = maxId
 	}
 
-	logger.Logger.Info("migrating app_permissions...")
-	if err := migrateTable[db.AppPermission](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate app_permissions: %w", err)
+	for _, table := range migratedTables {
+		logger.Logger.WithField("table", table.tableName()).Info("copying table...")
+		if _, err := table.copyRows(from, to, maxIds[table.tableName()], batchSize); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", table.tableName(), err)
+		}
 	}
 
-	logger.Logger.Info("migrating request_events...")
-	if err := migrateTable[db.RequestEvent](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate request_events: %w", err)
-	}
+	return finishMigration(to)
+}
 
-	logger.Logger.Info("migrating response_events...")
-	if err := migrateTable[db.ResponseEvent](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate response_events: %w", err)
+// syncDB copies rows which were created or updated since the previous copy or sync,
+// and removes rows which were deleted from the source. It can run while the source hub
+// is running; a final sync after stopping the hub completes the migration.
+func syncDB(from, to *gorm.DB, batchSize int) error {
+	// rows updated after the sync started are left for the next sync, so that
+	// referenced rows are always synced before the rows referencing them
+	until := time.Now()
+
+	for _, table := range migratedTables {
+		logger.Logger.WithField("table", table.tableName()).Info("syncing table...")
+		if _, err := table.syncRows(from, to, until, batchSize); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", table.tableName(), err)
+		}
 	}
 
-	logger.Logger.Info("migrating transactions...")
-	if err := migrateTable[db.Transaction](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate transactions: %w", err)
+	for _, table := range slices.Backward(migratedTables) {
+		count, err := table.deleteRemovedRows(from, to, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to delete removed rows of %s: %w", table.tableName(), err)
+		}
+		if count > 0 {
+			logger.Logger.WithFields(logrus.Fields{
+				"table": table.tableName(),
+				"rows":  count,
+			}).Info("deleted removed rows")
+		}
 	}
 
-	logger.Logger.Info("migrating user_configs...")
-	if err := migrateTable[db.UserConfig](from, tx); err != nil {
-		return fmt.Errorf("failed to migrate user_configs: %w", err)
-	}
+	return finishMigration(to)
+}
 
+func finishMigration(to *gorm.DB) error {
 	if to.Dialector.Name() == "postgres" {
 		logger.Logger.Info("resetting sequences...")
-		if err := resetSequences(tx); err != nil {
+		if err := resetSequences(to); err != nil {
 			return fmt.Errorf("failed to reset sequences: %w", err)
 		}
 	}
-
-	tx.Commit()
-	if err := tx.Error; err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return nil
-}
-
-func migrateTable[T any](from, to *gorm.DB) error {
-	var data []T
-	if err := from.Find(&data).Error; err != nil {
-		return fmt.Errorf("failed to fetch data: %w", err)
-	}
-
-	if len(data) == 0 {
-		return nil
-	}
-
-	// to avoid "failed to migrate transactions: failed to insert data: extended protocol limited to 65535 parameters"
-	// see https://stackoverflow.com/questions/77372430/extended-protocol-limited-to-65535-parameters-golang-gorm
-	// max statements is 65535
-	// but it's the number of records * columns
-	// to be safe, using a lower value of 1000.
-	// this will fail if any table has more than 65 columns, which I doubt we will have
-	max := 1000
-	for i := 0; i < len(data); i += max {
-		j := min(i+max, len(data))
-
-		if err := to.Create(data[i:j]).Error; err != nil {
-			return fmt.Errorf("failed to insert data: %w", err)
-		}
-	}
-
 	return nil
 }
 
@@ -230,7 +269,8 @@ func listTables(db *gorm.DB) ([]string, error) {
 
 	switch db.Dialector.Name() {
 	case "sqlite":
-		query = "SELECT name FROM sqlite_master WHERE type='table'  AND name NOT LIKE 'sqlite_%';"
+		// exclude the shadow tables of the transaction_search full-text index
+		query = "SELECT name FROM sqlite_master WHERE type='table'  AND name NOT LIKE 'sqlite_%' AND name NOT GLOB 'transaction_search_*';"
 	case "postgres":
 		query = "SELECT tablename FROM pg_tables WHERE schemaname = 'public';"
 	default:
@@ -271,7 +311,17 @@ func resetSequences(db *gorm.DB) error {
 		{"request_events", "request_events_id_seq"},
 		{"response_events", "response_events_id_seq"},
 		{"transactions", "transactions_id_seq"},
+		{"swaps", "swaps_id_seq"},
 		{"user_configs", "user_configs_id_seq"},
+		{"forwards", "forwards_id_seq"},
+		{"pending_channel_closes", "pending_channel_closes_id_seq"},
+		{"lsps", "lsps_id_seq"},
+		{"lsp_orders", "lsp_orders_id_seq"},
+		{"payment_approvals", "payment_approvals_id_seq"},
+		{"request_archives", "request_archives_id_seq"},
+		{"response_archives", "response_archives_id_seq"},
+		{"nip47_notifications", "nip47_notifications_id_seq"},
+		{"labels", "labels_id_seq"},
 	}
 
 	for _, req := range resetReqs {
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -64,6 +65,14 @@ func TestSchemaCheck(t *testing.T) {
 	}
 }
 
+func TestMigratedTables(t *testing.T) {
+	tables := slices.Clone(skippedTables)
+	for _, table := range migratedTables {
+		tables = append(tables, table.tableName())
+	}
+	require.ElementsMatch(t, db.Tables, tables)
+}
+
 func TestMigrate(t *testing.T) {
 	type testCase struct {
 		name      string
@@ -104,12 +113,95 @@ func TestMigrate(t *testing.T) {
 			require.NoError(t, err)
 			defer env.cleanup(t)
 
-			err = migrateDB(env.source, env.dest)
+			err = copyDB(env.source, env.dest, false, 1)
 			require.NoError(t, err)
+
+			err = verifyDB(env.source, env.dest, 1)
+			require.NoError(t, err)
+
+			// the destination is not empty anymore
+			err = copyDB(env.source, env.dest, false, 1)
+			require.ErrorContains(t, err, "destination table apps is not empty")
 		})
 	}
 }
 
+func TestMigrateResume(t *testing.T) {
+	env, err := setupTest(t, getTestSqliteURI(2), getTestSqliteURI(3))
+	require.NoError(t, err)
+	defer env.cleanup(t)
+
+	// simulate an interrupted copy which only copied the first app
+	var app db.App
+	require.NoError(t, env.source.Order("id").First(&app).Error)
+	require.NoError(t, env.dest.Create(&app).Error)
+
+	err = verifyDB(env.source, env.dest, 1000)
+	require.ErrorContains(t, err, "tables differ")
+
+	err = copyDB(env.source, env.dest, true, 1)
+	require.NoError(t, err)
+
+	err = verifyDB(env.source, env.dest, 1000)
+	require.NoError(t, err)
+}
+
+func TestSync(t *testing.T) {
+	env, err := setupTest(t, getTestSqliteURI(4), getTestSqliteURI(5))
+	require.NoError(t, err)
+	defer env.cleanup(t)
+
+	baseTime := time.Date(2025, 01, 15, 8, 0, 0, 0, time.UTC)
+	transaction := &db.Transaction{
+		Type:        "incoming",
+		State:       "PENDING",
+		AmountMsat:  1000,
+		PaymentHash: "payment-hash-1",
+		Description: "coffee",
+		CreatedAt:   baseTime,
+		UpdatedAt:   baseTime,
+	}
+	create(t, env.source, transaction)
+
+	err = copyDB(env.source, env.dest, false, 1000)
+	require.NoError(t, err)
+
+	// changes while the source hub keeps running
+	require.NoError(t, env.source.Model(transaction).Updates(map[string]interface{}{
+		"state":       "SETTLED",
+		"description": "tea",
+	}).Error)
+	create(t, env.source, &db.Transaction{
+		Type:        "outgoing",
+		State:       "SETTLED",
+		AmountMsat:  2000,
+		PaymentHash: "payment-hash-2",
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	})
+	var app db.App
+	require.NoError(t, env.source.Where("name = ?", "test2").First(&app).Error)
+	require.NoError(t, env.source.Delete(&app).Error)
+
+	err = verifyDB(env.source, env.dest, 1000)
+	require.ErrorContains(t, err, "tables differ")
+
+	err = syncDB(env.source, env.dest, 1)
+	require.NoError(t, err)
+
+	err = verifyDB(env.source, env.dest, 1000)
+	require.NoError(t, err)
+
+	var syncedTransaction db.Transaction
+	require.NoError(t, env.dest.First(&syncedTransaction, transaction.ID).Error)
+	require.Equal(t, "SETTLED", syncedTransaction.State)
+
+	// the search index of the destination is updated
+	var searchCount int64
+	require.NoError(t, env.dest.Raw("SELECT COUNT(*) FROM transaction_search WHERE content MATCH ?", "tea").Scan(&searchCount).Error)
+	require.Equal(t, int64(1), searchCount)
+}
+
 func getTestSqliteURI(dbIndex int) string {
 	if uri := os.Getenv("TEST_DB_MIGRATE_SQLITE_URI"); uri != "" {
 		return uri
@@ -200,6 +292,89 @@ func insertMockData(t *testing.T, tx *gorm.DB) {
 		UpdatedAt:     baseTime,
 	}
 	create(t, tx, app2Perm)
+
+	requestEvent := &db.RequestEvent{
+		App:       *app1,
+		NostrId:   "request-event-1",
+		Method:    "pay_invoice",
+		State:     db.REQUEST_EVENT_STATE_HANDLER_EXECUTED,
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	}
+	create(t, tx, requestEvent)
+
+	create(t, tx, &db.Swap{
+		SwapId:    "swap-1",
+		Type:      "out",
+		State:     "PENDING",
+		SwapTree:  datatypes.JSON("{}"),
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+	create(t, tx, &db.Forward{
+		OutboundAmountForwardedMsat: 100000,
+		TotalFeeEarnedMsat:          100,
+		CreatedAt:                   baseTime,
+		UpdatedAt:                   baseTime,
+	})
+	create(t, tx, &db.PendingChannelClose{
+		NodeId:     "node-1",
+		ChannelId:  "channel-1",
+		MaxFeeRate: 5,
+		State:      "PENDING",
+		CreatedAt:  baseTime,
+		UpdatedAt:  baseTime,
+	})
+	create(t, tx, &db.LSP{
+		Name:       "LSP",
+		Identifier: "lsp",
+		CreatedAt:  baseTime,
+		UpdatedAt:  baseTime,
+	})
+	create(t, tx, &db.LSPOrder{
+		LSPIdentifier: "lsp",
+		OrderId:       "order-1",
+		Amount:        1000000,
+		CreatedAt:     baseTime,
+		UpdatedAt:     baseTime,
+	})
+	create(t, tx, &db.PaymentApproval{
+		App:            *app1,
+		RequestEventId: requestEvent.ID,
+		Method:         "pay_invoice",
+		AmountMsat:     5_000_000_000_000,
+		State:          "pending",
+		ExpiresAt:      baseTime,
+		CreatedAt:      baseTime,
+		UpdatedAt:      baseTime,
+	})
+	create(t, tx, &db.RequestArchive{
+		App:            *app1,
+		RequestEventId: requestEvent.ID,
+		NostrId:        requestEvent.NostrId,
+		Method:         "pay_invoice",
+		Responses: []db.ResponseArchive{{
+			NostrId:   "response-event-1",
+			CreatedAt: baseTime,
+			UpdatedAt: baseTime,
+		}},
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+	create(t, tx, &db.OnchainNotifiedTransaction{
+		TxId:      "txid-1",
+		CreatedAt: baseTime,
+		UpdatedAt: baseTime,
+	})
+	create(t, tx, &db.Nip47Notification{
+		App:              *app1,
+		NotificationType: "payment_received",
+		Payload:          datatypes.JSON("{}"),
+		Tags:             datatypes.JSON("[]"),
+		State:            "published",
+		CreatedAt:        baseTime,
+		UpdatedAt:        baseTime,
+	})
 }
 
 func create[T any](t *testing.T, tx *gorm.DB, v T) *gorm.DB {
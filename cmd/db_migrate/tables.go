@@ -0,0 +1,336 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/transactions/metadataindex"
+)
+
+// rows updated shortly before the latest synced row may have been committed after
+// the previous sync read the source, so they are synced again
+const syncOverlap = 5 * time.Minute
+
+// progress is logged at most this often per table
+const progressInterval = 5 * time.Second
+
+type tableMigrator interface {
+	tableName() string
+	// copyRows copies rows with an id above the highest id in the destination,
+	// up to maxId, so an interrupted copy continues where it stopped
+	copyRows(from, to *gorm.DB, maxId uint, batchSize int) (int, error)
+	// syncRows upserts rows that were updated since the latest update in the
+	// destination or that were not copied yet, up to until
+	syncRows(from, to *gorm.DB, until time.Time, batchSize int) (int, error)
+	// deleteRemovedRows deletes rows from the destination which no longer exist in the source
+	deleteRemovedRows(from, to *gorm.DB, batchSize int) (int, error)
+	checksum(gormDB *gorm.DB, batchSize int) (*tableChecksum, error)
+	// seededByMigrations is true if the migrations insert default rows, which are
+	// replaced by the rows of the source
+	seededByMigrations() bool
+}
+
+type table[T any] struct {
+	name string
+	// optional, e.g. to maintain tables derived from the saved rows
+	afterSave   func(tx *gorm.DB, records []T) error
+	afterDelete func(tx *gorm.DB, ids []uint) error
+	seeded      bool
+}
+
+// Table migration order matters: referenced tables must be migrated
+// before referencing tables.
+var migratedTables = []tableMigrator{
+	&table[db.App]{name: "apps"},
+	&table[db.AppPermission]{name: "app_permissions"},
+	&table[db.RequestEvent]{name: "request_events"},
+	&table[db.ResponseEvent]{name: "response_events"},
+	// the normalized metadata and search index are derived from the transactions
+	&table[db.Transaction]{name: "transactions", afterSave: indexTransactions, afterDelete: deleteTransactionIndex},
+	&table[db.Swap]{name: "swaps"},
+	&table[db.UserConfig]{name: "user_configs"},
+	&table[db.Forward]{name: "forwards"},
+	&table[db.PendingChannelClose]{name: "pending_channel_closes"},
+	&table[db.LSP]{name: "lsps", seeded: true},
+	&table[db.LSPOrder]{name: "lsp_orders"},
+	&table[db.PaymentApproval]{name: "payment_approvals"},
+	&table[db.RequestArchive]{name: "request_archives"},
+	&table[db.ResponseArchive]{name: "response_archives"},
+	&table[db.Nip47Notification]{name: "nip47_notifications"},
+	&table[db.Label]{name: "labels"},
+	&table[db.OnchainNotifiedTransaction]{name: "onchain_notified_transactions"},
+}
+
+// tables which are not copied: the migrations are applied to the destination
+// before copying and the other tables are derived from the migrated rows
+var skippedTables = []string{
+	"migrations",
+	"transaction_metadata_entries",
+	"transaction_search",
+}
+
+func (t *table[T]) tableName() string {
+	return t.name
+}
+
+func (t *table[T]) seededByMigrations() bool {
+	return t.seeded
+}
+
+func (t *table[T]) copyRows(from, to *gorm.DB, maxId uint, batchSize int) (int, error) {
+	lastId, err := maxTableId(to, t.name)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	err = from.Model(new(T)).Where("id > ? AND id <= ?", lastId, maxId).Count(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	progress := newProgress(t.name, total)
+	for {
+		records := []T{}
+		err := from.Where("id > ? AND id <= ?", lastId, maxId).Order("id").Limit(batchSize).Find(&records).Error
+		if err != nil {
+			return progress.count, fmt.Errorf("failed to fetch data: %w", err)
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		err = t.save(to, records, false)
+		if err != nil {
+			return progress.count, err
+		}
+
+		lastId = recordId(records[len(records)-1])
+		progress.add(len(records))
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	progress.done()
+	return progress.count, nil
+}
+
+func (t *table[T]) syncRows(from, to *gorm.DB, until time.Time, batchSize int) (int, error) {
+	lastId, err := maxTableId(to, t.name)
+	if err != nil {
+		return 0, err
+	}
+
+	since := time.Time{}
+	var latest T
+	err = to.Order("updated_at DESC").Take(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to find latest update: %w", err)
+	}
+	if err == nil {
+		since = reflect.ValueOf(latest).FieldByName("UpdatedAt").Interface().(time.Time).Add(-syncOverlap)
+	}
+
+	query := func() *gorm.DB {
+		return from.Model(new(T)).Where("(updated_at >= ? OR id > ?) AND updated_at <= ?", since, lastId, until)
+	}
+
+	var total int64
+	err = query().Count(&total).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	progress := newProgress(t.name, total)
+	cursor := uint(0)
+	for {
+		records := []T{}
+		err := query().Where("id > ?", cursor).Order("id").Limit(batchSize).Find(&records).Error
+		if err != nil {
+			return progress.count, fmt.Errorf("failed to fetch data: %w", err)
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		err = t.save(to, records, true)
+		if err != nil {
+			return progress.count, err
+		}
+
+		cursor = recordId(records[len(records)-1])
+		progress.add(len(records))
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	progress.done()
+	return progress.count, nil
+}
+
+func (t *table[T]) deleteRemovedRows(from, to *gorm.DB, batchSize int) (int, error) {
+	var sourceIds, destinationIds []uint
+	err := from.Model(new(T)).Order("id").Pluck("id", &sourceIds).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list source ids: %w", err)
+	}
+	err = to.Model(new(T)).Order("id").Pluck("id", &destinationIds).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to list destination ids: %w", err)
+	}
+
+	// both lists are sorted
+	removedIds := []uint{}
+	i := 0
+	for _, id := range destinationIds {
+		for i < len(sourceIds) && sourceIds[i] < id {
+			i++
+		}
+		if i == len(sourceIds) || sourceIds[i] != id {
+			removedIds = append(removedIds, id)
+		}
+	}
+
+	for start := 0; start < len(removedIds); start += batchSize {
+		ids := removedIds[start:min(start+batchSize, len(removedIds))]
+		err = to.Transaction(func(tx *gorm.DB) error {
+			if t.afterDelete != nil {
+				err := t.afterDelete(tx, ids)
+				if err != nil {
+					return err
+				}
+			}
+			return tx.Where("id IN ?", ids).Delete(new(T)).Error
+		})
+		if err != nil {
+			return start, fmt.Errorf("failed to delete rows: %w", err)
+		}
+	}
+
+	return len(removedIds), nil
+}
+
+// save inserts a batch of rows in a single transaction, so the highest id
+// in the destination always marks the end of a completely copied batch
+func (t *table[T]) save(to *gorm.DB, records []T, upsert bool) error {
+	return to.Transaction(func(tx *gorm.DB) error {
+		query := tx.Omit(clause.Associations)
+		if upsert {
+			tableSchema, err := schema.Parse(new(T), schemaCache, tx.NamingStrategy)
+			if err != nil {
+				return err
+			}
+			// UpdateAll would set updated_at to the current time
+			columns := []string{}
+			for _, field := range tableSchema.Fields {
+				if field.DBName != "" && !field.PrimaryKey {
+					columns = append(columns, field.DBName)
+				}
+			}
+			query = query.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns(columns),
+			})
+		}
+		err := query.Create(&records).Error
+		if err != nil {
+			return fmt.Errorf("failed to insert data: %w", err)
+		}
+
+		if t.afterSave != nil {
+			return t.afterSave(tx, records)
+		}
+		return nil
+	})
+}
+
+func indexTransactions(tx *gorm.DB, transactions []db.Transaction) error {
+	for _, transaction := range transactions {
+		err := metadataindex.Index(tx, transaction.ID, transaction.Description, transaction.Metadata, transaction.Boostagram)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteTransactionIndex(tx *gorm.DB, ids []uint) error {
+	err := tx.Exec("DELETE FROM transaction_metadata_entries WHERE transaction_id IN ?", ids).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction metadata entries: %w", err)
+	}
+	err = tx.Exec("DELETE FROM transaction_search WHERE docid IN ?", ids).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction search documents: %w", err)
+	}
+	return nil
+}
+
+func maxTableId(gormDB *gorm.DB, table string) (uint, error) {
+	var maxId uint
+	err := gormDB.Table(table).Select("COALESCE(MAX(id), 0)").Scan(&maxId).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find highest id of %s: %w", table, err)
+	}
+	return maxId, nil
+}
+
+func recordId(record interface{}) uint {
+	return uint(reflect.ValueOf(record).FieldByName("ID").Uint())
+}
+
+type progress struct {
+	table     string
+	total     int64
+	count     int
+	startedAt time.Time
+	loggedAt  time.Time
+}
+
+func newProgress(table string, total int64) *progress {
+	now := time.Now()
+	return &progress{
+		table:     table,
+		total:     total,
+		startedAt: now,
+		loggedAt:  now,
+	}
+}
+
+func (p *progress) add(count int) {
+	p.count += count
+	if time.Since(p.loggedAt) >= progressInterval {
+		p.log("migrating...")
+		p.loggedAt = time.Now()
+	}
+}
+
+func (p *progress) done() {
+	p.log("migrated table")
+}
+
+func (p *progress) log(message string) {
+	fields := logrus.Fields{
+		"table": p.table,
+		"rows":  p.count,
+		"total": p.total,
+	}
+	if p.total > 0 {
+		fields["percent"] = p.count * 100 / int(p.total)
+	}
+	if elapsed := time.Since(p.startedAt).Seconds(); elapsed > 0 {
+		fields["rows_per_second"] = int(float64(p.count) / elapsed)
+	}
+	logger.Logger.WithFields(fields).Info(message)
+}
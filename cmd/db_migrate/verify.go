@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/getAlby/hub/logger"
+)
+
+type tableChecksum struct {
+	Count    int
+	Checksum string
+}
+
+// verifyDB compares the row count and checksum of every migrated table.
+// The source hub should be stopped, otherwise new rows are reported as differences.
+func verifyDB(from, to *gorm.DB, batchSize int) error {
+	mismatchedTables := []string{}
+	for _, table := range migratedTables {
+		sourceChecksum, err := table.checksum(from, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to checksum source table %s: %w", table.tableName(), err)
+		}
+		destinationChecksum, err := table.checksum(to, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to checksum destination table %s: %w", table.tableName(), err)
+		}
+
+		fields := logrus.Fields{
+			"table":                table.tableName(),
+			"source_count":         sourceChecksum.Count,
+			"destination_count":    destinationChecksum.Count,
+			"source_checksum":      sourceChecksum.Checksum,
+			"destination_checksum": destinationChecksum.Checksum,
+		}
+		if *sourceChecksum != *destinationChecksum {
+			logger.Logger.WithFields(fields).Error("table differs")
+			mismatchedTables = append(mismatchedTables, table.tableName())
+			continue
+		}
+		logger.Logger.WithFields(fields).Info("table verified")
+	}
+
+	if len(mismatchedTables) > 0 {
+		return fmt.Errorf("tables differ: %v", mismatchedTables)
+	}
+	return nil
+}
+
+// checksum hashes all rows ordered by id. Values are normalized so that the same
+// data stored in SQLite and Postgres results in the same checksum.
+func (t *table[T]) checksum(gormDB *gorm.DB, batchSize int) (*tableChecksum, error) {
+	tableSchema, err := schema.Parse(new(T), schemaCache, gormDB.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	count := 0
+	lastId := uint(0)
+	for {
+		records := []T{}
+		err := gormDB.Where("id > ?", lastId).Order("id").Limit(batchSize).Find(&records).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch data: %w", err)
+		}
+
+		for _, record := range records {
+			row, err := normalizeRow(tableSchema, reflect.ValueOf(record))
+			if err != nil {
+				return nil, fmt.Errorf("failed to normalize row %d: %w", recordId(record), err)
+			}
+			hash.Write(row)
+			hash.Write([]byte("\n"))
+			lastId = recordId(record)
+			count++
+		}
+
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	return &tableChecksum{
+		Count:    count,
+		Checksum: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+var schemaCache = &sync.Map{}
+
+func normalizeRow(tableSchema *schema.Schema, record reflect.Value) ([]byte, error) {
+	row := map[string]interface{}{}
+	for _, field := range tableSchema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		value, err := normalizeValue(record.FieldByIndex(field.StructField.Index).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", field.DBName, err)
+		}
+		row[field.DBName] = value
+	}
+	// map keys are sorted when encoded
+	return json.Marshal(row)
+}
+
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return normalizeTime(v), nil
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		return normalizeTime(*v), nil
+	case datatypes.JSON:
+		// postgres reformats JSON values
+		if len(v) == 0 {
+			return nil, nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(v))
+		decoder.UseNumber()
+		var decoded interface{}
+		err := decoder.Decode(&decoded)
+		if err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	default:
+		return value, nil
+	}
+}
+
+// postgres stores timestamps with microsecond precision
+func normalizeTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getAlby/hub/api"
+)
+
+// hubClient talks to the HTTP API of a running hub
+type hubClient struct {
+	baseUrl        string
+	token          string
+	unlockPassword string
+	httpClient     *http.Client
+}
+
+type authTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func newHubClient(baseUrl, token, unlockPassword string) *hubClient {
+	return &hubClient{
+		baseUrl:        strings.TrimSuffix(baseUrl, "/"),
+		token:          token,
+		unlockPassword: unlockPassword,
+		httpClient: &http.Client{
+			// channel opens and backups can take a while
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+func (client *hubClient) getInfo() (*api.InfoResponse, error) {
+	info := &api.InfoResponse{}
+	err := client.request(http.MethodGet, "/api/info", nil, info, false)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// unlock starts the node if it is not running yet and returns a new JWT
+func (client *hubClient) unlock(permission string, tokenExpiryDays *uint64) (string, error) {
+	if client.unlockPassword == "" {
+		return "", errors.New("an unlock password is required")
+	}
+
+	info, err := client.getInfo()
+	if err != nil {
+		return "", err
+	}
+	if !info.SetupCompleted {
+		return "", errors.New("hub setup has not been completed")
+	}
+
+	tokenResponse := &authTokenResponse{}
+	if !info.Running {
+		err = client.request(http.MethodPost, "/api/start", &api.StartRequest{
+			UnlockPassword: client.unlockPassword,
+		}, tokenResponse, false)
+	} else {
+		err = client.request(http.MethodPost, "/api/unlock", &api.UnlockRequest{
+			UnlockPassword:  client.unlockPassword,
+			TokenExpiryDays: tokenExpiryDays,
+			Permission:      permission,
+		}, tokenResponse, false)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return tokenResponse.Token, nil
+}
+
+// authorize makes sure a token is available, unlocking the hub with the
+// unlock password if no token was provided
+func (client *hubClient) authorize() error {
+	if client.token != "" {
+		return nil
+	}
+	if client.unlockPassword == "" {
+		return errors.New("no token or unlock password provided")
+	}
+
+	tokenResponse := &authTokenResponse{}
+	expiryDays := uint64(1)
+	err := client.request(http.MethodPost, "/api/unlock", &api.UnlockRequest{
+		UnlockPassword:  client.unlockPassword,
+		TokenExpiryDays: &expiryDays,
+		Permission:      "full",
+	}, tokenResponse, false)
+	if err != nil {
+		return fmt.Errorf("failed to unlock hub: %w", err)
+	}
+
+	client.token = tokenResponse.Token
+	return nil
+}
+
+func (client *hubClient) get(path string, query url.Values, responseBody interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return client.request(http.MethodGet, path, nil, responseBody, true)
+}
+
+func (client *hubClient) post(path string, requestBody interface{}, responseBody interface{}) error {
+	return client.request(http.MethodPost, path, requestBody, responseBody, true)
+}
+
+func (client *hubClient) patch(path string, requestBody interface{}, responseBody interface{}) error {
+	return client.request(http.MethodPatch, path, requestBody, responseBody, true)
+}
+
+func (client *hubClient) delete(path string, query url.Values, responseBody interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return client.request(http.MethodDelete, path, nil, responseBody, true)
+}
+
+func (client *hubClient) request(method, path string, requestBody interface{}, responseBody interface{}, authorized bool) error {
+	res, err := client.do(method, path, requestBody, authorized)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if responseBody == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	err = json.Unmarshal(body, responseBody)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// download writes a successful response body to w, for endpoints that do not return JSON
+func (client *hubClient) download(method, path string, requestBody interface{}, w io.Writer, authorized bool) error {
+	res, err := client.do(method, path, requestBody, authorized)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+func (client *hubClient) do(method, path string, requestBody interface{}, authorized bool) (*http.Response, error) {
+	if authorized {
+		err := client.authorize()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var body io.Reader
+	if requestBody != nil {
+		requestBytes, err := json.Marshal(requestBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(requestBytes)
+	}
+
+	req, err := http.NewRequest(method, client.baseUrl+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if requestBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if authorized {
+		req.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	res, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach hub: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		defer res.Body.Close()
+		return nil, responseError(res)
+	}
+
+	return res, nil
+}
+
+func responseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+
+	errResponse := &errorResponse{}
+	if json.Unmarshal(body, errResponse) == nil && errResponse.Message != "" {
+		return fmt.Errorf("hub returned %d: %s", res.StatusCode, errResponse.Message)
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(res.StatusCode)
+	}
+	return fmt.Errorf("hub returned %d: %s", res.StatusCode, message)
+}
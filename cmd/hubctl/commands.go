@@ -0,0 +1,629 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getAlby/hub/api"
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/constants"
+)
+
+var defaultAppScopes = []string{
+	constants.PAY_INVOICE_SCOPE,
+	constants.GET_BALANCE_SCOPE,
+	constants.GET_INFO_SCOPE,
+	constants.MAKE_INVOICE_SCOPE,
+	constants.LOOKUP_INVOICE_SCOPE,
+	constants.LIST_TRANSACTIONS_SCOPE,
+	constants.NOTIFICATIONS_SCOPE,
+}
+
+func infoCommand(c *cli, args []string) error {
+	flagSet := newFlagSet("info", "")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	info, err := c.client.getInfo()
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(info, func(w io.Writer) error {
+		return printTable(w, []string{"VERSION", "NETWORK", "BACKEND", "SETUP COMPLETED", "RUNNING"}, [][]string{{
+			info.Version,
+			info.Network,
+			info.BackendType,
+			strconv.FormatBool(info.SetupCompleted),
+			strconv.FormatBool(info.Running),
+		}})
+	})
+}
+
+func unlockCommand(c *cli, args []string) error {
+	var readOnly bool
+	var expiryDays uint64
+
+	flagSet := newFlagSet("unlock", "")
+	flagSet.BoolVar(&readOnly, "readonly", false, "issue a read-only token")
+	flagSet.Uint64Var(&expiryDays, "expiry-days", 0, "token lifetime in days (0 uses the hub default)")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	permission := "full"
+	if readOnly {
+		permission = "readonly"
+	}
+	var tokenExpiryDays *uint64
+	if expiryDays > 0 {
+		tokenExpiryDays = &expiryDays
+	}
+
+	token, err := c.client.unlock(permission, tokenExpiryDays)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(&authTokenResponse{Token: token}, func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, token)
+		return err
+	})
+}
+
+func appsListCommand(c *cli, args []string) error {
+	var limit uint64
+
+	flagSet := newFlagSet("apps list", "")
+	flagSet.Uint64Var(&limit, "limit", 100, "maximum number of apps to list")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	var appsResponse *api.ListAppsResponse
+	if c.offline != nil {
+		var err error
+		appsResponse, err = c.offline.listApps()
+		if err != nil {
+			return err
+		}
+	} else {
+		appsResponse = &api.ListAppsResponse{}
+		err := c.client.get("/api/apps", url.Values{
+			"limit": {strconv.FormatUint(limit, 10)},
+		}, appsResponse)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.printResult(appsResponse, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, app := range appsResponse.Apps {
+			lastUsedAt := "never"
+			if app.LastUsedAt != nil {
+				lastUsedAt = app.LastUsedAt.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{
+				strconv.FormatUint(uint64(app.ID), 10),
+				app.Name,
+				app.AppPubkey,
+				strings.Join(app.Scopes, ","),
+				strconv.FormatBool(app.Isolated),
+				lastUsedAt,
+			})
+		}
+		return printTable(w, []string{"ID", "NAME", "APP PUBKEY", "SCOPES", "ISOLATED", "LAST USED"}, rows)
+	})
+}
+
+func appsCreateCommand(c *cli, args []string) error {
+	var name, pubkey, scopes, budgetRenewal, expiresAt string
+	var maxAmountSat uint64
+	var isolated bool
+
+	flagSet := newFlagSet("apps create", "")
+	flagSet.StringVar(&name, "name", "", "name of the app connection")
+	flagSet.StringVar(&pubkey, "pubkey", "", "use an existing app public key instead of generating a pairing secret")
+	flagSet.StringVar(&scopes, "scopes", strings.Join(defaultAppScopes, ","), "comma-separated list of scopes")
+	flagSet.Uint64Var(&maxAmountSat, "max-amount", 0, "budget in sats (0 for no budget)")
+	flagSet.StringVar(&budgetRenewal, "budget-renewal", constants.BUDGET_RENEWAL_MONTHLY, "budget renewal: "+strings.Join(constants.GetBudgetRenewals(), ", "))
+	flagSet.StringVar(&expiresAt, "expires-at", "", "expiry date of the connection (RFC 3339)")
+	flagSet.BoolVar(&isolated, "isolated", false, "create an isolated sub-wallet")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if name == "" {
+		flagSet.Usage()
+		return errors.New("missing app name")
+	}
+	if c.offline != nil {
+		// the app wallet key is derived from the mnemonic, which is only
+		// available once the hub is unlocked
+		return errRequiresRunningHub
+	}
+
+	createAppResponse := &api.CreateAppResponse{}
+	err := c.client.post("/api/apps", &api.CreateAppRequest{
+		Name:           name,
+		Pubkey:         pubkey,
+		MaxAmountSat:   maxAmountSat,
+		BudgetRenewal:  budgetRenewal,
+		ExpiresAt:      expiresAt,
+		Scopes:         strings.Split(scopes, ","),
+		Isolated:       isolated,
+		UnlockPassword: c.client.unlockPassword,
+	}, createAppResponse)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(createAppResponse, func(w io.Writer) error {
+		// the pairing secret is not stored by the hub, so the connection
+		// URI can only be printed once
+		_, err := fmt.Fprintln(w, createAppResponse.PairingUri)
+		return err
+	})
+}
+
+func appsRevokeCommand(c *cli, args []string) error {
+	flagSet := newFlagSet("apps revoke", "<app pubkey>")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		flagSet.Usage()
+		return errors.New("expected the app pubkey")
+	}
+	appPubkey := flagSet.Arg(0)
+
+	var err error
+	if c.offline != nil {
+		err = c.offline.deleteApp(appPubkey)
+	} else {
+		err = c.client.delete("/api/apps/"+url.PathEscape(appPubkey), nil, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(map[string]string{"appPubkey": appPubkey}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "revoked app %s\n", appPubkey)
+		return err
+	})
+}
+
+func appsRotateCommand(c *cli, args []string) error {
+	var all bool
+	var gracePeriodHours uint
+
+	flagSet := newFlagSet("apps rotate", "<app pubkey>")
+	flagSet.BoolVar(&all, "all", false, "rotate the keys of all app connections")
+	flagSet.UintVar(&gracePeriodHours, "grace-period", uint(apps.DefaultKeyRotationGracePeriod.Hours()), "hours the previous connection keeps working")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if all == (flagSet.NArg() == 1) || flagSet.NArg() > 1 {
+		flagSet.Usage()
+		return errors.New("expected the app pubkey or -all")
+	}
+	if c.offline != nil {
+		// the app wallet keys are derived from the mnemonic, which is only
+		// available once the hub is unlocked
+		return errRequiresRunningHub
+	}
+
+	request := &api.RotateAppKeysRequest{
+		GracePeriodHours: &gracePeriodHours,
+	}
+	rotateResponse := &api.RotateAllAppKeysResponse{}
+	if all {
+		err := c.client.post("/api/apps/rotate-keys", request, rotateResponse)
+		if err != nil {
+			return err
+		}
+	} else {
+		rotateAppKeysResponse := api.RotateAppKeysResponse{}
+		err := c.client.post("/api/apps/"+url.PathEscape(flagSet.Arg(0))+"/rotate-keys", request, &rotateAppKeysResponse)
+		if err != nil {
+			return err
+		}
+		rotateResponse.Apps = append(rotateResponse.Apps, rotateAppKeysResponse)
+	}
+
+	return c.printResult(rotateResponse, func(w io.Writer) error {
+		// the pairing secrets are not stored by the hub, so the connection
+		// URIs can only be printed once
+		rows := [][]string{}
+		for _, app := range rotateResponse.Apps {
+			rows = append(rows, []string{
+				strconv.FormatUint(uint64(app.Id), 10),
+				app.Name,
+				app.PreviousKeysExpireAt.Format(time.RFC3339),
+				app.PairingUri,
+			})
+		}
+		for _, name := range rotateResponse.Skipped {
+			rows = append(rows, []string{"-", name, "skipped, reconnect instead", ""})
+		}
+		return printTable(w, []string{"ID", "NAME", "PREVIOUS KEYS EXPIRE", "NWC URI"}, rows)
+	})
+}
+
+func nostrKeyRotateCommand(c *cli, args []string) error {
+	var gracePeriodHours uint
+
+	flagSet := newFlagSet("nostr-key rotate", "")
+	flagSet.UintVar(&gracePeriodHours, "grace-period", uint(apps.DefaultKeyRotationGracePeriod.Hours()), "hours legacy connections using the previous key keep working")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.client.unlockPassword == "" {
+		flagSet.Usage()
+		return errors.New("the unlock password (-password) is required")
+	}
+	if c.offline != nil {
+		// the previous key is only served by a running hub
+		return errRequiresRunningHub
+	}
+
+	rotateResponse := &api.RotateNostrKeyResponse{}
+	err := c.client.post("/api/nostr-key/rotate", &api.RotateNostrKeyRequest{
+		UnlockPassword:   c.client.unlockPassword,
+		GracePeriodHours: &gracePeriodHours,
+	}, rotateResponse)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(rotateResponse, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "rotated nostr key %s to %s\nprevious key expires %s, %d legacy app connections have to be updated or have their keys rotated\n",
+			rotateResponse.PreviousPubkey, rotateResponse.Pubkey, rotateResponse.PreviousKeyExpiresAt.Format(time.RFC3339), rotateResponse.LegacyAppCount)
+		return err
+	})
+}
+
+func channelsListCommand(c *cli, args []string) error {
+	flagSet := newFlagSet("channels list", "")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	channels := []api.Channel{}
+	err := c.client.get("/api/channels", nil, &channels)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(channels, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, channel := range channels {
+			rows = append(rows, []string{
+				channel.Id,
+				channel.RemotePubkey,
+				strconv.FormatInt((channel.LocalBalance+channel.RemoteBalance)/1000, 10),
+				strconv.FormatInt(channel.LocalBalance/1000, 10),
+				strconv.FormatInt(channel.RemoteBalance/1000, 10),
+				channel.Status,
+				strconv.FormatBool(channel.Public),
+			})
+		}
+		return printTable(w, []string{"ID", "PEER", "CAPACITY (SATS)", "LOCAL (SATS)", "REMOTE (SATS)", "STATUS", "PUBLIC"}, rows)
+	})
+}
+
+func channelsOpenCommand(c *cli, args []string) error {
+	var pubkey string
+	var amountSats int64
+	var public bool
+
+	flagSet := newFlagSet("channels open", "")
+	flagSet.StringVar(&pubkey, "pubkey", "", "node pubkey of the connected peer")
+	flagSet.Int64Var(&amountSats, "amount", 0, "channel size in sats")
+	flagSet.BoolVar(&public, "public", false, "announce the channel")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if pubkey == "" || amountSats <= 0 {
+		flagSet.Usage()
+		return errors.New("missing peer pubkey or channel amount")
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	openChannelResponse := &api.OpenChannelResponse{}
+	err := c.client.post("/api/channels", &api.OpenChannelRequest{
+		Pubkey:     pubkey,
+		AmountSats: amountSats,
+		Public:     public,
+	}, openChannelResponse)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(openChannelResponse, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "channel opened, funding transaction %s\n", openChannelResponse.FundingTxId)
+		return err
+	})
+}
+
+func channelsCloseCommand(c *cli, args []string) error {
+	var force bool
+	var address string
+	var feeRate, maxFeeRate uint64
+
+	flagSet := newFlagSet("channels close", "<peer id> <channel id>")
+	flagSet.BoolVar(&force, "force", false, "force close the channel")
+	flagSet.StringVar(&address, "address", "", "on-chain address or xpub to send the channel balance to")
+	flagSet.Uint64Var(&feeRate, "fee-rate", 0, "fee rate in sat/vB for a cooperative close")
+	flagSet.Uint64Var(&maxFeeRate, "max-fee-rate", 0, "wait until the mempool fee rate in sat/vB is at or below this value")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 2 {
+		flagSet.Usage()
+		return errors.New("expected the peer id and channel id")
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	query := url.Values{}
+	if force {
+		query.Set("force", "true")
+	}
+	if address != "" {
+		query.Set("address", address)
+	}
+	if feeRate > 0 {
+		query.Set("feeRate", strconv.FormatUint(feeRate, 10))
+	}
+	if maxFeeRate > 0 {
+		query.Set("maxFeeRate", strconv.FormatUint(maxFeeRate, 10))
+	}
+
+	closeChannelResponse := &api.CloseChannelResponse{}
+	err := c.client.delete(fmt.Sprintf("/api/peers/%s/channels/%s", url.PathEscape(flagSet.Arg(0)), url.PathEscape(flagSet.Arg(1))), query, closeChannelResponse)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(closeChannelResponse, func(w io.Writer) error {
+		if closeChannelResponse.PendingClose != nil {
+			_, err := fmt.Fprintf(w, "channel close scheduled (id %d)\n", closeChannelResponse.PendingClose.Id)
+			return err
+		}
+		_, err := fmt.Fprintln(w, "channel closed")
+		return err
+	})
+}
+
+func backupsListCommand(c *cli, args []string) error {
+	flagSet := newFlagSet("backups list", "")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	autoBackups := []api.AutoBackup{}
+	err := c.client.get("/api/auto-backups", nil, &autoBackups)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(autoBackups, func(w io.Writer) error {
+		rows := [][]string{}
+		for _, autoBackup := range autoBackups {
+			rows = append(rows, []string{
+				autoBackup.Name,
+				strconv.FormatInt(autoBackup.Size, 10),
+				autoBackup.CreatedAt,
+			})
+		}
+		return printTable(w, []string{"NAME", "SIZE", "CREATED"}, rows)
+	})
+}
+
+func backupsCreateCommand(c *cli, args []string) error {
+	var output string
+
+	flagSet := newFlagSet("backups create", "")
+	flagSet.StringVar(&output, "output", "", "download a full migration backup to this file. This stops the node. "+
+		"Without this flag a backup is created in the configured backup directory or S3 bucket")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.offline != nil {
+		return errRequiresRunningHub
+	}
+
+	if output == "" {
+		autoBackup := &api.AutoBackup{}
+		err := c.client.post("/api/auto-backups", nil, autoBackup)
+		if err != nil {
+			return err
+		}
+		return c.printResult(autoBackup, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, "created backup %s (%d bytes)\n", autoBackup.Name, autoBackup.Size)
+			return err
+		})
+	}
+
+	if c.client.unlockPassword == "" {
+		return errors.New("the unlock password is required to create a migration backup")
+	}
+
+	err := downloadBackup(c.client, output)
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(map[string]string{"output": output}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "backup written to %s, the node has been stopped\n", output)
+		return err
+	})
+}
+
+func downloadBackup(client *hubClient, output string) error {
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	err = client.download(http.MethodPost, "/api/backup", &api.BasicBackupRequest{
+		UnlockPassword: client.unlockPassword,
+	}, file, false)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("failed to download backup: %w", err)
+	}
+	return nil
+}
+
+func passwordChangeCommand(c *cli, args []string) error {
+	var newUnlockPassword string
+
+	flagSet := newFlagSet("password change", "")
+	flagSet.StringVar(&newUnlockPassword, "new", os.Getenv("HUBCTL_NEW_PASSWORD"), "new unlock password (env HUBCTL_NEW_PASSWORD)")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if c.client.unlockPassword == "" || newUnlockPassword == "" {
+		flagSet.Usage()
+		return errors.New("both the current (-password) and new unlock password are required")
+	}
+
+	var err error
+	if c.offline != nil {
+		err = c.offline.changeUnlockPassword(c.client.unlockPassword, newUnlockPassword)
+	} else {
+		err = c.client.patch("/api/unlock-password", &api.ChangeUnlockPasswordRequest{
+			CurrentUnlockPassword: c.client.unlockPassword,
+			NewUnlockPassword:     newUnlockPassword,
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.printResult(map[string]bool{"changed": true}, func(w io.Writer) error {
+		message := "unlock password changed"
+		if c.offline == nil {
+			// all encrypted config values changed, so the hub stops the node
+			message += ", the node has been stopped: start it again with \"hubctl unlock\""
+		}
+		_, err := fmt.Fprintln(w, message)
+		return err
+	})
+}
+
+func logsCommand(c *cli, args []string) error {
+	var logType string
+	var maxLen int
+	var follow bool
+	var interval time.Duration
+
+	flagSet := newFlagSet("logs", "")
+	flagSet.StringVar(&logType, "type", api.LogTypeApp, "log type: app or node")
+	flagSet.IntVar(&maxLen, "bytes", 10000, "maximum number of bytes to read from the end of the log")
+	flagSet.BoolVar(&follow, "follow", false, "keep printing new log output")
+	flagSet.DurationVar(&interval, "interval", 2*time.Second, "poll interval when following")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if logType != api.LogTypeApp && logType != api.LogTypeNode {
+		return fmt.Errorf("invalid log type: %q", logType)
+	}
+	if c.offline != nil && logType != api.LogTypeApp {
+		return errRequiresRunningHub
+	}
+
+	readLog := func() (string, error) {
+		if c.offline != nil {
+			return c.offline.readAppLog(maxLen)
+		}
+		logResponse := &api.GetLogOutputResponse{}
+		err := c.client.get("/api/log/"+logType, url.Values{
+			"maxLen": {strconv.Itoa(maxLen)},
+		}, logResponse)
+		if err != nil {
+			return "", err
+		}
+		return logResponse.Log, nil
+	}
+
+	logs, err := readLog()
+	if err != nil {
+		return err
+	}
+
+	if !follow {
+		return c.printResult(&api.GetLogOutputResponse{Log: logs}, func(w io.Writer) error {
+			_, err := io.WriteString(w, logs)
+			return err
+		})
+	}
+
+	// app logs are already JSON lines, so following prints raw output in both modes
+	if _, err := io.WriteString(c.out, logs); err != nil {
+		return err
+	}
+	for {
+		time.Sleep(interval)
+		current, err := readLog()
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(c.out, newLogOutput(logs, current)); err != nil {
+			return err
+		}
+		logs = current
+	}
+}
+
+// newLogOutput returns the part of the current log tail that was not part of
+// the previous one. Both are windows over the end of the same log, so the
+// longest suffix of previous starting at a line boundary that current starts
+// with is the output that was already printed.
+func newLogOutput(previous, current string) string {
+	if previous == "" {
+		return current
+	}
+
+	for start := 0; start < len(previous); {
+		if strings.HasPrefix(current, previous[start:]) {
+			return current[len(previous)-start:]
+		}
+		nextLine := strings.IndexByte(previous[start:], '\n')
+		if nextLine < 0 {
+			break
+		}
+		start += nextLine + 1
+	}
+
+	// the log was rotated or more output was written than fits in the window
+	return current
+}
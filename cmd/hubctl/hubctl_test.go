@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/api"
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/db/migrations"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/utils"
+)
+
+func TestNewLogOutput(t *testing.T) {
+	assert.Equal(t, "a\nb\n", newLogOutput("", "a\nb\n"))
+	assert.Equal(t, "", newLogOutput("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "c\n", newLogOutput("a\nb\n", "a\nb\nc\n"))
+	// the window moved forward and cut the first line
+	assert.Equal(t, "c\nd\n", newLogOutput("xa\nb\n", "b\nc\nd\n"))
+	// no overlap, e.g. after the log file was rotated
+	assert.Equal(t, "e\n", newLogOutput("a\nb\n", "e\n"))
+}
+
+func TestHubClient_UnlocksWithPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/unlock":
+			unlockRequest := &api.UnlockRequest{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(unlockRequest))
+			if unlockRequest.UnlockPassword != "123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"message":"Invalid password"}`))
+				return
+			}
+			assert.Equal(t, "full", unlockRequest.Permission)
+			w.Write([]byte(`{"token":"abc"}`))
+		case "/api/apps":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "5", r.URL.Query().Get("limit"))
+			w.Write([]byte(`{"apps":[{"id":1,"name":"Damus"}],"totalCount":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	appsResponse := &api.ListAppsResponse{}
+	err := newHubClient(server.URL, "", "123").get("/api/apps", map[string][]string{"limit": {"5"}}, appsResponse)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), appsResponse.TotalCount)
+	assert.Equal(t, "Damus", appsResponse.Apps[0].Name)
+
+	err = newHubClient(server.URL, "", "wrong").get("/api/apps", nil, appsResponse)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid password")
+
+	err = newHubClient(server.URL, "", "").get("/api/apps", nil, appsResponse)
+	assert.EqualError(t, err, "no token or unlock password provided")
+}
+
+func TestOfflineHub(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+
+	workdir := t.TempDir()
+	databaseUri := filepath.Join(workdir, "nwc.db")
+	// the database of a stopped hub is already migrated
+	gormDB, err := db.NewDB(databaseUri, false)
+	require.NoError(t, err)
+	require.NoError(t, db.Stop(gormDB))
+
+	hub, err := openOfflineHub(databaseUri, workdir)
+	require.NoError(t, err)
+	defer hub.close()
+
+	// a running hub holds the lock of its work directory
+	_, err = openOfflineHub(databaseUri, workdir)
+	assert.EqualError(t, err, "the hub is running: stop it before using -database, or omit -database to use its API")
+
+	walletPubkey := "wallet-pubkey"
+	app := db.App{Name: "Damus", AppPubkey: "app-pubkey", WalletPubkey: &walletPubkey}
+	require.NoError(t, hub.db.Create(&app).Error)
+	require.NoError(t, hub.db.Create(&db.AppPermission{AppId: app.ID, Scope: "pay_invoice", MaxAmountSat: 1000, BudgetRenewal: "monthly"}).Error)
+
+	appsResponse, err := hub.listApps()
+	require.NoError(t, err)
+	require.Len(t, appsResponse.Apps, 1)
+	assert.Equal(t, "Damus", appsResponse.Apps[0].Name)
+	assert.Equal(t, walletPubkey, appsResponse.Apps[0].WalletPubkey)
+	assert.Equal(t, []string{"pay_invoice"}, appsResponse.Apps[0].Scopes)
+	assert.Equal(t, uint64(1000), appsResponse.Apps[0].MaxAmountSat)
+
+	assert.EqualError(t, hub.deleteApp("unknown"), "app not found")
+	require.NoError(t, hub.deleteApp("app-pubkey"))
+	appsResponse, err = hub.listApps()
+	require.NoError(t, err)
+	assert.Empty(t, appsResponse.Apps)
+
+	require.NoError(t, hub.cfg.SaveUnlockPasswordCheck("123"))
+	require.NoError(t, hub.cfg.SetUpdate("Secret", "value", "123"))
+	assert.EqualError(t, hub.changeUnlockPassword("wrong", "456"), "incorrect password")
+	require.NoError(t, hub.changeUnlockPassword("123", "456"))
+	secret, err := hub.cfg.Get("Secret", "456")
+	require.NoError(t, err)
+	assert.Equal(t, "value", secret)
+}
+
+func TestOfflineHub_NotMigrated(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+
+	workdir := t.TempDir()
+	databaseUri := filepath.Join(workdir, "nwc.db")
+	gormDB, err := db.NewDB(databaseUri, false)
+	require.NoError(t, err)
+	// e.g. the hub was stopped before the update was started
+	require.NoError(t, gormDB.Exec("DELETE FROM migrations WHERE id = ?", migrations.MigrationIds()[len(migrations.MigrationIds())-1]).Error)
+	require.NoError(t, db.Stop(gormDB))
+
+	_, err = openOfflineHub(databaseUri, workdir)
+	assert.EqualError(t, err, "the database was not migrated to this version of Alby Hub, start the hub once to migrate it")
+
+	_, err = openOfflineHub(databaseUri, "")
+	assert.EqualError(t, err, "-workdir is required with -database to check that the hub is stopped")
+
+	// the lock was released
+	lockFile, err := utils.LockFile(filepath.Join(workdir, config.LockFileName))
+	require.NoError(t, err)
+	lockFile.Close()
+}
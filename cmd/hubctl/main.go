@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/logger"
+)
+
+const usage = `hubctl manages an Alby Hub from the command line.
+
+Usage: hubctl [global flags] <command> [flags] [arguments]
+
+Commands:
+  info                              show the hub status
+  unlock                            start or unlock the hub and print an access token
+  apps list                         list app connections
+  apps create -name <name>          create an app connection and print its NWC URI
+  apps revoke <app pubkey>          delete an app connection
+  apps rotate <app pubkey>|-all     rotate app keys and print the new NWC URIs
+  nostr-key rotate                  rotate the hub Nostr key used by legacy app connections
+  channels list                     list channels
+  channels open -pubkey <node> -amount <sats>
+                                    open a channel to a connected peer
+  channels close <peer id> <channel id>
+                                    close a channel
+  backups list                      list the scheduled backups
+  backups create [-output <file>]   create a backup
+  password change -new <password>   rotate the unlock password
+  logs [-type app|node] [-follow]   print or tail the logs
+
+Use "hubctl <command> -h" for the flags of a command.
+
+Commands are sent to the HTTP API of a running hub. If -database is set,
+supported commands (apps list, apps revoke, password change and logs)
+operate directly on the database and work directory of a stopped hub.
+
+Global flags:
+`
+
+var errRequiresRunningHub = errors.New("this command requires a running hub")
+
+type cli struct {
+	client     *hubClient
+	offline    *offlineHub
+	jsonOutput bool
+	out        io.Writer
+}
+
+type command func(c *cli, args []string) error
+
+var commands = map[string]map[string]command{
+	"info":      {"": infoCommand},
+	"unlock":    {"": unlockCommand},
+	"apps":      {"list": appsListCommand, "create": appsCreateCommand, "revoke": appsRevokeCommand, "rotate": appsRotateCommand},
+	"channels":  {"list": channelsListCommand, "open": channelsOpenCommand, "close": channelsCloseCommand},
+	"backups":   {"list": backupsListCommand, "create": backupsCreateCommand},
+	"nostr-key": {"rotate": nostrKeyRotateCommand},
+	"password":  {"change": passwordChangeCommand},
+	"logs":      {"": logsCommand},
+}
+
+func main() {
+	var hubUrl, token, unlockPassword, databaseUri, workdir string
+	var jsonOutput bool
+
+	logger.Init(strconv.Itoa(int(logrus.WarnLevel)))
+	// keep stdout clean for command output
+	logger.Logger.SetOutput(os.Stderr)
+
+	// not using flag.CommandLine, which also has flags registered by dependencies
+	globalFlags := flag.NewFlagSet("hubctl", flag.ExitOnError)
+	globalFlags.StringVar(&hubUrl, "url", envOrDefault("HUBCTL_URL", "http://localhost:8080"), "URL of the hub (env HUBCTL_URL)")
+	globalFlags.StringVar(&token, "token", os.Getenv("HUBCTL_TOKEN"), "access token printed by \"hubctl unlock\" (env HUBCTL_TOKEN)")
+	globalFlags.StringVar(&unlockPassword, "password", os.Getenv("UNLOCK_PASSWORD"), "unlock password, used when no token is set (env UNLOCK_PASSWORD)")
+	globalFlags.StringVar(&databaseUri, "database", "", "database URI of a stopped hub, e.g. .data/nwc.db")
+	globalFlags.StringVar(&workdir, "workdir", os.Getenv("WORK_DIR"), "work directory of a stopped hub, required with -database (env WORK_DIR)")
+	globalFlags.BoolVar(&jsonOutput, "json", false, "print JSON output for scripting")
+	globalFlags.Usage = func() {
+		fmt.Fprint(globalFlags.Output(), usage)
+		globalFlags.PrintDefaults()
+	}
+
+	globalFlags.Parse(os.Args[1:])
+
+	run, args, err := findCommand(globalFlags.Args())
+	if err != nil {
+		globalFlags.Usage()
+		fmt.Fprintf(os.Stderr, "\n%v\n", err)
+		os.Exit(1)
+	}
+
+	c := &cli{
+		client:     newHubClient(hubUrl, token, unlockPassword),
+		jsonOutput: jsonOutput,
+		out:        os.Stdout,
+	}
+
+	if databaseUri != "" {
+		// the hub keeps state in memory and holds the database, so the
+		// database must not be changed behind the back of a running hub
+		c.offline, err = openOfflineHub(databaseUri, workdir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer c.offline.close()
+	}
+
+	err = run(c, args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, err)
+		// deferred functions do not run on os.Exit
+		if c.offline != nil {
+			c.offline.close()
+		}
+		os.Exit(1)
+	}
+}
+
+func findCommand(args []string) (command, []string, error) {
+	if len(args) == 0 {
+		return nil, nil, errors.New("missing command")
+	}
+
+	subcommands, ok := commands[args[0]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown command: %q", args[0])
+	}
+	if run, ok := subcommands[""]; ok {
+		return run, args[1:], nil
+	}
+
+	names := []string{}
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(args) < 2 {
+		return nil, nil, fmt.Errorf("missing subcommand for %q, expected one of %v", args[0], names)
+	}
+	run, ok := subcommands[args[1]]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown subcommand for %q: %q, expected one of %v", args[0], args[1], names)
+	}
+	return run, args[2:], nil
+}
+
+func newFlagSet(name, argsUsage string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		fmt.Fprintf(flagSet.Output(), "Usage: hubctl %s [flags] %s\n", name, argsUsage)
+		flagSet.PrintDefaults()
+	}
+	return flagSet
+}
+
+// printResult prints v as JSON in JSON mode, or calls printText otherwise
+func (c *cli) printResult(v interface{}, printText func(w io.Writer) error) error {
+	if c.jsonOutput {
+		encoder := json.NewEncoder(c.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	return printText(c.out)
+}
+
+func printTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		for i, column := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, column)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/api"
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/db/migrations"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/utils"
+)
+
+// offlineHub gives access to the database of a stopped hub. Only
+// operations that do not need the node or the nostr relay are supported.
+type offlineHub struct {
+	db       *gorm.DB
+	cfg      config.Config
+	workdir  string
+	lockFile *os.File
+}
+
+// openOfflineHub takes the lock of the hub work directory, so the hub cannot be
+// started while its database is changed, and opens the database without migrating it.
+func openOfflineHub(databaseUri, workdir string) (*offlineHub, error) {
+	if workdir == "" {
+		return nil, errors.New("-workdir is required with -database to check that the hub is stopped")
+	}
+
+	lockFile, err := utils.LockFile(filepath.Join(workdir, config.LockFileName))
+	if errors.Is(err, utils.ErrFileLocked) {
+		return nil, errors.New("the hub is running: stop it before using -database, or omit -database to use its API")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hub, err := openOfflineHubDatabase(databaseUri, workdir)
+	if err != nil {
+		lockFile.Close()
+		return nil, err
+	}
+	hub.lockFile = lockFile
+	return hub, nil
+}
+
+func openOfflineHubDatabase(databaseUri, workdir string) (*offlineHub, error) {
+	gormDB, err := db.NewDBWithConfig(&db.Config{
+		URI:            databaseUri,
+		SkipMigrations: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// the queries below are written for the schema of this version
+	err = migrations.CheckMigrated(gormDB)
+	if err != nil {
+		if stopErr := db.Stop(gormDB); stopErr != nil {
+			logger.Logger.WithError(stopErr).Error("failed to close database")
+		}
+		return nil, err
+	}
+
+	cfg, err := config.NewConfig(&config.AppConfig{
+		Workdir:     workdir,
+		DatabaseUri: databaseUri,
+	}, gormDB)
+	if err != nil {
+		if stopErr := db.Stop(gormDB); stopErr != nil {
+			logger.Logger.WithError(stopErr).Error("failed to close database")
+		}
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return &offlineHub{
+		db:      gormDB,
+		cfg:     cfg,
+		workdir: workdir,
+	}, nil
+}
+
+func (hub *offlineHub) close() {
+	if err := db.Stop(hub.db); err != nil {
+		logger.Logger.WithError(err).Error("failed to close database")
+	}
+	hub.lockFile.Close()
+}
+
+func (hub *offlineHub) listApps() (*api.ListAppsResponse, error) {
+	dbApps := []db.App{}
+	err := hub.db.Order("last_used_at IS NULL, last_used_at DESC, id DESC").Find(&dbApps).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	appIds := []uint{}
+	for _, dbApp := range dbApps {
+		appIds = append(appIds, dbApp.ID)
+	}
+
+	permissions := []db.AppPermission{}
+	err = hub.db.Where("app_id IN ?", appIds).Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app permissions: %w", err)
+	}
+
+	response := &api.ListAppsResponse{
+		Apps:       []api.App{},
+		TotalCount: uint64(len(dbApps)),
+	}
+	for _, dbApp := range dbApps {
+		app := api.App{
+			ID:         dbApp.ID,
+			Name:       dbApp.Name,
+			AppPubkey:  dbApp.AppPubkey,
+			CreatedAt:  dbApp.CreatedAt,
+			UpdatedAt:  dbApp.UpdatedAt,
+			LastUsedAt: dbApp.LastUsedAt,
+			Isolated:   dbApp.Isolated,
+			Scopes:     []string{},
+		}
+		if dbApp.WalletPubkey != nil {
+			app.WalletPubkey = *dbApp.WalletPubkey
+		}
+		for _, permission := range permissions {
+			if permission.AppId != dbApp.ID {
+				continue
+			}
+			app.Scopes = append(app.Scopes, permission.Scope)
+			app.ExpiresAt = permission.ExpiresAt
+			if permission.Scope == constants.PAY_INVOICE_SCOPE {
+				app.MaxAmountSat = uint64(permission.MaxAmountSat)
+				app.BudgetRenewal = permission.BudgetRenewal
+			}
+		}
+		response.Apps = append(response.Apps, app)
+	}
+
+	return response, nil
+}
+
+func (hub *offlineHub) deleteApp(appPubkey string) error {
+	appsSvc := apps.NewAppsService(hub.db, events.NewEventPublisher(), nil, hub.cfg)
+	app := appsSvc.GetAppByPubkey(appPubkey)
+	if app == nil {
+		return errors.New("app not found")
+	}
+	return appsSvc.DeleteApp(app)
+}
+
+func (hub *offlineHub) changeUnlockPassword(currentUnlockPassword, newUnlockPassword string) error {
+	autoUnlockPassword, err := hub.cfg.Get("AutoUnlockPassword", "")
+	if err != nil {
+		return err
+	}
+	if autoUnlockPassword != "" {
+		return errors.New("please disable auto-unlock before using this feature")
+	}
+
+	return hub.cfg.ChangeUnlockPassword(currentUnlockPassword, newUnlockPassword)
+}
+
+// readAppLog reads the tail of the hub's own log file. Node logs are only
+// available from the running node.
+func (hub *offlineHub) readAppLog(maxLen int) (string, error) {
+	if hub.workdir == "" {
+		return "", errors.New("the work directory is required to read logs of a stopped hub")
+	}
+
+	logData, err := utils.ReadFileTail(logger.GetLogFilePathForWorkdir(hub.workdir), maxLen)
+	if err != nil {
+		return "", err
+	}
+	return string(logData), nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/backups"
+	"github.com/getAlby/hub/logger"
+)
+
+// Checks that a backup file can be restored without touching the hub work directory.
+// The unlock password can also be passed in the UNLOCK_PASSWORD environment variable.
+func main() {
+	var backupPath, unlockPassword string
+
+	logger.Init(strconv.Itoa(int(logrus.WarnLevel)))
+
+	flag.StringVar(&backupPath, "backup", "", "path to the backup file")
+	flag.StringVar(&unlockPassword, "password", os.Getenv("UNLOCK_PASSWORD"), "unlock password of the backed up hub")
+
+	flag.Parse()
+
+	if backupPath == "" || unlockPassword == "" {
+		flag.Usage()
+		logger.Logger.Error("missing backup file or unlock password")
+		os.Exit(1)
+	}
+
+	backupFile, err := os.Open(backupPath)
+	if err != nil {
+		logger.Logger.WithError(err).Error("failed to open backup file")
+		os.Exit(1)
+	}
+	defer backupFile.Close()
+
+	report, err := backups.VerifyBackup(backupFile, unlockPassword)
+	if err != nil {
+		logger.Logger.WithError(err).Error("failed to verify backup")
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		logger.Logger.WithError(err).Error("failed to write report")
+		os.Exit(1)
+	}
+
+	if !report.Valid {
+		os.Exit(2)
+	}
+}
@@ -13,8 +13,19 @@ const (
 	AutoSwapAmountKey           = "AutoSwapAmount"
 	AutoSwapDestinationKey      = "AutoSwapDestination"
 	AutoSwapXpubIndexStart      = "AutoSwapXpubIndexStart"
+	// followed by the xpub of a channel close address which is not the auto swap destination
+	XpubIndexStartKeyPrefix = "XpubIndexStart:"
+	// set once the on-chain watcher recorded the transactions confirmed before it first ran
+	OnchainWatcherInitializedKey = "OnchainWatcherInitialized"
+	TransactionMetadataIndexKey  = "TransactionMetadataIndexVersion"
+	// hub Nostr key replaced by the last key rotation, served until it expires
+	PreviousNostrSecretKeyKey    = "PreviousNostrSecretKey"
+	PreviousNostrKeyExpiresAtKey = "PreviousNostrKeyExpiresAt"
 )
 
+// file in the work directory locked by the running hub
+const LockFileName = "albyhub.lock"
+
 type AppConfig struct {
 	Relay                              string `envconfig:"RELAY" default:"wss://relay.getalby.com/v1"`
 	LNBackendType                      string `envconfig:"LN_BACKEND_TYPE"`
@@ -58,6 +69,27 @@ type AppConfig struct {
 	AutoUnlockPassword                 string `envconfig:"AUTO_UNLOCK_PASSWORD"`
 	LogDBQueries                       bool   `envconfig:"LOG_DB_QUERIES" default:"false"`
 	BoltzApi                           string `envconfig:"BOLTZ_API" default:"https://api.boltz.exchange"`
+	PaymentApprovalWebhookUrl          string `envconfig:"PAYMENT_APPROVAL_WEBHOOK_URL"`
+	RequestArchiveRetentionDays        uint   `envconfig:"REQUEST_ARCHIVE_RETENTION_DAYS" default:"7"`
+	HoldInvoiceAutoCancelBlocks        uint32 `envconfig:"HOLD_INVOICE_AUTO_CANCEL_BLOCKS" default:"0"`
+	HoldInvoiceExpiryWarningBlocks     uint32 `envconfig:"HOLD_INVOICE_EXPIRY_WARNING_BLOCKS" default:"6"`
+	BackupIntervalHours                uint   `envconfig:"BACKUP_INTERVAL_HOURS" default:"0"`
+	BackupRetentionCount               uint   `envconfig:"BACKUP_RETENTION_COUNT" default:"7"`
+	BackupDir                          string `envconfig:"BACKUP_DIR"`
+	BackupS3Endpoint                   string `envconfig:"BACKUP_S3_ENDPOINT"`
+	BackupS3Bucket                     string `envconfig:"BACKUP_S3_BUCKET"`
+	BackupS3Region                     string `envconfig:"BACKUP_S3_REGION" default:"us-east-1"`
+	BackupS3Prefix                     string `envconfig:"BACKUP_S3_PREFIX"`
+	BackupS3AccessKeyId                string `envconfig:"BACKUP_S3_ACCESS_KEY_ID"`
+	BackupS3SecretAccessKey            string `envconfig:"BACKUP_S3_SECRET_ACCESS_KEY"`
+	RetentionPruneIntervalHours        uint   `envconfig:"RETENTION_PRUNE_INTERVAL_HOURS" default:"0"`
+	RetentionRequestEventDays          uint   `envconfig:"RETENTION_REQUEST_EVENT_DAYS" default:"0"`
+	RetentionExpiredInvoiceDays        uint   `envconfig:"RETENTION_EXPIRED_INVOICE_DAYS" default:"0"`
+	RetentionFailedPaymentDays         uint   `envconfig:"RETENTION_FAILED_PAYMENT_DAYS" default:"0"`
+	RetentionNotificationDays          uint   `envconfig:"RETENTION_NOTIFICATION_DAYS" default:"0"`
+	RetentionVacuum                    bool   `envconfig:"RETENTION_VACUUM" default:"false"`
+	NostrSignerUrl                     string `envconfig:"NOSTR_SIGNER_URL"`
+	TracingOtlpEndpoint                string `envconfig:"TRACING_OTLP_ENDPOINT"`
 }
 
 func (c *AppConfig) IsDefaultClientId() bool {
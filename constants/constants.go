@@ -18,6 +18,33 @@ const (
 	SWAP_STATE_SUCCESS  = "SUCCESS"
 	SWAP_STATE_FAILED   = "FAILED"
 	SWAP_STATE_REFUNDED = "REFUNDED"
+
+	CHANNEL_OPEN_STATE_PENDING = "PENDING"
+	CHANNEL_OPEN_STATE_FAILED  = "FAILED"
+
+	PENDING_CHANNEL_CLOSE_STATE_PENDING  = "PENDING"
+	PENDING_CHANNEL_CLOSE_STATE_CLOSING  = "CLOSING"
+	PENDING_CHANNEL_CLOSE_STATE_FAILED   = "FAILED"
+	PENDING_CHANNEL_CLOSE_STATE_CANCELED = "CANCELED"
+
+	PAYMENT_APPROVAL_STATE_PENDING  = "PENDING"
+	PAYMENT_APPROVAL_STATE_APPROVED = "APPROVED"
+	PAYMENT_APPROVAL_STATE_DENIED   = "DENIED"
+	PAYMENT_APPROVAL_STATE_EXPIRED  = "EXPIRED"
+	// the approved payment was attempted, an approval only allows a single attempt
+	PAYMENT_APPROVAL_STATE_USED = "USED"
+
+	NIP47_NOTIFICATION_STATE_PENDING   = "PENDING"
+	NIP47_NOTIFICATION_STATE_PUBLISHED = "PUBLISHED"
+	NIP47_NOTIFICATION_STATE_FAILED    = "FAILED"
+
+	LABEL_ENTITY_TYPE_TRANSACTION         = "transaction"
+	LABEL_ENTITY_TYPE_ONCHAIN_TRANSACTION = "onchain_transaction"
+	LABEL_ENTITY_TYPE_CHANNEL             = "channel"
+	LABEL_ENTITY_TYPE_PEER                = "peer"
+
+	PAYMENT_APPROVAL_REASON_THRESHOLD   = "threshold"
+	PAYMENT_APPROVAL_REASON_OVER_BUDGET = "over_budget"
 )
 
 const (
@@ -39,15 +66,30 @@ func GetBudgetRenewals() []string {
 }
 
 const (
-	PAY_INVOICE_SCOPE       = "pay_invoice" // also covers pay_keysend and multi_* payment methods
-	GET_BALANCE_SCOPE       = "get_balance"
-	GET_INFO_SCOPE          = "get_info"
-	MAKE_INVOICE_SCOPE      = "make_invoice"
-	LOOKUP_INVOICE_SCOPE    = "lookup_invoice"
-	LIST_TRANSACTIONS_SCOPE = "list_transactions"
-	SIGN_MESSAGE_SCOPE      = "sign_message"
-	NOTIFICATIONS_SCOPE     = "notifications" // covers all notification types
-	SUPERUSER_SCOPE         = "superuser"
+	PAY_INVOICE_SCOPE           = "pay_invoice" // also covers pay_keysend and multi_* payment methods
+	GET_BALANCE_SCOPE           = "get_balance"
+	GET_INFO_SCOPE              = "get_info"
+	MAKE_INVOICE_SCOPE          = "make_invoice"
+	LOOKUP_INVOICE_SCOPE        = "lookup_invoice"
+	LIST_TRANSACTIONS_SCOPE     = "list_transactions"
+	SIGN_MESSAGE_SCOPE          = "sign_message"
+	NOTIFICATIONS_SCOPE         = "notifications" // covers payment and hold invoice notification types
+	CHANNEL_NOTIFICATIONS_SCOPE = "channel_notifications"
+	SWAP_NOTIFICATIONS_SCOPE    = "swap_notifications"
+	ONCHAIN_NOTIFICATIONS_SCOPE = "onchain_notifications"
+	BUDGET_NOTIFICATIONS_SCOPE  = "budget_notifications"
+	SUPERUSER_SCOPE             = "superuser"
+)
+
+// NIP-47 notification types which are not related to lightning payments
+// and require their own notification scope
+const (
+	CHANNEL_OPENED_NOTIFICATION   = "channel_opened"
+	CHANNEL_CLOSED_NOTIFICATION   = "channel_closed"
+	SWAP_SUCCEEDED_NOTIFICATION   = "swap_succeeded"
+	SWAP_FAILED_NOTIFICATION      = "swap_failed"
+	ONCHAIN_RECEIVED_NOTIFICATION = "onchain_received"
+	BUDGET_WARNING_NOTIFICATION   = "budget_warning"
 )
 
 // limit encoded metadata length, otherwise relays may have trouble listing multiple transactions
@@ -69,6 +111,8 @@ const (
 	ERROR_NOT_FOUND              = "NOT_FOUND"
 	ERROR_UNSUPPORTED_ENCRYPTION = "UNSUPPORTED_ENCRYPTION"
 	ERROR_OTHER                  = "OTHER"
+	// the payment waits for approval by the wallet owner and can be retried
+	ERROR_PENDING_APPROVAL = "PENDING_APPROVAL"
 )
 
 const (
@@ -18,6 +18,8 @@ type Config struct {
 	URI        string
 	LogQueries bool
 	DriverName string
+	// open the database as it is, e.g. when it belongs to a stopped hub of another version
+	SkipMigrations bool
 }
 
 func NewDB(uri string, logDBQueries bool) (*gorm.DB, error) {
@@ -82,6 +84,10 @@ func NewDBWithConfig(cfg *Config) (*gorm.DB, error) {
 
 	logger.Logger.WithField("db_backend", ret.Dialector.Name()).Debug("loaded database")
 
+	if cfg.SkipMigrations {
+		return ret, nil
+	}
+
 	err := migrations.Migrate(ret)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to migrate")
@@ -91,6 +97,16 @@ func NewDBWithConfig(cfg *Config) (*gorm.DB, error) {
 	return ret, nil
 }
 
+// NewReadOnlySqliteDB opens an existing SQLite database without migrating it, e.g. to inspect a backup
+func NewReadOnlySqliteDB(path string) (*gorm.DB, error) {
+	return newSqliteDB(sqlite.Config{
+		DriverName: sqlite_wrapper.Sqlite3WrapperDriverName,
+		DSN:        "file:" + path + "?mode=ro&_query_only=1",
+	}, &gorm.Config{
+		TranslateError: true,
+	})
+}
+
 func newSqliteDB(sqliteConfig sqlite.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
 	gormDB, err := gorm.Open(sqlite.New(sqliteConfig), gormConfig)
 	if err != nil {
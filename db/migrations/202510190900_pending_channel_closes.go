@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const pendingChannelClosesMigration = `
+CREATE TABLE pending_channel_closes(
+	id {{ .AutoincrementPrimaryKey }},
+	node_id text,
+	channel_id text,
+	max_fee_rate integer,
+	fee_rate integer,
+	address text,
+	state text,
+	attempts integer,
+	error text,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }}
+);
+`
+
+var pendingChannelClosesMigrationTmpl = template.Must(template.New("pendingChannelClosesMigration").Parse(pendingChannelClosesMigration))
+
+var _202510190900_pending_channel_closes = &gormigrate.Migration{
+	ID: "202510190900_pending_channel_closes",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, pendingChannelClosesMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
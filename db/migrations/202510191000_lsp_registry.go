@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const lspRegistryMigration = `
+CREATE TABLE lsps(
+	id {{ .AutoincrementPrimaryKey }},
+	name text,
+	identifier text UNIQUE,
+	url text,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }}
+);
+
+CREATE TABLE lsp_orders(
+	id {{ .AutoincrementPrimaryKey }},
+	lsp_identifier text,
+	network text,
+	order_id text,
+	order_state text,
+	payment_state text,
+	accepted boolean,
+	lsp_pubkey text,
+	lsp_address text,
+	lsp_port integer,
+	amount integer,
+	fee integer,
+	invoice text,
+	public boolean,
+	channel_expiry_blocks integer,
+	required_channel_confirmations integer,
+	funding_confirms_within_blocks integer,
+	funding_outpoint text,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }}
+);
+
+CREATE INDEX idx_lsp_orders_order_state ON lsp_orders(order_state);
+`
+
+var lspRegistryMigrationTmpl = template.Must(template.New("lspRegistryMigration").Parse(lspRegistryMigration))
+
+var _202510191000_lsp_registry = &gormigrate.Migration{
+	ID: "202510191000_lsp_registry",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, lspRegistryMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
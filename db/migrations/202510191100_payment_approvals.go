@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const paymentApprovalsMigration = `
+ALTER TABLE apps ADD COLUMN approval_threshold_sat integer DEFAULT 0;
+ALTER TABLE apps ADD COLUMN approve_over_budget boolean DEFAULT false;
+
+CREATE TABLE payment_approvals(
+	id {{ .AutoincrementPrimaryKey }},
+	app_id integer,
+	request_event_id integer,
+	method text,
+	payment_hash text,
+	payment_request text,
+	description text,
+	amount_msat bigint,
+	reason text,
+	state text,
+	expires_at {{ .Timestamp }},
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }},
+	CONSTRAINT fk_payment_approvals_app FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payment_approvals_request_event_id ON payment_approvals(request_event_id);
+CREATE INDEX idx_payment_approvals_state ON payment_approvals(state);
+`
+
+var paymentApprovalsMigrationTmpl = template.Must(template.New("paymentApprovalsMigration").Parse(paymentApprovalsMigration))
+
+var _202510191100_payment_approvals = &gormigrate.Migration{
+	ID: "202510191100_payment_approvals",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, paymentApprovalsMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
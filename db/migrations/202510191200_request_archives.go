@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const requestArchivesMigration = `
+ALTER TABLE apps ADD COLUMN archive_requests boolean DEFAULT false;
+
+CREATE TABLE request_archives(
+	id {{ .AutoincrementPrimaryKey }},
+	app_id integer,
+	request_event_id integer,
+	nostr_id text,
+	method text,
+	encrypted_payload text,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }},
+	CONSTRAINT fk_request_archives_app FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE,
+	CONSTRAINT fk_request_archives_request_event FOREIGN KEY (request_event_id) REFERENCES request_events(id) ON DELETE CASCADE
+);
+
+CREATE TABLE response_archives(
+	id {{ .AutoincrementPrimaryKey }},
+	request_archive_id integer,
+	nostr_id text,
+	encrypted_payload text,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }},
+	CONSTRAINT fk_response_archives_request_archive FOREIGN KEY (request_archive_id) REFERENCES request_archives(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_request_archives_nostr_id ON request_archives(nostr_id);
+CREATE INDEX idx_request_archives_created_at ON request_archives(created_at);
+CREATE INDEX idx_response_archives_request_archive_id ON response_archives(request_archive_id);
+`
+
+var requestArchivesMigrationTmpl = template.Must(template.New("requestArchivesMigration").Parse(requestArchivesMigration))
+
+var _202510191200_request_archives = &gormigrate.Migration{
+	ID: "202510191200_request_archives",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, requestArchivesMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const nip47NotificationsMigration = `
+CREATE TABLE nip47_notifications(
+	id {{ .AutoincrementPrimaryKey }},
+	app_id integer,
+	notification_type text,
+	payload json,
+	tags json,
+	state text,
+	attempts integer DEFAULT 0,
+	last_error text,
+	published_at {{ .Timestamp }},
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }},
+	CONSTRAINT fk_nip47_notifications_app FOREIGN KEY (app_id) REFERENCES apps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_nip47_notifications_app_id_created_at ON nip47_notifications(app_id, created_at);
+CREATE INDEX idx_nip47_notifications_state ON nip47_notifications(state);
+`
+
+var nip47NotificationsMigrationTmpl = template.Must(template.New("nip47NotificationsMigration").Parse(nip47NotificationsMigration))
+
+var _202510201200_nip47_notifications = &gormigrate.Migration{
+	ID: "202510201200_nip47_notifications",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, nip47NotificationsMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
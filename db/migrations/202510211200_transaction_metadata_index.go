@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const transactionMetadataEntriesMigration = `
+CREATE TABLE transaction_metadata_entries(
+	id {{ .AutoincrementPrimaryKey }},
+	transaction_id integer,
+	key text,
+	value text,
+	CONSTRAINT fk_transaction_metadata_entries_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_metadata_entries_key_value ON transaction_metadata_entries(key, value);
+CREATE INDEX idx_transaction_metadata_entries_transaction_id ON transaction_metadata_entries(transaction_id);
+`
+
+var transactionMetadataEntriesMigrationTmpl = template.Must(template.New("transactionMetadataEntriesMigration").Parse(transactionMetadataEntriesMigration))
+
+// Full-text search over descriptions and extracted metadata.
+// The docid column references the transaction id.
+const transactionSearchSqliteMigration = `
+CREATE VIRTUAL TABLE transaction_search USING fts4(content, tokenize=unicode61);
+`
+
+const transactionSearchPostgresMigration = `
+CREATE TABLE transaction_search(
+	docid integer PRIMARY KEY,
+	content text,
+	CONSTRAINT fk_transaction_search_transaction FOREIGN KEY (docid) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_transaction_search_content ON transaction_search USING GIN (to_tsvector('simple', content));
+`
+
+var _202510211200_transaction_metadata_index = &gormigrate.Migration{
+	ID: "202510211200_transaction_metadata_index",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, transactionMetadataEntriesMigrationTmpl); err != nil {
+			return err
+		}
+
+		transactionSearchMigration := transactionSearchSqliteMigration
+		if tx.Dialector.Name() == "postgres" {
+			transactionSearchMigration = transactionSearchPostgresMigration
+		}
+		if err := tx.Exec(transactionSearchMigration).Error; err != nil {
+			return err
+		}
+
+		// existing transactions are indexed on startup, see service.reindexTransactionMetadata
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const labelsMigration = `
+CREATE TABLE labels(
+	id {{ .AutoincrementPrimaryKey }},
+	entity_type text NOT NULL,
+	entity_id text NOT NULL,
+	label text,
+	note text,
+	tags json,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }}
+);
+
+CREATE UNIQUE INDEX idx_labels_entity_type_entity_id ON labels(entity_type, entity_id);
+`
+
+var labelsMigrationTmpl = template.Must(template.New("labelsMigration").Parse(labelsMigration))
+
+var _202510221200_labels = &gormigrate.Migration{
+	ID: "202510221200_labels",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, labelsMigrationTmpl); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const holdInvoiceExpiryNotifiedMigration = `ALTER TABLE transactions ADD COLUMN hold_expiry_notified_at {{ .Timestamp }};`
+
+var holdInvoiceExpiryNotifiedMigrationTmpl = template.Must(template.New("holdInvoiceExpiryNotifiedMigration").Parse(holdInvoiceExpiryNotifiedMigration))
+
+var _202510231200_hold_invoice_expiry_notified = &gormigrate.Migration{
+	ID: "202510231200_hold_invoice_expiry_notified",
+	Migrate: func(tx *gorm.DB) error {
+
+		err := exec(tx, holdInvoiceExpiryNotifiedMigrationTmpl)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
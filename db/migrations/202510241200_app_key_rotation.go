@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	_ "embed"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const appKeyRotationMigration = `
+ALTER TABLE apps ADD COLUMN wallet_key_generation integer NOT NULL DEFAULT 0;
+ALTER TABLE apps ADD COLUMN previous_app_pubkey text NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN previous_wallet_pubkey text NOT NULL DEFAULT '';
+ALTER TABLE apps ADD COLUMN previous_keys_expire_at {{ .Timestamp }};
+CREATE INDEX idx_apps_previous_app_pubkey ON apps(previous_app_pubkey);
+`
+
+var appKeyRotationMigrationTmpl = template.Must(template.New("appKeyRotationMigration").Parse(appKeyRotationMigration))
+
+var _202510241200_app_key_rotation = &gormigrate.Migration{
+	ID: "202510241200_app_key_rotation",
+	Migrate: func(tx *gorm.DB) error {
+
+		err := exec(tx, appKeyRotationMigrationTmpl)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
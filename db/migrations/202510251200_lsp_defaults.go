@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// LSPs can now be registered per network with the pubkey of their node, which
+// is trusted for 0-conf channels. The identifier is only unique per network,
+// as LSPs proxied through Alby use the same identifier on every network.
+//
+// The LSPs which were previously hard-coded are seeded into the registry.
+var _202510251200_lsp_defaults = &gormigrate.Migration{
+	ID: "202510251200_lsp_defaults",
+	Migrate: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			err := tx.Exec(`
+ALTER TABLE lsps DROP CONSTRAINT lsps_identifier_key;
+ALTER TABLE lsps ADD COLUMN network text NOT NULL DEFAULT '';
+ALTER TABLE lsps ADD COLUMN pubkey text NOT NULL DEFAULT '';
+`).Error
+			if err != nil {
+				return err
+			}
+		} else {
+			// sqlite cannot drop the unique constraint of a column
+			err := tx.Exec(`
+CREATE TABLE lsps_2(
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name text,
+	identifier text,
+	network text NOT NULL DEFAULT '',
+	pubkey text NOT NULL DEFAULT '',
+	url text,
+	created_at datetime,
+	updated_at datetime
+);
+INSERT INTO lsps_2 (id, name, identifier, url, created_at, updated_at) SELECT id, name, identifier, url, created_at, updated_at FROM lsps;
+DROP TABLE lsps;
+ALTER TABLE lsps_2 RENAME TO lsps;
+`).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := tx.Exec(`CREATE UNIQUE INDEX idx_lsps_identifier_network ON lsps(identifier, network);`).Error
+		if err != nil {
+			return err
+		}
+
+		defaultLSPs := []struct {
+			name       string
+			identifier string
+			network    string
+			pubkey     string
+		}{
+			{"Olympus", "olympus", "bitcoin", "031b301307574bbe9b9ac7b79cbe1700e31e544513eae0b5d7497483083f99e581"},
+			{"Megalith", "megalith", "bitcoin", "038a9e56512ec98da2b5789761f7af8f280baf98a09282360cd6ff1381b5e889bf"},
+			{"LNServer Wave", "lnserver", "bitcoin", "02b4552a7a85274e4da01a7c71ca57407181752e8568b31d51f13c111a2941dce3"},
+			{"Olympus", "olympus", "signet", "032ae843e4d7d177f151d021ac8044b0636ec72b1ce3ffcde5c04748db2517ab03"},
+			{"Megalith", "megalith", "signet", "03e30fda71887a916ef5548a4d02b06fe04aaa1a8de9e24134ce7f139cf79d7579"},
+		}
+		for _, defaultLSP := range defaultLSPs {
+			// do not add an LSP which was already registered for all networks
+			err := tx.Exec(`
+INSERT INTO lsps (name, identifier, network, pubkey, url, created_at, updated_at)
+SELECT ?, ?, ?, ?, '', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+WHERE NOT EXISTS (SELECT 1 FROM lsps WHERE identifier = ? AND network IN (?, ''))
+`, defaultLSP.name, defaultLSP.identifier, defaultLSP.network, defaultLSP.pubkey, defaultLSP.identifier, defaultLSP.network).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
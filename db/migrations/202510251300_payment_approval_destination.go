@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Keysend payments have no invoice, so the destination is saved to find the
+// approval again when the app retries the payment.
+var _202510251300_payment_approval_destination = &gormigrate.Migration{
+	ID: "202510251300_payment_approval_destination",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+ALTER TABLE payment_approvals ADD COLUMN destination text;
+CREATE INDEX idx_payment_approvals_app_id_payment_hash ON payment_approvals(app_id, payment_hash);
+`).Error; err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
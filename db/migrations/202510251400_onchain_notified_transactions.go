@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	_ "embed"
+	"encoding/json"
+	"text/template"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+const onchainNotifiedTransactionsMigration = `
+CREATE TABLE onchain_notified_transactions(
+	id {{ .AutoincrementPrimaryKey }},
+	tx_id text NOT NULL,
+	created_at {{ .Timestamp }},
+	updated_at {{ .Timestamp }}
+);
+
+CREATE UNIQUE INDEX idx_onchain_notified_transactions_tx_id ON onchain_notified_transactions(tx_id);
+`
+
+var onchainNotifiedTransactionsMigrationTmpl = template.Must(template.New("onchainNotifiedTransactionsMigration").Parse(onchainNotifiedTransactionsMigration))
+
+// The notified incoming on-chain transactions were stored as a JSON array in
+// user_configs. They are moved into a table keyed by txid, and the config
+// entry only records that the watcher has run before.
+var _202510251400_onchain_notified_transactions = &gormigrate.Migration{
+	ID: "202510251400_onchain_notified_transactions",
+	Migrate: func(tx *gorm.DB) error {
+
+		if err := exec(tx, onchainNotifiedTransactionsMigrationTmpl); err != nil {
+			return err
+		}
+
+		var notifiedTxIdsJson []string
+		err := tx.Raw("SELECT value FROM user_configs WHERE key = 'OnchainNotifiedTxIds'").Scan(&notifiedTxIdsJson).Error
+		if err != nil {
+			return err
+		}
+		if len(notifiedTxIdsJson) == 0 {
+			return nil
+		}
+
+		var notifiedTxIds []string
+		if err := json.Unmarshal([]byte(notifiedTxIdsJson[0]), &notifiedTxIds); err != nil {
+			return err
+		}
+		for _, txId := range notifiedTxIds {
+			err := tx.Exec("INSERT INTO onchain_notified_transactions (tx_id, created_at, updated_at) VALUES (?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", txId).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec("UPDATE user_configs SET key = 'OnchainWatcherInitialized', value = 'true' WHERE key = 'OnchainNotifiedTxIds'").Error
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// A notification is published once per encryption and app pubkey. The published
+// variants are saved so that a retry does not publish them again.
+var _202510251500_nip47_notification_variants = &gormigrate.Migration{
+	ID: "202510251500_nip47_notification_variants",
+	Migrate: func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE nip47_notifications ADD COLUMN published_variants json;`).Error; err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// Transaction labels reference the transaction by an integer column, so that
+// they can be joined with transactions without casting the entity id.
+var _202510251600_label_transaction_id = &gormigrate.Migration{
+	ID: "202510251600_label_transaction_id",
+	Migrate: func(tx *gorm.DB) error {
+		numericEntityId := "entity_id GLOB '[0-9]*' AND entity_id NOT GLOB '*[^0-9]*'"
+		if tx.Dialector.Name() == "postgres" {
+			numericEntityId = "entity_id ~ '^[0-9]+$'"
+		}
+
+		if err := tx.Exec(`
+ALTER TABLE labels ADD COLUMN transaction_id integer;
+CREATE INDEX idx_labels_transaction_id ON labels(transaction_id);
+`).Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("UPDATE labels SET transaction_id = CAST(entity_id AS integer) WHERE entity_type = 'transaction' AND " + numericEntityId).Error
+	},
+	Rollback: func(tx *gorm.DB) error {
+		return nil
+	},
+}
@@ -1,7 +1,9 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -9,40 +11,87 @@ import (
 	"gorm.io/gorm"
 )
 
+var allMigrations = []*gormigrate.Migration{
+	_202401191539_initial_migration,
+	_202403171120_delete_ldk_payments,
+	_202404021909_nullable_expires_at,
+	_202405302121_store_decrypted_request,
+	_202406061259_delete_content,
+	_202406071726_vacuum,
+	_202406301207_rename_request_methods,
+	_202407012100_transactions,
+	_202407151352_autoincrement,
+	_202407201604_transactions_indexes,
+	_202407262257_remove_invalid_scopes,
+	_202408061737_add_boostagrams_and_use_json,
+	_202408191242_transaction_failure_reason,
+	_202408291715_app_metadata,
+	_202410141503_add_wallet_pubkey,
+	_202412212345_fix_types,
+	_202504231037_add_indexes,
+	_202505091314_hold_invoices,
+	_202506170342_swaps,
+	_202508041712_delete_non_cascade_deleted_records,
+	_202508041737_postgres_amount_bigint,
+	_202508041738_app_last_used,
+	_202508041739_response_events_index,
+	_202508151405_swap_xpub,
+	_202508192137_forwards,
+	_202509031250_transactions_updated_at_index,
+	_202510190900_pending_channel_closes,
+	_202510191000_lsp_registry,
+	_202510191100_payment_approvals,
+	_202510191200_request_archives,
+	_202510201200_nip47_notifications,
+	_202510211200_transaction_metadata_index,
+	_202510221200_labels,
+	_202510231200_hold_invoice_expiry_notified,
+	_202510241200_app_key_rotation,
+	_202510251200_lsp_defaults,
+	_202510251300_payment_approval_destination,
+	_202510251400_onchain_notified_transactions,
+	_202510251500_nip47_notification_variants,
+	_202510251600_label_transaction_id,
+}
+
 func Migrate(gormDB *gorm.DB) error {
 
-	m := gormigrate.New(gormDB, gormigrate.DefaultOptions, []*gormigrate.Migration{
-		_202401191539_initial_migration,
-		_202403171120_delete_ldk_payments,
-		_202404021909_nullable_expires_at,
-		_202405302121_store_decrypted_request,
-		_202406061259_delete_content,
-		_202406071726_vacuum,
-		_202406301207_rename_request_methods,
-		_202407012100_transactions,
-		_202407151352_autoincrement,
-		_202407201604_transactions_indexes,
-		_202407262257_remove_invalid_scopes,
-		_202408061737_add_boostagrams_and_use_json,
-		_202408191242_transaction_failure_reason,
-		_202408291715_app_metadata,
-		_202410141503_add_wallet_pubkey,
-		_202412212345_fix_types,
-		_202504231037_add_indexes,
-		_202505091314_hold_invoices,
-		_202506170342_swaps,
-		_202508041712_delete_non_cascade_deleted_records,
-		_202508041737_postgres_amount_bigint,
-		_202508041738_app_last_used,
-		_202508041739_response_events_index,
-		_202508151405_swap_xpub,
-		_202508192137_forwards,
-		_202509031250_transactions_updated_at_index,
-	})
+	m := gormigrate.New(gormDB, gormigrate.DefaultOptions, allMigrations)
 
 	return m.Migrate()
 }
 
+// MigrationIds returns the IDs of all migrations known to this version of the hub
+func MigrationIds() []string {
+	migrationIds := make([]string, 0, len(allMigrations))
+	for _, migration := range allMigrations {
+		migrationIds = append(migrationIds, migration.ID)
+	}
+	return migrationIds
+}
+
+// CheckMigrated returns an error if the database was not migrated to exactly this version of the hub
+func CheckMigrated(gormDB *gorm.DB) error {
+	var appliedMigrationIds []string
+	err := gormDB.Raw("SELECT id FROM " + gormigrate.DefaultOptions.TableName).Scan(&appliedMigrationIds).Error
+	if err != nil {
+		return fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	knownMigrationIds := MigrationIds()
+	for _, migrationId := range appliedMigrationIds {
+		if !slices.Contains(knownMigrationIds, migrationId) {
+			return errors.New("the database was migrated by a newer version of Alby Hub")
+		}
+	}
+	for _, migrationId := range knownMigrationIds {
+		if !slices.Contains(appliedMigrationIds, migrationId) {
+			return errors.New("the database was not migrated to this version of Alby Hub, start the hub once to migrate it")
+		}
+	}
+	return nil
+}
+
 type sqlDialectDef struct {
 	Timestamp               string
 	AutoincrementPrimaryKey string
@@ -26,6 +26,18 @@ type App struct {
 	LastUsedAt   *time.Time
 	Isolated     bool
 	Metadata     datatypes.JSON
+	// payments of at least this amount need to be approved by the owner (0 = disabled)
+	ApprovalThresholdSat uint64
+	// ask the owner to approve payments which exceed the budget instead of rejecting them
+	ApproveOverBudget bool
+	// store encrypted request and response payloads for debugging
+	ArchiveRequests bool
+	// incremented every time the app keys are rotated
+	WalletKeyGeneration uint
+	// keys replaced by the last rotation, which are still served until PreviousKeysExpireAt
+	PreviousAppPubkey    string
+	PreviousWalletPubkey string
+	PreviousKeysExpireAt *time.Time
 }
 
 type AppPermission struct {
@@ -88,6 +100,8 @@ type Transaction struct {
 	FailureReason   string
 	Hold            bool
 	SettleDeadline  *uint32 // block number for accepted hold invoices
+	// set once the app was notified that the accepted hold invoice will be auto-canceled
+	HoldExpiryNotifiedAt *time.Time
 }
 
 type Swap struct {
@@ -122,6 +136,142 @@ type Forward struct {
 	UpdatedAt                   time.Time
 }
 
+type PendingChannelClose struct {
+	ID        uint
+	NodeId    string
+	ChannelId string
+	// only close once the mempool fee rate (sat/vB) is at or below this value
+	MaxFeeRate uint64
+	// fee rate (sat/vB) to close with. If not set, the node's fee estimate is used
+	FeeRate   *uint64
+	Address   string
+	State     string
+	Attempts  int
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type PaymentApproval struct {
+	ID             uint
+	AppId          uint
+	App            App
+	RequestEventId uint
+	Method         string
+	PaymentHash    string
+	PaymentRequest string
+	// recipient pubkey of keysend payments
+	Destination string
+	Description string
+	AmountMsat  uint64
+	Reason      string
+	State       string
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type RequestArchive struct {
+	ID               uint
+	AppId            uint
+	App              App
+	RequestEventId   uint
+	NostrId          string
+	Method           string
+	EncryptedPayload string
+	Responses        []ResponseArchive
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+type ResponseArchive struct {
+	ID               uint
+	RequestArchiveId uint
+	NostrId          string
+	EncryptedPayload string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// OnchainNotifiedTransaction is an incoming on-chain transaction for which
+// a confirmation event was published
+type OnchainNotifiedTransaction struct {
+	ID        uint
+	TxId      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Nip47Notification struct {
+	ID               uint
+	AppId            uint
+	App              App
+	NotificationType string
+	Payload          datatypes.JSON
+	Tags             datatypes.JSON
+	State            string
+	Attempts         uint
+	LastError        string
+	// encryption and app pubkey of each published event, e.g. "nip44_v2:<pubkey>"
+	PublishedVariants datatypes.JSON
+	PublishedAt       *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Label is an owner-defined annotation on a transaction, on-chain transaction, channel or peer.
+// EntityId is the transaction ID, on-chain txid, channel ID or peer pubkey depending on EntityType
+type Label struct {
+	ID         uint
+	EntityType string
+	EntityId   string
+	// set for transaction labels, which are joined with the transactions by it
+	TransactionId *uint
+	Label         string
+	Note          string
+	Tags          datatypes.JSON
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type LSP struct {
+	ID         uint
+	Name       string
+	Identifier string
+	// empty if the LSP can be used on every network
+	Network string
+	// node pubkey of the LSP, trusted for 0-conf channels. Can be empty
+	Pubkey string
+	// LSPS1 HTTP API base url. If empty, requests are proxied through Alby
+	Url       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type LSPOrder struct {
+	ID            uint
+	LSPIdentifier string
+	Network       string
+	OrderId       string
+	OrderState    string
+	PaymentState  string
+	// set once the user chose this quote and connected to the LSP
+	Accepted                     bool
+	LSPPubkey                    string
+	LSPAddress                   string
+	LSPPort                      uint16
+	Amount                       uint64
+	Fee                          uint64
+	Invoice                      string
+	Public                       bool
+	ChannelExpiryBlocks          uint64
+	RequiredChannelConfirmations uint64
+	FundingConfirmsWithinBlocks  uint64
+	FundingOutpoint              string
+	CreatedAt                    time.Time
+	UpdatedAt                    time.Time
+}
+
 const (
 	REQUEST_EVENT_STATE_HANDLER_EXECUTING = "executing"
 	REQUEST_EVENT_STATE_HANDLER_EXECUTED  = "executed"
@@ -0,0 +1,26 @@
+package db
+
+// Tables lists all tables of the hub database. cmd/db_migrate checks the schema
+// against it and database exports in backups must cover all of them.
+var Tables = []string{
+	"apps",
+	"app_permissions",
+	"request_events",
+	"response_events",
+	"transactions",
+	"swaps",
+	"user_configs",
+	"migrations",
+	"forwards",
+	"pending_channel_closes",
+	"lsps",
+	"lsp_orders",
+	"payment_approvals",
+	"request_archives",
+	"response_archives",
+	"nip47_notifications",
+	"transaction_metadata_entries",
+	"transaction_search",
+	"labels",
+	"onchain_notified_transactions",
+}
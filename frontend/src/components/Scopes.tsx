@@ -74,6 +74,10 @@ const Scopes: React.FC<ScopesProps> = ({
       "lookup_invoice",
       "list_transactions",
       "notifications",
+      "channel_notifications",
+      "swap_notifications",
+      "onchain_notifications",
+      "budget_notifications",
     ];
 
     return capabilities.scopes.filter((scope) =>
@@ -90,6 +94,7 @@ const Scopes: React.FC<ScopesProps> = ({
       "lookup_invoice",
       "list_transactions",
       "notifications",
+      "budget_notifications",
     ];
 
     return capabilities.scopes.filter((scope) =>
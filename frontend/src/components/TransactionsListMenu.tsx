@@ -18,12 +18,20 @@ const convertToCSV = (transactions: Transaction[]) => {
     return "";
   }
 
+  // flatten owner labels into their own columns
+  const rows = transactions.map(({ label, ...tx }) => ({
+    ...tx,
+    label: label?.label ?? "",
+    note: label?.note ?? "",
+    tags: label?.tags.join(" ") ?? "",
+  }));
+
   // Get headers from all transactions
-  const headers = Object.keys(transactions[0]);
+  const headers = Object.keys(rows[0]);
   const csvHeaders = headers.join(",");
 
   // Convert each transaction to CSV row
-  const csvRows = transactions.map((tx) => {
+  const csvRows = rows.map((tx) => {
     return headers
       .map((header) => {
         const value = tx[header as keyof typeof tx];
@@ -37,6 +37,8 @@ export function HealthCheckAlert() {
           return "Could not connect to relay";
         case "vss_no_subscription":
           return "Your lightning channel data is stored encrypted by Alby's Versioned Storage Service which is a paid feature. Restart your subscription or send your funds to another wallet as soon as possible.";
+        case "watchtowers_inactive":
+          return "None of your watchtowers accept new sessions, so new channel states may not be backed up";
       }
     } catch (error) {
       console.error("failed to parse alarm details", alarm.kind, error);
@@ -29,6 +29,7 @@ import {
   Nip47RequestMethod,
   Scope,
   WalletCapabilities,
+  notificationTypeToScope,
   validBudgetRenewals,
 } from "src/types";
 
@@ -168,8 +169,11 @@ const NewAppInternal = ({ capabilities }: NewAppInternalProps) => {
     if (requestMethodsSet.has("sign_message") && isolatedParam !== "true") {
       scopes.push("sign_message");
     }
-    if (notificationTypes.length) {
-      scopes.push("notifications");
+    for (const notificationType of notificationTypesSet) {
+      const scope = notificationTypeToScope(notificationType);
+      if (!scopes.includes(scope)) {
+        scopes.push(scope);
+      }
     }
 
     return scopes;
@@ -1,14 +1,18 @@
 import {
+  ArrowLeftRightIcon,
   BellIcon,
   CirclePlusIcon,
   CrownIcon,
   HandCoinsIcon,
+  GaugeIcon,
   InfoIcon,
+  LinkIcon,
   LucideIcon,
   NotebookTabsIcon,
   PenLineIcon,
   SearchIcon,
   WalletMinimalIcon,
+  ZapIcon,
 } from "lucide-react";
 
 export type BackendType = "LND" | "LDK" | "PHOENIX" | "CASHU";
@@ -27,7 +31,8 @@ export type Nip47RequestMethod =
   | "multi_pay_keysend"
   | "make_hold_invoice"
   | "settle_hold_invoice"
-  | "cancel_hold_invoice";
+  | "cancel_hold_invoice"
+  | "list_notifications";
 
 export type BudgetRenewalType =
   | "daily"
@@ -45,10 +50,43 @@ export type Scope =
   | "lookup_invoice"
   | "list_transactions"
   | "sign_message"
-  | "notifications" // covers all notification types
+  | "notifications" // covers payment and hold invoice notification types
+  | "channel_notifications"
+  | "swap_notifications"
+  | "onchain_notifications"
+  | "budget_notifications"
   | "superuser";
 
-export type Nip47NotificationType = "payment_received" | "payment_sent";
+export type Nip47NotificationType =
+  | "payment_received"
+  | "payment_sent"
+  | "hold_invoice_accepted"
+  | "hold_invoice_expiring"
+  | "channel_opened"
+  | "channel_closed"
+  | "swap_succeeded"
+  | "swap_failed"
+  | "onchain_received"
+  | "budget_warning";
+
+export function notificationTypeToScope(
+  notificationType: Nip47NotificationType
+): Scope {
+  switch (notificationType) {
+    case "channel_opened":
+    case "channel_closed":
+      return "channel_notifications";
+    case "swap_succeeded":
+    case "swap_failed":
+      return "swap_notifications";
+    case "onchain_received":
+      return "onchain_notifications";
+    case "budget_warning":
+      return "budget_notifications";
+    default:
+      return "notifications";
+  }
+}
 
 export type ScopeIconMap = {
   [key in Scope]: LucideIcon;
@@ -63,6 +101,10 @@ export const scopeIconMap: ScopeIconMap = {
   pay_invoice: HandCoinsIcon,
   sign_message: PenLineIcon,
   notifications: BellIcon,
+  channel_notifications: ZapIcon,
+  swap_notifications: ArrowLeftRightIcon,
+  onchain_notifications: LinkIcon,
+  budget_notifications: GaugeIcon,
   superuser: CrownIcon,
 };
 
@@ -89,6 +131,10 @@ export const scopeDescriptions: Record<Scope, string> = {
   pay_invoice: "Send payments",
   sign_message: "Sign messages",
   notifications: "Receive wallet notifications",
+  channel_notifications: "Receive channel open and close notifications",
+  swap_notifications: "Receive swap notifications",
+  onchain_notifications: "Receive on-chain payment notifications",
+  budget_notifications: "Receive budget warnings",
   superuser: "Create other app connections",
 };
 
@@ -129,6 +175,7 @@ export interface App {
   budgetUsage: number;
   budgetRenewal: BudgetRenewalType;
   metadata?: AppMetadata;
+  previousKeysExpireAt?: string;
 }
 
 export interface AppPermissions {
@@ -170,7 +217,8 @@ export type HealthAlarmKind =
   | "node_not_ready"
   | "channels_offline"
   | "nostr_relay_offline"
-  | "vss_no_subscription";
+  | "vss_no_subscription"
+  | "watchtowers_inactive";
 
 export type HealthAlarm = {
   kind: HealthAlarmKind;
@@ -275,6 +323,37 @@ export interface CreateAppResponse {
   returnTo: string;
 }
 
+export type RotateAppKeysRequest = {
+  gracePeriodHours?: number;
+};
+
+export interface RotateAppKeysResponse {
+  id: number;
+  name: string;
+  pairingUri: string;
+  pairingPublicKey: string;
+  pairingSecretKey: string;
+  walletPubkey: string;
+  previousKeysExpireAt: string;
+}
+
+export interface RotateAllAppKeysResponse {
+  apps: RotateAppKeysResponse[];
+  skipped: string[];
+}
+
+export type RotateNostrKeyRequest = {
+  unlockPassword: string;
+  gracePeriodHours?: number;
+};
+
+export interface RotateNostrKeyResponse {
+  pubkey: string;
+  previousPubkey: string;
+  previousKeyExpiresAt: string;
+  legacyAppCount: number;
+}
+
 export type UpdateAppRequest = {
   name: string;
   maxAmount: number;
@@ -304,6 +383,7 @@ export type Channel = {
   error?: string;
   status: "online" | "opening" | "offline";
   isOutbound: boolean;
+  label: Label | null;
 };
 
 export type UpdateChannelRequest = {
@@ -315,6 +395,7 @@ export type Peer = {
   address: string;
   isPersisted: boolean;
   isConnected: boolean;
+  label: Label | null;
 };
 
 export type NodeConnectionInfo = {
@@ -560,6 +641,7 @@ export type BalancesResponse = {
 };
 
 export type Transaction = {
+  id: number;
   type: "incoming" | "outgoing";
   state: "settled" | "pending" | "failed";
   appId: number | undefined;
@@ -576,6 +658,7 @@ export type Transaction = {
   metadata?: TransactionMetadata;
   boostagram?: Boostagram;
   failureReason: string;
+  label: Label | null;
 };
 
 export type TransactionMetadata = {
@@ -623,6 +706,34 @@ export type OnchainTransaction = {
   state: "confirmed" | "unconfirmed";
   numConfirmations: number;
   txId: string;
+  label: Label | null;
+};
+
+export type LabelEntityType =
+  | "transaction"
+  | "onchain_transaction"
+  | "channel"
+  | "peer";
+
+// owner-defined annotation, see SetLabelRequest
+export type Label = {
+  entityType: LabelEntityType;
+  entityId: string; // transaction id, on-chain txid, channel id or peer pubkey
+  label: string;
+  note: string;
+  tags: string[];
+  updatedAt: string;
+};
+
+export type SetLabelRequest = {
+  label: string;
+  note: string;
+  tags: string[];
+};
+
+export type LabelFilters = {
+  label?: string; // case-insensitive match on the label or note
+  tag?: string;
 };
 
 export type ListAppsResponse = {
@@ -630,9 +741,109 @@ export type ListAppsResponse = {
   totalCount: number;
 };
 
+export type ListTransactionsFilters = {
+  cursor?: string;
+  type?: "incoming" | "outgoing";
+  states?: Transaction["state"][];
+  minAmountMsat?: number;
+  maxAmountMsat?: number;
+  metadata?: Record<string, string>;
+  payerName?: string;
+  recipient?: string;
+  nostrEventId?: string;
+  tag?: string;
+  search?: string; // full-text search over descriptions, comments, payer names, recipients and tags
+  label?: string;
+  labelTag?: string;
+};
+
+export type HoldInvoice = Transaction & {
+  settleDeadline: number;
+  autoCancelHeight: number | null; // null if auto-cancel is disabled
+  blocksRemaining: number;
+  estimatedSecondsRemaining: number;
+  expiryNotifiedAt: string | null;
+};
+
+export type BackupVerificationReport = {
+  valid: boolean;
+  errors: string[];
+  manifest?: {
+    version: number;
+    hub_version: string;
+    created_at: string;
+    database: string;
+    ln_backend_type: string;
+  };
+  databaseFormat: "sqlite" | "export" | "";
+  fileCount: number;
+  nodeStorageFileCount: number;
+  latestMigration: string;
+  unknownMigrations: string[];
+  pendingMigrationCount: number;
+  mnemonicDecryptable: boolean;
+  nostrSecretKeyDecryptable: boolean;
+  appCount: number;
+  transactionCount: number;
+  channelCount?: number;
+};
+
+export type AutoBackup = {
+  name: string;
+  size: number;
+  createdAt: string;
+};
+
+export type RetentionPolicy = {
+  requestEventDays: number;
+  expiredInvoiceDays: number;
+  failedPaymentDays: number;
+  notificationDays: number;
+  vacuum: boolean;
+};
+
+export type RetentionReport = {
+  startedAt: string;
+  finishedAt: string;
+  requestEvents: number;
+  responseEvents: number;
+  expiredInvoices: number;
+  failedPayments: number;
+  nip47Notifications: number;
+  transactionIndexEntries: number;
+  labels: number;
+  vacuumed: boolean;
+};
+
+export type RetentionResponse = {
+  policy: RetentionPolicy;
+  lastReport?: RetentionReport;
+};
+
+export type LogFilters = {
+  level?: "error" | "warning" | "info" | "debug" | "trace";
+  from?: string;
+  until?: string;
+  fields?: Record<string, string>;
+  search?: string;
+};
+
+export type LogEntry = {
+  time: string;
+  level: string;
+  message: string;
+  fields: Record<string, unknown>;
+};
+
+export type QueryLogsResponse = {
+  entries: LogEntry[];
+  truncated: boolean;
+};
+
 export type ListTransactionsResponse = {
   transactions: Transaction[];
   totalCount: number;
+  nextCursor?: string;
 };
 
 export type NewChannelOrderStatus = "pay" | "paid" | "success" | "opening";
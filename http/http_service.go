@@ -21,6 +21,7 @@ import (
 	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/labels"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/service"
 
@@ -126,8 +127,14 @@ func (httpSvc *HttpService) RegisterSharedRoutes(e *echo.Echo) {
 	readOnlyApiGroup.GET("/apps/:pubkey", httpSvc.appsShowByPubkeyHandler)
 	readOnlyApiGroup.GET("/v2/apps/:id", httpSvc.appsShowHandler)
 	readOnlyApiGroup.GET("/channels", httpSvc.channelsListHandler)
+	readOnlyApiGroup.GET("/channels/pending-closes", httpSvc.listPendingChannelClosesHandler)
+	readOnlyApiGroup.GET("/watchtowers", httpSvc.watchtowerStatusHandler)
 	readOnlyApiGroup.GET("/channels/suggestions", httpSvc.channelPeerSuggestionsHandler)
 	readOnlyApiGroup.GET("/channel-offer", httpSvc.channelOfferHandler)
+	readOnlyApiGroup.GET("/lsps", httpSvc.listLSPsHandler)
+	readOnlyApiGroup.GET("/lsp-orders", httpSvc.listLSPOrdersHandler)
+	readOnlyApiGroup.GET("/payment-approvals", httpSvc.listPaymentApprovalsHandler)
+	readOnlyApiGroup.GET("/archived-requests/:nostrId", httpSvc.getArchivedRequestHandler)
 	readOnlyApiGroup.GET("/node/connection-info", httpSvc.nodeConnectionInfoHandler)
 	readOnlyApiGroup.GET("/node/status", httpSvc.nodeStatusHandler)
 	readOnlyApiGroup.GET("/node/network-graph", httpSvc.nodeNetworkGraphHandler)
@@ -137,9 +144,14 @@ func (httpSvc *HttpService) RegisterSharedRoutes(e *echo.Echo) {
 	readOnlyApiGroup.GET("/wallet/capabilities", httpSvc.capabilitiesHandler)
 	readOnlyApiGroup.GET("/transactions", httpSvc.listTransactionsHandler)
 	readOnlyApiGroup.GET("/transactions/:paymentHash", httpSvc.lookupTransactionHandler)
+	readOnlyApiGroup.GET("/hold-invoices", httpSvc.listHoldInvoicesHandler)
+	readOnlyApiGroup.GET("/auto-backups", httpSvc.listAutoBackupsHandler)
+	readOnlyApiGroup.GET("/retention", httpSvc.getRetentionHandler)
 	readOnlyApiGroup.GET("/balances", httpSvc.balancesHandler)
 	readOnlyApiGroup.GET("/mempool", httpSvc.mempoolApiHandler)
 	readOnlyApiGroup.GET("/log/:type", httpSvc.getLogOutputHandler)
+	readOnlyApiGroup.GET("/logs", httpSvc.queryLogsHandler)
+	readOnlyApiGroup.GET("/logs/stream", httpSvc.streamLogsHandler)
 	readOnlyApiGroup.GET("/health", httpSvc.healthHandler)
 	readOnlyApiGroup.GET("/commands", httpSvc.getCustomNodeCommandsHandler)
 	readOnlyApiGroup.GET("/swaps", httpSvc.listSwapsHandler)
@@ -149,6 +161,7 @@ func (httpSvc *HttpService) RegisterSharedRoutes(e *echo.Echo) {
 	readOnlyApiGroup.GET("/swaps/mnemonic", httpSvc.swapMnemonicHandler)
 	readOnlyApiGroup.GET("/autoswap", httpSvc.getAutoSwapConfigHandler)
 	readOnlyApiGroup.GET("/forwards", httpSvc.forwardsHandler)
+	readOnlyApiGroup.GET("/labels", httpSvc.listLabelsHandler)
 
 	// Full access API group - requires a token with full permissions
 	fullAccessApiGroup := e.Group("/api")
@@ -162,13 +175,32 @@ func (httpSvc *HttpService) RegisterSharedRoutes(e *echo.Echo) {
 	fullAccessApiGroup.DELETE("/apps/:pubkey", httpSvc.appsDeleteHandler)
 	fullAccessApiGroup.POST("/transfers", httpSvc.transfersHandler)
 	fullAccessApiGroup.POST("/apps", httpSvc.appsCreateHandler)
+	fullAccessApiGroup.POST("/apps/rotate-keys", httpSvc.appsRotateAllKeysHandler)
+	fullAccessApiGroup.POST("/apps/:pubkey/rotate-keys", httpSvc.appsRotateKeysHandler)
+	fullAccessApiGroup.POST("/nostr-key/rotate", httpSvc.rotateNostrKeyHandler)
+	fullAccessApiGroup.POST("/wallet-auth/parse", httpSvc.parseWalletAuthUriHandler)
+	fullAccessApiGroup.POST("/wallet-auth", httpSvc.acceptWalletAuthHandler)
 	fullAccessApiGroup.POST("/lightning-addresses", httpSvc.lightningAddressesCreateHandler)
 	fullAccessApiGroup.DELETE("/lightning-addresses/:appId", httpSvc.lightningAddressesDeleteHandler)
 	fullAccessApiGroup.POST("/mnemonic", httpSvc.mnemonicHandler)
 	fullAccessApiGroup.PATCH("/backup-reminder", httpSvc.backupReminderHandler)
+	fullAccessApiGroup.POST("/auto-backups", httpSvc.createAutoBackupHandler)
+	fullAccessApiGroup.POST("/retention/prune", httpSvc.pruneDataHandler)
+	fullAccessApiGroup.POST("/backup/verify", httpSvc.verifyBackupHandler)
 	fullAccessApiGroup.POST("/channels", httpSvc.openChannelHandler)
+	fullAccessApiGroup.POST("/channels/batch", httpSvc.openChannelsHandler)
+	fullAccessApiGroup.DELETE("/channels/pending-closes/:id", httpSvc.cancelPendingChannelCloseHandler)
+	fullAccessApiGroup.POST("/watchtowers", httpSvc.addWatchtowerHandler)
+	fullAccessApiGroup.DELETE("/watchtowers/:pubkey", httpSvc.removeWatchtowerHandler)
 	fullAccessApiGroup.POST("/channels/rebalance", httpSvc.rebalanceChannelHandler)
 	fullAccessApiGroup.POST("/lsp-orders", httpSvc.newInstantChannelInvoiceHandler)
+	fullAccessApiGroup.POST("/lsps", httpSvc.addLSPHandler)
+	fullAccessApiGroup.DELETE("/lsps/:id", httpSvc.deleteLSPHandler)
+	fullAccessApiGroup.POST("/lsp-quotes", httpSvc.lspQuotesHandler)
+	fullAccessApiGroup.POST("/payment-approvals/:id/approve", httpSvc.approvePaymentHandler)
+	fullAccessApiGroup.POST("/payment-approvals/:id/deny", httpSvc.denyPaymentHandler)
+	fullAccessApiGroup.POST("/archived-requests/:nostrId/replay", httpSvc.replayArchivedRequestHandler)
+	fullAccessApiGroup.PUT("/labels/:entityType/:entityId", httpSvc.setLabelHandler)
 	fullAccessApiGroup.POST("/node/migrate-storage", httpSvc.migrateNodeStorageHandler)
 	fullAccessApiGroup.POST("/peers", httpSvc.connectPeerHandler)
 	fullAccessApiGroup.DELETE("/peers/:peerId", httpSvc.disconnectPeerHandler)
@@ -455,7 +487,14 @@ func (httpSvc *HttpService) createJWT(tokenExpiryDays *uint64, permission string
 func (httpSvc *HttpService) channelsListHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	channels, err := httpSvc.api.ListChannels(ctx)
+	filters, err := parseLabelFilters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	channels, err := httpSvc.api.ListChannels(ctx, filters)
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -705,7 +744,21 @@ func (httpSvc *HttpService) listTransactionsHandler(c echo.Context) error {
 		}
 	}
 
-	transactions, err := httpSvc.api.ListTransactions(ctx, appId, limit, offset)
+	filtersJSON := c.QueryParam("filters")
+	var filters api.ListTransactionsFilters
+	if filtersJSON != "" {
+		err := json.Unmarshal([]byte(filtersJSON), &filters)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"filters": filtersJSON,
+			}).Error("Failed to deserialize transaction filters")
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Message: fmt.Sprintf("Bad request: %s", err.Error()),
+			})
+		}
+	}
+
+	transactions, err := httpSvc.api.ListTransactions(ctx, appId, limit, offset, filters)
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -716,10 +769,69 @@ func (httpSvc *HttpService) listTransactionsHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, transactions)
 }
 
+func (httpSvc *HttpService) listHoldInvoicesHandler(c echo.Context) error {
+	holdInvoices, err := httpSvc.api.ListHoldInvoices(c.Request().Context())
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list hold invoices: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, holdInvoices)
+}
+
+func (httpSvc *HttpService) listAutoBackupsHandler(c echo.Context) error {
+	autoBackups, err := httpSvc.api.ListAutoBackups(c.Request().Context())
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list backups: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, autoBackups)
+}
+
+func (httpSvc *HttpService) createAutoBackupHandler(c echo.Context) error {
+	autoBackup, err := httpSvc.api.CreateAutoBackup(c.Request().Context())
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to create backup: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, autoBackup)
+}
+
+func (httpSvc *HttpService) getRetentionHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, httpSvc.api.GetRetention())
+}
+
+func (httpSvc *HttpService) pruneDataHandler(c echo.Context) error {
+	report, err := httpSvc.api.PruneData(c.Request().Context())
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to prune data: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
 func (httpSvc *HttpService) listOnchainTransactionsHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	transactions, err := httpSvc.api.ListOnchainTransactions(ctx)
+	filters, err := parseLabelFilters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	transactions, err := httpSvc.api.ListOnchainTransactions(ctx, filters)
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -768,7 +880,14 @@ func (httpSvc *HttpService) capabilitiesHandler(c echo.Context) error {
 }
 
 func (httpSvc *HttpService) listPeers(c echo.Context) error {
-	peers, err := httpSvc.api.ListPeers(c.Request().Context())
+	filters, err := parseLabelFilters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	peers, err := httpSvc.api.ListPeers(c.Request().Context(), filters)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Message: fmt.Sprintf("Failed to list peers: %s", err.Error()),
@@ -820,6 +939,27 @@ func (httpSvc *HttpService) openChannelHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, openChannelResponse)
 }
 
+func (httpSvc *HttpService) openChannelsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var openChannelsRequest api.OpenChannelsRequest
+	if err := c.Bind(&openChannelsRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	openChannelsResponse, err := httpSvc.api.OpenChannels(ctx, &openChannelsRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to open channels: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, openChannelsResponse)
+}
+
 func (httpSvc *HttpService) rebalanceChannelHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -858,7 +998,30 @@ func (httpSvc *HttpService) disconnectPeerHandler(c echo.Context) error {
 func (httpSvc *HttpService) closeChannelHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
-	closeChannelResponse, err := httpSvc.api.CloseChannel(ctx, c.Param("peerId"), c.Param("channelId"), c.QueryParam("force") == "true")
+	closeChannelRequest := &api.CloseChannelRequest{
+		Force:   c.QueryParam("force") == "true",
+		Address: c.QueryParam("address"),
+	}
+	if feeRateParam := c.QueryParam("feeRate"); feeRateParam != "" {
+		feeRate, err := strconv.ParseUint(feeRateParam, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Message: "Invalid fee rate",
+			})
+		}
+		closeChannelRequest.FeeRate = &feeRate
+	}
+	if maxFeeRateParam := c.QueryParam("maxFeeRate"); maxFeeRateParam != "" {
+		maxFeeRate, err := strconv.ParseUint(maxFeeRateParam, 10, 64)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{
+				Message: "Invalid max fee rate",
+			})
+		}
+		closeChannelRequest.MaxFeeRate = &maxFeeRate
+	}
+
+	closeChannelResponse, err := httpSvc.api.CloseChannel(ctx, c.Param("peerId"), c.Param("channelId"), closeChannelRequest)
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, ErrorResponse{
@@ -869,6 +1032,80 @@ func (httpSvc *HttpService) closeChannelHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, closeChannelResponse)
 }
 
+func (httpSvc *HttpService) listPendingChannelClosesHandler(c echo.Context) error {
+	pendingChannelCloses, err := httpSvc.api.ListPendingChannelCloses()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list pending channel closes: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, pendingChannelCloses)
+}
+
+func (httpSvc *HttpService) cancelPendingChannelCloseHandler(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid pending channel close ID",
+		})
+	}
+
+	err = httpSvc.api.CancelPendingChannelClose(uint(id))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to cancel pending channel close: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (httpSvc *HttpService) watchtowerStatusHandler(c echo.Context) error {
+	watchtowerStatus, err := httpSvc.api.GetWatchtowerStatus(c.Request().Context())
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to get watchtower status: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, watchtowerStatus)
+}
+
+func (httpSvc *HttpService) addWatchtowerHandler(c echo.Context) error {
+	var addWatchtowerRequest api.AddWatchtowerRequest
+	if err := c.Bind(&addWatchtowerRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	err := httpSvc.api.AddWatchtower(c.Request().Context(), &addWatchtowerRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to add watchtower: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (httpSvc *HttpService) removeWatchtowerHandler(c echo.Context) error {
+	err := httpSvc.api.RemoveWatchtower(c.Request().Context(), c.Param("pubkey"))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to remove watchtower: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (httpSvc *HttpService) updateChannelHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -914,6 +1151,237 @@ func (httpSvc *HttpService) newInstantChannelInvoiceHandler(c echo.Context) erro
 	return c.JSON(http.StatusOK, newLSPOrderResponse)
 }
 
+func (httpSvc *HttpService) listLSPsHandler(c echo.Context) error {
+	lsps, err := httpSvc.api.ListLSPs()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list LSPs: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, lsps)
+}
+
+func (httpSvc *HttpService) addLSPHandler(c echo.Context) error {
+	var addLSPRequest api.AddLSPRequest
+	if err := c.Bind(&addLSPRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	lsp, err := httpSvc.api.AddLSP(&addLSPRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to add LSP: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, lsp)
+}
+
+func (httpSvc *HttpService) deleteLSPHandler(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid LSP ID",
+		})
+	}
+
+	err = httpSvc.api.DeleteLSP(uint(id))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to delete LSP: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (httpSvc *HttpService) lspQuotesHandler(c echo.Context) error {
+	var lspQuotesRequest api.LSPQuotesRequest
+	if err := c.Bind(&lspQuotesRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	lspQuotesResponse, err := httpSvc.api.GetLSPQuotes(c.Request().Context(), &lspQuotesRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to request LSP quotes: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, lspQuotesResponse)
+}
+
+func (httpSvc *HttpService) listLSPOrdersHandler(c echo.Context) error {
+	lspOrders, err := httpSvc.api.ListLSPOrders()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list LSP orders: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, lspOrders)
+}
+
+func (httpSvc *HttpService) listPaymentApprovalsHandler(c echo.Context) error {
+	paymentApprovals, err := httpSvc.api.ListPendingPaymentApprovals()
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list payment approvals: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, paymentApprovals)
+}
+
+func (httpSvc *HttpService) listLabelsHandler(c echo.Context) error {
+	entityType := c.QueryParam("entityType")
+	if entityType != "" && !labels.IsValidEntityType(entityType) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid label entity type",
+		})
+	}
+
+	entityLabels, err := httpSvc.api.ListLabels(entityType)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to list labels: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, entityLabels)
+}
+
+func (httpSvc *HttpService) setLabelHandler(c echo.Context) error {
+	entityType := c.Param("entityType")
+	if !labels.IsValidEntityType(entityType) {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid label entity type",
+		})
+	}
+
+	var setLabelRequest api.SetLabelRequest
+	if err := c.Bind(&setLabelRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	label, err := httpSvc.api.SetLabel(entityType, c.Param("entityId"), &setLabelRequest)
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to set label: %s", err.Error()),
+		})
+	}
+
+	if label == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	return c.JSON(http.StatusOK, label)
+}
+
+func parseLabelFilters(c echo.Context) (api.LabelFilters, error) {
+	var filters api.LabelFilters
+	filtersJSON := c.QueryParam("filters")
+	if filtersJSON != "" {
+		err := json.Unmarshal([]byte(filtersJSON), &filters)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"filters": filtersJSON,
+			}).Error("Failed to deserialize label filters")
+			return filters, err
+		}
+	}
+	return filters, nil
+}
+
+func (httpSvc *HttpService) approvePaymentHandler(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid payment approval ID",
+		})
+	}
+
+	err = httpSvc.api.ApprovePayment(uint(id))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to approve payment: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (httpSvc *HttpService) denyPaymentHandler(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: "Invalid payment approval ID",
+		})
+	}
+
+	err = httpSvc.api.DenyPayment(uint(id))
+
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to deny payment: %s", err.Error()),
+		})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (httpSvc *HttpService) getArchivedRequestHandler(c echo.Context) error {
+	archivedRequest, err := httpSvc.api.GetArchivedRequest(c.Param("nostrId"))
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Message: "Archived request not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to get archived request: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, archivedRequest)
+}
+
+func (httpSvc *HttpService) replayArchivedRequestHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	replayResponse, err := httpSvc.api.ReplayArchivedRequest(ctx, c.Param("nostrId"))
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, ErrorResponse{
+				Message: "Archived request not found",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to replay archived request: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, replayResponse)
+}
+
 func (httpSvc *HttpService) onchainAddressHandler(c echo.Context) error {
 	ctx := c.Request().Context()
 
@@ -1130,6 +1598,67 @@ func (httpSvc *HttpService) appsDeleteHandler(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (httpSvc *HttpService) appsRotateKeysHandler(c echo.Context) error {
+	var requestData api.RotateAppKeysRequest
+	if err := c.Bind(&requestData); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	dbApp := httpSvc.appsSvc.GetAppByPubkey(c.Param("pubkey"))
+	if dbApp == nil {
+		return c.JSON(http.StatusNotFound, ErrorResponse{
+			Message: "App not found",
+		})
+	}
+
+	responseBody, err := httpSvc.api.RotateAppKeys(dbApp, &requestData)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to rotate app keys: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, responseBody)
+}
+
+func (httpSvc *HttpService) appsRotateAllKeysHandler(c echo.Context) error {
+	var requestData api.RotateAppKeysRequest
+	if err := c.Bind(&requestData); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	responseBody, err := httpSvc.api.RotateAllAppKeys(&requestData)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to rotate app keys: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, responseBody)
+}
+
+func (httpSvc *HttpService) rotateNostrKeyHandler(c echo.Context) error {
+	var requestData api.RotateNostrKeyRequest
+	if err := c.Bind(&requestData); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	responseBody, err := httpSvc.api.RotateNostrKey(&requestData)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to rotate nostr key: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, responseBody)
+}
+
 func (httpSvc *HttpService) appsCreateHandler(c echo.Context) error {
 	var requestData api.CreateAppRequest
 	if err := c.Bind(&requestData); err != nil {
@@ -1150,6 +1679,45 @@ func (httpSvc *HttpService) appsCreateHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, responseBody)
 }
 
+func (httpSvc *HttpService) parseWalletAuthUriHandler(c echo.Context) error {
+	var requestData api.ParseWalletAuthUriRequest
+	if err := c.Bind(&requestData); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	walletAuthRequest, err := httpSvc.api.ParseWalletAuthUri(requestData.Uri)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Invalid wallet auth uri: %s", err.Error()),
+		})
+	}
+
+	return c.JSON(http.StatusOK, walletAuthRequest)
+}
+
+func (httpSvc *HttpService) acceptWalletAuthHandler(c echo.Context) error {
+	var requestData api.AcceptWalletAuthRequest
+	if err := c.Bind(&requestData); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	responseBody, err := httpSvc.api.AcceptWalletAuthRequest(&requestData)
+
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to accept wallet auth request")
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to accept wallet auth request: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, responseBody)
+}
+
 func (httpSvc *HttpService) lightningAddressesCreateHandler(c echo.Context) error {
 	var requestData api.CreateLightningAddressRequest
 	if err := c.Bind(&requestData); err != nil {
@@ -1275,6 +1843,78 @@ func (httpSvc *HttpService) getLogOutputHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, getLogResponse)
 }
 
+func (httpSvc *HttpService) queryLogsHandler(c echo.Context) error {
+	limit := uint64(0)
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.ParseUint(limitParam, 10, 64); err == nil {
+			limit = parsedLimit
+		}
+	}
+
+	filters, err := parseLogFilters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	queryLogsResponse, err := httpSvc.api.QueryLogs(c.Request().Context(), limit, filters)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to query logs: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, queryLogsResponse)
+}
+
+// streamLogsHandler sends new app log entries matching the filters as server-sent events
+func (httpSvc *HttpService) streamLogsHandler(c echo.Context) error {
+	filters, err := parseLogFilters(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Bad request: %s", err.Error()),
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+
+	// write errors mean the client disconnected. They are not logged as they would be streamed again
+	_ = httpSvc.api.TailLogs(c.Request().Context(), filters, func(entry *api.LogEntry) error {
+		entryJSON, err := json.Marshal(entry)
+		if err != nil {
+			// skip entries with fields which cannot be serialized
+			return nil
+		}
+		_, err = fmt.Fprintf(c.Response(), "data: %s\n\n", entryJSON)
+		if err != nil {
+			return err
+		}
+		c.Response().Flush()
+		return nil
+	})
+	return nil
+}
+
+func parseLogFilters(c echo.Context) (api.LogFilters, error) {
+	var filters api.LogFilters
+	filtersJSON := c.QueryParam("filters")
+	if filtersJSON != "" {
+		err := json.Unmarshal([]byte(filtersJSON), &filters)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"filters": filtersJSON,
+			}).Error("Failed to deserialize log filters")
+			return filters, err
+		}
+	}
+	return filters, nil
+}
+
 func (httpSvc *HttpService) getCustomNodeCommandsHandler(c echo.Context) error {
 	nodeCommandsResponse, err := httpSvc.api.GetCustomNodeCommands()
 	if err != nil {
@@ -1376,6 +2016,34 @@ func (httpSvc *HttpService) restoreBackupHandler(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (httpSvc *HttpService) verifyBackupHandler(c echo.Context) error {
+	password := c.FormValue("unlockPassword")
+
+	fileHeader, err := c.FormFile("backup")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Failed to get backup file header: %v", err),
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Message: fmt.Sprintf("Failed to open backup file: %v", err),
+		})
+	}
+	defer file.Close()
+
+	report, err := httpSvc.api.VerifyBackup(password, file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{
+			Message: fmt.Sprintf("Failed to verify backup: %v", err),
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
 func (httpSvc *HttpService) healthHandler(c echo.Context) error {
 	healthResponse, err := httpSvc.api.Health(c.Request().Context())
 	if err != nil {
@@ -0,0 +1,200 @@
+package labels
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+)
+
+const (
+	maxLabelLength = 256
+	maxNoteLength  = 4096
+	maxTags        = 20
+)
+
+type labelsService struct {
+	db *gorm.DB
+}
+
+// LabelsService stores owner-defined labels, notes and tags for transactions,
+// on-chain transactions, channels and peers. Unlike transaction metadata they
+// are never set by apps.
+type LabelsService interface {
+	SetLabel(entityType string, entityId string, label string, note string, tags []string) (*db.Label, error)
+	GetLabels(entityType string, entityIds []string) (map[string]db.Label, error)
+	ListLabels(entityType string) ([]db.Label, error)
+}
+
+type Filters struct {
+	// case-insensitive match on the label or note
+	Label string
+	Tag   string
+}
+
+func NewLabelsService(db *gorm.DB) LabelsService {
+	return &labelsService{
+		db: db,
+	}
+}
+
+// SetLabel creates or replaces the label of an entity. Setting an empty label, note and tags removes it.
+func (svc *labelsService) SetLabel(entityType string, entityId string, label string, note string, tags []string) (*db.Label, error) {
+	if !IsValidEntityType(entityType) {
+		return nil, fmt.Errorf("invalid label entity type: %q", entityType)
+	}
+	if entityId == "" {
+		return nil, errors.New("missing label entity id")
+	}
+	var transactionId *uint
+	if entityType == constants.LABEL_ENTITY_TYPE_TRANSACTION {
+		id, err := strconv.ParseUint(entityId, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction id: %q", entityId)
+		}
+		transactionIdValue := uint(id)
+		transactionId = &transactionIdValue
+	}
+
+	label = strings.TrimSpace(label)
+	note = strings.TrimSpace(note)
+	if len(label) > maxLabelLength {
+		return nil, fmt.Errorf("label is too long (max %d characters)", maxLabelLength)
+	}
+	if len(note) > maxNoteLength {
+		return nil, fmt.Errorf("note is too long (max %d characters)", maxNoteLength)
+	}
+
+	normalizedTags := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(normalizedTags, tag) {
+			normalizedTags = append(normalizedTags, tag)
+		}
+	}
+	if len(normalizedTags) > maxTags {
+		return nil, fmt.Errorf("too many tags (max %d)", maxTags)
+	}
+
+	if label == "" && note == "" && len(normalizedTags) == 0 {
+		err := svc.db.Where("entity_type = ? AND entity_id = ?", entityType, entityId).Delete(&db.Label{}).Error
+		if err != nil {
+			logger.Logger.WithError(err).WithField("entity_id", entityId).Error("Failed to delete label")
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	tagsJson, err := json.Marshal(normalizedTags)
+	if err != nil {
+		return nil, err
+	}
+
+	dbLabel := db.Label{
+		EntityType:    entityType,
+		EntityId:      entityId,
+		TransactionId: transactionId,
+		Label:         label,
+		Note:          note,
+		Tags:          tagsJson,
+	}
+
+	err = svc.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"transaction_id", "label", "note", "tags", "updated_at"}),
+	}).Create(&dbLabel).Error
+	if err != nil {
+		logger.Logger.WithError(err).WithField("entity_id", entityId).Error("Failed to save label")
+		return nil, err
+	}
+
+	// the ID is not reliably returned on conflict
+	err = svc.db.Where("entity_type = ? AND entity_id = ?", entityType, entityId).First(&dbLabel).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &dbLabel, nil
+}
+
+// GetLabels returns the labels of the given entities keyed by entity ID
+func (svc *labelsService) GetLabels(entityType string, entityIds []string) (map[string]db.Label, error) {
+	labels := map[string]db.Label{}
+	if len(entityIds) == 0 {
+		return labels, nil
+	}
+
+	// query in batches to stay within the bound parameter limit
+	for batch := range slices.Chunk(entityIds, 500) {
+		var dbLabels []db.Label
+		err := svc.db.Where("entity_type = ? AND entity_id IN ?", entityType, batch).Find(&dbLabels).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, dbLabel := range dbLabels {
+			labels[dbLabel.EntityId] = dbLabel
+		}
+	}
+
+	return labels, nil
+}
+
+// ListLabels returns all labels, optionally only of the given entity type
+func (svc *labelsService) ListLabels(entityType string) ([]db.Label, error) {
+	query := svc.db.Order("entity_type, entity_id")
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	var dbLabels []db.Label
+	if err := query.Find(&dbLabels).Error; err != nil {
+		return nil, err
+	}
+	return dbLabels, nil
+}
+
+func IsValidEntityType(entityType string) bool {
+	return slices.Contains([]string{
+		constants.LABEL_ENTITY_TYPE_TRANSACTION,
+		constants.LABEL_ENTITY_TYPE_ONCHAIN_TRANSACTION,
+		constants.LABEL_ENTITY_TYPE_CHANNEL,
+		constants.LABEL_ENTITY_TYPE_PEER,
+	}, entityType)
+}
+
+func GetTags(label *db.Label) []string {
+	tags := []string{}
+	if label == nil || len(label.Tags) == 0 {
+		return tags
+	}
+	if err := json.Unmarshal(label.Tags, &tags); err != nil {
+		logger.Logger.WithError(err).WithField("id", label.ID).Error("Failed to deserialize label tags")
+	}
+	return tags
+}
+
+// Matches is used to filter entities that are listed from the LNClient rather than the database
+func Matches(label *db.Label, filters Filters) bool {
+	if filters.Label != "" {
+		if label == nil {
+			return false
+		}
+		search := strings.ToLower(filters.Label)
+		if !strings.Contains(strings.ToLower(label.Label), search) && !strings.Contains(strings.ToLower(label.Note), search) {
+			return false
+		}
+	}
+	if filters.Tag != "" && !slices.Contains(GetTags(label), filters.Tag) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestSetLabel(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	labelsService := NewLabelsService(svc.DB)
+
+	label, err := labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_CHANNEL, "channel1", " Kraken ", "our exchange link", []string{"exchange", " exchange", ""})
+	require.NoError(t, err)
+	assert.Equal(t, "Kraken", label.Label)
+	assert.Equal(t, []string{"exchange"}, GetTags(label))
+
+	// replaces the existing label
+	label, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_CHANNEL, "channel1", "Kraken", "", nil)
+	require.NoError(t, err)
+	assert.Empty(t, label.Note)
+	assert.Empty(t, GetTags(label))
+
+	_, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_PEER, "channel1", "Kraken node", "", nil)
+	require.NoError(t, err)
+
+	channelLabels, err := labelsService.GetLabels(constants.LABEL_ENTITY_TYPE_CHANNEL, []string{"channel1", "channel2"})
+	require.NoError(t, err)
+	assert.Len(t, channelLabels, 1)
+	assert.Equal(t, "Kraken", channelLabels["channel1"].Label)
+
+	allLabels, err := labelsService.ListLabels("")
+	require.NoError(t, err)
+	assert.Len(t, allLabels, 2)
+
+	// an empty label removes it
+	label, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_CHANNEL, "channel1", "", "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, label)
+
+	var count int64
+	svc.DB.Model(&db.Label{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestSetLabel_InvalidEntityType(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	_, err = NewLabelsService(svc.DB).SetLabel("app", "1", "label", "", nil)
+	assert.Error(t, err)
+}
+
+func TestSetLabel_Transaction(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	labelsService := NewLabelsService(svc.DB)
+
+	label, err := labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_TRANSACTION, "42", "rent", "", nil)
+	require.NoError(t, err)
+	require.NotNil(t, label.TransactionId)
+	assert.Equal(t, uint(42), *label.TransactionId)
+
+	_, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_TRANSACTION, "abc", "rent", "", nil)
+	assert.EqualError(t, err, `invalid transaction id: "abc"`)
+}
+
+func TestMatches(t *testing.T) {
+	label := &db.Label{Label: "Kraken", Note: "Exchange link", Tags: []byte(`["exchange"]`)}
+
+	assert.True(t, Matches(nil, Filters{}))
+	assert.False(t, Matches(nil, Filters{Label: "kraken"}))
+	assert.True(t, Matches(label, Filters{Label: "kraken"}))
+	assert.True(t, Matches(label, Filters{Label: "link"}))
+	assert.True(t, Matches(label, Filters{Tag: "exchange"}))
+	assert.False(t, Matches(label, Filters{Tag: "Exchange"}))
+}
@@ -72,7 +72,7 @@ func (cs *CashuService) Shutdown() error {
 	return cs.wallet.Shutdown()
 }
 
-func (cs *CashuService) SendPaymentSync(invoice string, amount *uint64) (response *lnclient.PayInvoiceResponse, err error) {
+func (cs *CashuService) SendPaymentSync(ctx context.Context, invoice string, amount *uint64) (response *lnclient.PayInvoiceResponse, err error) {
 	// TODO: support 0-amount invoices
 	if amount != nil {
 		return nil, errors.New("0-amount invoices not supported")
@@ -101,7 +101,7 @@ func (cs *CashuService) SendPaymentSync(invoice string, amount *uint64) (respons
 	}, nil
 }
 
-func (cs *CashuService) SendKeysend(amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+func (cs *CashuService) SendKeysend(ctx context.Context, amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
 	return nil, errors.New("keysend not supported")
 }
 
@@ -212,6 +212,10 @@ func (cs *CashuService) OpenChannel(ctx context.Context, openChannelRequest *lnc
 	return nil, nil
 }
 
+func (cs *CashuService) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
 func (cs *CashuService) CloseChannel(ctx context.Context, closeChannelRequest *lnclient.CloseChannelRequest) (*lnclient.CloseChannelResponse, error) {
 	return nil, nil
 }
@@ -572,3 +576,15 @@ func (svc *CashuService) MakeOffer(ctx context.Context, description string) (str
 func (cs *CashuService) ListOnchainTransactions(ctx context.Context) ([]lnclient.OnchainTransaction, error) {
 	return nil, errors.ErrUnsupported
 }
+
+func (cs *CashuService) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (cs *CashuService) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	return errors.ErrUnsupported
+}
+
+func (cs *CashuService) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	return errors.ErrUnsupported
+}
@@ -27,10 +27,10 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
-	"github.com/getAlby/hub/lsp"
 	"github.com/getAlby/hub/nip47/models"
 	"github.com/getAlby/hub/nip47/notifications"
 	"github.com/getAlby/hub/service/keys"
@@ -58,7 +58,7 @@ type LDKService struct {
 const resetRouterKey = "ResetRouter"
 const maxInvoiceExpiry = 24 * time.Hour
 
-func NewLDKService(ctx context.Context, cfg config.Config, eventPublisher events.EventPublisher, mnemonic, workDir string, network string, vssToken string, setStartupState func(startupState string)) (result lnclient.LNClient, err error) {
+func NewLDKService(ctx context.Context, cfg config.Config, eventPublisher events.EventPublisher, mnemonic, workDir string, network string, vssToken string, trustedPeers0conf []string, setStartupState func(startupState string)) (result lnclient.LNClient, err error) {
 	if mnemonic == "" || workDir == "" {
 		return nil, errors.New("one or more required LDK configuration are missing")
 	}
@@ -75,15 +75,8 @@ func NewLDKService(ctx context.Context, cfg config.Config, eventPublisher events
 
 	ldkConfig := ldk_node.DefaultConfig()
 
-	ldkConfig.TrustedPeers0conf = []string{
-		lsp.OlympusLSP().Pubkey,
-		lsp.MegalithLSP().Pubkey,
-		"02b4552a7a85274e4da01a7c71ca57407181752e8568b31d51f13c111a2941dce3", // LNServer_Wave
-
-		// Mutinynet
-		lsp.OlympusMutinynetLSP().Pubkey,
-		lsp.MegalithMutinynetLSP().Pubkey,
-	}
+	// the pubkeys of the registered LSPs
+	ldkConfig.TrustedPeers0conf = trustedPeers0conf
 
 	// rather than fully trusting our LSPs, we set the channel reserve to 0.
 	// this allows us to receive incoming channels without any on-chain balance
@@ -512,7 +505,7 @@ func (ls *LDKService) MakeOffer(ctx context.Context, description string) (string
 	return offer, nil
 }
 
-func (ls *LDKService) SendPaymentSync(invoice string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
+func (ls *LDKService) SendPaymentSync(ctx context.Context, invoice string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
 	paymentRequest, err := decodepay.Decodepay(invoice)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
@@ -622,7 +615,7 @@ func (ls *LDKService) SendPaymentSync(invoice string, amount *uint64) (*lnclient
 	}
 }
 
-func (ls *LDKService) SendKeysend(amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+func (ls *LDKService) SendKeysend(ctx context.Context, amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
 	paymentStart := time.Now()
 	customTlvs := []ldk_node.TlvEntry{}
 
@@ -1117,6 +1110,35 @@ func (ls *LDKService) OpenChannel(ctx context.Context, openChannelRequest *lncli
 	return nil, errors.New("open channel timeout")
 }
 
+// LDK does not support funding multiple channels in a single transaction,
+// so channels are opened one after another and each gets its own funding transaction
+func (ls *LDKService) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	openChannelsResponse := &lnclient.OpenChannelsResponse{
+		Channels: make([]lnclient.OpenChannelStatus, 0, len(openChannelsRequest.Channels)),
+	}
+
+	for _, openChannelRequest := range openChannelsRequest.Channels {
+		channelStatus := lnclient.OpenChannelStatus{
+			Pubkey:     openChannelRequest.Pubkey,
+			AmountSats: openChannelRequest.AmountSats,
+		}
+
+		openChannelResponse, err := ls.OpenChannel(ctx, &openChannelRequest)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("peer_id", openChannelRequest.Pubkey).Error("Failed to open channel in batch")
+			channelStatus.State = constants.CHANNEL_OPEN_STATE_FAILED
+			channelStatus.Error = err.Error()
+		} else {
+			channelStatus.State = constants.CHANNEL_OPEN_STATE_PENDING
+			channelStatus.FundingTxId = openChannelResponse.FundingTxId
+		}
+
+		openChannelsResponse.Channels = append(openChannelsResponse.Channels, channelStatus)
+	}
+
+	return openChannelsResponse, nil
+}
+
 func (ls *LDKService) UpdateChannel(ctx context.Context, updateChannelRequest *lnclient.UpdateChannelRequest) error {
 	channels := ls.node.ListChannels()
 
@@ -1156,6 +1178,10 @@ func (ls *LDKService) CloseChannel(ctx context.Context, closeChannelRequest *lnc
 		"request": closeChannelRequest,
 	}).Info("Closing Channel")
 
+	if closeChannelRequest.FeeRate != nil || closeChannelRequest.Address != "" {
+		return nil, errors.New("closing fee rate and address are not supported by LDK")
+	}
+
 	var err error
 	if closeChannelRequest.Force {
 		err = ls.node.ForceCloseChannel(closeChannelRequest.ChannelId, closeChannelRequest.NodeId, nil)
@@ -2067,6 +2093,10 @@ func (ls *LDKService) GetSupportedNIP47NotificationTypes() []string {
 		notifications.PAYMENT_RECEIVED_NOTIFICATION,
 		notifications.PAYMENT_SENT_NOTIFICATION,
 		notifications.HOLD_INVOICE_ACCEPTED_NOTIFICATION,
+		notifications.HOLD_INVOICE_EXPIRING_NOTIFICATION,
+		constants.CHANNEL_OPENED_NOTIFICATION,
+		constants.CHANNEL_CLOSED_NOTIFICATION,
+		constants.ONCHAIN_RECEIVED_NOTIFICATION,
 	}
 }
 
@@ -2502,3 +2532,16 @@ func (ls *LDKService) ConsumeEvent(ctx context.Context, event *events.Event, glo
 		ls.backupChannels()
 	}
 }
+
+// watchtowers are not supported by ldk-node yet
+func (ls *LDKService) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (ls *LDKService) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	return errors.ErrUnsupported
+}
+
+func (ls *LDKService) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	return errors.ErrUnsupported
+}
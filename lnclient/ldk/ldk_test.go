@@ -1,11 +1,14 @@
 package ldk
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/tests"
 )
 
@@ -35,3 +38,14 @@ func TestGetVssNodeIdentifier2(t *testing.T) {
 
 	assert.Equal(t, expectedVssNodeIdentifier, vssNodeIdentifier)
 }
+
+func TestWatchtowers_Unsupported(t *testing.T) {
+	ls := &LDKService{}
+
+	_, err := ls.GetWatchtowerStatus(context.TODO())
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+	err = ls.AddWatchtower(context.TODO(), &lnclient.AddWatchtowerRequest{Pubkey: "pubkey", Address: "127.0.0.1:9911"})
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+	err = ls.RemoveWatchtower(context.TODO(), "pubkey")
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
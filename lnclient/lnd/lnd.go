@@ -19,6 +19,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/lnclient/lnd/wrapper"
@@ -33,6 +34,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/wtclientrpc"
 )
 
 type LNDService struct {
@@ -430,7 +432,7 @@ func (svc *LNDService) Shutdown() error {
 	return nil
 }
 
-func (svc *LNDService) SendPaymentSync(payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
+func (svc *LNDService) SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
 	const MAX_PARTIAL_PAYMENTS = 16
 
 	paymentRequest, err := decodepay.Decodepay(payReq)
@@ -491,7 +493,7 @@ func (svc *LNDService) SendPaymentSync(payReq string, amount *uint64) (*lnclient
 	}, nil
 }
 
-func (svc *LNDService) SendKeysend(amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+func (svc *LNDService) SendKeysend(ctx context.Context, amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
 	destBytes, err := hex.DecodeString(destination)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
@@ -1171,6 +1173,71 @@ func (svc *LNDService) OpenChannel(ctx context.Context, openChannelRequest *lncl
 	}, err
 }
 
+func (svc *LNDService) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	peers, err := svc.ListPeers(ctx)
+	if err != nil {
+		return nil, errors.New("failed to list peers")
+	}
+
+	channels := make([]*lnrpc.BatchOpenChannel, 0, len(openChannelsRequest.Channels))
+	for _, openChannelRequest := range openChannelsRequest.Channels {
+		peered := slices.ContainsFunc(peers, func(peer lnclient.PeerDetails) bool {
+			return peer.NodeId == openChannelRequest.Pubkey
+		})
+		if !peered {
+			return nil, fmt.Errorf("node %s is not peered yet", openChannelRequest.Pubkey)
+		}
+
+		nodePub, err := hex.DecodeString(openChannelRequest.Pubkey)
+		if err != nil {
+			return nil, errors.New("failed to decode pubkey")
+		}
+
+		channels = append(channels, &lnrpc.BatchOpenChannel{
+			NodePubkey:         nodePub,
+			Private:            !openChannelRequest.Public,
+			LocalFundingAmount: openChannelRequest.AmountSats,
+			// set a super-high forwarding fee of 100K sats by default to disable unwanted routing
+			BaseFee:    100_000_000,
+			UseBaseFee: true,
+		})
+	}
+
+	logger.Logger.WithField("channel_count", len(channels)).Info("Opening channels in batch")
+
+	resp, err := svc.client.BatchOpenChannel(ctx, &lnrpc.BatchOpenChannelRequest{
+		Channels: channels,
+	})
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to batch open channels")
+		return nil, fmt.Errorf("failed to batch open channels: %w", err)
+	}
+
+	openChannelsResponse := &lnclient.OpenChannelsResponse{
+		Channels: make([]lnclient.OpenChannelStatus, 0, len(openChannelsRequest.Channels)),
+	}
+	for i, openChannelRequest := range openChannelsRequest.Channels {
+		channelStatus := lnclient.OpenChannelStatus{
+			Pubkey:     openChannelRequest.Pubkey,
+			AmountSats: openChannelRequest.AmountSats,
+			State:      constants.CHANNEL_OPEN_STATE_PENDING,
+		}
+		if i < len(resp.PendingChannels) {
+			fundingTxid, err := chainhash.NewHash(resp.PendingChannels[i].Txid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode funding txid: %w", err)
+			}
+			channelStatus.FundingTxId = fundingTxid.String()
+			channelStatus.FundingTxVout = resp.PendingChannels[i].OutputIndex
+			// all channels in a batch share the same funding transaction
+			openChannelsResponse.FundingTxId = channelStatus.FundingTxId
+		}
+		openChannelsResponse.Channels = append(openChannelsResponse.Channels, channelStatus)
+	}
+
+	return openChannelsResponse, nil
+}
+
 func (svc *LNDService) UpdateChannel(ctx context.Context, updateChannelRequest *lnclient.UpdateChannelRequest) error {
 	logger.Logger.WithFields(logrus.Fields{
 		"request": updateChannelRequest,
@@ -1249,10 +1316,16 @@ func (svc *LNDService) CloseChannel(ctx context.Context, closeChannelRequest *ln
 		return nil, err
 	}
 
-	stream, err := svc.client.CloseChannel(ctx, &lnrpc.CloseChannelRequest{
-		ChannelPoint: channelPoint,
-		Force:        closeChannelRequest.Force,
-	})
+	lndCloseChannelRequest := &lnrpc.CloseChannelRequest{
+		ChannelPoint:    channelPoint,
+		Force:           closeChannelRequest.Force,
+		DeliveryAddress: closeChannelRequest.Address,
+	}
+	if closeChannelRequest.FeeRate != nil {
+		lndCloseChannelRequest.SatPerVbyte = *closeChannelRequest.FeeRate
+	}
+
+	stream, err := svc.client.CloseChannel(ctx, lndCloseChannelRequest)
 	if err != nil {
 		logger.Logger.WithField("request", closeChannelRequest).WithError(err).Error("Failed to close channel")
 		return nil, err
@@ -1572,7 +1645,15 @@ func (svc *LNDService) GetSupportedNIP47Methods() []string {
 }
 
 func (svc *LNDService) GetSupportedNIP47NotificationTypes() []string {
-	return []string{notifications.PAYMENT_RECEIVED_NOTIFICATION, notifications.PAYMENT_SENT_NOTIFICATION, notifications.HOLD_INVOICE_ACCEPTED_NOTIFICATION}
+	return []string{
+		notifications.PAYMENT_RECEIVED_NOTIFICATION,
+		notifications.PAYMENT_SENT_NOTIFICATION,
+		notifications.HOLD_INVOICE_ACCEPTED_NOTIFICATION,
+		notifications.HOLD_INVOICE_EXPIRING_NOTIFICATION,
+		constants.CHANNEL_OPENED_NOTIFICATION,
+		constants.CHANNEL_CLOSED_NOTIFICATION,
+		constants.ONCHAIN_RECEIVED_NOTIFICATION,
+	}
 }
 
 func (svc *LNDService) GetPubkey() string {
@@ -1729,3 +1810,87 @@ func (svc *LNDService) ListOnchainTransactions(ctx context.Context) ([]lnclient.
 	})
 	return transactions, nil
 }
+
+func (svc *LNDService) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	towersResponse, err := svc.client.ListTowers(ctx, &wtclientrpc.ListTowersRequest{
+		IncludeSessions: true,
+	})
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to list watchtowers")
+		return nil, err
+	}
+
+	stats, err := svc.client.TowerStats(ctx, &wtclientrpc.StatsRequest{})
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to fetch watchtower stats")
+		return nil, err
+	}
+
+	towers := make([]lnclient.Watchtower, 0, len(towersResponse.Towers))
+	for _, tower := range towersResponse.Towers {
+		towers = append(towers, toLNClientWatchtower(tower))
+	}
+
+	return &lnclient.WatchtowerStatus{
+		Towers:               towers,
+		NumBackups:           stats.NumBackups,
+		NumPendingBackups:    stats.NumPendingBackups,
+		NumFailedBackups:     stats.NumFailedBackups,
+		NumSessionsAcquired:  stats.NumSessionsAcquired,
+		NumSessionsExhausted: stats.NumSessionsExhausted,
+	}, nil
+}
+
+// LND does not report whether a tower is reachable, only whether it is a candidate
+// for new sessions and the backups of the sessions negotiated with it
+func toLNClientWatchtower(tower *wtclientrpc.Tower) lnclient.Watchtower {
+	watchtower := lnclient.Watchtower{
+		Pubkey:    hex.EncodeToString(tower.Pubkey),
+		Addresses: tower.Addresses,
+	}
+	for _, sessionInfo := range tower.SessionInfo {
+		watchtower.ActiveSessionCandidate = watchtower.ActiveSessionCandidate || sessionInfo.ActiveSessionCandidate
+		watchtower.NumSessions += sessionInfo.NumSessions
+		for _, session := range sessionInfo.Sessions {
+			watchtower.NumBackups += session.NumBackups
+			watchtower.NumPendingBackups += session.NumPendingBackups
+			if session.NumBackups+session.NumPendingBackups < session.MaxBackups {
+				watchtower.NumUsableSessions++
+			}
+		}
+	}
+	return watchtower
+}
+
+func (svc *LNDService) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	pubkey, err := hex.DecodeString(addWatchtowerRequest.Pubkey)
+	if err != nil {
+		return errors.New("failed to decode pubkey")
+	}
+
+	_, err = svc.client.AddTower(ctx, &wtclientrpc.AddTowerRequest{
+		Pubkey:  pubkey,
+		Address: addWatchtowerRequest.Address,
+	})
+	if err != nil {
+		logger.Logger.WithError(err).WithField("pubkey", addWatchtowerRequest.Pubkey).Error("Failed to add watchtower")
+		return err
+	}
+	return nil
+}
+
+func (svc *LNDService) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	pubkeyBytes, err := hex.DecodeString(pubkey)
+	if err != nil {
+		return errors.New("failed to decode pubkey")
+	}
+
+	_, err = svc.client.RemoveTower(ctx, &wtclientrpc.RemoveTowerRequest{
+		Pubkey: pubkeyBytes,
+	})
+	if err != nil {
+		logger.Logger.WithError(err).WithField("pubkey", pubkey).Error("Failed to remove watchtower")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package lnd
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnrpc/wtclientrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToLNClientWatchtower(t *testing.T) {
+	pubkey := []byte{0x02, 0x01}
+	watchtower := toLNClientWatchtower(&wtclientrpc.Tower{
+		Pubkey:    pubkey,
+		Addresses: []string{"127.0.0.1:9911"},
+		SessionInfo: []*wtclientrpc.TowerSessionInfo{
+			{
+				ActiveSessionCandidate: false,
+				NumSessions:            2,
+				Sessions: []*wtclientrpc.TowerSession{
+					{NumBackups: 1024, MaxBackups: 1024},
+					{NumBackups: 10, NumPendingBackups: 1, MaxBackups: 1024},
+				},
+			},
+			{
+				ActiveSessionCandidate: true,
+				NumSessions:            1,
+				Sessions: []*wtclientrpc.TowerSession{
+					{NumBackups: 5, MaxBackups: 1024},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, hex.EncodeToString(pubkey), watchtower.Pubkey)
+	assert.Equal(t, []string{"127.0.0.1:9911"}, watchtower.Addresses)
+	assert.True(t, watchtower.ActiveSessionCandidate)
+	assert.Equal(t, uint32(3), watchtower.NumSessions)
+	// the first session is exhausted
+	assert.Equal(t, uint32(2), watchtower.NumUsableSessions)
+	assert.Equal(t, uint32(1039), watchtower.NumBackups)
+	assert.Equal(t, uint32(1), watchtower.NumPendingBackups)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnrpc/wtclientrpc"
 	"github.com/lightningnetwork/lnd/macaroons"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -35,6 +36,7 @@ type LNDWrapper struct {
 	routerClient   routerrpc.RouterClient
 	stateClient    lnrpc.StateClient
 	invoicesClient invoicesrpc.InvoicesClient
+	wtClient       wtclientrpc.WatchtowerClientClient
 	IdentityPubkey string
 }
 
@@ -91,6 +93,7 @@ func NewLNDclient(lndOptions LNDoptions) (result *LNDWrapper, err error) {
 		routerClient:   routerrpc.NewRouterClient(conn),
 		stateClient:    lnrpc.NewStateClient(conn),
 		invoicesClient: invoicesrpc.NewInvoicesClient(conn),
+		wtClient:       wtclientrpc.NewWatchtowerClientClient(conn),
 	}, nil
 }
 
@@ -212,6 +215,10 @@ func (wrapper *LNDWrapper) OpenChannelSync(ctx context.Context, req *lnrpc.OpenC
 	return wrapper.client.OpenChannelSync(ctx, req, options...)
 }
 
+func (wrapper *LNDWrapper) BatchOpenChannel(ctx context.Context, req *lnrpc.BatchOpenChannelRequest, options ...grpc.CallOption) (*lnrpc.BatchOpenChannelResponse, error) {
+	return wrapper.client.BatchOpenChannel(ctx, req, options...)
+}
+
 func (wrapper *LNDWrapper) CloseChannel(ctx context.Context, req *lnrpc.CloseChannelRequest, options ...grpc.CallOption) (lnrpc.Lightning_CloseChannelClient, error) {
 	return wrapper.client.CloseChannel(ctx, req, options...)
 }
@@ -247,3 +254,19 @@ func (wrapper *LNDWrapper) SubscribeChannelEvents(ctx context.Context, in *lnrpc
 func (wrapper *LNDWrapper) ForwardingHistory(ctx context.Context, in *lnrpc.ForwardingHistoryRequest, options ...grpc.CallOption) (*lnrpc.ForwardingHistoryResponse, error) {
 	return wrapper.client.ForwardingHistory(ctx, in, options...)
 }
+
+func (wrapper *LNDWrapper) AddTower(ctx context.Context, req *wtclientrpc.AddTowerRequest, options ...grpc.CallOption) (*wtclientrpc.AddTowerResponse, error) {
+	return wrapper.wtClient.AddTower(ctx, req, options...)
+}
+
+func (wrapper *LNDWrapper) RemoveTower(ctx context.Context, req *wtclientrpc.RemoveTowerRequest, options ...grpc.CallOption) (*wtclientrpc.RemoveTowerResponse, error) {
+	return wrapper.wtClient.RemoveTower(ctx, req, options...)
+}
+
+func (wrapper *LNDWrapper) ListTowers(ctx context.Context, req *wtclientrpc.ListTowersRequest, options ...grpc.CallOption) (*wtclientrpc.ListTowersResponse, error) {
+	return wrapper.wtClient.ListTowers(ctx, req, options...)
+}
+
+func (wrapper *LNDWrapper) TowerStats(ctx context.Context, req *wtclientrpc.StatsRequest, options ...grpc.CallOption) (*wtclientrpc.StatsResponse, error) {
+	return wrapper.wtClient.Stats(ctx, req, options...)
+}
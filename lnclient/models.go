@@ -57,8 +57,9 @@ type NodeConnectionInfo struct {
 }
 
 type LNClient interface {
-	SendPaymentSync(payReq string, amount *uint64) (*PayInvoiceResponse, error)
-	SendKeysend(amount uint64, destination string, customRecords []TLVRecord, preimage string) (*PayKeysendResponse, error)
+	// ctx carries the trace of the request, the payment is not cancelled with it
+	SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (*PayInvoiceResponse, error)
+	SendKeysend(ctx context.Context, amount uint64, destination string, customRecords []TLVRecord, preimage string) (*PayKeysendResponse, error)
 	GetPubkey() string
 	GetInfo(ctx context.Context) (info *NodeInfo, err error)
 	MakeInvoice(ctx context.Context, amount int64, description string, descriptionHash string, expiry int64, throughNodePubkey *string) (transaction *Transaction, err error)
@@ -74,8 +75,10 @@ type LNClient interface {
 	GetNodeStatus(ctx context.Context) (nodeStatus *NodeStatus, err error)
 	ConnectPeer(ctx context.Context, connectPeerRequest *ConnectPeerRequest) error
 	OpenChannel(ctx context.Context, openChannelRequest *OpenChannelRequest) (*OpenChannelResponse, error)
+	OpenChannels(ctx context.Context, openChannelsRequest *OpenChannelsRequest) (*OpenChannelsResponse, error)
 	CloseChannel(ctx context.Context, closeChannelRequest *CloseChannelRequest) (*CloseChannelResponse, error)
 	UpdateChannel(ctx context.Context, updateChannelRequest *UpdateChannelRequest) error
+	// TODO: add SpliceChannel once the ldk-node-go bindings expose splice_in and splice_out (blocked, no backend supports splicing yet)
 	DisconnectPeer(ctx context.Context, peerId string) error
 	MakeOffer(ctx context.Context, description string) (string, error)
 	GetNewOnchainAddress(ctx context.Context) (string, error)
@@ -95,6 +98,9 @@ type LNClient interface {
 	GetSupportedNIP47NotificationTypes() []string
 	GetCustomNodeCommandDefinitions() []CustomNodeCommandDef
 	ExecuteCustomNodeCommand(ctx context.Context, command *CustomNodeCommandRequest) (*CustomNodeCommandResponse, error)
+	GetWatchtowerStatus(ctx context.Context) (*WatchtowerStatus, error)
+	AddWatchtower(ctx context.Context, addWatchtowerRequest *AddWatchtowerRequest) error
+	RemoveWatchtower(ctx context.Context, pubkey string) error
 }
 
 type Channel struct {
@@ -139,10 +145,33 @@ type OpenChannelResponse struct {
 	FundingTxId string `json:"fundingTxId"`
 }
 
+type OpenChannelsRequest struct {
+	Channels []OpenChannelRequest `json:"channels"`
+}
+
+type OpenChannelsResponse struct {
+	// set if all channels are funded by a single transaction
+	FundingTxId string              `json:"fundingTxId,omitempty"`
+	Channels    []OpenChannelStatus `json:"channels"`
+}
+
+type OpenChannelStatus struct {
+	Pubkey        string `json:"pubkey"`
+	AmountSats    int64  `json:"amountSats"`
+	State         string `json:"state"`
+	FundingTxId   string `json:"fundingTxId,omitempty"`
+	FundingTxVout uint32 `json:"fundingTxVout"`
+	Error         string `json:"error,omitempty"`
+}
+
 type CloseChannelRequest struct {
 	ChannelId string `json:"channelId"`
 	NodeId    string `json:"nodeId"`
 	Force     bool   `json:"force"`
+	// fee rate in sat/vB for cooperative closes. If not set, the node's fee estimate is used
+	FeeRate *uint64 `json:"feeRate"`
+	// on-chain address to send our channel balance to on cooperative closes
+	Address string `json:"address"`
 }
 
 type UpdateChannelRequest struct {
@@ -211,6 +240,32 @@ type BalancesResponse struct {
 
 type NetworkGraphResponse = interface{}
 
+type AddWatchtowerRequest struct {
+	Pubkey  string `json:"pubkey"`
+	Address string `json:"address"`
+}
+
+type Watchtower struct {
+	Pubkey    string
+	Addresses []string
+	// whether new sessions can be negotiated with the tower. This does not mean it is reachable
+	ActiveSessionCandidate bool
+	NumSessions            uint32
+	// sessions which have room for more channel state backups
+	NumUsableSessions uint32
+	NumBackups        uint32
+	NumPendingBackups uint32
+}
+
+type WatchtowerStatus struct {
+	Towers               []Watchtower
+	NumBackups           uint32
+	NumPendingBackups    uint32
+	NumFailedBackups     uint32
+	NumSessionsAcquired  uint32
+	NumSessionsExhausted uint32
+}
+
 type PaymentFailedEventProperties struct {
 	Transaction *Transaction
 	Reason      string
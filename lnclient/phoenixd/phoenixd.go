@@ -363,7 +363,7 @@ func (svc *PhoenixService) LookupInvoice(ctx context.Context, paymentHash string
 	return transaction, nil
 }
 
-func (svc *PhoenixService) SendPaymentSync(payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
+func (svc *PhoenixService) SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
 	// TODO: support 0-amount invoices
 	if amount != nil {
 		return nil, errors.New("0-amount invoices not supported")
@@ -394,7 +394,7 @@ func (svc *PhoenixService) SendPaymentSync(payReq string, amount *uint64) (*lncl
 	}, nil
 }
 
-func (svc *PhoenixService) SendKeysend(amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+func (svc *PhoenixService) SendKeysend(ctx context.Context, amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
 	return nil, errors.New("not implemented")
 }
 
@@ -440,6 +440,10 @@ func (svc *PhoenixService) OpenChannel(ctx context.Context, openChannelRequest *
 	return nil, nil
 }
 
+func (svc *PhoenixService) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
+
 func (svc *PhoenixService) CloseChannel(ctx context.Context, closeChannelRequest *lnclient.CloseChannelRequest) (*lnclient.CloseChannelResponse, error) {
 	return nil, nil
 }
@@ -561,3 +565,15 @@ func (svc *PhoenixService) MakeOffer(ctx context.Context, description string) (s
 func (svc *PhoenixService) ListOnchainTransactions(ctx context.Context) ([]lnclient.OnchainTransaction, error) {
 	return nil, errors.ErrUnsupported
 }
+
+func (svc *PhoenixService) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (svc *PhoenixService) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	return errors.ErrUnsupported
+}
+
+func (svc *PhoenixService) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	return errors.ErrUnsupported
+}
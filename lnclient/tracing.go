@@ -0,0 +1,87 @@
+package lnclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/getAlby/hub/tracing"
+)
+
+// tracingLNClient records a span for every call to the node made while
+// handling a request. Calls which are not traced are passed through.
+type tracingLNClient struct {
+	LNClient
+}
+
+// NewTracingLNClient wraps lnClient so its calls are recorded as children of the span in ctx
+func NewTracingLNClient(lnClient LNClient) LNClient {
+	return &tracingLNClient{LNClient: lnClient}
+}
+
+func (c *tracingLNClient) SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (response *PayInvoiceResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.SendPaymentSync")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.SendPaymentSync(ctx, payReq, amount)
+}
+
+func (c *tracingLNClient) SendKeysend(ctx context.Context, amount uint64, destination string, customRecords []TLVRecord, preimage string) (response *PayKeysendResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.SendKeysend")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.SendKeysend(ctx, amount, destination, customRecords, preimage)
+}
+
+func (c *tracingLNClient) GetInfo(ctx context.Context) (info *NodeInfo, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.GetInfo")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.GetInfo(ctx)
+}
+
+func (c *tracingLNClient) MakeInvoice(ctx context.Context, amount int64, description string, descriptionHash string, expiry int64, throughNodePubkey *string) (transaction *Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.MakeInvoice")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.MakeInvoice(ctx, amount, description, descriptionHash, expiry, throughNodePubkey)
+}
+
+func (c *tracingLNClient) MakeHoldInvoice(ctx context.Context, amount int64, description string, descriptionHash string, expiry int64, paymentHash string) (transaction *Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.MakeHoldInvoice", attribute.String("payment_hash", paymentHash))
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.MakeHoldInvoice(ctx, amount, description, descriptionHash, expiry, paymentHash)
+}
+
+func (c *tracingLNClient) SettleHoldInvoice(ctx context.Context, preimage string) (err error) {
+	var attributes []attribute.KeyValue
+	if preimageBytes, decodeErr := hex.DecodeString(preimage); decodeErr == nil {
+		paymentHash := sha256.Sum256(preimageBytes)
+		attributes = append(attributes, attribute.String("payment_hash", hex.EncodeToString(paymentHash[:])))
+	}
+	ctx, span := tracing.StartSpan(ctx, "lnclient.SettleHoldInvoice", attributes...)
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.SettleHoldInvoice(ctx, preimage)
+}
+
+func (c *tracingLNClient) CancelHoldInvoice(ctx context.Context, paymentHash string) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.CancelHoldInvoice", attribute.String("payment_hash", paymentHash))
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.CancelHoldInvoice(ctx, paymentHash)
+}
+
+func (c *tracingLNClient) LookupInvoice(ctx context.Context, paymentHash string) (transaction *Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.LookupInvoice", attribute.String("payment_hash", paymentHash))
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.LookupInvoice(ctx, paymentHash)
+}
+
+func (c *tracingLNClient) GetBalances(ctx context.Context, includeInactiveChannels bool) (balances *BalancesResponse, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.GetBalances")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.GetBalances(ctx, includeInactiveChannels)
+}
+
+func (c *tracingLNClient) SignMessage(ctx context.Context, message string) (signature string, err error) {
+	ctx, span := tracing.StartSpan(ctx, "lnclient.SignMessage")
+	defer func() { tracing.EndSpan(span, err) }()
+	return c.LNClient.SignMessage(ctx, message)
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// matches the file names of the backups rotated by lumberjack, e.g. nwc-2006-01-02T15-04-05.000.log
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+const maxLogLineSize = 1024 * 1024
+
+// LogEntry is a single structured log line
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  map[string]interface{}
+}
+
+type LogQuery struct {
+	// only entries of this level or more severe are returned, e.g. logrus.WarnLevel
+	// returns warnings and errors. Defaults to all levels (logrus.TraceLevel)
+	Level logrus.Level
+	From  *time.Time
+	Until *time.Time
+	// all fields must be set to the given value, e.g. {"paymentHash": "abc"}
+	Fields map[string]string
+	// case-insensitive substring of the message
+	Search string
+	// maximum number of entries to return, the most recent entries are kept
+	Limit int
+}
+
+func NewLogQuery() *LogQuery {
+	return &LogQuery{
+		Level: logrus.TraceLevel,
+	}
+}
+
+func (query *LogQuery) Matches(entry *LogEntry) bool {
+	level, err := logrus.ParseLevel(entry.Level)
+	if err != nil || level > query.Level {
+		return false
+	}
+	if query.From != nil && entry.Time.Before(*query.From) {
+		return false
+	}
+	if query.Until != nil && entry.Time.After(*query.Until) {
+		return false
+	}
+	for key, value := range query.Fields {
+		fieldValue, ok := entry.Fields[key]
+		if !ok || fmt.Sprint(fieldValue) != value {
+			return false
+		}
+	}
+	if query.Search != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(query.Search)) {
+		return false
+	}
+	return true
+}
+
+// QueryLogFile returns the most recent entries of the JSON log file and its rotated
+// backups which match the query, oldest first. Truncated is true if older matching
+// entries were dropped because of the query limit.
+func QueryLogFile(logFilePath string, query *LogQuery) (entries []LogEntry, truncated bool, err error) {
+	logFilePaths, err := getRotatedLogFilePaths(logFilePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries = []LogEntry{}
+	for _, path := range logFilePaths {
+		if query.From != nil {
+			fileInfo, err := os.Stat(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					// rotated in the meantime
+					continue
+				}
+				return nil, false, err
+			}
+			if fileInfo.ModTime().Before(*query.From) {
+				// the file was last written to before the requested time range
+				continue
+			}
+		}
+
+		err = scanLogFile(path, func(entry *LogEntry) {
+			if !query.Matches(entry) {
+				return
+			}
+			entries = append(entries, *entry)
+			if query.Limit > 0 && len(entries) > query.Limit {
+				entries = entries[1:]
+				truncated = true
+			}
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	return entries, truncated, nil
+}
+
+// getRotatedLogFilePaths returns the backups of the log file rotated by lumberjack
+// followed by the current log file, oldest first
+func getRotatedLogFilePaths(logFilePath string) ([]string, error) {
+	dir := filepath.Dir(logFilePath)
+	ext := filepath.Ext(logFilePath)
+	prefix := strings.TrimSuffix(filepath.Base(logFilePath), ext) + "-"
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	backupTimes := map[string]time.Time{}
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		timestamp := strings.TrimPrefix(name, prefix)
+		timestamp = strings.TrimSuffix(timestamp, ".gz")
+		if !strings.HasSuffix(timestamp, ext) {
+			continue
+		}
+		backupTime, err := time.Parse(backupTimeFormat, strings.TrimSuffix(timestamp, ext))
+		if err != nil {
+			continue
+		}
+		backupTimes[filepath.Join(dir, name)] = backupTime
+	}
+
+	paths := make([]string, 0, len(backupTimes)+1)
+	for path := range backupTimes {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return backupTimes[paths[i]].Before(backupTimes[paths[j]])
+	})
+
+	if _, err := os.Stat(logFilePath); err == nil {
+		paths = append(paths, logFilePath)
+	}
+	return paths, nil
+}
+
+func scanLogFile(path string, onEntry func(entry *LogEntry)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// rotated in the meantime
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		entry, err := parseLogLine(scanner.Bytes())
+		if err != nil {
+			// not written by the JSON formatter
+			continue
+		}
+		onEntry(entry)
+	}
+	return scanner.Err()
+}
+
+func parseLogLine(line []byte) (*LogEntry, error) {
+	fields := map[string]interface{}{}
+	err := json.Unmarshal(line, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &LogEntry{}
+	if value, ok := fields[logrus.FieldKeyTime].(string); ok {
+		entry.Time, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	entry.Level, _ = fields[logrus.FieldKeyLevel].(string)
+	entry.Message, _ = fields[logrus.FieldKeyMsg].(string)
+	delete(fields, logrus.FieldKeyTime)
+	delete(fields, logrus.FieldKeyLevel)
+	delete(fields, logrus.FieldKeyMsg)
+	entry.Fields = fields
+	return entry, nil
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLogFile(t *testing.T) {
+	logFilePath := GetLogFilePathForWorkdir(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Dir(logFilePath), os.ModePerm))
+
+	writeLogFile(t, filepath.Join(filepath.Dir(logFilePath), "nwc-2025-10-20T10-00-00.000.log"),
+		`{"level":"info","msg":"Sending payment","paymentHash":"abc","appId":3,"time":"2025-10-20T09:00:00Z"}`,
+		`{"level":"error","msg":"Failed to send payment","paymentHash":"abc","appId":3,"error":"no route","time":"2025-10-20T09:00:01Z"}`,
+	)
+	writeLogFile(t, filepath.Join(filepath.Dir(logFilePath), "nwc-2025-10-19T10-00-00.000.log"),
+		`{"level":"info","msg":"Hub started","time":"2025-10-19T09:00:00Z"}`,
+	)
+	writeLogFile(t, logFilePath,
+		`not a json line`,
+		`{"level":"warning","msg":"Relay disconnected","time":"2025-10-21T09:00:00Z"}`,
+		`{"level":"info","msg":"Sending payment","paymentHash":"def","appId":4,"time":"2025-10-21T09:00:01Z"}`,
+	)
+
+	// rotated backups are read oldest first, followed by the current log file
+	entries, truncated, err := QueryLogFile(logFilePath, NewLogQuery())
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	require.Len(t, entries, 5)
+	assert.Equal(t, "Hub started", entries[0].Message)
+	assert.Equal(t, "Sending payment", entries[4].Message)
+	assert.Equal(t, "def", entries[4].Fields["paymentHash"])
+	assert.Equal(t, time.Date(2025, 10, 21, 9, 0, 1, 0, time.UTC), entries[4].Time.UTC())
+
+	query := NewLogQuery()
+	query.Fields = map[string]string{"paymentHash": "abc", "appId": "3"}
+	entries, _, err = QueryLogFile(logFilePath, query)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "no route", entries[1].Fields["error"])
+
+	query = NewLogQuery()
+	query.Level = logrus.WarnLevel
+	entries, _, err = QueryLogFile(logFilePath, query)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "error", entries[0].Level)
+	assert.Equal(t, "warning", entries[1].Level)
+
+	query = NewLogQuery()
+	from := time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 10, 21, 0, 0, 0, 0, time.UTC)
+	query.From = &from
+	query.Until = &until
+	query.Search = "PAYMENT"
+	entries, _, err = QueryLogFile(logFilePath, query)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "abc", entries[0].Fields["paymentHash"])
+
+	// the most recent entries are returned
+	query = NewLogQuery()
+	query.Limit = 2
+	entries, truncated, err = QueryLogFile(logFilePath, query)
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Relay disconnected", entries[0].Message)
+	assert.Equal(t, "def", entries[1].Fields["paymentHash"])
+}
+
+func TestQueryLogFile_NoLogFile(t *testing.T) {
+	entries, truncated, err := QueryLogFile(GetLogFilePathForWorkdir(t.TempDir()), NewLogQuery())
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	assert.Empty(t, entries)
+}
+
+func TestSubscribeLogs(t *testing.T) {
+	Init(strconv.Itoa(int(logrus.InfoLevel)))
+
+	entries, unsubscribe := SubscribeLogs()
+	defer unsubscribe()
+
+	Logger.WithField("paymentHash", "abc").WithError(assert.AnError).Error("Failed to send payment")
+
+	select {
+	case entry := <-entries:
+		assert.Equal(t, "error", entry.Level)
+		assert.Equal(t, "Failed to send payment", entry.Message)
+		assert.Equal(t, "abc", entry.Fields["paymentHash"])
+		assert.Equal(t, assert.AnError.Error(), entry.Fields["error"])
+	case <-time.After(time.Second):
+		t.Fatal("log entry was not streamed")
+	}
+
+	unsubscribe()
+	Logger.Error("Not streamed")
+	_, ok := <-entries
+	assert.False(t, ok)
+}
+
+func writeLogFile(t *testing.T, path string, lines ...string) {
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	require.NoError(t, err)
+}
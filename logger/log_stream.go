@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const logSubscriberBufferSize = 100
+
+// logStream is a logrus hook which passes every log entry to the subscribers
+// which are tailing the log
+type logStream struct {
+	mu          sync.RWMutex
+	subscribers map[chan *LogEntry]struct{}
+}
+
+var stream = &logStream{
+	subscribers: map[chan *LogEntry]struct{}{},
+}
+
+// SubscribeLogs returns a channel which receives every entry logged from now on,
+// until unsubscribe is called. Entries are dropped if the subscriber falls behind.
+func SubscribeLogs() (entries <-chan *LogEntry, unsubscribe func()) {
+	subscriber := make(chan *LogEntry, logSubscriberBufferSize)
+
+	stream.mu.Lock()
+	stream.subscribers[subscriber] = struct{}{}
+	stream.mu.Unlock()
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			stream.mu.Lock()
+			delete(stream.subscribers, subscriber)
+			stream.mu.Unlock()
+			close(subscriber)
+		})
+	}
+}
+
+func (stream *logStream) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (stream *logStream) Fire(logrusEntry *logrus.Entry) error {
+	stream.mu.RLock()
+	defer stream.mu.RUnlock()
+	if len(stream.subscribers) == 0 {
+		return nil
+	}
+
+	entry := &LogEntry{
+		Time:    logrusEntry.Time,
+		Level:   logrusEntry.Level.String(),
+		Message: logrusEntry.Message,
+		Fields:  make(map[string]interface{}, len(logrusEntry.Data)),
+	}
+	for key, value := range logrusEntry.Data {
+		// same as the JSON formatter, errors are not serializable
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry.Fields[key] = value
+	}
+
+	for subscriber := range stream.subscribers {
+		select {
+		case subscriber <- entry:
+		default:
+		}
+	}
+	return nil
+}
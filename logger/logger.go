@@ -26,6 +26,7 @@ func Init(logLevel string) {
 		logrusLogLevel = int(logrus.InfoLevel)
 	}
 	Logger.SetLevel(logrus.Level(logrusLogLevel))
+	Logger.AddHook(stream)
 	if logrusLogLevel >= int(logrus.DebugLevel) {
 		Logger.ReportCaller = true
 		Logger.Debug("Logrus report caller enabled in debug mode")
@@ -33,7 +34,7 @@ func Init(logLevel string) {
 }
 
 func AddFileLogger(workdir string) error {
-	logFilePath = filepath.Join(workdir, logDir, logFilename)
+	logFilePath = GetLogFilePathForWorkdir(workdir)
 	fileLoggerHook, err := lumberjackrus.NewHook(
 		&lumberjackrus.LogFile{
 			Filename:   logFilePath,
@@ -54,3 +55,7 @@ func AddFileLogger(workdir string) error {
 func GetLogFilePath() string {
 	return logFilePath
 }
+
+func GetLogFilePathForWorkdir(workdir string) string {
+	return filepath.Join(workdir, logDir, logFilename)
+}
@@ -0,0 +1,208 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+)
+
+type LSPService interface {
+	ListLSPs() ([]db.LSP, error)
+	ListNetworkLSPs(network string) ([]db.LSP, error)
+	AddLSP(name, identifier, network, pubkey, lspUrl string) (*db.LSP, error)
+	DeleteLSP(id uint) error
+	GetLSPS1Client(identifier, network string) LSPS1Client
+	SaveOrder(order *db.LSPOrder) error
+	ListOrders() ([]db.LSPOrder, error)
+}
+
+type lspService struct {
+	ctx          context.Context
+	db           *gorm.DB
+	albyOAuthSvc alby.AlbyOAuthService
+}
+
+const (
+	lspOrderCheckInterval = 5 * time.Minute
+)
+
+func NewLSPService(ctx context.Context, db *gorm.DB, albyOAuthSvc alby.AlbyOAuthService) LSPService {
+	svc := &lspService{
+		ctx:          ctx,
+		db:           db,
+		albyOAuthSvc: albyOAuthSvc,
+	}
+
+	go svc.watchOrders()
+
+	return svc
+}
+
+func (svc *lspService) ListLSPs() ([]db.LSP, error) {
+	var lsps []db.LSP
+	if err := svc.db.Order("name").Find(&lsps).Error; err != nil {
+		return nil, err
+	}
+	return lsps, nil
+}
+
+// ListNetworkLSPs returns the LSPs which can be used on the given network
+func (svc *lspService) ListNetworkLSPs(network string) ([]db.LSP, error) {
+	var lsps []db.LSP
+	if err := svc.db.Where("network IN (?, '')", network).Order("name").Find(&lsps).Error; err != nil {
+		return nil, err
+	}
+	return lsps, nil
+}
+
+// ListTrustedPubkeys returns the node pubkeys of the LSPs registered for the given
+// network, which are trusted for 0-conf channels
+func ListTrustedPubkeys(gormDB *gorm.DB, network string) ([]string, error) {
+	var pubkeys []string
+	err := gormDB.Model(&db.LSP{}).
+		Where("network IN (?, '') AND pubkey != ''", network).
+		Order("id").
+		Pluck("pubkey", &pubkeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return pubkeys, nil
+}
+
+func (svc *lspService) AddLSP(name, identifier, network, pubkey, lspUrl string) (*db.LSP, error) {
+	if identifier == "" {
+		return nil, errors.New("identifier is required")
+	}
+	if lspUrl != "" {
+		parsedUrl, err := url.Parse(lspUrl)
+		if err != nil || (parsedUrl.Scheme != "https" && parsedUrl.Scheme != "http") || parsedUrl.Host == "" {
+			return nil, errors.New("invalid LSP url")
+		}
+	}
+	if name == "" {
+		name = identifier
+	}
+
+	var existingCount int64
+	if err := svc.db.Model(&db.LSP{}).Where("identifier = ? AND network = ?", identifier, network).Count(&existingCount).Error; err != nil {
+		return nil, err
+	}
+	if existingCount > 0 {
+		return nil, errors.New("an LSP with this identifier already exists")
+	}
+
+	lsp := &db.LSP{
+		Name:       name,
+		Identifier: identifier,
+		Network:    network,
+		Pubkey:     pubkey,
+		Url:        lspUrl,
+	}
+	if err := svc.db.Create(lsp).Error; err != nil {
+		logger.Logger.WithError(err).Error("Failed to save LSP")
+		return nil, err
+	}
+	return lsp, nil
+}
+
+func (svc *lspService) DeleteLSP(id uint) error {
+	result := svc.db.Delete(&db.LSP{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("LSP not found")
+	}
+	return nil
+}
+
+// GetLSPS1Client returns a client for a registered LSP. Unknown identifiers
+// (e.g. from Alby channel suggestions) are proxied through Alby
+func (svc *lspService) GetLSPS1Client(identifier, network string) LSPS1Client {
+	lsp := &db.LSP{}
+	// prefer the LSP registered for this network over one registered for all networks
+	err := svc.db.Where("identifier = ? AND network IN (?, '')", identifier, network).Order("network DESC").Limit(1).Find(lsp).Error
+	if err != nil || lsp.ID == 0 {
+		lsp = &db.LSP{Identifier: identifier}
+	}
+	return NewLSPS1Client(lsp, network, svc.albyOAuthSvc)
+}
+
+func (svc *lspService) SaveOrder(order *db.LSPOrder) error {
+	return svc.db.Save(order).Error
+}
+
+func (svc *lspService) ListOrders() ([]db.LSPOrder, error) {
+	var orders []db.LSPOrder
+	if err := svc.db.Where("accepted = ?", true).Order("created_at DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (svc *lspService) watchOrders() {
+	for {
+		select {
+		case <-time.After(lspOrderCheckInterval):
+			svc.updateOrders()
+		case <-svc.ctx.Done():
+			logger.Logger.Info("Stopping LSP order watcher")
+			return
+		}
+	}
+}
+
+// updateOrders polls the LSP for accepted orders until the channel is opened
+// or the order failed
+func (svc *lspService) updateOrders() {
+	var orders []db.LSPOrder
+	err := svc.db.Where("accepted = ? AND order_state = ?", true, LSPS1_ORDER_STATE_CREATED).Find(&orders).Error
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to load LSP orders")
+		return
+	}
+
+	for _, order := range orders {
+		client := svc.GetLSPS1Client(order.LSPIdentifier, order.Network)
+		orderResponse, err := client.GetOrder(svc.ctx, order.OrderId)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"id":            order.ID,
+				"lspIdentifier": order.LSPIdentifier,
+			}).Error("Failed to fetch LSP order")
+			continue
+		}
+
+		updates := map[string]interface{}{}
+		if orderResponse.OrderState != "" && orderResponse.OrderState != order.OrderState {
+			updates["order_state"] = orderResponse.OrderState
+		}
+		if orderResponse.Payment != nil && orderResponse.Payment.Bolt11.State != order.PaymentState {
+			updates["payment_state"] = orderResponse.Payment.Bolt11.State
+		}
+		if orderResponse.Channel != nil && orderResponse.Channel.FundingOutpoint != order.FundingOutpoint {
+			updates["funding_outpoint"] = orderResponse.Channel.FundingOutpoint
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		logger.Logger.WithFields(logrus.Fields{
+			"id":      order.ID,
+			"updates": updates,
+		}).Info("Updating LSP order")
+
+		err = svc.db.Model(&db.LSPOrder{}).Where("id = ?", order.ID).Updates(updates).Error
+		if err != nil {
+			logger.Logger.WithError(err).WithField("id", order.ID).Error("Failed to update LSP order")
+		}
+	}
+}
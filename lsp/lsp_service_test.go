@@ -0,0 +1,134 @@
+package lsp_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/lsp"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/tests/mocks"
+)
+
+func TestLSPRegistry(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lspSvc := lsp.NewLSPService(ctx, svc.DB, mocks.NewMockAlbyOAuthService(t))
+
+	_, err = lspSvc.AddLSP("", "", "", "", "")
+	assert.EqualError(t, err, "identifier is required")
+
+	_, err = lspSvc.AddLSP("Example", "example", "", "", "ftp://lsp.example.com")
+	assert.EqualError(t, err, "invalid LSP url")
+
+	registeredLSP, err := lspSvc.AddLSP("", "example", "", "", "https://lsp.example.com/api")
+	require.NoError(t, err)
+	assert.Equal(t, "example", registeredLSP.Name)
+
+	_, err = lspSvc.AddLSP("Example", "example", "", "", "")
+	assert.EqualError(t, err, "an LSP with this identifier already exists")
+
+	// the same identifier can be registered for a specific network
+	_, err = lspSvc.AddLSP("Example", "example", "signet", "", "https://signet.lsp.example.com/api")
+	require.NoError(t, err)
+
+	lsps, err := lspSvc.ListLSPs()
+	require.NoError(t, err)
+	var exampleLSPs []string
+	for _, registeredLSP := range lsps {
+		if registeredLSP.Identifier == "example" {
+			exampleLSPs = append(exampleLSPs, registeredLSP.Url)
+		}
+	}
+	assert.ElementsMatch(t, []string{"https://lsp.example.com/api", "https://signet.lsp.example.com/api"}, exampleLSPs)
+
+	require.NoError(t, lspSvc.DeleteLSP(registeredLSP.ID))
+	assert.EqualError(t, lspSvc.DeleteLSP(registeredLSP.ID), "LSP not found")
+}
+
+func TestDefaultLSPs(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lspSvc := lsp.NewLSPService(ctx, svc.DB, mocks.NewMockAlbyOAuthService(t))
+
+	mainnetLSPs, err := lspSvc.ListNetworkLSPs("bitcoin")
+	require.NoError(t, err)
+	var identifiers []string
+	for _, registeredLSP := range mainnetLSPs {
+		identifiers = append(identifiers, registeredLSP.Identifier)
+	}
+	assert.Equal(t, []string{"lnserver", "megalith", "olympus"}, identifiers)
+
+	_, err = lspSvc.AddLSP("Custom", "custom", "", "02abcd", "https://lsp.example.com/api")
+	require.NoError(t, err)
+
+	pubkeys, err := lsp.ListTrustedPubkeys(svc.DB, "signet")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"032ae843e4d7d177f151d021ac8044b0636ec72b1ce3ffcde5c04748db2517ab03",
+		"03e30fda71887a916ef5548a4d02b06fe04aaa1a8de9e24134ce7f139cf79d7579",
+		"02abcd",
+	}, pubkeys)
+}
+
+func TestGetLSPS1Client(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/get_info":
+			w.Write([]byte(`{"min_required_channel_confirmations":0,"min_funding_confirms_within_blocks":6,"max_channel_expiry_blocks":13140,"uris":["02abcd@127.0.0.1:9735"]}`))
+		case "/api/v1/get_order":
+			assert.Equal(t, "order1", r.URL.Query().Get("order_id"))
+			w.Write([]byte(`{"order_id":"order1","order_state":"COMPLETED","payment":{"bolt11":{"state":"PAID"}},"channel":{"funding_outpoint":"txid:0"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	albyOAuthSvc := mocks.NewMockAlbyOAuthService(t)
+	albyOAuthSvc.On("GetLSPInfo", context.Background(), "proxied", "bitcoin").Return(&alby.LSPInfo{Pubkey: "03ef"}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lspSvc := lsp.NewLSPService(ctx, svc.DB, albyOAuthSvc)
+
+	_, err = lspSvc.AddLSP("Direct", "direct", "", "", server.URL+"/api/")
+	require.NoError(t, err)
+
+	directClient := lspSvc.GetLSPS1Client("direct", "bitcoin")
+	lspInfo, err := directClient.GetInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "02abcd", lspInfo.Pubkey)
+	assert.Equal(t, "127.0.0.1", lspInfo.Address)
+	assert.Equal(t, uint16(9735), lspInfo.Port)
+	assert.Equal(t, uint64(13140), lspInfo.MaxChannelExpiryBlocks)
+
+	order, err := directClient.GetOrder(context.Background(), "order1")
+	require.NoError(t, err)
+	assert.Equal(t, lsp.LSPS1_ORDER_STATE_COMPLETED, order.OrderState)
+	assert.Equal(t, "PAID", order.Payment.Bolt11.State)
+	assert.Equal(t, "txid:0", order.Channel.FundingOutpoint)
+
+	// unregistered LSPs are proxied through Alby
+	proxiedClient := lspSvc.GetLSPS1Client("proxied", "bitcoin")
+	lspInfo, err = proxiedClient.GetInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "03ef", lspInfo.Pubkey)
+}
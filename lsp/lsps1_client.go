@@ -0,0 +1,126 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/db"
+)
+
+type LSPS1Client interface {
+	GetInfo(ctx context.Context) (*alby.LSPInfo, error)
+	CreateOrder(ctx context.Context, request *alby.LSPChannelRequest) (*alby.LSPChannelResponse, error)
+	GetOrder(ctx context.Context, orderId string) (*alby.LSPChannelResponse, error)
+}
+
+// NewLSPS1Client returns a client which talks to the LSP directly if it has
+// a URL configured, otherwise requests are proxied through Alby
+func NewLSPS1Client(lsp *db.LSP, network string, albyOAuthSvc alby.AlbyOAuthService) LSPS1Client {
+	if lsp.Url != "" {
+		return &httpLSPS1Client{
+			url: strings.TrimSuffix(lsp.Url, "/"),
+		}
+	}
+	return &albyLSPS1Client{
+		albyOAuthSvc: albyOAuthSvc,
+		identifier:   lsp.Identifier,
+		network:      network,
+	}
+}
+
+type albyLSPS1Client struct {
+	albyOAuthSvc alby.AlbyOAuthService
+	identifier   string
+	network      string
+}
+
+func (client *albyLSPS1Client) GetInfo(ctx context.Context) (*alby.LSPInfo, error) {
+	return client.albyOAuthSvc.GetLSPInfo(ctx, client.identifier, client.network)
+}
+
+func (client *albyLSPS1Client) CreateOrder(ctx context.Context, request *alby.LSPChannelRequest) (*alby.LSPChannelResponse, error) {
+	return client.albyOAuthSvc.CreateLSPOrder(ctx, client.identifier, client.network, request)
+}
+
+func (client *albyLSPS1Client) GetOrder(ctx context.Context, orderId string) (*alby.LSPChannelResponse, error) {
+	return client.albyOAuthSvc.GetLSPOrder(ctx, client.identifier, client.network, orderId)
+}
+
+// httpLSPS1Client calls the LSPS1 HTTP API, where url is the base path
+// without the version (e.g. https://lsp.example.com/api)
+type httpLSPS1Client struct {
+	url string
+}
+
+func (client *httpLSPS1Client) GetInfo(ctx context.Context) (*alby.LSPInfo, error) {
+	body, err := client.request(ctx, http.MethodGet, "/v1/get_info", nil)
+	if err != nil {
+		return nil, err
+	}
+	return alby.ParseLSPS1Info(body)
+}
+
+func (client *httpLSPS1Client) CreateOrder(ctx context.Context, request *alby.LSPChannelRequest) (*alby.LSPChannelResponse, error) {
+	payloadBytes, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := client.request(ctx, http.MethodPost, "/v1/create_order", payloadBytes)
+	if err != nil {
+		return nil, err
+	}
+	return decodeLSPChannelResponse(body)
+}
+
+func (client *httpLSPS1Client) GetOrder(ctx context.Context, orderId string) (*alby.LSPChannelResponse, error) {
+	body, err := client.request(ctx, http.MethodGet, "/v1/get_order?order_id="+url.QueryEscape(orderId), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeLSPChannelResponse(body)
+}
+
+func (client *httpLSPS1Client) request(ctx context.Context, method, endpoint string, payload []byte) ([]byte, error) {
+	httpClient := http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, client.url+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("LSP %s returned non-success code %d: %s", endpoint, res.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+func decodeLSPChannelResponse(body []byte) (*alby.LSPChannelResponse, error) {
+	channelResponse := &alby.LSPChannelResponse{}
+	if err := json.Unmarshal(body, channelResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode LSP order response: %w", err)
+	}
+	return channelResponse, nil
+}
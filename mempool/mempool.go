@@ -0,0 +1,134 @@
+package mempool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/utils"
+)
+
+const addressLookAheadLimit = 100
+
+type FeeRates struct {
+	FastestFee  uint64 `json:"fastestFee"`
+	HalfHourFee uint64 `json:"halfHourFee"`
+	HourFee     uint64 `json:"hourFee"`
+	EconomyFee  uint64 `json:"economyFee"`
+	MinimumFee  uint64 `json:"minimumFee"`
+}
+
+type TxStatusInfo struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight uint32 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockTime   uint64 `json:"block_time"`
+}
+
+type Tx struct {
+	TxId   string       `json:"txid"`
+	Status TxStatusInfo `json:"status"`
+}
+
+// Client requests a mempool.space or Esplora compatible API
+type Client struct {
+	apiUrl string
+}
+
+// NewClient returns a client for the configured mempool API (MEMPOOL_API)
+func NewClient(cfg config.Config) *Client {
+	// the mempool API is always requested without the testnet/ prefix
+	return &Client{
+		apiUrl: strings.ReplaceAll(cfg.GetEnv().MempoolApi, "testnet/", ""),
+	}
+}
+
+// NewEsploraClient returns a client for the Esplora server used by LDK (LDK_ESPLORA_SERVER)
+func NewEsploraClient(cfg config.Config) *Client {
+	return &Client{
+		apiUrl: cfg.GetEnv().LDKEsploraServer,
+	}
+}
+
+func (client *Client) GetFeeRates(ctx context.Context) (*FeeRates, error) {
+	var rates FeeRates
+	if err := client.Request(ctx, "/v1/fees/recommended", &rates); err != nil {
+		return nil, err
+	}
+	return &rates, nil
+}
+
+func (client *Client) GetTx(ctx context.Context, txId string) (*Tx, error) {
+	var tx Tx
+	if err := client.Request(ctx, "/tx/"+txId, &tx); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+func (client *Client) AddressHasTransactions(ctx context.Context, address string) (bool, error) {
+	var transactions []interface{}
+	if err := client.Request(ctx, "/address/"+address+"/txs", &transactions); err != nil {
+		return false, fmt.Errorf("failed to get address transactions: %w", err)
+	}
+	return len(transactions) > 0, nil
+}
+
+// GetNextUnusedAddressFromXpub returns the first address of the xpub external chain,
+// starting at startIndex, which has not received any transactions, and its index
+func (client *Client) GetNextUnusedAddressFromXpub(ctx context.Context, network string, xpub string, startIndex uint32) (string, uint32, error) {
+	for i := startIndex; i < startIndex+addressLookAheadLimit; i++ {
+		address, err := utils.DeriveAddressFromXpub(network, xpub, i)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to derive address at index %d: %w", i, err)
+		}
+
+		hasTransactions, err := client.AddressHasTransactions(ctx, address)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to check address for transactions at index %d: %w", i, err)
+		}
+
+		if !hasTransactions {
+			return address, i, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("could not find unused address within %d addresses starting from index %d", addressLookAheadLimit, startIndex)
+}
+
+func (client *Client) Request(ctx context.Context, endpoint string, result interface{}) error {
+	url := client.apiUrl + endpoint
+
+	httpClient := http.Client{
+		Timeout: time.Second * 10,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request to %s: %w", url, err)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %w", url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", res.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body from %s: %w", url, err)
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to deserialize json %s %s", url, string(body))
+	}
+	return nil
+}
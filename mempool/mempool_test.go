@@ -0,0 +1,58 @@
+package mempool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/utils"
+)
+
+func TestGetNextUnusedAddressFromXpub(t *testing.T) {
+	seed := make([]byte, hdkeychain.RecommendedSeedLen)
+	masterKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	require.NoError(t, err)
+	xpubKey, err := masterKey.Neuter()
+	require.NoError(t, err)
+	xpub := xpubKey.String()
+
+	usedAddress, err := utils.DeriveAddressFromXpub("bitcoin", xpub, 3)
+	require.NoError(t, err)
+	unusedAddress, err := utils.DeriveAddressFromXpub("bitcoin", xpub, 4)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/address/"+usedAddress+"/txs" {
+			w.Write([]byte(`[{"txid":"abc"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := &Client{apiUrl: server.URL}
+
+	// the lookahead starts at the given index
+	address, index, err := client.GetNextUnusedAddressFromXpub(context.TODO(), "bitcoin", xpub, 3)
+	require.NoError(t, err)
+	assert.Equal(t, unusedAddress, address)
+	assert.Equal(t, uint32(4), index)
+}
+
+func TestGetFeeRates_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &Client{apiUrl: server.URL}
+
+	_, err := client.GetFeeRates(context.TODO())
+	assert.ErrorContains(t, err, "unexpected status code 429")
+}
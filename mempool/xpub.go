@@ -0,0 +1,38 @@
+package mempool
+
+import (
+	"strconv"
+
+	"github.com/getAlby/hub/config"
+)
+
+// GetXpubIndexStart returns the index of the xpub external chain address from which
+// to look for an unused address. Addresses before it have already been used by the hub.
+func GetXpubIndexStart(cfg config.Config, xpub string) (uint32, error) {
+	indexStr, err := cfg.Get(xpubIndexStartKey(cfg, xpub), "")
+	if err != nil {
+		return 0, err
+	}
+	if indexStr == "" {
+		return 0, nil
+	}
+	index, err := strconv.ParseUint(indexStr, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(index), nil
+}
+
+func SetXpubIndexStart(cfg config.Config, xpub string, index uint32) error {
+	return cfg.SetUpdate(xpubIndexStartKey(cfg, xpub), strconv.FormatUint(uint64(index), 10), "")
+}
+
+// the auto swap destination keeps its existing key, so swaps and channel closes
+// to the same xpub share the index
+func xpubIndexStartKey(cfg config.Config, xpub string) string {
+	autoSwapDestination, _ := cfg.Get(config.AutoSwapDestinationKey, "")
+	if xpub == autoSwapDestination {
+		return config.AutoSwapXpubIndexStart
+	}
+	return config.XpubIndexStartKeyPrefix + xpub
+}
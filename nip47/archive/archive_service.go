@@ -0,0 +1,202 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tyler-smith/go-bip32"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/service/keys"
+)
+
+type requestArchiveService struct {
+	db   *gorm.DB
+	cfg  config.Config
+	keys keys.Keys
+}
+
+type ArchivedResponse struct {
+	NostrId   string
+	Payload   string
+	CreatedAt time.Time
+}
+
+type ArchivedRequest struct {
+	AppId          uint
+	RequestEventId uint
+	NostrId        string
+	Method         string
+	Payload        string
+	CreatedAt      time.Time
+	Responses      []ArchivedResponse
+}
+
+// RequestArchiveService stores decrypted NIP-47 requests and responses of apps
+// with request archiving enabled so that they can be inspected later.
+// Payloads are encrypted at rest with a key derived from the wallet keys,
+// which are only available once the hub is unlocked.
+type RequestArchiveService interface {
+	ArchiveRequest(app *db.App, requestEventId uint, nostrId string, method string, payload string) (*db.RequestArchive, error)
+	ArchiveResponse(requestArchiveId uint, nostrId string, payload string) error
+	GetArchivedRequest(nostrId string) (*ArchivedRequest, error)
+	PruneArchivedRequests() (int64, error)
+	StartPruner(ctx context.Context)
+}
+
+const requestArchivePruneInterval = 1 * time.Hour
+
+func NewRequestArchiveService(db *gorm.DB, cfg config.Config, keys keys.Keys) RequestArchiveService {
+	return &requestArchiveService{
+		db:   db,
+		cfg:  cfg,
+		keys: keys,
+	}
+}
+
+func (svc *requestArchiveService) ArchiveRequest(app *db.App, requestEventId uint, nostrId string, method string, payload string) (*db.RequestArchive, error) {
+	if !app.ArchiveRequests {
+		return nil, nil
+	}
+
+	encryptedPayload, err := svc.encrypt(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	requestArchive := &db.RequestArchive{
+		AppId:            app.ID,
+		RequestEventId:   requestEventId,
+		NostrId:          nostrId,
+		Method:           method,
+		EncryptedPayload: encryptedPayload,
+	}
+	err = svc.db.Omit("App", "Responses").Create(requestArchive).Error
+	if err != nil {
+		return nil, err
+	}
+	return requestArchive, nil
+}
+
+func (svc *requestArchiveService) ArchiveResponse(requestArchiveId uint, nostrId string, payload string) error {
+	encryptedPayload, err := svc.encrypt(payload)
+	if err != nil {
+		return err
+	}
+
+	return svc.db.Create(&db.ResponseArchive{
+		RequestArchiveId: requestArchiveId,
+		NostrId:          nostrId,
+		EncryptedPayload: encryptedPayload,
+	}).Error
+}
+
+func (svc *requestArchiveService) GetArchivedRequest(nostrId string) (*ArchivedRequest, error) {
+	var requestArchive db.RequestArchive
+	err := svc.db.
+		Preload("Responses", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("id")
+		}).
+		Where("nostr_id = ?", nostrId).
+		First(&requestArchive).Error
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := svc.decrypt(requestArchive.EncryptedPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	archivedRequest := &ArchivedRequest{
+		AppId:          requestArchive.AppId,
+		RequestEventId: requestArchive.RequestEventId,
+		NostrId:        requestArchive.NostrId,
+		Method:         requestArchive.Method,
+		Payload:        payload,
+		CreatedAt:      requestArchive.CreatedAt,
+		Responses:      []ArchivedResponse{},
+	}
+
+	for _, responseArchive := range requestArchive.Responses {
+		responsePayload, err := svc.decrypt(responseArchive.EncryptedPayload)
+		if err != nil {
+			return nil, err
+		}
+		archivedRequest.Responses = append(archivedRequest.Responses, ArchivedResponse{
+			NostrId:   responseArchive.NostrId,
+			Payload:   responsePayload,
+			CreatedAt: responseArchive.CreatedAt,
+		})
+	}
+
+	return archivedRequest, nil
+}
+
+// PruneArchivedRequests deletes archived requests older than REQUEST_ARCHIVE_RETENTION_DAYS.
+// Same as the data retention rules, 0 keeps archived requests forever.
+func (svc *requestArchiveService) PruneArchivedRequests() (int64, error) {
+	retentionDays := svc.cfg.GetEnv().RequestArchiveRetentionDays
+	if retentionDays == 0 {
+		return 0, nil
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+
+	result := svc.db.
+		Where("created_at < ?", time.Now().Add(-retention)).
+		Delete(&db.RequestArchive{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (svc *requestArchiveService) StartPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(requestArchivePruneInterval)
+		defer ticker.Stop()
+		for {
+			pruned, err := svc.PruneArchivedRequests()
+			if err != nil {
+				logger.Logger.WithError(err).Error("Failed to prune archived requests")
+			} else if pruned > 0 {
+				logger.Logger.WithField("count", pruned).Info("Pruned archived requests")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (svc *requestArchiveService) encrypt(payload string) (string, error) {
+	archiveKey, err := svc.getArchiveKey()
+	if err != nil {
+		return "", err
+	}
+	return config.AesGcmEncryptWithKey(payload, archiveKey)
+}
+
+func (svc *requestArchiveService) decrypt(encryptedPayload string) (string, error) {
+	archiveKey, err := svc.getArchiveKey()
+	if err != nil {
+		return "", err
+	}
+	return config.AesGcmDecryptWithKey(encryptedPayload, archiveKey)
+}
+
+func (svc *requestArchiveService) getArchiveKey() ([]byte, error) {
+	archiveKey, err := svc.keys.DeriveKey([]uint32{bip32.FirstHardenedChild + 3})
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to derive request archive key")
+		return nil, errors.New("failed to derive request archive key")
+	}
+	return archiveKey.Key, nil
+}
@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestPruneArchivedRequests(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	require.NoError(t, err)
+
+	for i, createdAt := range []time.Time{time.Now().Add(-10 * 24 * time.Hour), time.Now()} {
+		requestEvent := &db.RequestEvent{AppId: &app.ID, NostrId: strconv.Itoa(i)}
+		require.NoError(t, svc.DB.Omit("App").Create(requestEvent).Error)
+
+		err = svc.DB.Omit("App", "Responses").Create(&db.RequestArchive{
+			AppId:          app.ID,
+			RequestEventId: requestEvent.ID,
+			NostrId:        requestEvent.NostrId,
+			CreatedAt:      createdAt,
+		}).Error
+		require.NoError(t, err)
+	}
+
+	archiveSvc := NewRequestArchiveService(svc.DB, svc.Cfg, svc.Keys)
+
+	// 0 keeps archived requests forever
+	svc.Cfg.GetEnv().RequestArchiveRetentionDays = 0
+	pruned, err := archiveSvc.PruneArchivedRequests()
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+
+	svc.Cfg.GetEnv().RequestArchiveRetentionDays = 7
+	pruned, err = archiveSvc.PruneArchivedRequests()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	var count int64
+	require.NoError(t, svc.DB.Model(&db.RequestArchive{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
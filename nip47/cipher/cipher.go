@@ -1,11 +1,11 @@
 package cipher
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/getAlby/hub/constants"
-	"github.com/nbd-wtf/go-nostr/nip04"
-	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/getAlby/hub/nostr/signer"
 )
 
 const (
@@ -14,70 +14,42 @@ const (
 )
 
 type Nip47Cipher struct {
-	encryption      string
-	pubkey          string
-	privkey         string
-	sharedSecret    []byte
-	conversationKey [32]byte
+	encryption string
+	pubkey     string
+	signer     signer.Signer
 }
 
 func NewNip47Cipher(encryption, pubkey, privkey string) (*Nip47Cipher, error) {
-	_, err := isEncryptionSupported(encryption)
+	keySigner, err := signer.NewKeySigner(privkey)
 	if err != nil {
 		return nil, err
 	}
+	return NewNip47CipherWithSigner(encryption, pubkey, keySigner)
+}
 
-	var ss []byte
-	var ck [32]byte
-	if encryption == constants.ENCRYPTION_TYPE_NIP04 {
-		ss, err = nip04.ComputeSharedSecret(pubkey, privkey)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		ck, err = nip44.GenerateConversationKey(pubkey, privkey)
-		if err != nil {
-			return nil, err
-		}
+// NewNip47CipherWithSigner creates a cipher which delegates encryption to the signer
+// of the wallet key, e.g. a remote signer
+func NewNip47CipherWithSigner(encryption, pubkey string, signer signer.Signer) (*Nip47Cipher, error) {
+	_, err := isEncryptionSupported(encryption)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Nip47Cipher{
-		encryption:      encryption,
-		pubkey:          pubkey,
-		privkey:         privkey,
-		sharedSecret:    ss,
-		conversationKey: ck,
+		encryption: encryption,
+		pubkey:     pubkey,
+		signer:     signer,
 	}, nil
 }
 
-func (c *Nip47Cipher) Encrypt(message string) (msg string, err error) {
-	if c.encryption == constants.ENCRYPTION_TYPE_NIP04 {
-		msg, err = nip04.Encrypt(message, c.sharedSecret)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		msg, err = nip44.Encrypt(message, c.conversationKey)
-		if err != nil {
-			return "", err
-		}
-	}
-	return msg, nil
+// Encrypt and Decrypt take the context of the request, since a remote signer
+// does a round trip to its relay
+func (c *Nip47Cipher) Encrypt(ctx context.Context, message string) (msg string, err error) {
+	return c.signer.Encrypt(ctx, c.encryption, c.pubkey, message)
 }
 
-func (c *Nip47Cipher) Decrypt(content string) (payload string, err error) {
-	if c.encryption == constants.ENCRYPTION_TYPE_NIP04 {
-		payload, err = nip04.Decrypt(content, c.sharedSecret)
-		if err != nil {
-			return "", err
-		}
-	} else {
-		payload, err = nip44.Decrypt(content, c.conversationKey)
-		if err != nil {
-			return "", err
-		}
-	}
-	return payload, nil
+func (c *Nip47Cipher) Decrypt(ctx context.Context, content string) (payload string, err error) {
+	return c.signer.Decrypt(ctx, c.encryption, c.pubkey, content)
 }
 
 func isEncryptionSupported(encryption string) (bool, error) {
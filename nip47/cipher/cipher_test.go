@@ -1,10 +1,12 @@
 package cipher
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/nbd-wtf/go-nostr"
 
 	"github.com/stretchr/testify/assert"
@@ -23,10 +25,10 @@ func doTestCipher(t *testing.T, encryption string) {
 	assert.NoError(t, err)
 
 	payload := "test payload"
-	msg, err := nip47Cipher.Encrypt(payload)
+	msg, err := nip47Cipher.Encrypt(context.TODO(), payload)
 	assert.NoError(t, err)
 
-	decrypted, err := nip47Cipher.Decrypt(msg)
+	decrypted, err := nip47Cipher.Decrypt(context.TODO(), msg)
 	assert.Equal(t, payload, decrypted)
 }
 
@@ -46,3 +48,36 @@ func doTestCipher_UnsupportedEncrptions(t *testing.T, encryption string) {
 	assert.Error(t, err)
 	assert.Equal(t, fmt.Sprintf("invalid encryption: %s", encryption), err.Error())
 }
+
+func TestCipher_WithSigner(t *testing.T) {
+	doTestCipher_WithSigner(t, constants.ENCRYPTION_TYPE_NIP04)
+	doTestCipher_WithSigner(t, constants.ENCRYPTION_TYPE_NIP44_V2)
+}
+
+func doTestCipher_WithSigner(t *testing.T, encryption string) {
+	walletPrivateKey := nostr.GeneratePrivateKey()
+	walletSigner, err := signer.NewKeySigner(walletPrivateKey)
+	assert.NoError(t, err)
+
+	reqPrivateKey := nostr.GeneratePrivateKey()
+	reqPubkey, err := nostr.GetPublicKey(reqPrivateKey)
+	assert.NoError(t, err)
+
+	walletCipher, err := NewNip47CipherWithSigner(encryption, reqPubkey, walletSigner)
+	assert.NoError(t, err)
+	reqCipher, err := NewNip47Cipher(encryption, walletSigner.GetPublicKey(), reqPrivateKey)
+	assert.NoError(t, err)
+
+	// the app and the wallet can decrypt each other's messages
+	msg, err := reqCipher.Encrypt(context.TODO(), "request")
+	assert.NoError(t, err)
+	decrypted, err := walletCipher.Decrypt(context.TODO(), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "request", decrypted)
+
+	msg, err = walletCipher.Encrypt(context.TODO(), "response")
+	assert.NoError(t, err)
+	decrypted, err = reqCipher.Decrypt(context.TODO(), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "response", decrypted)
+}
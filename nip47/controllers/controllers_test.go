@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/approvals"
 	"github.com/getAlby/hub/nip47/permissions"
 	"github.com/getAlby/hub/tests"
 	"github.com/getAlby/hub/transactions"
@@ -10,6 +11,8 @@ import (
 func NewTestNip47Controller(svc *tests.TestService) *nip47Controller {
 	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
 	transactionsSvc := transactions.NewTransactionsService(svc.DB, svc.EventPublisher)
+	albySvc := alby.NewAlbyService(svc.Cfg)
 	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
-	return NewNip47Controller(svc.LNClient, svc.DB, svc.EventPublisher, permissionsSvc, transactionsSvc, svc.AppsService, albyOAuthSvc)
+	approvalsSvc := approvals.NewApprovalsService(svc.DB, svc.Cfg, svc.EventPublisher)
+	return NewNip47Controller(svc.LNClient, svc.DB, svc.EventPublisher, permissionsSvc, transactionsSvc, svc.AppsService, albySvc, albyOAuthSvc, approvalsSvc)
 }
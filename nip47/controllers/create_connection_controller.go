@@ -88,7 +88,7 @@ func (controller *nip47Controller) HandleCreateConnectionEvent(ctx context.Conte
 		return
 	}
 
-	supportedMethods := controller.lnClient.GetSupportedNIP47Methods()
+	supportedMethods := permissions.GetSupportedMethods(controller.lnClient)
 	if slices.ContainsFunc(params.RequestMethods, func(method string) bool {
 		return !slices.Contains(supportedMethods, method)
 	}) {
@@ -104,7 +104,7 @@ func (controller *nip47Controller) HandleCreateConnectionEvent(ctx context.Conte
 
 	scopes, err := permissions.RequestMethodsToScopes(params.RequestMethods)
 
-	supportedNotificationTypes := controller.lnClient.GetSupportedNIP47NotificationTypes()
+	supportedNotificationTypes := permissions.GetSupportedNotificationTypes(controller.lnClient)
 	if len(params.NotificationTypes) > 0 {
 		if slices.ContainsFunc(params.NotificationTypes, func(method string) bool {
 			return !slices.Contains(supportedNotificationTypes, method)
@@ -118,7 +118,7 @@ func (controller *nip47Controller) HandleCreateConnectionEvent(ctx context.Conte
 			}, nostr.Tags{})
 			return
 		}
-		scopes = append(scopes, constants.NOTIFICATIONS_SCOPE)
+		scopes = append(scopes, permissions.NotificationTypesToScopes(params.NotificationTypes)...)
 	}
 
 	app, _, err := controller.appsService.CreateApp(params.Name, params.Pubkey, maxAmountSat, params.BudgetRenewal, expiresAt, scopes, params.Isolated, params.Metadata)
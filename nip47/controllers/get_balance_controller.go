@@ -15,8 +15,15 @@ const (
 	MSAT_PER_SAT = 1000
 )
 
+type getBalanceParams struct {
+	// not part of the NIP-47 spec: also return the balance in the hub's currency
+	IncludeFiat bool `json:"include_fiat"`
+}
+
 type getBalanceResponse struct {
-	Balance int64 `json:"balance"`
+	Balance      int64    `json:"balance"`
+	FiatBalance  *float64 `json:"fiat_balance,omitempty"`
+	FiatCurrency *string  `json:"fiat_currency,omitempty"`
 	// MaxAmount     int    `json:"max_amount"`
 	// BudgetRenewal string `json:"budget_renewal"`
 }
@@ -24,6 +31,16 @@ type getBalanceResponse struct {
 // TODO: remove checkPermission - can it be a middleware?
 func (controller *nip47Controller) HandleGetBalanceEvent(ctx context.Context, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc) {
 
+	getBalanceParams := &getBalanceParams{}
+	// params are optional
+	if len(nip47Request.Params) > 0 {
+		resp := decodeRequest(nip47Request, getBalanceParams)
+		if resp != nil {
+			publishResponse(resp, nostr.Tags{})
+			return
+		}
+	}
+
 	logger.Logger.WithFields(logrus.Fields{
 		"request_event_id": requestEventId,
 	}).Debug("Getting balance")
@@ -50,6 +67,20 @@ func (controller *nip47Controller) HandleGetBalanceEvent(ctx context.Context, ni
 		Balance: balance,
 	}
 
+	if getBalanceParams.IncludeFiat {
+		// the balance is still returned if the rate is unavailable
+		rate, err := controller.albyService.GetBitcoinRate(ctx)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"request_event_id": requestEventId,
+			}).WithError(err).Error("Failed to fetch bitcoin rate")
+		} else {
+			fiatBalance := float64(balance) / MSAT_PER_SAT / 100_000_000 * rate.RateFloat
+			responsePayload.FiatBalance = &fiatBalance
+			responsePayload.FiatCurrency = &rate.Code
+		}
+	}
+
 	// this is not part of the spec and does not seem to be used
 	/*appPermission := db.AppPermission{}
 	controller.db.Where("app_id = ? AND request_method = ?", app.ID, models.PAY_INVOICE_METHOD).First(&appPermission)
@@ -3,16 +3,19 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/getAlby/hub/alby"
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/nip47/models"
 	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/tests/mocks"
 )
 
 const nip47GetBalanceJson = `
@@ -127,3 +130,90 @@ func TestHandleGetBalanceEvent_IsolatedApp_Transactions(t *testing.T) {
 	assert.Equal(t, int64(1000), publishedResponse.Result.(*getBalanceResponse).Balance)
 	assert.Nil(t, publishedResponse.Error)
 }
+
+const nip47GetBalanceFiatJson = `
+{
+	"method": "get_balance",
+	"params": {
+		"include_fiat": true
+	}
+}
+`
+
+func TestHandleGetBalanceEvent_IncludeFiat(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47GetBalanceFiatJson), nip47Request)
+	assert.NoError(t, err)
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	albySvc := mocks.NewMockAlbyService(t)
+	albySvc.On("GetBitcoinRate", ctx).Return(&alby.BitcoinRate{
+		Code:      "EUR",
+		RateFloat: 100_000,
+	}, nil)
+
+	controller := NewTestNip47Controller(svc)
+	controller.albyService = albySvc
+	controller.HandleGetBalanceEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse)
+
+	assert.Nil(t, publishedResponse.Error)
+	balanceResponse := publishedResponse.Result.(*getBalanceResponse)
+	assert.Equal(t, int64(21000), balanceResponse.Balance)
+	// 21 sats at 100k EUR/BTC
+	assert.InDelta(t, 0.021, *balanceResponse.FiatBalance, 0.0000001)
+	assert.Equal(t, "EUR", *balanceResponse.FiatCurrency)
+}
+
+func TestHandleGetBalanceEvent_IncludeFiat_RateUnavailable(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47GetBalanceFiatJson), nip47Request)
+	assert.NoError(t, err)
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	albySvc := mocks.NewMockAlbyService(t)
+	albySvc.On("GetBitcoinRate", ctx).Return(nil, errors.New("rate unavailable"))
+
+	controller := NewTestNip47Controller(svc)
+	controller.albyService = albySvc
+	controller.HandleGetBalanceEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse)
+
+	assert.Nil(t, publishedResponse.Error)
+	balanceResponse := publishedResponse.Result.(*getBalanceResponse)
+	assert.Equal(t, int64(21000), balanceResponse.Balance)
+	assert.Nil(t, balanceResponse.FiatBalance)
+	assert.Nil(t, balanceResponse.FiatCurrency)
+}
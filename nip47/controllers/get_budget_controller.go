@@ -25,11 +25,8 @@ func (controller *nip47Controller) HandleGetBudgetEvent(ctx context.Context, nip
 		"request_event_id": requestEventId,
 	}).Debug("Getting budget")
 
-	appPermission := db.AppPermission{}
-	controller.db.Where("app_id = ? AND scope = ?", app.ID, models.PAY_INVOICE_METHOD).First(&appPermission)
-
-	maxAmount := appPermission.MaxAmountSat
-	if maxAmount == 0 {
+	responsePayload := controller.getBudget(app)
+	if responsePayload == nil {
 		publishResponse(&models.Response{
 			ResultType: nip47Request.Method,
 			Result:     struct{}{},
@@ -37,16 +34,27 @@ func (controller *nip47Controller) HandleGetBudgetEvent(ctx context.Context, nip
 		return
 	}
 
+	publishResponse(&models.Response{
+		ResultType: nip47Request.Method,
+		Result:     responsePayload,
+	}, nostr.Tags{})
+}
+
+// returns nil if the app has no budget
+func (controller *nip47Controller) getBudget(app *db.App) *getBudgetResponse {
+	appPermission := db.AppPermission{}
+	controller.db.Where("app_id = ? AND scope = ?", app.ID, models.PAY_INVOICE_METHOD).First(&appPermission)
+
+	maxAmount := appPermission.MaxAmountSat
+	if maxAmount == 0 {
+		return nil
+	}
+
 	usedBudget := queries.GetBudgetUsageSat(controller.db, &appPermission)
-	responsePayload := &getBudgetResponse{
+	return &getBudgetResponse{
 		TotalBudget:   uint64(maxAmount * 1000),
 		UsedBudget:    usedBudget * 1000,
 		RenewalPeriod: appPermission.BudgetRenewal,
 		RenewsAt:      queries.GetBudgetRenewsAt(appPermission.BudgetRenewal),
 	}
-
-	publishResponse(&models.Response{
-		ResultType: nip47Request.Method,
-		Result:     responsePayload,
-	}, nostr.Tags{})
 }
@@ -23,17 +23,19 @@ type getInfoResponse struct {
 	Notifications    []string    `json:"notifications"`
 	Metadata         interface{} `json:"metadata,omitempty"`
 	LightningAddress *string     `json:"lud16"`
+	// not part of the NIP-47 spec: lets sub-wallets discover their own state
+	Isolated bool               `json:"isolated"`
+	Budget   *getBudgetResponse `json:"budget,omitempty"`
 }
 
 func (controller *nip47Controller) HandleGetInfoEvent(ctx context.Context, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc) {
-	supportedNotifications := []string{}
-	if controller.permissionsService.PermitsNotifications(app) {
-		supportedNotifications = controller.lnClient.GetSupportedNIP47NotificationTypes()
-	}
+	supportedNotifications := controller.permissionsService.GetPermittedNotificationTypes(app, controller.lnClient)
 
 	responsePayload := &getInfoResponse{
 		Methods:       controller.permissionsService.GetPermittedMethods(app, controller.lnClient),
 		Notifications: supportedNotifications,
+		Isolated:      app.Isolated,
+		Budget:        controller.getBudget(app),
 	}
 
 	// basic permissions check
@@ -91,8 +93,8 @@ func (controller *nip47Controller) HandleGetInfoEvent(ctx context.Context, nip47
 			if !app.Isolated {
 				lightningAddress, _ := controller.albyOAuthService.GetLightningAddress()
 				responsePayload.LightningAddress = &lightningAddress
-			} else if metadata["app_store_app_id"] == "uncle_jim" && metadata["lud16"] != nil {
-				lightningAddress := metadata["lud16"].(string)
+			} else if lightningAddress, ok := metadata["lud16"].(string); ok && lightningAddress != "" {
+				// sub-wallets report their own lightning address
 				responsePayload.LightningAddress = &lightningAddress
 			}
 
@@ -351,3 +351,46 @@ func TestHandleGetInfoEvent_WithNotifications(t *testing.T) {
 	assert.Contains(t, nodeInfo.Methods, "get_info")
 	assert.Equal(t, []string{"payment_received", "payment_sent"}, nodeInfo.Notifications)
 }
+
+func TestHandleGetInfoEvent_IsolatedAppWithBudgetAndLightningAddress(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	lightningAddress := "subwallet@getalby.com"
+
+	metadata := map[string]interface{}{
+		"lud16": lightningAddress,
+	}
+
+	svc.Cfg.SetUpdate("LNBackendType", config.LDKBackendType, "")
+	app, _, err := svc.AppsService.CreateApp("test", "", 1000, "monthly", nil, []string{constants.GET_INFO_SCOPE, constants.PAY_INVOICE_SCOPE}, true, metadata)
+	assert.NoError(t, err)
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47GetInfoJson), nip47Request)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	NewTestNip47Controller(svc).
+		HandleGetInfoEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse)
+
+	assert.Nil(t, publishedResponse.Error)
+	nodeInfo := publishedResponse.Result.(*getInfoResponse)
+	assert.True(t, nodeInfo.Isolated)
+	assert.Equal(t, lightningAddress, *nodeInfo.LightningAddress)
+	require.NotNil(t, nodeInfo.Budget)
+	assert.Equal(t, uint64(1000_000), nodeInfo.Budget.TotalBudget)
+	assert.Equal(t, uint64(0), nodeInfo.Budget.UsedBudget)
+	assert.Equal(t, "monthly", nodeInfo.Budget.RenewalPeriod)
+}
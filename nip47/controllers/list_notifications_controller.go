@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
+)
+
+type listNotificationsParams struct {
+	From              uint64   `json:"from,omitempty"`
+	Until             uint64   `json:"until,omitempty"`
+	Limit             uint64   `json:"limit,omitempty"`
+	Offset            uint64   `json:"offset,omitempty"`
+	NotificationTypes []string `json:"notification_types,omitempty"`
+}
+
+type listNotificationsResponseNotification struct {
+	NotificationType string          `json:"notification_type"`
+	Notification     json.RawMessage `json:"notification"`
+	CreatedAt        int64           `json:"created_at"`
+}
+
+type listNotificationsResponse struct {
+	Notifications []listNotificationsResponseNotification `json:"notifications"`
+	TotalCount    uint64                                  `json:"total_count"`
+}
+
+func (controller *nip47Controller) HandleListNotificationsEvent(ctx context.Context, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc) {
+
+	listParams := &listNotificationsParams{}
+	resp := decodeRequest(nip47Request, listParams)
+	if resp != nil {
+		publishResponse(resp, nostr.Tags{})
+		return
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"params":           listParams,
+		"request_event_id": requestEventId,
+	}).Debug("Fetching notifications")
+
+	limit := listParams.Limit
+	maxLimit := uint64(50)
+	if limit == 0 || limit > maxLimit {
+		// make sure a sensible limit is passed
+		limit = maxLimit
+	}
+
+	// only return notification types the app is still allowed to receive
+	notificationTypes := controller.permissionsService.GetPermittedNotificationTypes(app, controller.lnClient)
+	if len(listParams.NotificationTypes) > 0 {
+		requestedNotificationTypes := []string{}
+		for _, notificationType := range listParams.NotificationTypes {
+			if slices.Contains(notificationTypes, notificationType) {
+				requestedNotificationTypes = append(requestedNotificationTypes, notificationType)
+			}
+		}
+		notificationTypes = requestedNotificationTypes
+	}
+
+	query := controller.db.
+		Model(&db.Nip47Notification{}).
+		Where("app_id = ? AND notification_type IN ?", app.ID, notificationTypes)
+
+	if listParams.From > 0 {
+		query = query.Where("created_at >= ?", time.Unix(int64(listParams.From), 0))
+	}
+	if listParams.Until > 0 {
+		query = query.Where("created_at <= ?", time.Unix(int64(listParams.Until), 0))
+	}
+
+	var totalCount int64
+	err := query.Count(&totalCount).Error
+	if err != nil {
+		controller.publishListNotificationsError(nip47Request, requestEventId, listParams, err, publishResponse)
+		return
+	}
+
+	dbNotifications := []db.Nip47Notification{}
+	err = query.
+		Order("created_at ASC, id ASC").
+		Limit(int(limit)).
+		Offset(int(listParams.Offset)).
+		Find(&dbNotifications).Error
+	if err != nil {
+		controller.publishListNotificationsError(nip47Request, requestEventId, listParams, err, publishResponse)
+		return
+	}
+
+	notifications := []listNotificationsResponseNotification{}
+	for _, dbNotification := range dbNotifications {
+		notifications = append(notifications, listNotificationsResponseNotification{
+			NotificationType: dbNotification.NotificationType,
+			Notification:     json.RawMessage(dbNotification.Payload),
+			CreatedAt:        dbNotification.CreatedAt.Unix(),
+		})
+	}
+
+	responsePayload := &listNotificationsResponse{
+		Notifications: notifications,
+		TotalCount:    uint64(totalCount),
+	}
+
+	publishResponse(&models.Response{
+		ResultType: nip47Request.Method,
+		Result:     responsePayload,
+	}, nostr.Tags{})
+}
+
+func (controller *nip47Controller) publishListNotificationsError(nip47Request *models.Request, requestEventId uint, listParams *listNotificationsParams, err error, publishResponse publishFunc) {
+	logger.Logger.WithFields(logrus.Fields{
+		"params":           listParams,
+		"request_event_id": requestEventId,
+	}).WithError(err).Error("Failed to fetch notifications")
+
+	publishResponse(&models.Response{
+		ResultType: nip47Request.Method,
+		Error:      mapNip47Error(err),
+	}, nostr.Tags{})
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestHandleListNotificationsEvent(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		Scope: constants.NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	otherApp, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	for _, dbNotification := range []db.Nip47Notification{
+		// too old
+		{AppId: app.ID, NotificationType: "payment_received", Payload: datatypes.JSON(`{"amount":1000}`), CreatedAt: now.Add(-2 * time.Hour)},
+		{AppId: app.ID, NotificationType: "payment_received", Payload: datatypes.JSON(`{"amount":2000}`), CreatedAt: now.Add(-30 * time.Minute)},
+		{AppId: app.ID, NotificationType: "payment_sent", Payload: datatypes.JSON(`{"amount":3000}`), CreatedAt: now.Add(-20 * time.Minute)},
+		// no longer permitted
+		{AppId: app.ID, NotificationType: constants.SWAP_SUCCEEDED_NOTIFICATION, Payload: datatypes.JSON(`{"swap_id":"abc"}`), CreatedAt: now.Add(-10 * time.Minute)},
+		// other app
+		{AppId: otherApp.ID, NotificationType: "payment_received", Payload: datatypes.JSON(`{"amount":4000}`), CreatedAt: now.Add(-10 * time.Minute)},
+	} {
+		dbNotification.State = constants.NIP47_NOTIFICATION_STATE_PUBLISHED
+		err = svc.DB.Create(&dbNotification).Error
+		assert.NoError(t, err)
+	}
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(fmt.Sprintf(`{"method": "list_notifications", "params": {"from": %d}}`, now.Add(-time.Hour).Unix())), nip47Request)
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	NewTestNip47Controller(svc).
+		HandleListNotificationsEvent(ctx, nip47Request, 0, app, publishResponse)
+
+	require.Nil(t, publishedResponse.Error)
+	result := publishedResponse.Result.(*listNotificationsResponse)
+	assert.Equal(t, uint64(2), result.TotalCount)
+	require.Len(t, result.Notifications, 2)
+	assert.Equal(t, "payment_received", result.Notifications[0].NotificationType)
+	assert.JSONEq(t, `{"amount":2000}`, string(result.Notifications[0].Notification))
+	assert.Equal(t, "payment_sent", result.Notifications[1].NotificationType)
+	assert.JSONEq(t, `{"amount":3000}`, string(result.Notifications[1].Notification))
+}
+
+func TestHandleListNotificationsEvent_FilterByNotificationType(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		Scope: constants.NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	for _, notificationType := range []string{"payment_received", "payment_sent"} {
+		err = svc.DB.Create(&db.Nip47Notification{
+			AppId:            app.ID,
+			NotificationType: notificationType,
+			Payload:          datatypes.JSON(`{}`),
+			State:            constants.NIP47_NOTIFICATION_STATE_PENDING,
+		}).Error
+		assert.NoError(t, err)
+	}
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(`{"method": "list_notifications", "params": {"notification_types": ["payment_sent"]}}`), nip47Request)
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	NewTestNip47Controller(svc).
+		HandleListNotificationsEvent(ctx, nip47Request, 0, app, publishResponse)
+
+	require.Nil(t, publishedResponse.Error)
+	result := publishedResponse.Result.(*listNotificationsResponse)
+	assert.Equal(t, uint64(1), result.TotalCount)
+	assert.Equal(t, "payment_sent", result.Notifications[0].NotificationType)
+}
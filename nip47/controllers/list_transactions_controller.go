@@ -2,9 +2,11 @@ package controllers
 
 import (
 	"context"
+	"strings"
 
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/transactions"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sirupsen/logrus"
 )
@@ -18,11 +20,20 @@ type listTransactionsParams struct {
 	UnpaidOutgoing bool   `json:"unpaid_outgoing,omitempty"`
 	UnpaidIncoming bool   `json:"unpaid_incoming,omitempty"`
 	Type           string `json:"type,omitempty"`
+	// pagination and filters below are hub extensions
+	// pass an empty cursor to start paging by creation time, then next_cursor
+	Cursor    *string           `json:"cursor,omitempty"`
+	State     []string          `json:"state,omitempty"`
+	MinAmount uint64            `json:"min_amount,omitempty"` // msat
+	MaxAmount uint64            `json:"max_amount,omitempty"` // msat
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Search    string            `json:"search,omitempty"`
 }
 
 type listTransactionsResponse struct {
 	Transactions []models.Transaction `json:"transactions"`
 	TotalCount   uint64               `json:"total_count"`
+	NextCursor   string               `json:"next_cursor,omitempty"`
 }
 
 func (controller *nip47Controller) HandleListTransactionsEvent(ctx context.Context, nip47Request *models.Request, requestEventId uint, appId uint, publishResponse publishFunc) {
@@ -50,7 +61,20 @@ func (controller *nip47Controller) HandleListTransactionsEvent(ctx context.Conte
 		transactionType = &listParams.Type
 	}
 
-	dbTransactions, totalCount, err := controller.transactionsService.ListTransactions(ctx, listParams.From, listParams.Until, limit, listParams.Offset, listParams.Unpaid || listParams.UnpaidOutgoing, listParams.Unpaid || listParams.UnpaidIncoming, transactionType, controller.lnClient, &appId, false)
+	states := []string{}
+	for _, state := range listParams.State {
+		states = append(states, strings.ToUpper(state))
+	}
+	filters := transactions.ListTransactionsFilters{
+		Cursor:        listParams.Cursor,
+		States:        states,
+		MinAmountMsat: listParams.MinAmount,
+		MaxAmountMsat: listParams.MaxAmount,
+		Metadata:      listParams.Metadata,
+		Search:        listParams.Search,
+	}
+
+	dbTransactions, totalCount, err := controller.transactionsService.ListTransactions(ctx, listParams.From, listParams.Until, limit, listParams.Offset, listParams.Unpaid || listParams.UnpaidOutgoing, listParams.Unpaid || listParams.UnpaidIncoming, transactionType, controller.lnClient, &appId, false, filters)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"params":           listParams,
@@ -64,15 +88,18 @@ func (controller *nip47Controller) HandleListTransactionsEvent(ctx context.Conte
 		return
 	}
 
-	transactions := []models.Transaction{}
+	nip47Transactions := []models.Transaction{}
 	for _, dbTransaction := range dbTransactions {
-		transactions = append(transactions, *models.ToNip47Transaction(&dbTransaction))
+		nip47Transactions = append(nip47Transactions, *models.ToNip47Transaction(&dbTransaction))
 	}
 
 	responsePayload := &listTransactionsResponse{
-		Transactions: transactions,
+		Transactions: nip47Transactions,
 		TotalCount:   totalCount,
 	}
+	if listParams.Cursor != nil && uint64(len(dbTransactions)) == limit {
+		responsePayload.NextCursor = transactions.NewTransactionsCursor(&dbTransactions[len(dbTransactions)-1])
+	}
 
 	publishResponse(&models.Response{
 		ResultType: nip47Request.Method,
@@ -339,3 +339,65 @@ func TestHandleListTransactionsEvent_Paid(t *testing.T) {
 }
 
 // TODO: add tests for pagination args
+
+func TestHandleListTransactionsEvent_CursorAndState(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+
+	for i, state := range []string{constants.TRANSACTION_STATE_FAILED, constants.TRANSACTION_STATE_FAILED, constants.TRANSACTION_STATE_SETTLED} {
+		err = svc.DB.Create(&db.Transaction{
+			Type:       constants.TRANSACTION_TYPE_OUTGOING,
+			State:      state,
+			AmountMsat: uint64(i+1) * 1000,
+			AppId:      &app.ID,
+			CreatedAt:  time.Now().Add(time.Duration(-i) * time.Minute),
+		}).Error
+		assert.NoError(t, err)
+	}
+
+	var publishedResponse *models.Response
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(`{"method": "list_transactions", "params": {"limit": 1, "state": ["failed"], "cursor": ""}}`), nip47Request)
+	assert.NoError(t, err)
+
+	NewTestNip47Controller(svc).
+		HandleListTransactionsEvent(ctx, nip47Request, 0, app.ID, publishResponse)
+
+	require.Nil(t, publishedResponse.Error)
+	firstPage := publishedResponse.Result.(*listTransactionsResponse)
+	assert.Equal(t, uint64(2), firstPage.TotalCount)
+	require.Len(t, firstPage.Transactions, 1)
+	assert.Equal(t, int64(1000), firstPage.Transactions[0].Amount)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	nip47Request = &models.Request{}
+	err = json.Unmarshal([]byte(`{"method": "list_transactions", "params": {"limit": 1, "state": ["failed"], "cursor": "`+firstPage.NextCursor+`"}}`), nip47Request)
+	assert.NoError(t, err)
+
+	NewTestNip47Controller(svc).
+		HandleListTransactionsEvent(ctx, nip47Request, 0, app.ID, publishResponse)
+
+	require.Nil(t, publishedResponse.Error)
+	secondPage := publishedResponse.Result.(*listTransactionsResponse)
+	require.Len(t, secondPage.Transactions, 1)
+	assert.Equal(t, int64(2000), secondPage.Transactions[0].Amount)
+
+	nip47Request = &models.Request{}
+	err = json.Unmarshal([]byte(`{"method": "list_transactions", "params": {"cursor": "invalid"}}`), nip47Request)
+	assert.NoError(t, err)
+
+	NewTestNip47Controller(svc).
+		HandleListTransactionsEvent(ctx, nip47Request, 0, app.ID, publishResponse)
+
+	require.NotNil(t, publishedResponse.Error)
+	assert.Equal(t, constants.ERROR_BAD_REQUEST, publishedResponse.Error.Code)
+}
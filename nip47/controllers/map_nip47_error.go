@@ -16,6 +16,9 @@ func mapNip47Error(err error) *models.Error {
 	if errors.Is(err, transactions.NewInsufficientBalanceError()) {
 		code = constants.ERROR_INSUFFICIENT_BALANCE
 	}
+	if errors.Is(err, transactions.NewInvalidCursorError()) {
+		code = constants.ERROR_BAD_REQUEST
+	}
 	if errors.Is(err, transactions.NewQuotaExceededError()) {
 		code = constants.ERROR_QUOTA_EXCEEDED
 	}
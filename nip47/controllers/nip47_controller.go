@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/approvals"
 	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
@@ -17,7 +18,9 @@ type nip47Controller struct {
 	permissionsService  permissions.PermissionsService
 	transactionsService transactions.TransactionsService
 	appsService         apps.AppsService
+	albyService         alby.AlbyService
 	albyOAuthService    alby.AlbyOAuthService
+	approvalsService    approvals.ApprovalsService
 }
 
 func NewNip47Controller(
@@ -27,7 +30,9 @@ func NewNip47Controller(
 	permissionsService permissions.PermissionsService,
 	transactionsService transactions.TransactionsService,
 	appsService apps.AppsService,
-	albyOAuthService alby.AlbyOAuthService) *nip47Controller {
+	albyService alby.AlbyService,
+	albyOAuthService alby.AlbyOAuthService,
+	approvalsService approvals.ApprovalsService) *nip47Controller {
 	return &nip47Controller{
 		lnClient:            lnClient,
 		db:                  db,
@@ -35,6 +40,8 @@ func NewNip47Controller(
 		permissionsService:  permissionsService,
 		transactionsService: transactionsService,
 		appsService:         appsService,
+		albyService:         albyService,
 		albyOAuthService:    albyOAuthService,
+		approvalsService:    approvalsService,
 	}
 }
@@ -4,16 +4,23 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/db/queries"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/transactions"
 	"github.com/nbd-wtf/go-nostr"
 	decodepay "github.com/nbd-wtf/ln-decodepay"
 	"github.com/sirupsen/logrus"
 )
 
+// how long a payment which requires approval waits for the owner's decision before
+// the app is asked to retry it. Most apps time out requests after a minute
+var paymentApprovalWaitTimeout = 30 * time.Second
+
 type payInvoiceParams struct {
 	Invoice  string                 `json:"invoice"`
 	Amount   *uint64                `json:"amount"`
@@ -49,17 +56,31 @@ func (controller *nip47Controller) HandlePayInvoiceEvent(ctx context.Context, ni
 		return
 	}
 
-	controller.pay(bolt11, payParams.Amount, payParams.Metadata, &paymentRequest, nip47Request, requestEventId, app, publishResponse, tags)
+	controller.pay(ctx, bolt11, payParams.Amount, payParams.Metadata, &paymentRequest, nip47Request, requestEventId, app, publishResponse, tags)
 }
 
-func (controller *nip47Controller) pay(bolt11 string, amount *uint64, metadata map[string]interface{}, paymentRequest *decodepay.Bolt11, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc, tags nostr.Tags) {
+func (controller *nip47Controller) pay(ctx context.Context, bolt11 string, amount *uint64, metadata map[string]interface{}, paymentRequest *decodepay.Bolt11, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc, tags nostr.Tags) {
 	logger.Logger.WithFields(logrus.Fields{
 		"request_event_id": requestEventId,
 		"app_id":           app.ID,
 		"bolt11":           bolt11,
 	}).Info("Sending payment")
 
-	transaction, err := controller.transactionsService.SendPaymentSync(bolt11, amount, metadata, controller.lnClient, &app.ID, &requestEventId)
+	paymentAmountMsat := uint64(paymentRequest.MSatoshi)
+	if amount != nil && paymentRequest.MSatoshi == 0 {
+		paymentAmountMsat = *amount
+	}
+	paymentApproval := &db.PaymentApproval{
+		PaymentHash:    paymentRequest.PaymentHash,
+		PaymentRequest: bolt11,
+		Description:    paymentRequest.Description,
+		AmountMsat:     paymentAmountMsat,
+	}
+	if !controller.awaitPaymentApproval(ctx, paymentApproval, nip47Request, requestEventId, app, publishResponse, tags) {
+		return
+	}
+
+	transaction, err := controller.transactionsService.SendPaymentSync(ctx, bolt11, amount, metadata, controller.lnClient, &app.ID, &requestEventId)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"request_event_id": requestEventId,
@@ -81,3 +102,121 @@ func (controller *nip47Controller) pay(bolt11 string, amount *uint64, metadata m
 		},
 	}, tags)
 }
+
+// awaitPaymentApproval checks if the app requires approval for this payment and waits
+// a short time for the owner to decide on it. If the payment is still pending the app
+// is asked to retry, and the retry uses the same approval. Returns false if the
+// payment must not be made, in which case a response has already been published.
+func (controller *nip47Controller) awaitPaymentApproval(ctx context.Context, paymentApproval *db.PaymentApproval, nip47Request *models.Request, requestEventId uint, app *db.App, publishResponse publishFunc, tags nostr.Tags) bool {
+	reason := controller.getPaymentApprovalReason(app, paymentApproval.AmountMsat)
+	if reason == "" {
+		return true
+	}
+
+	state, err := controller.requestPaymentApproval(ctx, paymentApproval, nip47Request, requestEventId, app, reason)
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"request_event_id": requestEventId,
+			"app_id":           app.ID,
+		}).WithError(err).Error("Failed to request payment approval")
+		publishResponse(&models.Response{
+			ResultType: nip47Request.Method,
+			Error: &models.Error{
+				Code:    constants.ERROR_INTERNAL,
+				Message: fmt.Sprintf("Failed to request payment approval: %s", err.Error()),
+			},
+		}, tags)
+		return false
+	}
+
+	logger.Logger.WithFields(logrus.Fields{
+		"request_event_id":    requestEventId,
+		"app_id":              app.ID,
+		"payment_approval_id": paymentApproval.ID,
+		"state":               state,
+	}).Info("Payment approval state")
+
+	switch state {
+	case constants.PAYMENT_APPROVAL_STATE_APPROVED:
+		return true
+	case constants.PAYMENT_APPROVAL_STATE_PENDING:
+		publishResponse(&models.Response{
+			ResultType: nip47Request.Method,
+			Error: &models.Error{
+				Code:    constants.ERROR_PENDING_APPROVAL,
+				Message: "The payment is waiting for approval by the wallet owner, retry the payment once it was approved",
+			},
+		}, tags)
+	case constants.PAYMENT_APPROVAL_STATE_DENIED:
+		publishResponse(&models.Response{
+			ResultType: nip47Request.Method,
+			Error: &models.Error{
+				Code:    constants.ERROR_RESTRICTED,
+				Message: "The payment was denied by the wallet owner",
+			},
+		}, tags)
+	default:
+		publishResponse(&models.Response{
+			ResultType: nip47Request.Method,
+			Error: &models.Error{
+				Code:    constants.ERROR_RESTRICTED,
+				Message: "The payment was not approved in time",
+			},
+		}, tags)
+	}
+	return false
+}
+
+// requestPaymentApproval reuses the approval of a retried payment, or requests a new one,
+// and returns its state. An approved payment is claimed by this request
+func (controller *nip47Controller) requestPaymentApproval(ctx context.Context, paymentApproval *db.PaymentApproval, nip47Request *models.Request, requestEventId uint, app *db.App, reason string) (string, error) {
+	existingPaymentApproval, err := controller.approvalsService.FindPaymentApproval(app.ID, paymentApproval.PaymentHash, paymentApproval.Destination, paymentApproval.AmountMsat)
+	if err != nil {
+		return "", err
+	}
+	if existingPaymentApproval != nil {
+		*paymentApproval = *existingPaymentApproval
+	} else {
+		paymentApproval.AppId = app.ID
+		paymentApproval.RequestEventId = requestEventId
+		paymentApproval.Method = nip47Request.Method
+		paymentApproval.Reason = reason
+		if err := controller.approvalsService.RequestPaymentApproval(paymentApproval); err != nil {
+			return "", err
+		}
+	}
+
+	state, err := controller.approvalsService.WaitForDecision(ctx, paymentApproval.ID, paymentApprovalWaitTimeout)
+	if err != nil {
+		return "", err
+	}
+	if state == constants.PAYMENT_APPROVAL_STATE_APPROVED {
+		if err := controller.approvalsService.ClaimApprovedPayment(paymentApproval.ID, requestEventId); err != nil {
+			return "", err
+		}
+	}
+	return state, nil
+}
+
+func (controller *nip47Controller) getPaymentApprovalReason(app *db.App, amountMsat uint64) string {
+	if app.ApprovalThresholdSat > 0 && amountMsat/1000 >= app.ApprovalThresholdSat {
+		return constants.PAYMENT_APPROVAL_REASON_THRESHOLD
+	}
+
+	if app.ApproveOverBudget {
+		var appPermission db.AppPermission
+		result := controller.db.Limit(1).Find(&appPermission, &db.AppPermission{
+			AppId: app.ID,
+			Scope: constants.PAY_INVOICE_SCOPE,
+		})
+		if result.RowsAffected > 0 && appPermission.MaxAmountSat > 0 {
+			amountWithFeeReserve := amountMsat + transactions.CalculateFeeReserveMsat(amountMsat)
+			budgetUsageSat := queries.GetBudgetUsageSat(controller.db, &appPermission)
+			if int(amountWithFeeReserve/1000) > appPermission.MaxAmountSat-int(budgetUsageSat) {
+				return constants.PAYMENT_APPROVAL_REASON_OVER_BUDGET
+			}
+		}
+	}
+
+	return ""
+}
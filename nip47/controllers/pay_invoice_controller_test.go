@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/stretchr/testify/assert"
@@ -224,3 +225,191 @@ func TestHandlePayInvoiceEvent_ExpiredInvoice(t *testing.T) {
 	assert.Equal(t, constants.ERROR_INTERNAL, publishedResponse.Error.Code)
 	assert.Equal(t, "this invoice has expired", publishedResponse.Error.Message)
 }
+
+func TestHandlePayInvoiceEvent_ApprovalThreshold(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	app.ApprovalThresholdSat = 100
+	err = svc.DB.Save(app).Error
+	assert.NoError(t, err)
+
+	appPermission := &db.AppPermission{
+		AppId: app.ID,
+		App:   *app,
+		Scope: constants.PAY_INVOICE_SCOPE,
+	}
+	err = svc.DB.Create(appPermission).Error
+	assert.NoError(t, err)
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47PayInvoiceJson), nip47Request)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	controller := NewTestNip47Controller(svc)
+	done := make(chan struct{})
+	go func() {
+		controller.HandlePayInvoiceEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse, nostr.Tags{})
+		close(done)
+	}()
+
+	var paymentApprovals []db.PaymentApproval
+	require.Eventually(t, func() bool {
+		paymentApprovals, err = controller.approvalsService.ListPendingPaymentApprovals()
+		return err == nil && len(paymentApprovals) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, uint64(123000), paymentApprovals[0].AmountMsat)
+	assert.Equal(t, constants.PAYMENT_APPROVAL_REASON_THRESHOLD, paymentApprovals[0].Reason)
+
+	err = controller.approvalsService.ApprovePayment(paymentApprovals[0].ID)
+	require.NoError(t, err)
+	<-done
+
+	assert.Nil(t, publishedResponse.Error)
+	assert.Equal(t, "123preimage", publishedResponse.Result.(payResponse).Preimage)
+}
+
+func TestHandlePayInvoiceEvent_ApproveOverBudgetDenied(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	app.ApproveOverBudget = true
+	err = svc.DB.Save(app).Error
+	assert.NoError(t, err)
+
+	appPermission := &db.AppPermission{
+		AppId:        app.ID,
+		App:          *app,
+		Scope:        constants.PAY_INVOICE_SCOPE,
+		MaxAmountSat: 10,
+	}
+	err = svc.DB.Create(appPermission).Error
+	assert.NoError(t, err)
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47PayInvoiceJson), nip47Request)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	controller := NewTestNip47Controller(svc)
+	done := make(chan struct{})
+	go func() {
+		controller.HandlePayInvoiceEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse, nostr.Tags{})
+		close(done)
+	}()
+
+	var paymentApprovals []db.PaymentApproval
+	require.Eventually(t, func() bool {
+		paymentApprovals, err = controller.approvalsService.ListPendingPaymentApprovals()
+		return err == nil && len(paymentApprovals) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, constants.PAYMENT_APPROVAL_REASON_OVER_BUDGET, paymentApprovals[0].Reason)
+
+	err = controller.approvalsService.DenyPayment(paymentApprovals[0].ID)
+	require.NoError(t, err)
+	<-done
+
+	assert.Nil(t, publishedResponse.Result)
+	assert.Equal(t, constants.ERROR_RESTRICTED, publishedResponse.Error.Code)
+}
+
+func TestHandlePayInvoiceEvent_ApprovalPendingRetry(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	defaultWaitTimeout := paymentApprovalWaitTimeout
+	paymentApprovalWaitTimeout = 50 * time.Millisecond
+	defer func() {
+		paymentApprovalWaitTimeout = defaultWaitTimeout
+	}()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	app.ApproveOverBudget = true
+	err = svc.DB.Save(app).Error
+	assert.NoError(t, err)
+
+	appPermission := &db.AppPermission{
+		AppId:        app.ID,
+		App:          *app,
+		Scope:        constants.PAY_INVOICE_SCOPE,
+		MaxAmountSat: 10,
+	}
+	err = svc.DB.Create(appPermission).Error
+	assert.NoError(t, err)
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47PayInvoiceJson), nip47Request)
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	controller := NewTestNip47Controller(svc)
+
+	dbRequestEvent := &db.RequestEvent{NostrId: "request"}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+	controller.HandlePayInvoiceEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse, nostr.Tags{})
+
+	assert.Nil(t, publishedResponse.Result)
+	assert.Equal(t, constants.ERROR_PENDING_APPROVAL, publishedResponse.Error.Code)
+
+	paymentApprovals, err := controller.approvalsService.ListPendingPaymentApprovals()
+	require.NoError(t, err)
+	require.Len(t, paymentApprovals, 1)
+	err = controller.approvalsService.ApprovePayment(paymentApprovals[0].ID)
+	require.NoError(t, err)
+
+	// the retry uses the approval of the first request and may exceed the budget
+	retryRequestEvent := &db.RequestEvent{NostrId: "retry"}
+	err = svc.DB.Create(&retryRequestEvent).Error
+	assert.NoError(t, err)
+	controller.HandlePayInvoiceEvent(ctx, nip47Request, retryRequestEvent.ID, app, publishResponse, nostr.Tags{})
+
+	assert.Nil(t, publishedResponse.Error)
+	assert.Equal(t, "123preimage", publishedResponse.Result.(payResponse).Preimage)
+
+	var paymentApproval db.PaymentApproval
+	err = svc.DB.First(&paymentApproval, paymentApprovals[0].ID).Error
+	require.NoError(t, err)
+	assert.Equal(t, constants.PAYMENT_APPROVAL_STATE_USED, paymentApproval.State)
+	assert.Equal(t, retryRequestEvent.ID, paymentApproval.RequestEventId)
+
+	var paymentApprovalCount int64
+	err = svc.DB.Model(&db.PaymentApproval{}).Count(&paymentApprovalCount).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), paymentApprovalCount)
+}
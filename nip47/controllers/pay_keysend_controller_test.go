@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/stretchr/testify/assert"
@@ -122,3 +123,60 @@ func TestHandlePayKeysendEvent_WithPreimage(t *testing.T) {
 	assert.Equal(t, "018465013e2337234a7e5530a21c4a8cf70d84231f4a8ff0b1e2cce3cb2bd03b", publishedResponse.Result.(payResponse).Preimage)
 	assert.Equal(t, uint64(1), publishedResponse.Result.(payResponse).FeesPaid)
 }
+
+func TestHandlePayKeysendEvent_ApprovalThreshold(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	app.ApprovalThresholdSat = 100
+	err = svc.DB.Save(app).Error
+	assert.NoError(t, err)
+
+	appPermission := &db.AppPermission{
+		AppId: app.ID,
+		App:   *app,
+		Scope: constants.PAY_INVOICE_SCOPE,
+	}
+	err = svc.DB.Create(appPermission).Error
+	assert.NoError(t, err)
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(nip47KeysendJson), nip47Request)
+	assert.NoError(t, err)
+
+	dbRequestEvent := &db.RequestEvent{}
+	err = svc.DB.Create(&dbRequestEvent).Error
+	assert.NoError(t, err)
+
+	var publishedResponse *models.Response
+
+	publishResponse := func(response *models.Response, tags nostr.Tags) {
+		publishedResponse = response
+	}
+
+	controller := NewTestNip47Controller(svc)
+	done := make(chan struct{})
+	go func() {
+		controller.HandlePayKeysendEvent(ctx, nip47Request, dbRequestEvent.ID, app, publishResponse, nostr.Tags{})
+		close(done)
+	}()
+
+	var paymentApprovals []db.PaymentApproval
+	require.Eventually(t, func() bool {
+		paymentApprovals, err = controller.approvalsService.ListPendingPaymentApprovals()
+		return err == nil && len(paymentApprovals) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "123pubkey2", paymentApprovals[0].Destination)
+	assert.Equal(t, uint64(123000), paymentApprovals[0].AmountMsat)
+
+	err = controller.approvalsService.ApprovePayment(paymentApprovals[0].ID)
+	require.NoError(t, err)
+	<-done
+
+	assert.Nil(t, publishedResponse.Error)
+	assert.Equal(t, 64, len(publishedResponse.Result.(payResponse).Preimage))
+}
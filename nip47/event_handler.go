@@ -8,6 +8,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
@@ -18,8 +19,11 @@ import (
 	"github.com/getAlby/hub/nip47/models"
 	"github.com/getAlby/hub/nip47/permissions"
 	nostrmodels "github.com/getAlby/hub/nostr/models"
+	"github.com/getAlby/hub/nostr/signer"
+	"github.com/getAlby/hub/tracing"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
@@ -30,6 +34,14 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		"eventKind":           event.Kind,
 	}).Info("Processing Event")
 
+	ctx, span := tracing.StartSpan(ctx, "nip47.HandleEvent",
+		attribute.String("nostr.event_id", event.ID),
+		attribute.Int("nostr.event_kind", event.Kind),
+		// time between the app creating the request and the hub receiving it from the relay
+		attribute.Int64("nip47.request_delay_ms", time.Since(event.CreatedAt.Time()).Milliseconds()),
+	)
+	defer span.End()
+
 	// go-nostr already checks this, but just to be sure:
 	validEventSignature, err := event.CheckSignature()
 	if err != nil {
@@ -64,9 +76,11 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		return
 	}
 	app := db.App{}
-	err = svc.db.First(&app, &db.App{
-		AppPubkey: event.PubKey,
-	}).Error
+	// after a key rotation, the previous keys are served until they expire
+	err = svc.db.
+		Where("app_pubkey = ?", event.PubKey).
+		Or("previous_app_pubkey = ? AND previous_keys_expire_at > ?", event.PubKey, time.Now()).
+		First(&app).Error
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"appPubkey": event.PubKey,
@@ -87,18 +101,27 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		"eventKind":           event.Kind,
 		"appId":               app.ID,
 	}).Debug("App found for nostr event")
-
-	appWalletPrivKey := svc.keys.GetNostrSecretKey()
-
-	if app.WalletPubkey != nil {
-		// This is a new child key derived from master using app ID as index
-		appWalletPrivKey, err = svc.keys.GetAppWalletKey(app.ID)
-		if err != nil {
-			logger.Logger.WithFields(logrus.Fields{
-				"appId": app.ID,
-			}).WithError(err).Error("error deriving child key")
-			return
+	span.SetAttributes(attribute.Int64("app.id", int64(app.ID)))
+
+	var appWalletSigner signer.Signer
+	if event.PubKey == app.AppPubkey {
+		appWalletSigner, err = apps.GetAppWalletSigner(svc.keys, &app)
+		if app.WalletPubkey == nil {
+			// after a hub key rotation, legacy apps are served with the previous hub key until it expires
+			if walletPubkeyTag := event.Tags.Find("p"); walletPubkeyTag != nil {
+				if hubNostrSigner := apps.GetHubNostrSigner(svc.keys, walletPubkeyTag[1]); hubNostrSigner != nil {
+					appWalletSigner = hubNostrSigner
+				}
+			}
 		}
+	} else {
+		appWalletSigner, err = apps.GetPreviousAppWalletSigner(svc.keys, &app)
+	}
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"appId": app.ID,
+		}).WithError(err).Error("error deriving child key")
+		return
 	}
 
 	encryption := constants.ENCRYPTION_TYPE_NIP04
@@ -107,7 +130,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		encryption = encryptionTag[1]
 	}
 
-	nip47Cipher, err := cipher.NewNip47Cipher(encryption, app.AppPubkey, appWalletPrivKey)
+	nip47Cipher, err := cipher.NewNip47CipherWithSigner(encryption, event.PubKey, appWalletSigner)
 	if err != nil {
 		cipherErr := err
 		logger.Logger.WithFields(logrus.Fields{
@@ -129,7 +152,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 
 		// whenever we are unable to handle the request encryption, we always respond with our preferred encryption
 		// re-create the cipher with NIP-44 to send an error response
-		nip47Cipher, err := cipher.NewNip47Cipher(constants.ENCRYPTION_TYPE_NIP44_V2, app.AppPubkey, appWalletPrivKey)
+		nip47Cipher, err := cipher.NewNip47CipherWithSigner(constants.ENCRYPTION_TYPE_NIP44_V2, event.PubKey, appWalletSigner)
 
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
@@ -148,7 +171,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 			},
 		}
 
-		resp, err := svc.CreateResponse(event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletPrivKey)
+		resp, err := svc.CreateResponse(ctx, event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletSigner)
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
 				"requestEventNostrId": event.ID,
@@ -175,7 +198,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 				Message: fmt.Sprintf("Failed to save app to nostr event: %s", err.Error()),
 			},
 		}
-		resp, err := svc.CreateResponse(event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletPrivKey)
+		resp, err := svc.CreateResponse(ctx, event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletSigner)
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
 				"requestEventNostrId": event.ID,
@@ -197,7 +220,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		return
 	}
 
-	payload, err := nip47Cipher.Decrypt(event.Content)
+	payload, err := nip47Cipher.Decrypt(ctx, event.Content)
 	if err != nil {
 		decryptionErr := err
 		logger.Logger.WithFields(logrus.Fields{
@@ -218,7 +241,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 
 		// whenever we are unable to handle the request encryption, we always respond with our preferred encryption
 		// re-create the cipher with NIP-44 to send an error response
-		nip47Cipher, err := cipher.NewNip47Cipher(constants.ENCRYPTION_TYPE_NIP44_V2, app.AppPubkey, appWalletPrivKey)
+		nip47Cipher, err := cipher.NewNip47CipherWithSigner(constants.ENCRYPTION_TYPE_NIP44_V2, event.PubKey, appWalletSigner)
 
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
@@ -237,7 +260,7 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 			},
 		}
 
-		resp, err := svc.CreateResponse(event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletPrivKey)
+		resp, err := svc.CreateResponse(ctx, event, nip47Response, nostr.Tags{}, nip47Cipher, appWalletSigner)
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
 				"requestEventNostrId": event.ID,
@@ -269,16 +292,29 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		return
 	}
 
+	span.SetAttributes(attribute.String("nip47.method", nip47Request.Method))
+
 	// we ignore potential DB errors here as this only saves the method and content data
 	svc.db.Model(&requestEvent).Updates(map[string]interface{}{
 		"method":       nip47Request.Method,
 		"content_data": payload,
 	})
+
+	requestArchive, err := svc.requestArchiveService.ArchiveRequest(&app, requestEvent.ID, event.ID, nip47Request.Method, payload)
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"requestEventNostrId": event.ID,
+			"appId":               app.ID,
+		}).WithError(err).Error("Failed to archive request")
+	}
+
 	// TODO: replace with a channel
 	// TODO: update all previous occurrences of svc.publishResponseEvent to also use the channel
 	publishResponse := func(nip47Response *models.Response, tags nostr.Tags) {
+		publishCtx, publishSpan := tracing.StartSpan(ctx, "nip47.PublishResponse")
 		var state string
-		resp, err := svc.CreateResponse(event, nip47Response, tags, nip47Cipher, appWalletPrivKey)
+		resp, err := svc.CreateResponse(ctx, event, nip47Response, tags, nip47Cipher, appWalletSigner)
+		publishErr := err
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
 				"requestEventNostrId": event.ID,
@@ -287,7 +323,11 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 			}).WithError(err).Error("Failed to create response")
 			state = db.REQUEST_EVENT_STATE_HANDLER_ERROR
 		} else {
-			err = svc.publishResponseEvent(ctx, relay, &requestEvent, resp, &app)
+			if requestArchive != nil {
+				svc.archiveResponse(requestArchive.ID, resp.ID, nip47Response)
+			}
+			err = svc.publishResponseEvent(publishCtx, relay, &requestEvent, resp, &app)
+			publishErr = err
 			if err != nil {
 				logger.Logger.WithFields(logrus.Fields{
 					"requestEventNostrId":  event.ID,
@@ -306,6 +346,8 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 				state = db.REQUEST_EVENT_STATE_HANDLER_EXECUTED
 			}
 		}
+		tracing.EndSpan(publishSpan, publishErr)
+
 		err = svc.db.
 			Model(&requestEvent).
 			Update("state", state).
@@ -383,54 +425,60 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 		}
 	}
 
-	controller := controllers.NewNip47Controller(lnClient, svc.db, svc.eventPublisher, svc.permissionsService, svc.transactionsService, svc.appsService, svc.albyOAuthSvc)
+	controller := controllers.NewNip47Controller(lnClient, svc.db, svc.eventPublisher, svc.permissionsService, svc.transactionsService, svc.appsService, svc.albySvc, svc.albyOAuthSvc, svc.approvalsService)
+
+	controllerCtx, controllerSpan := tracing.StartSpan(ctx, "nip47.controller", attribute.String("nip47.method", nip47Request.Method))
+	defer controllerSpan.End()
 
 	switch nip47Request.Method {
 	case models.MULTI_PAY_INVOICE_METHOD:
 		controller.
-			HandleMultiPayInvoiceEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse)
+			HandleMultiPayInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
 	case models.MULTI_PAY_KEYSEND_METHOD:
 		controller.
-			HandleMultiPayKeysendEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse)
+			HandleMultiPayKeysendEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
 	case models.PAY_INVOICE_METHOD:
 		controller.
-			HandlePayInvoiceEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse, nostr.Tags{})
+			HandlePayInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse, nostr.Tags{})
 	case models.PAY_KEYSEND_METHOD:
 		controller.
-			HandlePayKeysendEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse, nostr.Tags{})
+			HandlePayKeysendEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse, nostr.Tags{})
 	case models.GET_BALANCE_METHOD:
 		controller.
-			HandleGetBalanceEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse)
+			HandleGetBalanceEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
 	case models.GET_BUDGET_METHOD:
 		controller.
-			HandleGetBudgetEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse)
+			HandleGetBudgetEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
 	case models.MAKE_INVOICE_METHOD:
 		controller.
-			HandleMakeInvoiceEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleMakeInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	case models.LOOKUP_INVOICE_METHOD:
 		controller.
-			HandleLookupInvoiceEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleLookupInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	case models.LIST_TRANSACTIONS_METHOD:
 		controller.
-			HandleListTransactionsEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleListTransactionsEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	case models.GET_INFO_METHOD:
 		controller.
-			HandleGetInfoEvent(ctx, nip47Request, requestEvent.ID, &app, publishResponse)
+			HandleGetInfoEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
+	case models.LIST_NOTIFICATIONS_METHOD:
+		controller.
+			HandleListNotificationsEvent(controllerCtx, nip47Request, requestEvent.ID, &app, publishResponse)
 	case models.SIGN_MESSAGE_METHOD:
 		controller.
-			HandleSignMessageEvent(ctx, nip47Request, requestEvent.ID, publishResponse)
+			HandleSignMessageEvent(controllerCtx, nip47Request, requestEvent.ID, publishResponse)
 	case models.CREATE_CONNECTION_METHOD:
 		controller.
-			HandleCreateConnectionEvent(ctx, nip47Request, requestEvent.ID, publishResponse)
+			HandleCreateConnectionEvent(controllerCtx, nip47Request, requestEvent.ID, publishResponse)
 	case models.MAKE_HOLD_INVOICE_METHOD:
 		controller.
-			HandleMakeHoldInvoiceEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleMakeHoldInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	case models.CANCEL_HOLD_INVOICE_METHOD:
 		controller.
-			HandleCancelHoldInvoiceEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleCancelHoldInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	case models.SETTLE_HOLD_INVOICE_METHOD:
 		controller.
-			HandleSettleHoldInvoiceEvent(ctx, nip47Request, requestEvent.ID, app.ID, publishResponse)
+			HandleSettleHoldInvoiceEvent(controllerCtx, nip47Request, requestEvent.ID, app.ID, publishResponse)
 	default:
 		publishResponse(&models.Response{
 			ResultType: nip47Request.Method,
@@ -442,13 +490,26 @@ func (svc *nip47Service) HandleEvent(ctx context.Context, relay nostrmodels.Rela
 	}
 }
 
-func (svc *nip47Service) CreateResponse(initialEvent *nostr.Event, content interface{}, tags nostr.Tags, cipher *cipher.Nip47Cipher, appWalletPrivKey string) (result *nostr.Event, err error) {
+func (svc *nip47Service) archiveResponse(requestArchiveId uint, responseNostrId string, nip47Response *models.Response) {
+	payloadBytes, err := json.Marshal(nip47Response)
+	if err == nil {
+		err = svc.requestArchiveService.ArchiveResponse(requestArchiveId, responseNostrId, string(payloadBytes))
+	}
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"requestArchiveId":     requestArchiveId,
+			"responseEventNostrId": responseNostrId,
+		}).WithError(err).Error("Failed to archive response")
+	}
+}
+
+func (svc *nip47Service) CreateResponse(ctx context.Context, initialEvent *nostr.Event, content interface{}, tags nostr.Tags, cipher *cipher.Nip47Cipher, appWalletSigner signer.Signer) (result *nostr.Event, err error) {
 	payloadBytes, err := json.Marshal(content)
 	if err != nil {
 		return nil, err
 	}
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(ctx, string(payloadBytes))
 	if err != nil {
 		return nil, err
 	}
@@ -456,20 +517,14 @@ func (svc *nip47Service) CreateResponse(initialEvent *nostr.Event, content inter
 	allTags := nostr.Tags{[]string{"p", initialEvent.PubKey}, []string{"e", initialEvent.ID}}
 	allTags = append(allTags, tags...)
 
-	appWalletPubKey, err := nostr.GetPublicKey(appWalletPrivKey)
-	if err != nil {
-		logger.Logger.WithError(err).Error("Error converting nostr privkey to pubkey")
-		return
-	}
-
 	resp := &nostr.Event{
-		PubKey:    appWalletPubKey,
+		PubKey:    appWalletSigner.GetPublicKey(),
 		CreatedAt: nostr.Now(),
 		Kind:      models.RESPONSE_KIND,
 		Tags:      allTags,
 		Content:   msg,
 	}
-	err = resp.Sign(appWalletPrivKey)
+	err = appWalletSigner.SignEvent(ctx, resp)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,165 @@
+package nip47
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/nip47/cipher"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestHandleEvent_RotatedAppKeys(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	doTestHandleEvent_RotatedAppKeys(t, svc, tests.CreateAppWithPrivateKey)
+}
+
+func TestHandleEvent_RotatedLegacyAppKeys(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	doTestHandleEvent_RotatedAppKeys(t, svc, tests.CreateAppWithSharedWalletPubkey)
+}
+
+func doTestHandleEvent_RotatedAppKeys(t *testing.T, svc *tests.TestService, createAppFn tests.CreateAppFn) {
+	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
+	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
+
+	previousPrivateKey := nostr.GeneratePrivateKey()
+	app, previousCipher, err := createAppFn(svc, previousPrivateKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+	previousWalletPubkey := svc.Keys.GetNostrPublicKey()
+	if app.WalletPubkey != nil {
+		previousWalletPubkey = *app.WalletPubkey
+	}
+
+	pairingSecretKey, err := svc.AppsService.RotateAppKeys(app, time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, app.WalletPubkey)
+	assert.NotEqual(t, previousWalletPubkey, *app.WalletPubkey)
+	assert.Equal(t, previousWalletPubkey, app.PreviousWalletPubkey)
+
+	newCipher, err := cipher.NewNip47Cipher(constants.ENCRYPTION_TYPE_NIP44_V2, *app.WalletPubkey, pairingSecretKey)
+	require.NoError(t, err)
+
+	// the new connection is answered with the new wallet key
+	relay := tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequest(t, newCipher, pairingSecretKey), svc.LNClient)
+	require.Len(t, relay.PublishedEvents, 1)
+	assert.Equal(t, *app.WalletPubkey, relay.PublishedEvents[0].PubKey)
+	assertGetInfoResponse(t, newCipher, relay.PublishedEvents[0])
+
+	// the previous connection still works during the grace period
+	relay = tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequest(t, previousCipher, previousPrivateKey), svc.LNClient)
+	require.Len(t, relay.PublishedEvents, 1)
+	assert.Equal(t, previousWalletPubkey, relay.PublishedEvents[0].PubKey)
+	assertGetInfoResponse(t, previousCipher, relay.PublishedEvents[0])
+
+	// the previous connection is rejected once the grace period ended
+	err = svc.DB.Model(&db.App{}).Where("id = ?", app.ID).Update("previous_keys_expire_at", time.Now().Add(-time.Minute)).Error
+	require.NoError(t, err)
+	relay = tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequest(t, previousCipher, previousPrivateKey), svc.LNClient)
+	assert.Empty(t, relay.PublishedEvents)
+}
+
+func TestHandleEvent_RotatedNostrKey(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
+	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
+
+	privateKey := nostr.GeneratePrivateKey()
+	_, previousCipher, err := tests.CreateAppWithSharedWalletPubkey(svc, privateKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+	previousPubkey := svc.Keys.GetNostrPublicKey()
+
+	err = svc.Keys.RotateNostrKey(svc.Cfg, "", time.Hour)
+	require.NoError(t, err)
+	newPubkey := svc.Keys.GetNostrPublicKey()
+	require.NotEqual(t, previousPubkey, newPubkey)
+	newCipher, err := cipher.NewNip47Cipher(constants.ENCRYPTION_TYPE_NIP44_V2, newPubkey, privateKey)
+	require.NoError(t, err)
+
+	// requests to the new hub key are answered with the new key
+	relay := tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequestToWallet(t, newCipher, privateKey, newPubkey), svc.LNClient)
+	require.Len(t, relay.PublishedEvents, 1)
+	assert.Equal(t, newPubkey, relay.PublishedEvents[0].PubKey)
+	assertGetInfoResponse(t, newCipher, relay.PublishedEvents[0])
+
+	// requests to the previous hub key are still answered during the grace period
+	relay = tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequestToWallet(t, previousCipher, privateKey, previousPubkey), svc.LNClient)
+	require.Len(t, relay.PublishedEvents, 1)
+	assert.Equal(t, previousPubkey, relay.PublishedEvents[0].PubKey)
+	assertGetInfoResponse(t, previousCipher, relay.PublishedEvents[0])
+
+	// the previous hub key is not served once it is retired
+	err = svc.Keys.RetirePreviousNostrKey(svc.Cfg)
+	require.NoError(t, err)
+	relay = tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, createGetInfoRequestToWallet(t, previousCipher, privateKey, previousPubkey), svc.LNClient)
+	for _, event := range relay.PublishedEvents {
+		assert.NotEqual(t, previousPubkey, event.PubKey)
+	}
+}
+
+func createGetInfoRequestToWallet(t *testing.T, nip47Cipher *cipher.Nip47Cipher, privateKey string, walletPubkey string) *nostr.Event {
+	reqEvent := createGetInfoRequest(t, nip47Cipher, privateKey)
+	reqEvent.Tags = append(reqEvent.Tags, nostr.Tag{"p", walletPubkey})
+	err := reqEvent.Sign(privateKey)
+	require.NoError(t, err)
+	return reqEvent
+}
+
+func createGetInfoRequest(t *testing.T, nip47Cipher *cipher.Nip47Cipher, privateKey string) *nostr.Event {
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"method": models.GET_INFO_METHOD,
+	})
+	require.NoError(t, err)
+
+	msg, err := nip47Cipher.Encrypt(context.TODO(), string(payloadBytes))
+	require.NoError(t, err)
+
+	pubkey, err := nostr.GetPublicKey(privateKey)
+	require.NoError(t, err)
+
+	reqEvent := &nostr.Event{
+		Kind:      models.REQUEST_KIND,
+		PubKey:    pubkey,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{[]string{"encryption", constants.ENCRYPTION_TYPE_NIP44_V2}},
+		Content:   msg,
+	}
+	err = reqEvent.Sign(privateKey)
+	require.NoError(t, err)
+	return reqEvent
+}
+
+func assertGetInfoResponse(t *testing.T, nip47Cipher *cipher.Nip47Cipher, event *nostr.Event) {
+	decrypted, err := nip47Cipher.Decrypt(context.TODO(), event.Content)
+	require.NoError(t, err)
+
+	response := models.Response{}
+	err = json.Unmarshal([]byte(decrypted), &response)
+	require.NoError(t, err)
+	assert.Nil(t, response.Error)
+	assert.Equal(t, models.GET_INFO_METHOD, response.ResultType)
+}
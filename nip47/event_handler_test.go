@@ -53,7 +53,7 @@ func doTestCreateResponse(t *testing.T, svc *tests.TestService, nip47Encryption
 
 	reqEvent.ID = "12345"
 
-	nip47Cipher, err := cipher.NewNip47Cipher(nip47Encryption, reqPubkey, svc.Keys.GetNostrSecretKey())
+	nip47Cipher, err := cipher.NewNip47CipherWithSigner(nip47Encryption, reqPubkey, svc.Keys.GetNostrSigner())
 	assert.NoError(t, err)
 
 	type dummyResponse struct {
@@ -70,13 +70,13 @@ func doTestCreateResponse(t *testing.T, svc *tests.TestService, nip47Encryption
 	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
 	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
 
-	res, err := nip47svc.CreateResponse(reqEvent, nip47Response, nostr.Tags{}, nip47Cipher, svc.Keys.GetNostrSecretKey())
+	res, err := nip47svc.CreateResponse(context.TODO(), reqEvent, nip47Response, nostr.Tags{}, nip47Cipher, svc.Keys.GetNostrSigner())
 	assert.NoError(t, err)
 	assert.Equal(t, reqPubkey, res.Tags.Find("p")[1])
 	assert.Equal(t, reqEvent.ID, res.Tags.Find("e")[1])
 	assert.Equal(t, svc.Keys.GetNostrPublicKey(), res.PubKey)
 
-	decrypted, err := nip47Cipher.Decrypt(res.Content)
+	decrypted, err := nip47Cipher.Decrypt(context.TODO(), res.Content)
 	assert.NoError(t, err)
 	unmarshalledResponse := models.Response{
 		Result: &dummyResponse{},
@@ -131,7 +131,7 @@ func doTestHandleResponse_WithPermission(t *testing.T, svc *tests.TestService, c
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -156,7 +156,7 @@ func doTestHandleResponse_WithPermission(t *testing.T, svc *tests.TestService, c
 	assert.NotNil(t, relay.PublishedEvents[0])
 	assert.NotEmpty(t, relay.PublishedEvents[0].Content)
 
-	decrypted, err := cipher.Decrypt(relay.PublishedEvents[0].Content)
+	decrypted, err := cipher.Decrypt(context.TODO(), relay.PublishedEvents[0].Content)
 	assert.NoError(t, err)
 
 	type getInfoResult struct {
@@ -220,7 +220,7 @@ func doTestHandleResponse_DuplicateRequest(t *testing.T, svc *tests.TestService,
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -287,7 +287,7 @@ func doTestHandleResponse_NoPermission(t *testing.T, svc *tests.TestService, cre
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -312,7 +312,7 @@ func doTestHandleResponse_NoPermission(t *testing.T, svc *tests.TestService, cre
 	assert.NotNil(t, relay.PublishedEvents[0])
 	assert.NotEmpty(t, relay.PublishedEvents[0].Content)
 
-	decrypted, err := cipher.Decrypt(relay.PublishedEvents[0].Content)
+	decrypted, err := cipher.Decrypt(context.TODO(), relay.PublishedEvents[0].Content)
 	assert.NoError(t, err)
 
 	unmarshalledResponse := models.Response{}
@@ -367,7 +367,7 @@ func doTestHandleResponse_OldRequestForPayment(t *testing.T, svc *tests.TestServ
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -445,7 +445,7 @@ func doTestHandleResponse_IncorrectPubkey(t *testing.T, svc *tests.TestService,
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -497,7 +497,7 @@ func TestHandleResponse_NoApp(t *testing.T) {
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	reqEvent := &nostr.Event{
@@ -554,7 +554,7 @@ func doTestHandleResponse_UnknownEncryptionTag(t *testing.T, svc *tests.TestServ
 	payloadBytes, err := json.Marshal(content)
 	assert.NoError(t, err)
 
-	msg, err := cipher.Encrypt(string(payloadBytes))
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	// don't pass correct encryption
@@ -575,7 +575,7 @@ func doTestHandleResponse_UnknownEncryptionTag(t *testing.T, svc *tests.TestServ
 
 	assert.NotNil(t, relay.PublishedEvents)
 	responseContent := relay.PublishedEvents[0].Content
-	msg, err = cipher.Decrypt(responseContent)
+	msg, err = cipher.Decrypt(context.TODO(), responseContent)
 	assert.NoError(t, err)
 	assert.NotEqual(t, "", msg)
 
@@ -631,7 +631,7 @@ func doTestHandleResponse_EncryptionTagDoesNotMatchPayload(t *testing.T, svc *te
 	// whenever we are unable to handle the request encryption, we always respond with our preferred encryption (NIP44)
 	nip44Cipher, err := cipher.NewNip47Cipher(constants.ENCRYPTION_TYPE_NIP44_V2, *app.WalletPubkey, reqPrivateKey)
 	assert.NoError(t, err)
-	msg, err := reqCipher.Encrypt(string(payloadBytes))
+	msg, err := reqCipher.Encrypt(context.TODO(), string(payloadBytes))
 	assert.NoError(t, err)
 
 	// don't pass correct encryption
@@ -656,7 +656,7 @@ func doTestHandleResponse_EncryptionTagDoesNotMatchPayload(t *testing.T, svc *te
 
 	assert.NotNil(t, relay.PublishedEvents)
 	responseContent := relay.PublishedEvents[0].Content
-	msg, err = nip44Cipher.Decrypt(responseContent)
+	msg, err = nip44Cipher.Decrypt(context.TODO(), responseContent)
 	assert.NoError(t, err)
 	assert.NotEqual(t, "", msg)
 
@@ -0,0 +1,127 @@
+package nip47
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestHandleEvent_Tracing(t *testing.T) {
+	spans, reqEvent, app := handleTracedEvent(t, constants.PAY_INVOICE_SCOPE, models.PAY_INVOICE_METHOD, map[string]interface{}{
+		"invoice": tests.MockLNClientTransaction.Invoice,
+	})
+
+	handleEventSpan, ok := spans["nip47.HandleEvent"]
+	require.True(t, ok)
+	assert.Contains(t, handleEventSpan.Attributes, attribute.String("nostr.event_id", reqEvent.ID))
+	assert.Contains(t, handleEventSpan.Attributes, attribute.String("nip47.method", models.PAY_INVOICE_METHOD))
+	assert.Contains(t, handleEventSpan.Attributes, attribute.Int64("app.id", int64(app.ID)))
+
+	// the payment is traced from the request down to the LN backend
+	assertSpanParents(t, spans, map[string]string{
+		"nip47.controller":             "nip47.HandleEvent",
+		"transactions.SendPaymentSync": "nip47.controller",
+		"transactions.validateCanPay":  "transactions.SendPaymentSync",
+		"lnclient.SendPaymentSync":     "transactions.SendPaymentSync",
+		"nip47.PublishResponse":        "nip47.HandleEvent",
+	})
+	assert.Contains(t, spans["transactions.SendPaymentSync"].Attributes, attribute.String("payment_hash", tests.MockLNClientTransaction.PaymentHash))
+}
+
+func TestHandleEvent_Tracing_GetBalance(t *testing.T) {
+	spans, _, _ := handleTracedEvent(t, constants.GET_BALANCE_SCOPE, models.GET_BALANCE_METHOD, map[string]interface{}{})
+
+	assertSpanParents(t, spans, map[string]string{
+		"nip47.controller":      "nip47.HandleEvent",
+		"lnclient.GetBalances":  "nip47.controller",
+		"nip47.PublishResponse": "nip47.HandleEvent",
+	})
+}
+
+// handleTracedEvent handles a request from a new app with the given scope and
+// returns the recorded spans by name
+func handleTracedEvent(t *testing.T, scope string, method string, params map[string]interface{}) (map[string]tracetest.SpanStub, *nostr.Event, *db.App) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	t.Cleanup(svc.Remove)
+
+	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
+	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
+
+	reqPrivateKey := nostr.GeneratePrivateKey()
+	app, cipher, err := tests.CreateAppWithPrivateKey(svc, reqPrivateKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		App:   *app,
+		Scope: scope,
+	}).Error
+	require.NoError(t, err)
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+	})
+	require.NoError(t, err)
+
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
+	require.NoError(t, err)
+
+	reqPubkey, err := nostr.GetPublicKey(reqPrivateKey)
+	require.NoError(t, err)
+
+	reqEvent := &nostr.Event{
+		Kind:      models.REQUEST_KIND,
+		PubKey:    reqPubkey,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{[]string{"encryption", constants.ENCRYPTION_TYPE_NIP44_V2}},
+		Content:   msg,
+	}
+	err = reqEvent.Sign(reqPrivateKey)
+	require.NoError(t, err)
+
+	relay := tests.NewMockRelay()
+	// the hub wraps the LN client when tracing is enabled
+	nip47svc.HandleEvent(context.TODO(), relay, reqEvent, lnclient.NewTracingLNClient(svc.LNClient))
+	require.Len(t, relay.PublishedEvents, 1)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	return spans, reqEvent, app
+}
+
+// assertSpanParents asserts that each span is a child of its parent span in the same trace
+func assertSpanParents(t *testing.T, spans map[string]tracetest.SpanStub, parentNames map[string]string) {
+	handleEventSpan, ok := spans["nip47.HandleEvent"]
+	require.True(t, ok)
+	for name, parentName := range parentNames {
+		span, ok := spans[name]
+		require.True(t, ok, name)
+		assert.Equal(t, handleEventSpan.SpanContext.TraceID(), span.SpanContext.TraceID(), name)
+		assert.Equal(t, spans[parentName].SpanContext.SpanID(), span.Parent.SpanID(), name)
+	}
+}
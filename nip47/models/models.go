@@ -27,6 +27,7 @@ const (
 	MAKE_HOLD_INVOICE_METHOD   = "make_hold_invoice"
 	CANCEL_HOLD_INVOICE_METHOD = "cancel_hold_invoice"
 	SETTLE_HOLD_INVOICE_METHOD = "settle_hold_invoice"
+	LIST_NOTIFICATIONS_METHOD  = "list_notifications"
 )
 
 type Transaction struct {
@@ -5,15 +5,18 @@ import (
 	"time"
 
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/approvals"
 	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nip47/archive"
 	"github.com/getAlby/hub/nip47/cipher"
 	"github.com/getAlby/hub/nip47/notifications"
 	"github.com/getAlby/hub/nip47/permissions"
 	nostrmodels "github.com/getAlby/hub/nostr/models"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/getAlby/hub/transactions"
 	"github.com/nbd-wtf/go-nostr"
@@ -21,16 +24,20 @@ import (
 )
 
 type nip47Service struct {
-	permissionsService     permissions.PermissionsService
-	transactionsService    transactions.TransactionsService
-	appsService            apps.AppsService
-	albyOAuthSvc           alby.AlbyOAuthService
-	nip47NotificationQueue notifications.Nip47NotificationQueue
-	nip47InfoPublishQueue  *nip47InfoPublishQueue
-	cfg                    config.Config
-	keys                   keys.Keys
-	db                     *gorm.DB
-	eventPublisher         events.EventPublisher
+	permissionsService    permissions.PermissionsService
+	transactionsService   transactions.TransactionsService
+	appsService           apps.AppsService
+	albySvc               alby.AlbyService
+	albyOAuthSvc          alby.AlbyOAuthService
+	approvalsService      approvals.ApprovalsService
+	requestArchiveService archive.RequestArchiveService
+	nip47Notifier         *notifications.Nip47Notifier
+	notificationsPending  chan struct{}
+	nip47InfoPublishQueue *nip47InfoPublishQueue
+	cfg                   config.Config
+	keys                  keys.Keys
+	db                    *gorm.DB
+	eventPublisher        events.EventPublisher
 }
 
 type Nip47Service interface {
@@ -39,61 +46,78 @@ type Nip47Service interface {
 	StartNip47InfoPublisher(relay *nostr.Relay, lnClient lnclient.LNClient)
 	HandleEvent(ctx context.Context, relay nostrmodels.Relay, event *nostr.Event, lnClient lnclient.LNClient)
 	GetNip47Info(ctx context.Context, relay *nostr.Relay, appWalletPubKey string) (*nostr.Event, error)
-	PublishNip47Info(ctx context.Context, relay nostrmodels.Relay, appId uint, appWalletPubKey string, appWalletPrivKey string, lnClient lnclient.LNClient) (*nostr.Event, error)
-	PublishNip47InfoDeletion(ctx context.Context, relay nostrmodels.Relay, appWalletPubKey string, appWalletPrivKey string, infoEventId string) error
-	CreateResponse(initialEvent *nostr.Event, content interface{}, tags nostr.Tags, cipher *cipher.Nip47Cipher, walletPrivKey string) (result *nostr.Event, err error)
-	EnqueueNip47InfoPublishRequest(appId uint, appWalletPubKey, appWalletPrivKey string)
+	PublishNip47Info(ctx context.Context, relay nostrmodels.Relay, appId uint, appWalletSigner signer.Signer, lnClient lnclient.LNClient) (*nostr.Event, error)
+	PublishNip47InfoDeletion(ctx context.Context, relay nostrmodels.Relay, appWalletSigner signer.Signer, infoEventId string) error
+	CreateResponse(ctx context.Context, initialEvent *nostr.Event, content interface{}, tags nostr.Tags, cipher *cipher.Nip47Cipher, appWalletSigner signer.Signer) (result *nostr.Event, err error)
+	EnqueueNip47InfoPublishRequest(appId uint, appWalletSigner signer.Signer)
+	ReplayArchivedRequest(ctx context.Context, nostrId string, lnClient lnclient.LNClient) (*ReplayResult, error)
 }
 
 func NewNip47Service(db *gorm.DB, cfg config.Config, keys keys.Keys, eventPublisher events.EventPublisher, albyOAuthSvc alby.AlbyOAuthService) *nip47Service {
+	permissionsService := permissions.NewPermissionsService(db, eventPublisher)
 	return &nip47Service{
-		nip47NotificationQueue: notifications.NewNip47NotificationQueue(),
-		nip47InfoPublishQueue:  NewNip47InfoPublishQueue(),
-		cfg:                    cfg,
-		db:                     db,
-		permissionsService:     permissions.NewPermissionsService(db, eventPublisher),
-		transactionsService:    transactions.NewTransactionsService(db, eventPublisher),
-		appsService:            apps.NewAppsService(db, eventPublisher, keys, cfg),
-		eventPublisher:         eventPublisher,
-		keys:                   keys,
-		albyOAuthSvc:           albyOAuthSvc,
+		nip47Notifier:         notifications.NewNip47Notifier(nil, db, cfg, keys, permissionsService),
+		notificationsPending:  make(chan struct{}, 1),
+		nip47InfoPublishQueue: NewNip47InfoPublishQueue(),
+		cfg:                   cfg,
+		db:                    db,
+		permissionsService:    permissionsService,
+		transactionsService:   transactions.NewTransactionsService(db, eventPublisher),
+		appsService:           apps.NewAppsService(db, eventPublisher, keys, cfg),
+		eventPublisher:        eventPublisher,
+		keys:                  keys,
+		albySvc:               alby.NewAlbyService(cfg),
+		albyOAuthSvc:          albyOAuthSvc,
+		approvalsService:      approvals.NewApprovalsService(db, cfg, eventPublisher),
+		requestArchiveService: archive.NewRequestArchiveService(db, cfg, keys),
 	}
 }
 
 func (svc *nip47Service) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
-	svc.nip47NotificationQueue.AddToQueue(event)
+	// notifications are persisted immediately and published by the notifier
+	// once the relay connection is online
+	err := svc.nip47Notifier.ConsumeEvent(ctx, event)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("event", event).Error("Failed to save notifications for event")
+		return
+	}
+
+	select {
+	case svc.notificationsPending <- struct{}{}:
+	default:
+		// the notifier has already been signalled
+	}
 }
 
-// The notifier is decoupled from the notification queue
-// so that if Alby Hub disconnects from the relay, it will wait to reconnect
-// to send notifications rather than dropping them
+// The notifier is decoupled from the persisted notifications
+// so that if Alby Hub disconnects from the relay or restarts, it will publish
+// them after reconnecting rather than dropping them
 func (svc *nip47Service) StartNotifier(relay *nostr.Relay) {
 	nip47Notifier := notifications.NewNip47Notifier(relay, svc.db, svc.cfg, svc.keys, svc.permissionsService)
 	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
 		for {
+			err := nip47Notifier.PublishPendingNotifications(relay.Context())
+			if err != nil {
+				logger.Logger.WithError(err).Error("Failed to publish pending notifications")
+			}
+
 			select {
 			case <-relay.Context().Done():
 				// relay disconnected
 				return
-			case event := <-svc.nip47NotificationQueue.Channel():
-				logger.Logger.WithField("event", event).Debug("Consuming event from notification queue")
-				err := nip47Notifier.ConsumeEvent(relay.Context(), event)
-				if err != nil {
-					logger.Logger.WithError(err).WithField("event", event).Error("Failed to consume event from notification queue")
-					// wait and then re-add the item to the queue
-					time.Sleep(5 * time.Second)
-					svc.nip47NotificationQueue.AddToQueue(event)
-				}
+			case <-svc.notificationsPending:
+			case <-ticker.C:
 			}
 		}
 	}()
 }
 
-func (svc *nip47Service) EnqueueNip47InfoPublishRequest(appId uint, appWalletPubKey, appWalletPrivKey string) {
+func (svc *nip47Service) EnqueueNip47InfoPublishRequest(appId uint, appWalletSigner signer.Signer) {
 	svc.nip47InfoPublishQueue.AddToQueue(&Nip47InfoPublishRequest{
-		AppId:            appId,
-		AppWalletPubKey:  appWalletPubKey,
-		AppWalletPrivKey: appWalletPrivKey,
+		AppId:           appId,
+		AppWalletSigner: appWalletSigner,
 	})
 }
 
@@ -105,12 +129,12 @@ func (svc *nip47Service) StartNip47InfoPublisher(relay *nostr.Relay, lnClient ln
 				// relay disconnected
 				return
 			case req := <-svc.nip47InfoPublishQueue.Channel():
-				_, err := svc.PublishNip47Info(relay.Context(), relay, req.AppId, req.AppWalletPubKey, req.AppWalletPrivKey, lnClient)
+				_, err := svc.PublishNip47Info(relay.Context(), relay, req.AppId, req.AppWalletSigner, lnClient)
 				if err != nil {
-					logger.Logger.WithError(err).WithField("wallet_pubkey", req.AppWalletPubKey).Error("Failed to publish NIP47 info from queue")
+					logger.Logger.WithError(err).WithField("wallet_pubkey", req.AppWalletSigner.GetPublicKey()).Error("Failed to publish NIP47 info from queue")
 					// wait and then re-add the item to the queue
 					time.Sleep(5 * time.Second)
-					svc.EnqueueNip47InfoPublishRequest(req.AppId, req.AppWalletPubKey, req.AppWalletPrivKey)
+					svc.EnqueueNip47InfoPublishRequest(req.AppId, req.AppWalletSigner)
 				}
 			}
 		}
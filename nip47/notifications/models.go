@@ -11,6 +11,7 @@ const (
 	PAYMENT_RECEIVED_NOTIFICATION      = "payment_received"
 	PAYMENT_SENT_NOTIFICATION          = "payment_sent"
 	HOLD_INVOICE_ACCEPTED_NOTIFICATION = "hold_invoice_accepted"
+	HOLD_INVOICE_EXPIRING_NOTIFICATION = "hold_invoice_expiring"
 )
 
 type PaymentSentNotification struct {
@@ -24,3 +25,42 @@ type PaymentReceivedNotification struct {
 type HoldInvoiceAcceptedNotification struct {
 	models.Transaction
 }
+
+// sent before an accepted hold invoice that was not settled is canceled by the hub
+type HoldInvoiceExpiringNotification struct {
+	models.Transaction
+	AutoCancelHeight uint32 `json:"auto_cancel_height"`
+}
+
+type ChannelOpenedNotification struct {
+	CounterpartyNodeId string `json:"counterparty_node_id"`
+	Capacity           uint64 `json:"capacity"` // msat
+	Public             bool   `json:"public"`
+	IsOutbound         bool   `json:"is_outbound"`
+}
+
+type ChannelClosedNotification struct {
+	CounterpartyNodeId string `json:"counterparty_node_id"`
+	Reason             string `json:"reason"`
+}
+
+type SwapNotification struct {
+	SwapId        string `json:"swap_id"`
+	Type          string `json:"type"`
+	State         string `json:"state"`
+	SendAmount    uint64 `json:"send_amount"`    // msat
+	ReceiveAmount uint64 `json:"receive_amount"` // msat
+	LockupTxId    string `json:"lockup_tx_id,omitempty"`
+	ClaimTxId     string `json:"claim_tx_id,omitempty"`
+}
+
+type OnchainReceivedNotification struct {
+	TxId   string `json:"txid"`
+	Amount uint64 `json:"amount"` // msat
+}
+
+type BudgetWarningNotification struct {
+	UsedBudget    uint64 `json:"used_budget"`  // msat
+	TotalBudget   uint64 `json:"total_budget"` // msat
+	RenewalPeriod string `json:"renewal_period"`
+}
@@ -4,22 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
+	"time"
 
+	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/nip47/cipher"
 	"github.com/getAlby/hub/nip47/models"
 	"github.com/getAlby/hub/nip47/permissions"
 	nostrmodels "github.com/getAlby/hub/nostr/models"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sirupsen/logrus"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// notifications which could not be published after this many attempts
+// are no longer retried, but can still be fetched with list_notifications
+const maxNotificationPublishAttempts = 20
+
 type Nip47Notifier struct {
 	relay          nostrmodels.Relay
 	cfg            config.Config
@@ -28,6 +38,8 @@ type Nip47Notifier struct {
 	permissionsSvc permissions.PermissionsService
 }
 
+// relay can be nil, in which case notifications are only persisted
+// and published later with PublishPendingNotifications
 func NewNip47Notifier(relay nostrmodels.Relay, db *gorm.DB, cfg config.Config, keys keys.Keys, permissionsSvc permissions.PermissionsService) *Nip47Notifier {
 	return &Nip47Notifier{
 		relay:          relay,
@@ -89,6 +101,136 @@ func (notifier *Nip47Notifier) ConsumeEvent(ctx context.Context, event *events.E
 			Notification:     notification,
 			NotificationType: HOLD_INVOICE_ACCEPTED_NOTIFICATION,
 		}, nostr.Tags{}, dbTransaction.AppId)
+
+	case "nwc_hold_invoice_expiring":
+		properties, ok := event.Properties.(map[string]interface{})
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+		dbTransaction, ok := properties["transaction"].(*db.Transaction)
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event properties to db.Transaction for hold invoice expiring")
+			return errors.New("failed to cast event")
+		}
+
+		notification := HoldInvoiceExpiringNotification{
+			Transaction:      *models.ToNip47Transaction(dbTransaction),
+			AutoCancelHeight: uint32(getUintProperty(properties, "auto_cancel_height")),
+		}
+
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: HOLD_INVOICE_EXPIRING_NOTIFICATION,
+		}, nostr.Tags{}, dbTransaction.AppId)
+
+	case "nwc_channel_ready":
+		properties, ok := event.Properties.(map[string]interface{})
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+
+		notification := ChannelOpenedNotification{
+			CounterpartyNodeId: getStringProperty(properties, "counterparty_node_id"),
+			Capacity:           getUintProperty(properties, "capacity") * 1000,
+			Public:             properties["public"] == true,
+			IsOutbound:         properties["is_outbound"] == true,
+		}
+
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: constants.CHANNEL_OPENED_NOTIFICATION,
+		}, nostr.Tags{}, nil)
+
+	case "nwc_channel_closed":
+		properties, ok := event.Properties.(map[string]interface{})
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+
+		notification := ChannelClosedNotification{
+			CounterpartyNodeId: getStringProperty(properties, "counterparty_node_id"),
+			Reason:             getStringProperty(properties, "reason"),
+		}
+
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: constants.CHANNEL_CLOSED_NOTIFICATION,
+		}, nostr.Tags{}, nil)
+
+	case "nwc_swap_succeeded", "nwc_swap_failed":
+		properties, ok := event.Properties.(map[string]interface{})
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+
+		var swap db.Swap
+		err := notifier.db.Limit(1).Find(&swap, &db.Swap{
+			SwapId: getStringProperty(properties, "swapId"),
+		}).Error
+		if err != nil || swap.ID == 0 {
+			logger.Logger.WithField("event", event).WithError(err).Error("Failed to find swap for notification")
+			return errors.New("failed to find swap")
+		}
+
+		notification := SwapNotification{
+			SwapId:        swap.SwapId,
+			Type:          swap.Type,
+			State:         swap.State,
+			SendAmount:    swap.SendAmount * 1000,
+			ReceiveAmount: swap.ReceiveAmount * 1000,
+			LockupTxId:    swap.LockupTxId,
+			ClaimTxId:     swap.ClaimTxId,
+		}
+
+		notificationType := constants.SWAP_SUCCEEDED_NOTIFICATION
+		if event.Event == "nwc_swap_failed" {
+			notificationType = constants.SWAP_FAILED_NOTIFICATION
+		}
+
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: notificationType,
+		}, nostr.Tags{}, nil)
+
+	case "nwc_onchain_received":
+		onchainTransaction, ok := event.Properties.(*lnclient.OnchainTransaction)
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+
+		notification := OnchainReceivedNotification{
+			TxId:   onchainTransaction.TxId,
+			Amount: onchainTransaction.AmountSat * 1000,
+		}
+
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: constants.ONCHAIN_RECEIVED_NOTIFICATION,
+		}, nostr.Tags{}, nil)
+
+	case "nwc_budget_warning":
+		properties, ok := event.Properties.(map[string]interface{})
+		if !ok {
+			logger.Logger.WithField("event", event).Error("Failed to cast event")
+			return errors.New("failed to cast event")
+		}
+
+		notification := BudgetWarningNotification{
+			UsedBudget:    getUintProperty(properties, "used_budget") * 1000,
+			TotalBudget:   getUintProperty(properties, "total_budget") * 1000,
+			RenewalPeriod: getStringProperty(properties, "renewal_period"),
+		}
+
+		appId := uint(getUintProperty(properties, "id"))
+		notifier.notifySubscribers(ctx, &Notification{
+			Notification:     notification,
+			NotificationType: constants.BUDGET_WARNING_NOTIFICATION,
+		}, nostr.Tags{}, &appId)
 	}
 	return nil
 }
@@ -103,52 +245,215 @@ func (notifier *Nip47Notifier) notifySubscribers(ctx context.Context, notificati
 		return errors.New("failed to list apps")
 	}
 
+	payloadBytes, err := json.Marshal(notification.Notification)
+	if err != nil {
+		logger.Logger.WithField("notification", notification).WithError(err).Error("Failed to stringify notification")
+		return err
+	}
+
+	tagsBytes, err := json.Marshal(tags)
+	if err != nil {
+		logger.Logger.WithField("notification", notification).WithError(err).Error("Failed to stringify notification tags")
+		return err
+	}
+
 	for _, app := range apps {
 		if app.Isolated && (appId == nil || app.ID != *appId) {
 			continue
 		}
 
-		hasPermission, _, _ := notifier.permissionsSvc.HasPermission(&app, constants.NOTIFICATIONS_SCOPE)
-		if !hasPermission {
+		// budget warnings only concern the app which used its budget
+		// and hold invoice expiry warnings the app which has to settle it
+		if (notification.NotificationType == constants.BUDGET_WARNING_NOTIFICATION || notification.NotificationType == HOLD_INVOICE_EXPIRING_NOTIFICATION) && (appId == nil || app.ID != *appId) {
+			continue
+		}
+
+		if !notifier.permissionsSvc.PermitsNotifications(&app, notification.NotificationType) {
 			continue
 		}
 
-		appWalletPrivKey := notifier.keys.GetNostrSecretKey()
-		if app.WalletPubkey != nil {
-			appWalletPrivKey, err = notifier.keys.GetAppWalletKey(app.ID)
-			if err != nil {
-				logger.Logger.WithFields(logrus.Fields{
-					"notification": notification,
-					"appId":        app.ID,
-				}).WithError(err).Error("error deriving child key")
-				return errors.New("failed to derive child key")
-			}
+		// persist the notification first so it is not lost if the relay
+		// is offline or the hub restarts before it could be published
+		dbNotification := db.Nip47Notification{
+			AppId:            app.ID,
+			NotificationType: notification.NotificationType,
+			Payload:          datatypes.JSON(payloadBytes),
+			Tags:             datatypes.JSON(tagsBytes),
+			State:            constants.NIP47_NOTIFICATION_STATE_PENDING,
 		}
+		err = notifier.db.Create(&dbNotification).Error
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"notification": notification,
+				"appId":        app.ID,
+			}).WithError(err).Error("Failed to save notification")
+			return err
+		}
+
+		if notifier.relay == nil {
+			// will be published by PublishPendingNotifications once the relay is online
+			continue
+		}
+
+		dbNotification.App = app
+		// failed notifications stay pending and are retried later
+		_ = notifier.publishNotification(ctx, &dbNotification)
+	}
+	return nil
+}
+
+// PublishPendingNotifications publishes persisted notifications which have not been
+// confirmed by the relay yet, oldest first. A failed notification is retried by the
+// next call and does not hold back the notifications after it.
+func (notifier *Nip47Notifier) PublishPendingNotifications(ctx context.Context) error {
+	if notifier.relay == nil {
+		return errors.New("no relay to publish notifications to")
+	}
+
+	pendingNotifications := []db.Nip47Notification{}
+	err := notifier.db.
+		Preload("App").
+		Where("state = ?", constants.NIP47_NOTIFICATION_STATE_PENDING).
+		Order("id ASC").
+		Find(&pendingNotifications).Error
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to list pending notifications")
+		return err
+	}
+
+	for _, dbNotification := range pendingNotifications {
+		err := notifier.publishNotification(ctx, &dbNotification)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"notificationId": dbNotification.ID,
+				"appId":          dbNotification.AppId,
+			}).WithError(err).Error("Failed to publish pending notification")
+		}
+	}
+	return nil
+}
+
+func (notifier *Nip47Notifier) publishNotification(ctx context.Context, dbNotification *db.Nip47Notification) error {
+	dbNotification.Attempts++
+	err := notifier.publishNotificationEvents(ctx, dbNotification)
+	if err != nil {
+		state := constants.NIP47_NOTIFICATION_STATE_PENDING
+		if dbNotification.Attempts >= maxNotificationPublishAttempts {
+			state = constants.NIP47_NOTIFICATION_STATE_FAILED
+		}
+		updateErr := notifier.db.Model(dbNotification).Updates(map[string]interface{}{
+			"state":      state,
+			"attempts":   dbNotification.Attempts,
+			"last_error": err.Error(),
+		}).Error
+		if updateErr != nil {
+			logger.Logger.WithField("notificationId", dbNotification.ID).WithError(updateErr).Error("Failed to update notification")
+		}
+		return err
+	}
+
+	now := time.Now()
+	err = notifier.db.Model(dbNotification).Updates(map[string]interface{}{
+		"state":        constants.NIP47_NOTIFICATION_STATE_PUBLISHED,
+		"attempts":     dbNotification.Attempts,
+		"published_at": &now,
+	}).Error
+	if err != nil {
+		logger.Logger.WithField("notificationId", dbNotification.ID).WithError(err).Error("Failed to mark notification as published")
+		return err
+	}
+	return nil
+}
+
+func (notifier *Nip47Notifier) publishNotificationEvents(ctx context.Context, dbNotification *db.Nip47Notification) error {
+	app := &dbNotification.App
+	notification := &Notification{
+		Notification:     json.RawMessage(dbNotification.Payload),
+		NotificationType: dbNotification.NotificationType,
+	}
 
-		appWalletPubKey, err := nostr.GetPublicKey(appWalletPrivKey)
+	tags := nostr.Tags{}
+	if len(dbNotification.Tags) > 0 {
+		err := json.Unmarshal(dbNotification.Tags, &tags)
+		if err != nil {
+			logger.Logger.WithField("notificationId", dbNotification.ID).WithError(err).Error("Failed to parse notification tags")
+			return err
+		}
+	}
+
+	appWalletSigner, err := apps.GetAppWalletSigner(notifier.keys, app)
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"notification": notification,
+			"appId":        app.ID,
+		}).WithError(err).Error("error deriving child key")
+		return errors.New("failed to derive child key")
+	}
+
+	publishedVariants := []string{}
+	if len(dbNotification.PublishedVariants) > 0 {
+		err := json.Unmarshal(dbNotification.PublishedVariants, &publishedVariants)
+		if err != nil {
+			logger.Logger.WithField("notificationId", dbNotification.ID).WithError(err).Error("Failed to parse published notification variants")
+			return err
+		}
+	}
+
+	err = notifier.notifyAppPubkey(ctx, dbNotification, app.AppPubkey, notification, tags, appWalletSigner, &publishedVariants)
+	if err != nil {
+		return err
+	}
+
+	// the connection replaced by a key rotation is notified until its keys expire
+	if apps.HasPreviousKeys(app) {
+		previousWalletSigner, err := apps.GetPreviousAppWalletSigner(notifier.keys, app)
 		if err != nil {
 			logger.Logger.WithFields(logrus.Fields{
 				"notification": notification,
 				"appId":        app.ID,
-			}).WithError(err).Error("Failed to calculate app wallet pub key")
-			return errors.New("failed to calculate app wallet pubkey")
+			}).WithError(err).Error("error deriving previous child key")
+			return errors.New("failed to derive previous child key")
+		}
+		err = notifier.notifyAppPubkey(ctx, dbNotification, app.PreviousAppPubkey, notification, tags, previousWalletSigner, &publishedVariants)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyAppPubkey publishes the NIP-04 and NIP-44 variants of the notification which
+// have not been published yet. Each published variant is saved right away, so a
+// failure of one variant does not publish the other one twice on retry.
+func (notifier *Nip47Notifier) notifyAppPubkey(ctx context.Context, dbNotification *db.Nip47Notification, appPubkey string, notification *Notification, tags nostr.Tags, appWalletSigner signer.Signer, publishedVariants *[]string) error {
+	for _, encryption := range []string{constants.ENCRYPTION_TYPE_NIP04, constants.ENCRYPTION_TYPE_NIP44_V2} {
+		variant := encryption + ":" + appPubkey
+		if slices.Contains(*publishedVariants, variant) {
+			continue
+		}
+
+		err := notifier.notifySubscriber(ctx, &dbNotification.App, appPubkey, notification, tags, appWalletSigner, encryption)
+		if err != nil {
+			logger.Logger.WithError(err).WithField("encryption", encryption).Error("failed to notify subscriber")
+			return err
 		}
 
-		err = notifier.notifySubscriber(ctx, &app, notification, tags, appWalletPubKey, appWalletPrivKey, constants.ENCRYPTION_TYPE_NIP04)
+		*publishedVariants = append(*publishedVariants, variant)
+		publishedVariantsBytes, err := json.Marshal(*publishedVariants)
 		if err != nil {
-			logger.Logger.WithError(err).Error("failed to notify subscriber (NIP-04)")
 			return err
 		}
-		err = notifier.notifySubscriber(ctx, &app, notification, tags, appWalletPubKey, appWalletPrivKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+		dbNotification.PublishedVariants = datatypes.JSON(publishedVariantsBytes)
+		err = notifier.db.Model(dbNotification).Update("published_variants", dbNotification.PublishedVariants).Error
 		if err != nil {
-			logger.Logger.WithError(err).Error("failed to notify subscriber (NIP-44)")
+			logger.Logger.WithField("notificationId", dbNotification.ID).WithError(err).Error("Failed to save published notification variant")
 			return err
 		}
 	}
 	return nil
 }
 
-func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App, notification *Notification, tags nostr.Tags, appWalletPubKey, appWalletPrivKey string, encryption string) error {
+func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App, appPubkey string, notification *Notification, tags nostr.Tags, appWalletSigner signer.Signer, encryption string) error {
 	logger.Logger.WithFields(logrus.Fields{
 		"notification": notification,
 		"appId":        app.ID,
@@ -167,7 +472,7 @@ func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App
 		return err
 	}
 
-	nip47Cipher, err := cipher.NewNip47Cipher(encryption, app.AppPubkey, appWalletPrivKey)
+	nip47Cipher, err := cipher.NewNip47CipherWithSigner(encryption, appPubkey, appWalletSigner)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"notification": notification,
@@ -177,7 +482,7 @@ func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App
 		return err
 	}
 
-	msg, err := nip47Cipher.Encrypt(string(payloadBytes))
+	msg, err := nip47Cipher.Encrypt(ctx, string(payloadBytes))
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"notification": notification,
@@ -187,11 +492,11 @@ func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App
 		return err
 	}
 
-	allTags := nostr.Tags{[]string{"p", app.AppPubkey}}
+	allTags := nostr.Tags{[]string{"p", appPubkey}}
 	allTags = append(allTags, tags...)
 
 	event := &nostr.Event{
-		PubKey:    appWalletPubKey,
+		PubKey:    appWalletSigner.GetPublicKey(),
 		CreatedAt: nostr.Now(),
 		Kind:      models.NOTIFICATION_KIND,
 		Tags:      allTags,
@@ -202,7 +507,7 @@ func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App
 		event.Kind = models.LEGACY_NOTIFICATION_KIND
 	}
 
-	err = event.Sign(appWalletPrivKey)
+	err = appWalletSigner.SignEvent(ctx, event)
 	if err != nil {
 		logger.Logger.WithFields(logrus.Fields{
 			"notification": notification,
@@ -227,3 +532,31 @@ func (notifier *Nip47Notifier) notifySubscriber(ctx context.Context, app *db.App
 	}).Debug("Published notification event")
 	return nil
 }
+
+func getStringProperty(properties map[string]interface{}, key string) string {
+	switch value := properties[key].(type) {
+	case string:
+		return value
+	case *string:
+		if value != nil {
+			return *value
+		}
+	}
+	return ""
+}
+
+func getUintProperty(properties map[string]interface{}, key string) uint64 {
+	switch value := properties[key].(type) {
+	case uint:
+		return uint64(value)
+	case uint32:
+		return uint64(value)
+	case uint64:
+		return value
+	case int:
+		return uint64(value)
+	case int64:
+		return uint64(value)
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,22 +15,23 @@ import (
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/nip47/models"
 	"github.com/getAlby/hub/nip47/permissions"
 	"github.com/getAlby/hub/tests"
 )
 
 type mockConsumer struct {
-	nip47NotificationQueue Nip47NotificationQueue
+	channel chan *events.Event
 }
 
-func NewMockConsumer(nip47NotificationQueue Nip47NotificationQueue) *mockConsumer {
+func NewMockConsumer() *mockConsumer {
 	return &mockConsumer{
-		nip47NotificationQueue: nip47NotificationQueue,
+		channel: make(chan *events.Event, 1),
 	}
 }
 
 func (svc *mockConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
-	svc.nip47NotificationQueue.AddToQueue(event)
+	svc.channel <- event
 }
 
 func doTestSendNotificationPaymentReceived(t *testing.T, svc *tests.TestService, createAppFn tests.CreateAppFn, nip47Encryption string) {
@@ -63,8 +65,8 @@ func doTestSendNotificationPaymentReceived(t *testing.T, svc *tests.TestService,
 	err = svc.DB.Create(&initialTransaction).Error
 	assert.NoError(t, err)
 
-	nip47NotificationQueue := NewNip47NotificationQueue()
-	svc.EventPublisher.RegisterSubscriber(NewMockConsumer(nip47NotificationQueue))
+	consumer := NewMockConsumer()
+	svc.EventPublisher.RegisterSubscriber(consumer)
 
 	testEvent := &events.Event{
 		Event:      "nwc_payment_received",
@@ -73,7 +75,7 @@ func doTestSendNotificationPaymentReceived(t *testing.T, svc *tests.TestService,
 
 	svc.EventPublisher.Publish(testEvent)
 
-	receivedEvent := <-nip47NotificationQueue.Channel()
+	receivedEvent := <-consumer.channel
 	assert.Equal(t, testEvent, receivedEvent)
 
 	relay := tests.NewMockRelay()
@@ -93,7 +95,7 @@ func doTestSendNotificationPaymentReceived(t *testing.T, svc *tests.TestService,
 	assert.NotNil(t, publishedEvent)
 	assert.NotEmpty(t, publishedEvent.Content)
 
-	decrypted, err := cipher.Decrypt(publishedEvent.Content)
+	decrypted, err := cipher.Decrypt(context.TODO(), publishedEvent.Content)
 	assert.NoError(t, err)
 	unmarshalledResponse := Notification{
 		Notification: &PaymentReceivedNotification{},
@@ -177,8 +179,8 @@ func doTestSendNotificationPaymentSent(t *testing.T, svc *tests.TestService, cre
 	err = svc.DB.Create(&initialTransaction).Error
 	assert.NoError(t, err)
 
-	nip47NotificationQueue := NewNip47NotificationQueue()
-	svc.EventPublisher.RegisterSubscriber(NewMockConsumer(nip47NotificationQueue))
+	consumer := NewMockConsumer()
+	svc.EventPublisher.RegisterSubscriber(consumer)
 
 	testEvent := &events.Event{
 		Event:      "nwc_payment_sent",
@@ -187,7 +189,7 @@ func doTestSendNotificationPaymentSent(t *testing.T, svc *tests.TestService, cre
 
 	svc.EventPublisher.Publish(testEvent)
 
-	receivedEvent := <-nip47NotificationQueue.Channel()
+	receivedEvent := <-consumer.channel
 	assert.Equal(t, testEvent, receivedEvent)
 
 	relay := tests.NewMockRelay()
@@ -207,7 +209,7 @@ func doTestSendNotificationPaymentSent(t *testing.T, svc *tests.TestService, cre
 	assert.NotNil(t, publishedEvent)
 	assert.NotEmpty(t, publishedEvent.Content)
 
-	decrypted, err := cipher.Decrypt(publishedEvent.Content)
+	decrypted, err := cipher.Decrypt(context.TODO(), publishedEvent.Content)
 	assert.NoError(t, err)
 	unmarshalledResponse := Notification{
 		Notification: &PaymentReceivedNotification{},
@@ -268,8 +270,8 @@ func doTestSendNotificationNoPermission(t *testing.T, svc *tests.TestService) {
 		PaymentHash: tests.MockPaymentHash,
 	})
 
-	nip47NotificationQueue := NewNip47NotificationQueue()
-	svc.EventPublisher.RegisterSubscriber(NewMockConsumer(nip47NotificationQueue))
+	consumer := NewMockConsumer()
+	svc.EventPublisher.RegisterSubscriber(consumer)
 
 	testEvent := &events.Event{
 		Event: "nwc_payment_received",
@@ -280,7 +282,7 @@ func doTestSendNotificationNoPermission(t *testing.T, svc *tests.TestService) {
 
 	svc.EventPublisher.Publish(testEvent)
 
-	receivedEvent := <-nip47NotificationQueue.Channel()
+	receivedEvent := <-consumer.channel
 	assert.Equal(t, testEvent, receivedEvent)
 
 	relay := tests.NewMockRelay()
@@ -310,3 +312,299 @@ func TestSendNotification_SharedWalletPubkey_NoPermission(t *testing.T) {
 	assert.NoError(t, err)
 	doTestSendNotificationNoPermission(t, svc)
 }
+
+func TestSendNotification_ChannelOpened_RequiresChannelNotificationsScope(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	paymentsApp, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: paymentsApp.ID,
+		Scope: constants.NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	channelsApp, cipher, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: channelsApp.ID,
+		Scope: constants.CHANNEL_NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	counterpartyNodeId := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	relay := tests.NewMockRelay()
+	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
+	notifier := NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc)
+	notifier.ConsumeEvent(ctx, &events.Event{
+		Event: "nwc_channel_ready",
+		Properties: map[string]interface{}{
+			"counterparty_node_id": &counterpartyNodeId,
+			"capacity":             uint64(100_000),
+			"public":               false,
+			"is_outbound":          true,
+		},
+	})
+
+	// NIP-04 and NIP-44 notifications for the single subscribed app
+	require.Len(t, relay.PublishedEvents, 2)
+	for _, publishedEvent := range relay.PublishedEvents {
+		assert.Equal(t, channelsApp.AppPubkey, publishedEvent.Tags.GetFirst([]string{"p"}).Value())
+	}
+
+	decrypted, err := cipher.Decrypt(context.TODO(), relay.PublishedEvents[1].Content)
+	assert.NoError(t, err)
+	unmarshalledResponse := Notification{
+		Notification: &ChannelOpenedNotification{},
+	}
+	err = json.Unmarshal([]byte(decrypted), &unmarshalledResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, constants.CHANNEL_OPENED_NOTIFICATION, unmarshalledResponse.NotificationType)
+	notification := unmarshalledResponse.Notification.(*ChannelOpenedNotification)
+	assert.Equal(t, counterpartyNodeId, notification.CounterpartyNodeId)
+	assert.Equal(t, uint64(100_000_000), notification.Capacity)
+	assert.True(t, notification.IsOutbound)
+}
+
+func TestSendNotification_BudgetWarning_OnlyNotifiesOwnApp(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	var apps []*db.App
+	for range 2 {
+		app, _, err := tests.CreateApp(svc)
+		assert.NoError(t, err)
+		err = svc.DB.Create(&db.AppPermission{
+			AppId: app.ID,
+			Scope: constants.BUDGET_NOTIFICATIONS_SCOPE,
+		}).Error
+		assert.NoError(t, err)
+		apps = append(apps, app)
+	}
+
+	relay := tests.NewMockRelay()
+	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
+	notifier := NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc)
+	notifier.ConsumeEvent(ctx, &events.Event{
+		Event: "nwc_budget_warning",
+		Properties: map[string]interface{}{
+			"id":             apps[1].ID,
+			"used_budget":    uint64(8_000),
+			"total_budget":   uint64(10_000),
+			"renewal_period": constants.BUDGET_RENEWAL_MONTHLY,
+		},
+	})
+
+	require.NotEmpty(t, relay.PublishedEvents)
+	for _, publishedEvent := range relay.PublishedEvents {
+		assert.Equal(t, apps[1].AppPubkey, publishedEvent.Tags.GetFirst([]string{"p"}).Value())
+	}
+}
+
+func TestSendNotification_HoldInvoiceExpiring_OnlyNotifiesOwnApp(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	var apps []*db.App
+	for range 2 {
+		app, _, err := tests.CreateApp(svc)
+		assert.NoError(t, err)
+		err = svc.DB.Create(&db.AppPermission{
+			AppId: app.ID,
+			Scope: constants.NOTIFICATIONS_SCOPE,
+		}).Error
+		assert.NoError(t, err)
+		apps = append(apps, app)
+	}
+
+	settleDeadline := uint32(200)
+	relay := tests.NewMockRelay()
+	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
+	notifier := NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc)
+	notifier.ConsumeEvent(ctx, &events.Event{
+		Event: "nwc_hold_invoice_expiring",
+		Properties: map[string]interface{}{
+			"transaction": &db.Transaction{
+				Type:           constants.TRANSACTION_TYPE_INCOMING,
+				State:          constants.TRANSACTION_STATE_ACCEPTED,
+				PaymentHash:    tests.MockPaymentHash,
+				AmountMsat:     123_000,
+				Hold:           true,
+				SettleDeadline: &settleDeadline,
+				AppId:          &apps[0].ID,
+			},
+			"auto_cancel_height": uint32(188),
+		},
+	})
+
+	var notifications []db.Nip47Notification
+	require.NoError(t, svc.DB.Find(&notifications).Error)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, apps[0].ID, notifications[0].AppId)
+	assert.Equal(t, HOLD_INVOICE_EXPIRING_NOTIFICATION, notifications[0].NotificationType)
+	assert.Contains(t, string(notifications[0].Payload), `"auto_cancel_height":188`)
+
+	require.NotEmpty(t, relay.PublishedEvents)
+	for _, publishedEvent := range relay.PublishedEvents {
+		assert.Equal(t, apps[0].AppPubkey, publishedEvent.Tags.GetFirst([]string{"p"}).Value())
+	}
+}
+
+type failingRelay struct{}
+
+func (relay *failingRelay) Publish(ctx context.Context, event nostr.Event) error {
+	return errors.New("relay rejected event")
+}
+
+func TestSendNotification_PersistedAndPublishedWhenRelayOnline(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		Scope: constants.NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	transaction := &db.Transaction{
+		Type:        constants.TRANSACTION_TYPE_INCOMING,
+		PaymentHash: tests.MockPaymentHash,
+		AmountMsat:  123_000,
+		State:       constants.TRANSACTION_STATE_SETTLED,
+	}
+	err = svc.DB.Create(transaction).Error
+	assert.NoError(t, err)
+
+	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
+
+	// no relay connection yet
+	notifier := NewNip47Notifier(nil, svc.DB, svc.Cfg, svc.Keys, permissionsSvc)
+	err = notifier.ConsumeEvent(ctx, &events.Event{
+		Event:      "nwc_payment_received",
+		Properties: transaction,
+	})
+	assert.NoError(t, err)
+
+	var dbNotification db.Nip47Notification
+	err = svc.DB.First(&dbNotification, &db.Nip47Notification{AppId: app.ID}).Error
+	require.NoError(t, err)
+	assert.Equal(t, PAYMENT_RECEIVED_NOTIFICATION, dbNotification.NotificationType)
+	assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PENDING, dbNotification.State)
+	assert.Nil(t, dbNotification.PublishedAt)
+
+	// relay rejects the notification, it stays pending
+	err = NewNip47Notifier(&failingRelay{}, svc.DB, svc.Cfg, svc.Keys, permissionsSvc).PublishPendingNotifications(ctx)
+	assert.NoError(t, err)
+
+	err = svc.DB.First(&dbNotification, dbNotification.ID).Error
+	require.NoError(t, err)
+	assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PENDING, dbNotification.State)
+	assert.Equal(t, uint(1), dbNotification.Attempts)
+	assert.Equal(t, "relay rejected event", dbNotification.LastError)
+
+	// relay confirms the notification
+	relay := tests.NewMockRelay()
+	err = NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc).PublishPendingNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, relay.PublishedEvents, 2)
+
+	err = svc.DB.First(&dbNotification, dbNotification.ID).Error
+	require.NoError(t, err)
+	assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PUBLISHED, dbNotification.State)
+	assert.Equal(t, uint(2), dbNotification.Attempts)
+	assert.NotNil(t, dbNotification.PublishedAt)
+
+	// nothing left to publish
+	relay = tests.NewMockRelay()
+	err = NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc).PublishPendingNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, relay.PublishedEvents)
+}
+
+// nip44Relay accepts only legacy NIP-04 notification events until nip44 is set
+type nip44Relay struct {
+	nip44           bool
+	PublishedEvents []*nostr.Event
+}
+
+func (relay *nip44Relay) Publish(ctx context.Context, event nostr.Event) error {
+	if event.Kind == models.NOTIFICATION_KIND && !relay.nip44 {
+		return errors.New("relay rejected event")
+	}
+	relay.PublishedEvents = append(relay.PublishedEvents, &event)
+	return nil
+}
+
+func TestPublishPendingNotifications_RetriesOnlyFailedVariant(t *testing.T) {
+	ctx := context.TODO()
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		Scope: constants.NOTIFICATIONS_SCOPE,
+	}).Error
+	assert.NoError(t, err)
+
+	permissionsSvc := permissions.NewPermissionsService(svc.DB, svc.EventPublisher)
+	notifier := NewNip47Notifier(nil, svc.DB, svc.Cfg, svc.Keys, permissionsSvc)
+	for _, paymentHash := range []string{"hash1", "hash2"} {
+		transaction := &db.Transaction{
+			Type:        constants.TRANSACTION_TYPE_INCOMING,
+			PaymentHash: paymentHash,
+			AmountMsat:  123_000,
+			State:       constants.TRANSACTION_STATE_SETTLED,
+		}
+		require.NoError(t, svc.DB.Create(transaction).Error)
+		err = notifier.ConsumeEvent(ctx, &events.Event{
+			Event:      "nwc_payment_received",
+			Properties: transaction,
+		})
+		require.NoError(t, err)
+	}
+
+	// the NIP-44 variant fails, every notification is still attempted
+	relay := &nip44Relay{}
+	err = NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc).PublishPendingNotifications(ctx)
+	assert.NoError(t, err)
+	require.Len(t, relay.PublishedEvents, 2)
+	for _, publishedEvent := range relay.PublishedEvents {
+		assert.Equal(t, models.LEGACY_NOTIFICATION_KIND, publishedEvent.Kind)
+	}
+
+	var dbNotifications []db.Nip47Notification
+	require.NoError(t, svc.DB.Order("id").Find(&dbNotifications).Error)
+	require.Len(t, dbNotifications, 2)
+	for _, dbNotification := range dbNotifications {
+		assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PENDING, dbNotification.State)
+		assert.JSONEq(t, `["nip04:`+app.AppPubkey+`"]`, string(dbNotification.PublishedVariants))
+	}
+
+	// the retry only publishes the NIP-44 variant
+	relay = &nip44Relay{nip44: true}
+	err = NewNip47Notifier(relay, svc.DB, svc.Cfg, svc.Keys, permissionsSvc).PublishPendingNotifications(ctx)
+	assert.NoError(t, err)
+	require.Len(t, relay.PublishedEvents, 2)
+	for _, publishedEvent := range relay.PublishedEvents {
+		assert.Equal(t, models.NOTIFICATION_KIND, publishedEvent.Kind)
+	}
+
+	require.NoError(t, svc.DB.Order("id").Find(&dbNotifications).Error)
+	for _, dbNotification := range dbNotifications {
+		assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PUBLISHED, dbNotification.State)
+	}
+}
@@ -25,7 +25,8 @@ type permissionsService struct {
 type PermissionsService interface {
 	HasPermission(app *db.App, requestMethod string) (result bool, code string, message string)
 	GetPermittedMethods(app *db.App, lnClient lnclient.LNClient) []string
-	PermitsNotifications(app *db.App) bool
+	PermitsNotifications(app *db.App, notificationType string) bool
+	GetPermittedNotificationTypes(app *db.App, lnClient lnclient.LNClient) []string
 }
 
 func NewPermissionsService(db *gorm.DB, eventPublisher events.EventPublisher) *permissionsService {
@@ -80,7 +81,7 @@ func (svc *permissionsService) GetPermittedMethods(app *db.App, lnClient lnclien
 	lnClientSupportedMethods := lnClient.GetSupportedNIP47Methods()
 	requestMethods = utils.Filter(requestMethods, func(requestMethod string) bool {
 		// TODO: better way to exclude methods unrelated to the lnclient
-		if requestMethod == models.CREATE_CONNECTION_METHOD {
+		if requestMethod == models.CREATE_CONNECTION_METHOD || requestMethod == models.LIST_NOTIFICATIONS_METHOD {
 			return true
 		}
 
@@ -90,14 +91,64 @@ func (svc *permissionsService) GetPermittedMethods(app *db.App, lnClient lnclien
 	return requestMethods
 }
 
-func (svc *permissionsService) PermitsNotifications(app *db.App) bool {
-	notificationPermission := db.AppPermission{}
-	err := svc.db.First(&notificationPermission, &db.AppPermission{
-		AppId: app.ID,
-		Scope: constants.NOTIFICATIONS_SCOPE,
-	}).Error
+// each notification type is gated by the notification scope it belongs to
+func (svc *permissionsService) PermitsNotifications(app *db.App, notificationType string) bool {
+	hasPermission, _, _ := svc.HasPermission(app, NotificationTypeToScope(notificationType))
+	return hasPermission
+}
+
+func (svc *permissionsService) GetPermittedNotificationTypes(app *db.App, lnClient lnclient.LNClient) []string {
+	notificationTypes := []string{}
+	for _, notificationType := range GetSupportedNotificationTypes(lnClient) {
+		if svc.PermitsNotifications(app, notificationType) {
+			notificationTypes = append(notificationTypes, notificationType)
+		}
+	}
+	return notificationTypes
+}
 
-	return err == nil
+// returns the request methods supported by the LNClient
+// plus the request methods implemented by the hub itself
+func GetSupportedMethods(lnClient lnclient.LNClient) []string {
+	return slices.Concat(lnClient.GetSupportedNIP47Methods(), []string{
+		models.LIST_NOTIFICATIONS_METHOD,
+	})
+}
+
+// returns the notification types supported by the LNClient
+// plus the notification types emitted by the hub itself
+func GetSupportedNotificationTypes(lnClient lnclient.LNClient) []string {
+	return slices.Concat(lnClient.GetSupportedNIP47NotificationTypes(), []string{
+		constants.SWAP_SUCCEEDED_NOTIFICATION,
+		constants.SWAP_FAILED_NOTIFICATION,
+		constants.BUDGET_WARNING_NOTIFICATION,
+	})
+}
+
+func NotificationTypeToScope(notificationType string) string {
+	switch notificationType {
+	case constants.CHANNEL_OPENED_NOTIFICATION, constants.CHANNEL_CLOSED_NOTIFICATION:
+		return constants.CHANNEL_NOTIFICATIONS_SCOPE
+	case constants.SWAP_SUCCEEDED_NOTIFICATION, constants.SWAP_FAILED_NOTIFICATION:
+		return constants.SWAP_NOTIFICATIONS_SCOPE
+	case constants.ONCHAIN_RECEIVED_NOTIFICATION:
+		return constants.ONCHAIN_NOTIFICATIONS_SCOPE
+	case constants.BUDGET_WARNING_NOTIFICATION:
+		return constants.BUDGET_NOTIFICATIONS_SCOPE
+	}
+	// payment and hold invoice notifications
+	return constants.NOTIFICATIONS_SCOPE
+}
+
+func NotificationTypesToScopes(notificationTypes []string) []string {
+	scopes := []string{}
+	for _, notificationType := range notificationTypes {
+		scope := NotificationTypeToScope(notificationType)
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
 }
 
 func scopesToRequestMethods(scopes []string) []string {
@@ -153,7 +204,7 @@ func RequestMethodToScope(requestMethod string) (string, error) {
 		return constants.PAY_INVOICE_SCOPE, nil
 	case models.GET_BALANCE_METHOD:
 		return constants.GET_BALANCE_SCOPE, nil
-	case models.GET_BUDGET_METHOD:
+	case models.GET_BUDGET_METHOD, models.LIST_NOTIFICATIONS_METHOD:
 		return "", nil
 	case models.GET_INFO_METHOD:
 		return constants.GET_INFO_SCOPE, nil
@@ -184,10 +235,15 @@ func AllScopes() []string {
 		constants.LIST_TRANSACTIONS_SCOPE,
 		constants.SIGN_MESSAGE_SCOPE,
 		constants.NOTIFICATIONS_SCOPE,
+		constants.CHANNEL_NOTIFICATIONS_SCOPE,
+		constants.SWAP_NOTIFICATIONS_SCOPE,
+		constants.ONCHAIN_NOTIFICATIONS_SCOPE,
+		constants.BUDGET_NOTIFICATIONS_SCOPE,
 		constants.SUPERUSER_SCOPE,
 	}
 }
 
 func GetAlwaysGrantedMethods() []string {
-	return []string{models.GET_INFO_METHOD, models.GET_BUDGET_METHOD}
+	// list_notifications only returns notifications the app is permitted to receive
+	return []string{models.GET_INFO_METHOD, models.GET_BUDGET_METHOD, models.LIST_NOTIFICATIONS_METHOD}
 }
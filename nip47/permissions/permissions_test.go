@@ -162,3 +162,45 @@ func TestGetPermittedMethods_PayInvoiceScopeGivesAllPaymentMethods(t *testing.T)
 	assert.Contains(t, result, models.MULTI_PAY_INVOICE_METHOD)
 	assert.Contains(t, result, models.MULTI_PAY_KEYSEND_METHOD)
 }
+
+func TestNotificationTypeToScope(t *testing.T) {
+	assert.Equal(t, constants.NOTIFICATIONS_SCOPE, NotificationTypeToScope("payment_received"))
+	assert.Equal(t, constants.NOTIFICATIONS_SCOPE, NotificationTypeToScope("hold_invoice_accepted"))
+	assert.Equal(t, constants.CHANNEL_NOTIFICATIONS_SCOPE, NotificationTypeToScope(constants.CHANNEL_OPENED_NOTIFICATION))
+	assert.Equal(t, constants.SWAP_NOTIFICATIONS_SCOPE, NotificationTypeToScope(constants.SWAP_FAILED_NOTIFICATION))
+	assert.Equal(t, constants.ONCHAIN_NOTIFICATIONS_SCOPE, NotificationTypeToScope(constants.ONCHAIN_RECEIVED_NOTIFICATION))
+	assert.Equal(t, constants.BUDGET_NOTIFICATIONS_SCOPE, NotificationTypeToScope(constants.BUDGET_WARNING_NOTIFICATION))
+}
+
+func TestNotificationTypesToScopes_Unique(t *testing.T) {
+	scopes := NotificationTypesToScopes([]string{
+		"payment_received",
+		"payment_sent",
+		constants.CHANNEL_OPENED_NOTIFICATION,
+		constants.CHANNEL_CLOSED_NOTIFICATION,
+	})
+	assert.Equal(t, []string{constants.NOTIFICATIONS_SCOPE, constants.CHANNEL_NOTIFICATIONS_SCOPE}, scopes)
+}
+
+func TestGetPermittedNotificationTypes(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	assert.NoError(t, err)
+
+	permissionsSvc := NewPermissionsService(svc.DB, svc.EventPublisher)
+	assert.Empty(t, permissionsSvc.GetPermittedNotificationTypes(app, svc.LNClient))
+
+	appPermission := &db.AppPermission{
+		AppId: app.ID,
+		App:   *app,
+		Scope: constants.SWAP_NOTIFICATIONS_SCOPE,
+	}
+	err = svc.DB.Create(appPermission).Error
+	assert.NoError(t, err)
+
+	result := permissionsSvc.GetPermittedNotificationTypes(app, svc.LNClient)
+	assert.Equal(t, []string{constants.SWAP_SUCCEEDED_NOTIFICATION, constants.SWAP_FAILED_NOTIFICATION}, result)
+}
@@ -6,20 +6,22 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/getAlby/hub/apps"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/nip47/cipher"
 	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/nip47/permissions"
 	nostrmodels "github.com/getAlby/hub/nostr/models"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/sirupsen/logrus"
 )
 
 type Nip47InfoPublishRequest struct {
-	AppId            uint
-	AppWalletPubKey  string
-	AppWalletPrivKey string
+	AppId           uint
+	AppWalletSigner signer.Signer
 }
 
 type nip47InfoPublishQueue struct {
@@ -62,15 +64,16 @@ func (svc *nip47Service) GetNip47Info(ctx context.Context, relay *nostr.Relay, a
 	return events[0], nil
 }
 
-func (svc *nip47Service) PublishNip47Info(ctx context.Context, relay nostrmodels.Relay, appId uint, appWalletPubKey string, appWalletPrivKey string, lnClient lnclient.LNClient) (*nostr.Event, error) {
+func (svc *nip47Service) PublishNip47Info(ctx context.Context, relay nostrmodels.Relay, appId uint, appWalletSigner signer.Signer, lnClient lnclient.LNClient) (*nostr.Event, error) {
+	appWalletPubKey := appWalletSigner.GetPublicKey()
 	var capabilities []string
-	var permitsNotifications bool
+	var notificationTypes []string
 	tags := nostr.Tags{[]string{"encryption", cipher.SUPPORTED_ENCRYPTIONS}}
 
-	if svc.keys.GetNostrPublicKey() == appWalletPubKey {
-		// legacy app, so return lnClient.GetSupportedNIP47Methods()
-		capabilities = lnClient.GetSupportedNIP47Methods()
-		permitsNotifications = true
+	if apps.GetHubNostrSigner(svc.keys, appWalletPubKey) != nil {
+		// legacy app, so return all supported methods
+		capabilities = permissions.GetSupportedMethods(lnClient)
+		notificationTypes = permissions.GetSupportedNotificationTypes(lnClient)
 	} else {
 		app := db.App{}
 		err := svc.db.First(&app, &db.App{
@@ -83,14 +86,14 @@ func (svc *nip47Service) PublishNip47Info(ctx context.Context, relay nostrmodels
 			return nil, err
 		}
 		capabilities = svc.permissionsService.GetPermittedMethods(&app, lnClient)
-		permitsNotifications = svc.permissionsService.PermitsNotifications(&app)
+		notificationTypes = svc.permissionsService.GetPermittedNotificationTypes(&app, lnClient)
 
 		// NWA: associate the info event with the app so that the app can receive the wallet pubkey
 		tags = append(tags, []string{"p", app.AppPubkey})
 	}
-	if permitsNotifications && len(lnClient.GetSupportedNIP47NotificationTypes()) > 0 {
+	if len(notificationTypes) > 0 {
 		capabilities = append(capabilities, "notifications")
-		tags = append(tags, []string{"notifications", strings.Join(lnClient.GetSupportedNIP47NotificationTypes(), " ")})
+		tags = append(tags, []string{"notifications", strings.Join(notificationTypes, " ")})
 	}
 
 	ev := &nostr.Event{}
@@ -99,7 +102,7 @@ func (svc *nip47Service) PublishNip47Info(ctx context.Context, relay nostrmodels
 	ev.CreatedAt = nostr.Now()
 	ev.PubKey = appWalletPubKey
 	ev.Tags = tags
-	err := ev.Sign(appWalletPrivKey)
+	err := appWalletSigner.SignEvent(ctx, ev)
 	if err != nil {
 		return nil, err
 	}
@@ -111,14 +114,14 @@ func (svc *nip47Service) PublishNip47Info(ctx context.Context, relay nostrmodels
 	return ev, nil
 }
 
-func (svc *nip47Service) PublishNip47InfoDeletion(ctx context.Context, relay nostrmodels.Relay, appWalletPubKey string, appWalletPrivKey string, infoEventId string) error {
+func (svc *nip47Service) PublishNip47InfoDeletion(ctx context.Context, relay nostrmodels.Relay, appWalletSigner signer.Signer, infoEventId string) error {
 	ev := &nostr.Event{}
 	ev.Kind = nostr.KindDeletion
 	ev.Content = "deleting nip47 info since app connection for this key was deleted"
 	ev.Tags = nostr.Tags{[]string{"e", infoEventId}, []string{"k", strconv.Itoa(models.INFO_EVENT_KIND)}}
 	ev.CreatedAt = nostr.Now()
-	ev.PubKey = appWalletPubKey
-	err := ev.Sign(appWalletPrivKey)
+	ev.PubKey = appWalletSigner.GetPublicKey()
+	err := appWalletSigner.SignEvent(ctx, ev)
 	if err != nil {
 		return err
 	}
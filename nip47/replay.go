@@ -0,0 +1,101 @@
+package nip47
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/nip47/controllers"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/nip47/permissions"
+)
+
+type ReplayResult struct {
+	Method string
+	// permission check against the current app permissions
+	PermissionGranted bool
+	PermissionCode    string
+	PermissionMessage string
+	// only methods without side effects are executed
+	Executed  bool
+	Responses []*models.Response
+}
+
+// methods which do not move funds or create invoices and therefore can be replayed
+var replayableMethods = []string{
+	models.GET_INFO_METHOD,
+	models.GET_BALANCE_METHOD,
+	models.GET_BUDGET_METHOD,
+	models.LOOKUP_INVOICE_METHOD,
+	models.LIST_TRANSACTIONS_METHOD,
+}
+
+// ReplayArchivedRequest re-runs an archived request in dry-run mode: the
+// responses are returned rather than published to the app
+func (svc *nip47Service) ReplayArchivedRequest(ctx context.Context, nostrId string, lnClient lnclient.LNClient) (*ReplayResult, error) {
+	archivedRequest, err := svc.requestArchiveService.GetArchivedRequest(nostrId)
+	if err != nil {
+		return nil, err
+	}
+
+	var app db.App
+	err = svc.db.First(&app, archivedRequest.AppId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	nip47Request := &models.Request{}
+	err = json.Unmarshal([]byte(archivedRequest.Payload), nip47Request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse archived request: %w", err)
+	}
+
+	result := &ReplayResult{
+		Method:            nip47Request.Method,
+		PermissionGranted: true,
+		Responses:         []*models.Response{},
+	}
+
+	if !slices.Contains(permissions.GetAlwaysGrantedMethods(), nip47Request.Method) {
+		scope, err := permissions.RequestMethodToScope(nip47Request.Method)
+		if err != nil {
+			return nil, err
+		}
+		result.PermissionGranted, result.PermissionCode, result.PermissionMessage = svc.permissionsService.HasPermission(&app, scope)
+	}
+
+	if !result.PermissionGranted || !slices.Contains(replayableMethods, nip47Request.Method) {
+		return result, nil
+	}
+
+	if lnClient == nil {
+		return nil, fmt.Errorf("LNClient not started")
+	}
+
+	collectResponse := func(nip47Response *models.Response, tags nostr.Tags) {
+		result.Responses = append(result.Responses, nip47Response)
+	}
+
+	controller := controllers.NewNip47Controller(lnClient, svc.db, svc.eventPublisher, svc.permissionsService, svc.transactionsService, svc.appsService, svc.albySvc, svc.albyOAuthSvc, svc.approvalsService)
+
+	switch nip47Request.Method {
+	case models.GET_INFO_METHOD:
+		controller.HandleGetInfoEvent(ctx, nip47Request, archivedRequest.RequestEventId, &app, collectResponse)
+	case models.GET_BALANCE_METHOD:
+		controller.HandleGetBalanceEvent(ctx, nip47Request, archivedRequest.RequestEventId, &app, collectResponse)
+	case models.GET_BUDGET_METHOD:
+		controller.HandleGetBudgetEvent(ctx, nip47Request, archivedRequest.RequestEventId, &app, collectResponse)
+	case models.LOOKUP_INVOICE_METHOD:
+		controller.HandleLookupInvoiceEvent(ctx, nip47Request, archivedRequest.RequestEventId, app.ID, collectResponse)
+	case models.LIST_TRANSACTIONS_METHOD:
+		controller.HandleListTransactionsEvent(ctx, nip47Request, archivedRequest.RequestEventId, app.ID, collectResponse)
+	}
+	result.Executed = true
+
+	return result, nil
+}
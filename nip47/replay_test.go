@@ -0,0 +1,129 @@
+package nip47
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/nip47/models"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestHandleEvent_ArchiveAndReplay(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
+	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
+
+	reqPrivateKey := nostr.GeneratePrivateKey()
+	reqPubkey, err := nostr.GetPublicKey(reqPrivateKey)
+	require.NoError(t, err)
+
+	app, cipher, err := tests.CreateAppWithPrivateKey(svc, reqPrivateKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+	err = svc.DB.Model(app).Update("archive_requests", true).Error
+	require.NoError(t, err)
+
+	err = svc.DB.Create(&db.AppPermission{
+		AppId: app.ID,
+		App:   *app,
+		Scope: constants.GET_BALANCE_SCOPE,
+	}).Error
+	require.NoError(t, err)
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"method": models.GET_BALANCE_METHOD,
+	})
+	require.NoError(t, err)
+
+	msg, err := cipher.Encrypt(context.TODO(), string(payloadBytes))
+	require.NoError(t, err)
+
+	reqEvent := &nostr.Event{
+		Kind:      models.REQUEST_KIND,
+		PubKey:    reqPubkey,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{[]string{"encryption", constants.ENCRYPTION_TYPE_NIP44_V2}},
+		Content:   msg,
+	}
+	err = reqEvent.Sign(reqPrivateKey)
+	require.NoError(t, err)
+
+	relay := tests.NewMockRelay()
+	nip47svc.HandleEvent(context.TODO(), relay, reqEvent, svc.LNClient)
+	require.Len(t, relay.PublishedEvents, 1)
+
+	// payloads are encrypted at rest
+	var requestArchive db.RequestArchive
+	err = svc.DB.Preload("Responses").Where("nostr_id = ?", reqEvent.ID).First(&requestArchive).Error
+	require.NoError(t, err)
+	assert.Equal(t, models.GET_BALANCE_METHOD, requestArchive.Method)
+	assert.NotContains(t, requestArchive.EncryptedPayload, models.GET_BALANCE_METHOD)
+	require.Len(t, requestArchive.Responses, 1)
+	assert.Equal(t, relay.PublishedEvents[0].ID, requestArchive.Responses[0].NostrId)
+
+	archivedRequest, err := nip47svc.requestArchiveService.GetArchivedRequest(reqEvent.ID)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(payloadBytes), archivedRequest.Payload)
+	require.Len(t, archivedRequest.Responses, 1)
+	decrypted, err := cipher.Decrypt(context.TODO(), relay.PublishedEvents[0].Content)
+	require.NoError(t, err)
+	assert.JSONEq(t, decrypted, archivedRequest.Responses[0].Payload)
+
+	replayResult, err := nip47svc.ReplayArchivedRequest(context.TODO(), reqEvent.ID, svc.LNClient)
+	require.NoError(t, err)
+	assert.True(t, replayResult.PermissionGranted)
+	assert.True(t, replayResult.Executed)
+	require.Len(t, replayResult.Responses, 1)
+	assert.Equal(t, models.GET_BALANCE_METHOD, replayResult.Responses[0].ResultType)
+	assert.Nil(t, replayResult.Responses[0].Error)
+	// nothing is published when replaying
+	assert.Len(t, relay.PublishedEvents, 1)
+}
+
+func TestHandleEvent_ArchiveRequestsDisabled(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	albyOAuthSvc := alby.NewAlbyOAuthService(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher)
+	nip47svc := NewNip47Service(svc.DB, svc.Cfg, svc.Keys, svc.EventPublisher, albyOAuthSvc)
+
+	reqPrivateKey := nostr.GeneratePrivateKey()
+	reqPubkey, err := nostr.GetPublicKey(reqPrivateKey)
+	require.NoError(t, err)
+
+	_, cipher, err := tests.CreateAppWithPrivateKey(svc, reqPrivateKey, constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+
+	msg, err := cipher.Encrypt(context.TODO(), `{"method":"get_info"}`)
+	require.NoError(t, err)
+
+	reqEvent := &nostr.Event{
+		Kind:      models.REQUEST_KIND,
+		PubKey:    reqPubkey,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{[]string{"encryption", constants.ENCRYPTION_TYPE_NIP44_V2}},
+		Content:   msg,
+	}
+	err = reqEvent.Sign(reqPrivateKey)
+	require.NoError(t, err)
+
+	nip47svc.HandleEvent(context.TODO(), tests.NewMockRelay(), reqEvent, svc.LNClient)
+
+	var count int64
+	svc.DB.Model(&db.RequestArchive{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	_, err = nip47svc.ReplayArchivedRequest(context.TODO(), reqEvent.ID, svc.LNClient)
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package nip47
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getAlby/hub/nip47/permissions"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const WALLET_AUTH_URI_SCHEME = "nostr+walletauth"
+
+// WalletAuthRequest is a connection request created by an app (NWA).
+// Once the connection is created, the app receives the wallet pubkey through
+// the NIP-47 info event which is tagged with the app pubkey.
+type WalletAuthRequest struct {
+	AppPubkey         string
+	Relays            []string
+	Name              string
+	Icon              string
+	ReturnTo          string
+	RequestMethods    []string
+	NotificationTypes []string
+	Scopes            []string
+	MaxAmountSat      uint64
+	BudgetRenewal     string
+	ExpiresAt         *time.Time
+	Isolated          bool
+	Metadata          map[string]interface{}
+}
+
+// ParseWalletAuthUri parses a nostr+walletauth://<app_pubkey>?relay=...&request_methods=... URI
+func ParseWalletAuthUri(uri string) (*WalletAuthRequest, error) {
+	parsedUri, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, fmt.Errorf("invalid wallet auth uri: %w", err)
+	}
+	// also accept wallet-specific schemes e.g. nostr+walletauth+alby://
+	if parsedUri.Scheme != WALLET_AUTH_URI_SCHEME && !strings.HasPrefix(parsedUri.Scheme, WALLET_AUTH_URI_SCHEME+"+") {
+		return nil, fmt.Errorf("unsupported uri scheme: %s", parsedUri.Scheme)
+	}
+
+	appPubkey := parsedUri.Host
+	if appPubkey == "" {
+		appPubkey = strings.TrimPrefix(parsedUri.Opaque, "//")
+	}
+	if !nostr.IsValidPublicKey(appPubkey) {
+		return nil, errors.New("invalid app pubkey")
+	}
+
+	query := parsedUri.Query()
+	walletAuthRequest := &WalletAuthRequest{
+		AppPubkey:     appPubkey,
+		Relays:        query["relay"],
+		Name:          query.Get("name"),
+		Icon:          query.Get("icon"),
+		ReturnTo:      query.Get("return_to"),
+		BudgetRenewal: query.Get("budget_renewal"),
+		Isolated:      query.Get("isolated") == "true",
+	}
+	if len(walletAuthRequest.Relays) == 0 {
+		return nil, errors.New("no relay provided")
+	}
+	if walletAuthRequest.Name == "" {
+		return nil, errors.New("no app name provided")
+	}
+
+	walletAuthRequest.RequestMethods = strings.Fields(query.Get("request_methods"))
+	walletAuthRequest.NotificationTypes = strings.Fields(query.Get("notification_types"))
+	if len(walletAuthRequest.RequestMethods) == 0 {
+		return nil, errors.New("no request methods provided")
+	}
+
+	scopes, err := permissions.RequestMethodsToScopes(walletAuthRequest.RequestMethods)
+	if err != nil {
+		return nil, err
+	}
+	walletAuthRequest.Scopes = append(scopes, permissions.NotificationTypesToScopes(walletAuthRequest.NotificationTypes)...)
+
+	if maxAmount := query.Get("max_amount"); maxAmount != "" {
+		walletAuthRequest.MaxAmountSat, err = strconv.ParseUint(maxAmount, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max_amount: %w", err)
+		}
+	}
+
+	if expiresAt := query.Get("expires_at"); expiresAt != "" {
+		expiresAtUnix, err := strconv.ParseInt(expiresAt, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at: %w", err)
+		}
+		expiresAtTime := time.Unix(expiresAtUnix, 0)
+		walletAuthRequest.ExpiresAt = &expiresAtTime
+	}
+
+	if metadata := query.Get("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &walletAuthRequest.Metadata); err != nil {
+			return nil, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+
+	return walletAuthRequest, nil
+}
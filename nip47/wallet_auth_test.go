@@ -0,0 +1,58 @@
+package nip47
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/logger"
+)
+
+const walletAuthAppPubkey = "e0f2b3b1c6c4e8b2ff0b3a5a8b6d8a8e6b0f9e3c1d2a4b6c8d0e2f4a6b8c0d2e"
+
+func TestParseWalletAuthUri(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	walletAuthRequest, err := ParseWalletAuthUri("nostr+walletauth://" + walletAuthAppPubkey +
+		"?relay=wss%3A%2F%2Frelay.getalby.com%2Fv1&name=Test+App&request_methods=pay_invoice%20get_balance%20get_budget" +
+		"&notification_types=payment_received&max_amount=10000&budget_renewal=monthly&expires_at=1893456000" +
+		"&isolated=true&return_to=https%3A%2F%2Fexample.com&metadata=%7B%22a%22%3A1%7D")
+	require.NoError(t, err)
+
+	assert.Equal(t, walletAuthAppPubkey, walletAuthRequest.AppPubkey)
+	assert.Equal(t, []string{"wss://relay.getalby.com/v1"}, walletAuthRequest.Relays)
+	assert.Equal(t, "Test App", walletAuthRequest.Name)
+	assert.Equal(t, []string{"pay_invoice", "get_balance", "get_budget"}, walletAuthRequest.RequestMethods)
+	assert.Equal(t, []string{constants.PAY_INVOICE_SCOPE, constants.GET_BALANCE_SCOPE, constants.NOTIFICATIONS_SCOPE}, walletAuthRequest.Scopes)
+	assert.Equal(t, uint64(10000), walletAuthRequest.MaxAmountSat)
+	assert.Equal(t, "monthly", walletAuthRequest.BudgetRenewal)
+	assert.Equal(t, int64(1893456000), walletAuthRequest.ExpiresAt.Unix())
+	assert.True(t, walletAuthRequest.Isolated)
+	assert.Equal(t, "https://example.com", walletAuthRequest.ReturnTo)
+	assert.Equal(t, float64(1), walletAuthRequest.Metadata["a"])
+
+	walletAuthRequest, err = ParseWalletAuthUri("nostr+walletauth+alby://" + walletAuthAppPubkey + "?relay=wss://relay.example.com&name=Test&request_methods=get_info")
+	require.NoError(t, err)
+	assert.Equal(t, []string{constants.GET_INFO_SCOPE}, walletAuthRequest.Scopes)
+}
+
+func TestParseWalletAuthUri_Invalid(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	_, err := ParseWalletAuthUri("nostr+walletconnect://" + walletAuthAppPubkey + "?relay=wss://relay.example.com&name=Test&request_methods=get_info")
+	assert.EqualError(t, err, "unsupported uri scheme: nostr+walletconnect")
+
+	_, err = ParseWalletAuthUri("nostr+walletauth://abc?relay=wss://relay.example.com&name=Test&request_methods=get_info")
+	assert.EqualError(t, err, "invalid app pubkey")
+
+	_, err = ParseWalletAuthUri("nostr+walletauth://" + walletAuthAppPubkey + "?name=Test&request_methods=get_info")
+	assert.EqualError(t, err, "no relay provided")
+
+	_, err = ParseWalletAuthUri("nostr+walletauth://" + walletAuthAppPubkey + "?relay=wss://relay.example.com&name=Test")
+	assert.EqualError(t, err, "no request methods provided")
+
+	_, err = ParseWalletAuthUri("nostr+walletauth://" + walletAuthAppPubkey + "?relay=wss://relay.example.com&name=Test&request_methods=unknown")
+	assert.EqualError(t, err, "unsupported request method: unknown")
+}
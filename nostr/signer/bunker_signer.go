@@ -0,0 +1,115 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip46"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/logger"
+)
+
+const bunkerRequestTimeout = 30 * time.Second
+
+// the hub cannot be unlocked while connecting, so an unreachable remote signer fails fast
+const bunkerConnectTimeout = 10 * time.Second
+
+// bunkerSigner delegates signing and encryption to a NIP-46 remote signer
+// (a bunker or a local signing daemon), so the secret key never enters the hub.
+type bunkerSigner struct {
+	client    *nip46.BunkerClient
+	publicKey string
+	cancel    context.CancelFunc
+}
+
+// NewBunkerSigner connects to the remote signer of a bunker:// URL.
+// clientSecretKey identifies the hub to the remote signer, which may require
+// the connection to be approved once.
+func NewBunkerSigner(clientSecretKey, bunkerUrl string) (*bunkerSigner, error) {
+	if !nip46.IsValidBunkerURL(bunkerUrl) {
+		return nil, errors.New("invalid bunker URL, expected bunker://<pubkey>?relay=<relay>&secret=<secret>")
+	}
+	parsed, err := url.Parse(bunkerUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bunker URL: %w", err)
+	}
+	targetPublicKey := parsed.Host
+	relays := parsed.Query()["relay"]
+	secret := parsed.Query().Get("secret")
+
+	// the relay subscription lives until the signer is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	client := nip46.NewBunker(ctx, clientSecretKey, targetPublicKey, relays, nostr.NewSimplePool(ctx), func(authUrl string) {
+		logger.Logger.WithField("auth_url", authUrl).Warn("Remote signer requires authorization, open the URL to approve the hub")
+	})
+
+	requestCtx, requestCancel := context.WithTimeout(ctx, bunkerConnectTimeout)
+	defer requestCancel()
+	_, err = client.RPC(requestCtx, "connect", []string{targetPublicKey, secret})
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	publicKey, err := client.GetPublicKey(requestCtx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to get public key from remote signer: %w", err)
+	}
+	if !nostr.IsValidPublicKey(publicKey) {
+		cancel()
+		return nil, fmt.Errorf("remote signer returned an invalid public key: %s", publicKey)
+	}
+
+	logger.Logger.WithField("pubkey", publicKey).Info("Connected to remote signer")
+
+	return &bunkerSigner{
+		client:    client,
+		publicKey: publicKey,
+		cancel:    cancel,
+	}, nil
+}
+
+func (s *bunkerSigner) GetPublicKey() string {
+	return s.publicKey
+}
+
+func (s *bunkerSigner) SignEvent(ctx context.Context, event *nostr.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, bunkerRequestTimeout)
+	defer cancel()
+	event.PubKey = s.publicKey
+	return s.client.SignEvent(ctx, event)
+}
+
+func (s *bunkerSigner) Encrypt(ctx context.Context, encryption, pubkey, plaintext string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bunkerRequestTimeout)
+	defer cancel()
+	switch encryption {
+	case constants.ENCRYPTION_TYPE_NIP04:
+		return s.client.NIP04Encrypt(ctx, pubkey, plaintext)
+	case constants.ENCRYPTION_TYPE_NIP44_V2:
+		return s.client.NIP44Encrypt(ctx, pubkey, plaintext)
+	}
+	return "", fmt.Errorf("invalid encryption: %s", encryption)
+}
+
+func (s *bunkerSigner) Decrypt(ctx context.Context, encryption, pubkey, ciphertext string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, bunkerRequestTimeout)
+	defer cancel()
+	switch encryption {
+	case constants.ENCRYPTION_TYPE_NIP04:
+		return s.client.NIP04Decrypt(ctx, pubkey, ciphertext)
+	case constants.ENCRYPTION_TYPE_NIP44_V2:
+		return s.client.NIP44Decrypt(ctx, pubkey, ciphertext)
+	}
+	return "", fmt.Errorf("invalid encryption: %s", encryption)
+}
+
+// Close disconnects from the remote signer
+func (s *bunkerSigner) Close() {
+	s.cancel()
+}
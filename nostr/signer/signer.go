@@ -0,0 +1,84 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+
+	"github.com/getAlby/hub/constants"
+)
+
+// Signer signs Nostr events and encrypts and decrypts NIP-04 and NIP-44 payloads
+// with a Nostr key, without exposing the secret key to the caller.
+type Signer interface {
+	GetPublicKey() string
+	SignEvent(ctx context.Context, event *nostr.Event) error
+	// Encrypt encrypts plaintext for pubkey using encryption (nip04 or nip44_v2)
+	Encrypt(ctx context.Context, encryption, pubkey, plaintext string) (string, error)
+	// Decrypt decrypts ciphertext received from pubkey using encryption (nip04 or nip44_v2)
+	Decrypt(ctx context.Context, encryption, pubkey, ciphertext string) (string, error)
+}
+
+// keySigner holds the secret key in memory. This is the default signer.
+type keySigner struct {
+	secretKey string
+	publicKey string
+}
+
+func NewKeySigner(secretKey string) (*keySigner, error) {
+	publicKey, err := nostr.GetPublicKey(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &keySigner{
+		secretKey: secretKey,
+		publicKey: publicKey,
+	}, nil
+}
+
+func (s *keySigner) GetPublicKey() string {
+	return s.publicKey
+}
+
+func (s *keySigner) SignEvent(ctx context.Context, event *nostr.Event) error {
+	return event.Sign(s.secretKey)
+}
+
+func (s *keySigner) Encrypt(ctx context.Context, encryption, pubkey, plaintext string) (string, error) {
+	switch encryption {
+	case constants.ENCRYPTION_TYPE_NIP04:
+		sharedSecret, err := nip04.ComputeSharedSecret(pubkey, s.secretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip04.Encrypt(plaintext, sharedSecret)
+	case constants.ENCRYPTION_TYPE_NIP44_V2:
+		conversationKey, err := nip44.GenerateConversationKey(pubkey, s.secretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip44.Encrypt(plaintext, conversationKey)
+	}
+	return "", fmt.Errorf("invalid encryption: %s", encryption)
+}
+
+func (s *keySigner) Decrypt(ctx context.Context, encryption, pubkey, ciphertext string) (string, error) {
+	switch encryption {
+	case constants.ENCRYPTION_TYPE_NIP04:
+		sharedSecret, err := nip04.ComputeSharedSecret(pubkey, s.secretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip04.Decrypt(ciphertext, sharedSecret)
+	case constants.ENCRYPTION_TYPE_NIP44_V2:
+		conversationKey, err := nip44.GenerateConversationKey(pubkey, s.secretKey)
+		if err != nil {
+			return "", err
+		}
+		return nip44.Decrypt(ciphertext, conversationKey)
+	}
+	return "", fmt.Errorf("invalid encryption: %s", encryption)
+}
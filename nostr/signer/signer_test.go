@@ -0,0 +1,85 @@
+package signer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+)
+
+func TestKeySigner_SignEvent(t *testing.T) {
+	secretKey := nostr.GeneratePrivateKey()
+	keySigner, err := NewKeySigner(secretKey)
+	require.NoError(t, err)
+
+	publicKey, err := nostr.GetPublicKey(secretKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, keySigner.GetPublicKey())
+
+	event := &nostr.Event{
+		PubKey:    keySigner.GetPublicKey(),
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindTextNote,
+		Content:   "test",
+	}
+	err = keySigner.SignEvent(context.TODO(), event)
+	require.NoError(t, err)
+
+	valid, err := event.CheckSignature()
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestKeySigner_Encrypt(t *testing.T) {
+	secretKey := nostr.GeneratePrivateKey()
+	keySigner, err := NewKeySigner(secretKey)
+	require.NoError(t, err)
+
+	otherSecretKey := nostr.GeneratePrivateKey()
+	otherPublicKey, err := nostr.GetPublicKey(otherSecretKey)
+	require.NoError(t, err)
+
+	// NIP-04
+	ciphertext, err := keySigner.Encrypt(context.TODO(), constants.ENCRYPTION_TYPE_NIP04, otherPublicKey, "nip04 payload")
+	require.NoError(t, err)
+	sharedSecret, err := nip04.ComputeSharedSecret(keySigner.GetPublicKey(), otherSecretKey)
+	require.NoError(t, err)
+	plaintext, err := nip04.Decrypt(ciphertext, sharedSecret)
+	require.NoError(t, err)
+	assert.Equal(t, "nip04 payload", plaintext)
+
+	ciphertext, err = nip04.Encrypt("nip04 reply", sharedSecret)
+	require.NoError(t, err)
+	plaintext, err = keySigner.Decrypt(context.TODO(), constants.ENCRYPTION_TYPE_NIP04, otherPublicKey, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "nip04 reply", plaintext)
+
+	// NIP-44
+	ciphertext, err = keySigner.Encrypt(context.TODO(), constants.ENCRYPTION_TYPE_NIP44_V2, otherPublicKey, "nip44 payload")
+	require.NoError(t, err)
+	conversationKey, err := nip44.GenerateConversationKey(keySigner.GetPublicKey(), otherSecretKey)
+	require.NoError(t, err)
+	plaintext, err = nip44.Decrypt(ciphertext, conversationKey)
+	require.NoError(t, err)
+	assert.Equal(t, "nip44 payload", plaintext)
+
+	ciphertext, err = nip44.Encrypt("nip44 reply", conversationKey)
+	require.NoError(t, err)
+	plaintext, err = keySigner.Decrypt(context.TODO(), constants.ENCRYPTION_TYPE_NIP44_V2, otherPublicKey, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "nip44 reply", plaintext)
+
+	_, err = keySigner.Encrypt(context.TODO(), "nip44", otherPublicKey, "payload")
+	assert.EqualError(t, err, "invalid encryption: nip44")
+}
+
+func TestNewBunkerSigner_InvalidUrl(t *testing.T) {
+	_, err := NewBunkerSigner(nostr.GeneratePrivateKey(), "nostrconnect://invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,273 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/logger"
+)
+
+// RetentionService periodically deletes data which is no longer needed according
+// to the configured retention policy. Settled transactions are always kept.
+type RetentionService interface {
+	Start(ctx context.Context)
+	Prune(ctx context.Context) (*Report, error)
+	GetPolicy() Policy
+	GetLastReport() *Report
+}
+
+// Policy configures after how many days data is pruned. 0 keeps the data forever.
+type Policy struct {
+	RequestEventDays   uint `json:"requestEventDays"`
+	ExpiredInvoiceDays uint `json:"expiredInvoiceDays"`
+	FailedPaymentDays  uint `json:"failedPaymentDays"`
+	NotificationDays   uint `json:"notificationDays"`
+	Vacuum             bool `json:"vacuum"`
+}
+
+// Report lists the number of rows deleted by a prune run
+type Report struct {
+	StartedAt      time.Time `json:"startedAt"`
+	FinishedAt     time.Time `json:"finishedAt"`
+	RequestEvents  int64     `json:"requestEvents"`
+	ResponseEvents int64     `json:"responseEvents"`
+	// unpaid incoming invoices
+	ExpiredInvoices int64 `json:"expiredInvoices"`
+	// failed outgoing payments
+	FailedPayments     int64 `json:"failedPayments"`
+	Nip47Notifications int64 `json:"nip47Notifications"`
+	// index rows and labels which referenced deleted transactions
+	TransactionIndexEntries int64 `json:"transactionIndexEntries"`
+	Labels                  int64 `json:"labels"`
+	Vacuumed                bool  `json:"vacuumed"`
+}
+
+func (report *Report) total() int64 {
+	return report.RequestEvents + report.ResponseEvents + report.ExpiredInvoices + report.FailedPayments +
+		report.Nip47Notifications + report.TransactionIndexEntries + report.Labels
+}
+
+const pruneBatchSize = 1000
+
+// payment and invoice requests older than 6 hours are ignored by the NIP-47 handler,
+// so request events must be kept at least this long to detect replayed requests
+const minRequestEventRetention = 24 * time.Hour
+
+type retentionService struct {
+	db  *gorm.DB
+	cfg config.Config
+
+	// only one prune runs at a time
+	pruneMutex sync.Mutex
+
+	mu         sync.Mutex
+	lastReport *Report
+}
+
+func NewRetentionService(db *gorm.DB, cfg config.Config) RetentionService {
+	return &retentionService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+func (svc *retentionService) GetPolicy() Policy {
+	env := svc.cfg.GetEnv()
+	return Policy{
+		RequestEventDays:   env.RetentionRequestEventDays,
+		ExpiredInvoiceDays: env.RetentionExpiredInvoiceDays,
+		FailedPaymentDays:  env.RetentionFailedPaymentDays,
+		NotificationDays:   env.RetentionNotificationDays,
+		Vacuum:             env.RetentionVacuum,
+	}
+}
+
+func (svc *retentionService) GetLastReport() *Report {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.lastReport
+}
+
+// Start runs the pruner until ctx is cancelled (when the hub is stopped)
+func (svc *retentionService) Start(ctx context.Context) {
+	interval := time.Duration(svc.cfg.GetEnv().RetentionPruneIntervalHours) * time.Hour
+	if interval == 0 {
+		logger.Logger.Info("Data retention pruning is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			_, err := svc.Prune(ctx)
+			if err != nil {
+				logger.Logger.WithError(err).Error("Failed to prune data")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (svc *retentionService) Prune(ctx context.Context) (*Report, error) {
+	svc.pruneMutex.Lock()
+	defer svc.pruneMutex.Unlock()
+
+	policy := svc.GetPolicy()
+	report := &Report{
+		StartedAt: time.Now(),
+	}
+
+	err := svc.prune(ctx, policy, report)
+	if err != nil {
+		return nil, err
+	}
+	report.FinishedAt = time.Now()
+
+	logger.Logger.WithFields(logrus.Fields{
+		"request_events":            report.RequestEvents,
+		"response_events":           report.ResponseEvents,
+		"expired_invoices":          report.ExpiredInvoices,
+		"failed_payments":           report.FailedPayments,
+		"nip47_notifications":       report.Nip47Notifications,
+		"transaction_index_entries": report.TransactionIndexEntries,
+		"labels":                    report.Labels,
+		"vacuumed":                  report.Vacuumed,
+		"duration":                  report.FinishedAt.Sub(report.StartedAt).String(),
+	}).Info("Pruned data")
+
+	svc.mu.Lock()
+	svc.lastReport = report
+	svc.mu.Unlock()
+
+	return report, nil
+}
+
+func (svc *retentionService) prune(ctx context.Context, policy Policy, report *Report) error {
+	var err error
+	now := time.Now()
+
+	if policy.RequestEventDays > 0 {
+		cutoff := now.Add(-max(days(policy.RequestEventDays), minRequestEventRetention))
+		// response events and archived requests are deleted with their request events,
+		// the responses are deleted first so that they are included in the report
+		report.ResponseEvents, err = svc.deleteInBatches(ctx, "response_events",
+			"request_id IN (SELECT id FROM request_events WHERE created_at < ?)", cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to prune response events: %w", err)
+		}
+		report.RequestEvents, err = svc.deleteInBatches(ctx, "request_events", "created_at < ?", cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to prune request events: %w", err)
+		}
+	}
+
+	if policy.ExpiredInvoiceDays > 0 {
+		report.ExpiredInvoices, err = svc.deleteInBatches(ctx, "transactions",
+			"type = ? AND state IN ? AND expires_at < ?",
+			constants.TRANSACTION_TYPE_INCOMING,
+			[]string{constants.TRANSACTION_STATE_PENDING, constants.TRANSACTION_STATE_FAILED},
+			now.Add(-days(policy.ExpiredInvoiceDays)))
+		if err != nil {
+			return fmt.Errorf("failed to prune expired invoices: %w", err)
+		}
+	}
+
+	if policy.FailedPaymentDays > 0 {
+		report.FailedPayments, err = svc.deleteInBatches(ctx, "transactions",
+			"type = ? AND state = ? AND updated_at < ?",
+			constants.TRANSACTION_TYPE_OUTGOING,
+			constants.TRANSACTION_STATE_FAILED,
+			now.Add(-days(policy.FailedPaymentDays)))
+		if err != nil {
+			return fmt.Errorf("failed to prune failed payments: %w", err)
+		}
+	}
+
+	if policy.NotificationDays > 0 {
+		// pending notifications are still being delivered
+		report.Nip47Notifications, err = svc.deleteInBatches(ctx, "nip47_notifications",
+			"state IN ? AND updated_at < ?",
+			[]string{constants.NIP47_NOTIFICATION_STATE_PUBLISHED, constants.NIP47_NOTIFICATION_STATE_FAILED},
+			now.Add(-days(policy.NotificationDays)))
+		if err != nil {
+			return fmt.Errorf("failed to prune nip47 notifications: %w", err)
+		}
+	}
+
+	err = svc.deleteTransactionReferences(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	if policy.Vacuum && report.total() > 0 && svc.db.Dialector.Name() == "sqlite" {
+		// postgres reclaims space with autovacuum
+		err = svc.db.WithContext(ctx).Exec("VACUUM").Error
+		if err != nil {
+			return fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		report.Vacuumed = true
+	}
+
+	return nil
+}
+
+// deleteTransactionReferences removes rows which referenced deleted transactions.
+// The sqlite search index and labels have no foreign key to cascade the delete.
+func (svc *retentionService) deleteTransactionReferences(ctx context.Context, report *Report) error {
+	metadataEntries, err := svc.deleteInBatches(ctx, "transaction_metadata_entries",
+		"transaction_id NOT IN (SELECT id FROM transactions)")
+	if err != nil {
+		return fmt.Errorf("failed to prune transaction metadata entries: %w", err)
+	}
+
+	// full-text search tables have no id column, so they are not deleted in batches
+	result := svc.db.WithContext(ctx).Exec("DELETE FROM transaction_search WHERE docid NOT IN (SELECT id FROM transactions)")
+	if result.Error != nil {
+		return fmt.Errorf("failed to prune transaction search documents: %w", result.Error)
+	}
+	report.TransactionIndexEntries = metadataEntries + result.RowsAffected
+
+	report.Labels, err = svc.deleteInBatches(ctx, "labels",
+		"entity_type = ? AND transaction_id NOT IN (SELECT id FROM transactions)",
+		constants.LABEL_ENTITY_TYPE_TRANSACTION)
+	if err != nil {
+		return fmt.Errorf("failed to prune transaction labels: %w", err)
+	}
+
+	return nil
+}
+
+// deleteInBatches deletes matching rows in small transactions so that the
+// database is not locked for long while the hub is running
+func (svc *retentionService) deleteInBatches(ctx context.Context, table string, condition string, args ...interface{}) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT id FROM %s WHERE %s LIMIT %d)", table, table, condition, pruneBatchSize)
+
+	deleted := int64(0)
+	for {
+		result := svc.db.WithContext(ctx).Exec(query, args...)
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += result.RowsAffected
+		if result.RowsAffected < pruneBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+func days(count uint) time.Duration {
+	return time.Duration(count) * 24 * time.Hour
+}
@@ -0,0 +1,151 @@
+package retention
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/transactions/metadataindex"
+)
+
+func TestPrune(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	app, _, err := tests.CreateApp(svc)
+	require.NoError(t, err)
+
+	old := time.Now().AddDate(0, 0, -60)
+	recent := time.Now().Add(-time.Hour)
+
+	for i, createdAt := range []time.Time{old, recent} {
+		requestEvent := &db.RequestEvent{AppId: &app.ID, NostrId: "request" + strconv.Itoa(i), CreatedAt: createdAt}
+		require.NoError(t, svc.DB.Create(requestEvent).Error)
+		require.NoError(t, svc.DB.Create(&db.ResponseEvent{NostrId: "response" + strconv.Itoa(i), RequestId: requestEvent.ID}).Error)
+	}
+
+	expiredAt := old
+	expiredInvoice := &db.Transaction{
+		Type:        constants.TRANSACTION_TYPE_INCOMING,
+		State:       constants.TRANSACTION_STATE_PENDING,
+		PaymentHash: "expired",
+		Description: "expired invoice",
+		ExpiresAt:   &expiredAt,
+	}
+	settledInvoice := &db.Transaction{
+		Type:        constants.TRANSACTION_TYPE_INCOMING,
+		State:       constants.TRANSACTION_STATE_SETTLED,
+		PaymentHash: "settled",
+		Description: "settled invoice",
+		ExpiresAt:   &expiredAt,
+	}
+	failedPayment := &db.Transaction{
+		Type:        constants.TRANSACTION_TYPE_OUTGOING,
+		State:       constants.TRANSACTION_STATE_FAILED,
+		PaymentHash: "failed",
+	}
+	for _, transaction := range []*db.Transaction{expiredInvoice, settledInvoice, failedPayment} {
+		require.NoError(t, svc.DB.Create(transaction).Error)
+		require.NoError(t, metadataindex.Index(svc.DB, transaction.ID, transaction.Description, nil, nil))
+		require.NoError(t, svc.DB.Create(&db.Label{
+			EntityType:    constants.LABEL_ENTITY_TYPE_TRANSACTION,
+			EntityId:      strconv.FormatUint(uint64(transaction.ID), 10),
+			TransactionId: &transaction.ID,
+			Label:         transaction.PaymentHash,
+		}).Error)
+	}
+	require.NoError(t, svc.DB.Model(failedPayment).UpdateColumn("updated_at", old).Error)
+
+	require.NoError(t, svc.DB.Create(&db.Nip47Notification{
+		AppId:     app.ID,
+		State:     constants.NIP47_NOTIFICATION_STATE_PUBLISHED,
+		UpdatedAt: old,
+	}).Error)
+	require.NoError(t, svc.DB.Create(&db.Nip47Notification{
+		AppId: app.ID,
+		State: constants.NIP47_NOTIFICATION_STATE_PENDING,
+	}).Error)
+
+	svc.Cfg.GetEnv().RetentionRequestEventDays = 30
+	svc.Cfg.GetEnv().RetentionExpiredInvoiceDays = 7
+	svc.Cfg.GetEnv().RetentionFailedPaymentDays = 30
+	svc.Cfg.GetEnv().RetentionNotificationDays = 30
+	svc.Cfg.GetEnv().RetentionVacuum = true
+
+	retentionService := NewRetentionService(svc.DB, svc.Cfg)
+	assert.Nil(t, retentionService.GetLastReport())
+
+	report, err := retentionService.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.RequestEvents)
+	assert.Equal(t, int64(1), report.ResponseEvents)
+	assert.Equal(t, int64(1), report.ExpiredInvoices)
+	assert.Equal(t, int64(1), report.FailedPayments)
+	assert.Equal(t, int64(1), report.Nip47Notifications)
+	assert.Equal(t, int64(2), report.Labels)
+	assert.Positive(t, report.TransactionIndexEntries)
+	assert.True(t, report.Vacuumed)
+	assert.Equal(t, report, retentionService.GetLastReport())
+
+	var requestEvents []db.RequestEvent
+	require.NoError(t, svc.DB.Find(&requestEvents).Error)
+	require.Len(t, requestEvents, 1)
+	assert.Equal(t, "request1", requestEvents[0].NostrId)
+
+	var transactions []db.Transaction
+	require.NoError(t, svc.DB.Find(&transactions).Error)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, settledInvoice.ID, transactions[0].ID)
+
+	var labels []db.Label
+	require.NoError(t, svc.DB.Find(&labels).Error)
+	require.Len(t, labels, 1)
+	assert.Equal(t, "settled", labels[0].Label)
+
+	var searchCount int64
+	require.NoError(t, svc.DB.Raw("SELECT COUNT(*) FROM transaction_search WHERE transaction_search MATCH ?", "invoice").Scan(&searchCount).Error)
+	assert.Equal(t, int64(1), searchCount)
+
+	var notifications []db.Nip47Notification
+	require.NoError(t, svc.DB.Find(&notifications).Error)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, constants.NIP47_NOTIFICATION_STATE_PENDING, notifications[0].State)
+
+	// nothing left to prune
+	report, err = retentionService.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.total())
+	assert.False(t, report.Vacuumed)
+}
+
+func TestPrune_KeepsRecentRequestEvents(t *testing.T) {
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	require.NoError(t, svc.DB.Create(&db.RequestEvent{NostrId: "request", CreatedAt: time.Now().Add(-12 * time.Hour)}).Error)
+
+	svc.Cfg.GetEnv().RetentionRequestEventDays = 0
+	svc.Cfg.GetEnv().RetentionExpiredInvoiceDays = 0
+	svc.Cfg.GetEnv().RetentionFailedPaymentDays = 0
+	svc.Cfg.GetEnv().RetentionNotificationDays = 0
+
+	retentionService := NewRetentionService(svc.DB, svc.Cfg)
+	report, err := retentionService.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.total())
+
+	// request events are kept for at least a day to detect replayed requests
+	svc.Cfg.GetEnv().RetentionRequestEventDays = 1
+	report, err = retentionService.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, report.RequestEvents)
+}
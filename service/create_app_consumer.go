@@ -10,6 +10,7 @@ import (
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
 )
 
 type createAppConsumer struct {
@@ -51,15 +52,15 @@ func (s *createAppConsumer) ConsumeEvent(ctx context.Context, event *events.Even
 		logger.Logger.WithError(err).Error("Failed to calculate app wallet priv key")
 		return
 	}
-	walletPubKey, err := nostr.GetPublicKey(walletPrivKey)
+	walletSigner, err := signer.NewKeySigner(walletPrivKey)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to calculate app wallet pub key")
 		return
 	}
-	s.svc.nip47Service.EnqueueNip47InfoPublishRequest(id, walletPubKey, walletPrivKey)
+	s.svc.nip47Service.EnqueueNip47InfoPublishRequest(id, walletSigner)
 
 	go func() {
-		err = s.svc.startAppWalletSubscription(ctx, s.relay, walletPubKey)
+		err = s.svc.startAppWalletSubscription(ctx, s.relay, id, walletSigner)
 		if err != nil && !errors.Is(err, context.Canceled) {
 			logger.Logger.WithError(err).WithFields(logrus.Fields{
 				"app_id": id}).Error("Failed to subscribe to wallet")
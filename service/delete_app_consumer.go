@@ -5,21 +5,26 @@ import (
 
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/nbd-wtf/go-nostr"
 )
 
 type deleteAppConsumer struct {
 	events.EventSubscriber
-	walletPubkey      string
+	// 0 for the legacy subscription which is shared by all legacy apps
+	appId             uint
+	walletSigner      signer.Signer
 	relay             *nostr.Relay
 	nostrSubscription *nostr.Subscription
 	svc               *service
 }
 
-// When an app is deleted, unsubscribe from events for that app on the relay
-// and publish a deletion event for that app's info event
+// When an app is deleted or the keys replaced by a key rotation are retired,
+// unsubscribe from events for that app wallet key on the relay
+// and publish a deletion event for that key's info event.
+// The legacy subscription of a hub key ends when the hub key is retired.
 func (s *deleteAppConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
-	if event.Event != "nwc_app_deleted" {
+	if event.Event != "nwc_app_deleted" && event.Event != "nwc_app_keys_retired" && event.Event != "nwc_nostr_key_retired" {
 		return
 	}
 	properties, ok := event.Properties.(map[string]interface{})
@@ -27,41 +32,48 @@ func (s *deleteAppConsumer) ConsumeEvent(ctx context.Context, event *events.Even
 		logger.Logger.WithField("event", event).Error("Failed to cast event.Properties to map")
 		return
 	}
-	id, ok := properties["id"].(uint)
-	if !ok {
-		logger.Logger.WithField("event", event).Error("missing id in properties event")
-		return
-	}
 
-	walletPrivKey, err := s.svc.keys.GetAppWalletKey(id)
-	if err != nil {
-		logger.Logger.WithError(err).WithField("id", id).Error("Failed to calculate app wallet priv key")
-		return
+	if event.Event == "nwc_nostr_key_retired" {
+		pubkey, _ := properties["pubkey"].(string)
+		if s.appId != 0 || pubkey != s.walletSigner.GetPublicKey() {
+			return
+		}
+	} else {
+		id, ok := properties["id"].(uint)
+		if !ok {
+			logger.Logger.WithField("event", event).Error("missing id in properties event")
+			return
+		}
+
+		// Note: for legacy apps this check will always return false as the legacy
+		// subscription is not for a single app
+		if s.appId == 0 || s.appId != id {
+			return
+		}
+		if event.Event == "nwc_app_keys_retired" {
+			// the subscription for the current app wallet key continues
+			walletPubkey, _ := properties["wallet_pubkey"].(string)
+			if walletPubkey != s.walletSigner.GetPublicKey() {
+				return
+			}
+		}
 	}
-	walletPubKey, err := nostr.GetPublicKey(walletPrivKey)
+
+	s.nostrSubscription.Unsub()
+
+	// remove this consumer as subscriber in eventPublisher
+	s.svc.eventPublisher.RemoveSubscriber(s)
+
+	// get nip47 event info for this app wallet key
+	nip47InfoEvent, err := s.svc.GetNip47Service().GetNip47Info(ctx, s.relay, s.walletSigner.GetPublicKey())
 	if err != nil {
-		logger.Logger.WithError(err).WithField("id", id).Error("Failed to calculate app wallet pub key")
+		logger.Logger.WithError(err).Error("Could not get nip47 info event")
 		return
 	}
-	// Note: for legacy apps this check will always return false as the wallet pubkey
-	// generated by the id will not match the master key which is used for all legacy apps
-	if s.walletPubkey == walletPubKey {
-		s.nostrSubscription.Unsub()
-
-		// remove this consumer as subscriber in eventPublisher
-		s.svc.eventPublisher.RemoveSubscriber(s)
-
-		// get nip47 event info for this app wallet key
-		nip47InfoEvent, err := s.svc.GetNip47Service().GetNip47Info(ctx, s.relay, s.walletPubkey)
+	if nip47InfoEvent != nil {
+		err = s.svc.nip47Service.PublishNip47InfoDeletion(ctx, s.relay, s.walletSigner, nip47InfoEvent.ID)
 		if err != nil {
-			logger.Logger.WithError(err).Error("Could not get nip47 info event")
-			return
-		}
-		if nip47InfoEvent != nil {
-			err = s.svc.nip47Service.PublishNip47InfoDeletion(ctx, s.relay, walletPubKey, walletPrivKey, nip47InfoEvent.ID)
-			if err != nil {
-				logger.Logger.WithError(err).WithField("event", event).Error("Failed to publish nip47 info deletion")
-			}
+			logger.Logger.WithError(err).WithField("event", event).Error("Failed to publish nip47 info deletion")
 		}
 	}
 }
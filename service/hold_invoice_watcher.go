@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/transactions"
+)
+
+const holdInvoicesPollInterval = 1 * time.Minute
+
+// Cancels accepted hold invoices which were not settled or canceled by their app
+// before the HTLC gets close to expiry, see transactions.HoldInvoicePolicy
+func (svc *service) watchHoldInvoices(ctx context.Context, lnClient lnclient.LNClient) {
+	policy := transactions.HoldInvoicePolicy{
+		AutoCancelBlocks:    svc.cfg.GetEnv().HoldInvoiceAutoCancelBlocks,
+		ExpiryWarningBlocks: svc.cfg.GetEnv().HoldInvoiceExpiryWarningBlocks,
+	}
+	if policy.AutoCancelBlocks == 0 {
+		logger.Logger.Info("Hold invoice auto-cancel is disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(holdInvoicesPollInterval)
+		defer ticker.Stop()
+		for {
+			info, err := lnClient.GetInfo(ctx)
+			if err != nil {
+				logger.Logger.WithError(err).Error("Failed to get node info for hold invoice expiry check")
+			} else if info.BlockHeight > 0 {
+				err = svc.transactionsService.ProcessExpiringHoldInvoices(ctx, lnClient, info.BlockHeight, policy)
+				if err != nil {
+					logger.Logger.WithError(err).Error("Failed to process expiring hold invoices")
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
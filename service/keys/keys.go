@@ -5,6 +5,9 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/tyler-smith/go-bip32"
 	"github.com/tyler-smith/go-bip39"
@@ -21,12 +25,26 @@ type Keys interface {
 	Init(cfg config.Config, encryptionKey string) error
 	// Wallet Service Nostr pubkey (DEPRECATED)
 	GetNostrPublicKey() string
-	// Wallet Service Nostr secret key (DEPRECATED)
-	GetNostrSecretKey() string
+	// Signs and encrypts with the Wallet Service Nostr key (DEPRECATED).
+	// Backed by a remote signer if NOSTR_SIGNER_URL is set
+	GetNostrSigner() signer.Signer
+	// Signer of the hub Nostr key replaced by the last rotation of the hub Nostr key.
+	// Nil if there is none or its grace period ended
+	GetPreviousNostrSigner() signer.Signer
+	// End of the grace period of the previous hub Nostr key, nil if there is none
+	GetPreviousNostrKeyExpiresAt() *time.Time
+	// Replaces the hub Nostr key. Only the replaced key is kept until the grace period ends
+	RotateNostrKey(cfg config.Config, encryptionKey string, gracePeriod time.Duration) error
+	// Removes the hub Nostr key replaced by the last rotation
+	RetirePreviousNostrKey(cfg config.Config) error
 	// Swap rescue key derived from master key using BIP-85
 	GetSwapMnemonic() string
-	// Derives a BIP32 child key from appKey derived child dedicated for app wallet keys
+	// Derives a BIP32 child key from appKey derived child dedicated for app wallet keys.
+	// App wallet keys are always held in memory, they are not delegated to NOSTR_SIGNER_URL
 	GetAppWalletKey(childIndex uint) (string, error)
+	// Derives the app wallet key of a key generation. Generation 0 is the key returned
+	// by GetAppWalletKey, the generation is incremented every time the app key is rotated
+	GetAppWalletKeyGeneration(childIndex uint, generation uint) (string, error)
 	// Derives a child BIP-32 key from the app key (derived from the mnemonic)
 	DeriveKey(path []uint32) (*bip32.Key, error)
 	// Derives a BIP32 child key from appKey derived child dedicated for swaps
@@ -34,11 +52,15 @@ type Keys interface {
 }
 
 type keys struct {
-	nostrSecretKey string
-	nostrPublicKey string
-	appKey         *bip32.Key
-	swapKey        *hdkeychain.ExtendedKey
-	swapMnemonic   string
+	// the hub Nostr key can be rotated while it is in use
+	nostrKeyMu                sync.RWMutex
+	nostrSigner               signer.Signer
+	nostrPublicKey            string
+	previousNostrSigner       signer.Signer
+	previousNostrKeyExpiresAt *time.Time
+	appKey                    *bip32.Key
+	swapKey                   *hdkeychain.ExtendedKey
+	swapMnemonic              string
 }
 
 func NewKeys() *keys {
@@ -56,13 +78,44 @@ func (keys *keys) Init(cfg config.Config, encryptionKey string) error {
 			return err
 		}
 	}
-	nostrPublicKey, err := nostr.GetPublicKey(nostrSecretKey)
+	if closer, ok := keys.nostrSigner.(interface{ Close() }); ok {
+		// keys are re-initialized when the hub is restarted
+		closer.Close()
+	}
+
+	var nostrSigner signer.Signer
+	nostrSignerUrl := cfg.GetEnv().NostrSignerUrl
+	if nostrSignerUrl != "" {
+		// the stored key only authenticates the hub to the remote signer
+		bunkerSigner, err := signer.NewBunkerSigner(nostrSecretKey, nostrSignerUrl)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to connect to remote nostr signer")
+			return fmt.Errorf("the remote nostr signer configured with NOSTR_SIGNER_URL is not reachable, make sure it is running and approved the hub, or remove NOSTR_SIGNER_URL to use the hub nostr key stored in the database: %w", err)
+		}
+		// only the hub key is delegated, see GetAppWalletKey
+		logger.Logger.Info("Using remote signer for the hub nostr key, app wallet keys are signed in-process")
+		nostrSigner = bunkerSigner
+	} else {
+		keySigner, err := signer.NewKeySigner(nostrSecretKey)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Error converting nostr privkey to pubkey")
+			return err
+		}
+		nostrSigner = keySigner
+	}
+
+	previousNostrSigner, previousNostrKeyExpiresAt, err := loadPreviousNostrKey(cfg, encryptionKey)
 	if err != nil {
-		logger.Logger.WithError(err).Error("Error converting nostr privkey to pubkey")
+		logger.Logger.WithError(err).Error("Failed to load previous nostr key")
 		return err
 	}
-	keys.nostrSecretKey = nostrSecretKey
-	keys.nostrPublicKey = nostrPublicKey
+
+	keys.nostrKeyMu.Lock()
+	keys.nostrSigner = nostrSigner
+	keys.nostrPublicKey = nostrSigner.GetPublicKey()
+	keys.previousNostrSigner = previousNostrSigner
+	keys.previousNostrKeyExpiresAt = previousNostrKeyExpiresAt
+	keys.nostrKeyMu.Unlock()
 
 	mnemonic, err := cfg.Get("Mnemonic", encryptionKey)
 	if err != nil {
@@ -131,15 +184,133 @@ func (keys *keys) GetSwapMnemonic() string {
 }
 
 func (keys *keys) GetNostrPublicKey() string {
+	keys.nostrKeyMu.RLock()
+	defer keys.nostrKeyMu.RUnlock()
 	return keys.nostrPublicKey
 }
 
-func (keys *keys) GetNostrSecretKey() string {
-	return keys.nostrSecretKey
+func (keys *keys) GetNostrSigner() signer.Signer {
+	keys.nostrKeyMu.RLock()
+	defer keys.nostrKeyMu.RUnlock()
+	return keys.nostrSigner
+}
+
+func (keys *keys) GetPreviousNostrSigner() signer.Signer {
+	keys.nostrKeyMu.RLock()
+	defer keys.nostrKeyMu.RUnlock()
+	if keys.previousNostrKeyExpiresAt == nil || !keys.previousNostrKeyExpiresAt.After(time.Now()) {
+		return nil
+	}
+	return keys.previousNostrSigner
+}
+
+func (keys *keys) GetPreviousNostrKeyExpiresAt() *time.Time {
+	keys.nostrKeyMu.RLock()
+	defer keys.nostrKeyMu.RUnlock()
+	return keys.previousNostrKeyExpiresAt
+}
+
+func (keys *keys) RotateNostrKey(cfg config.Config, encryptionKey string, gracePeriod time.Duration) error {
+	if cfg.GetEnv().NostrSignerUrl != "" {
+		return errors.New("the hub nostr key is held by the remote signer and cannot be rotated")
+	}
+
+	keys.nostrKeyMu.Lock()
+	defer keys.nostrKeyMu.Unlock()
+
+	nostrSecretKey, err := cfg.Get("NostrSecretKey", encryptionKey)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to decrypt nostr secret key")
+		return err
+	}
+	if nostrSecretKey == "" {
+		return errors.New("nostr secret key not set")
+	}
+	previousNostrSigner, err := signer.NewKeySigner(nostrSecretKey)
+	if err != nil {
+		return err
+	}
+
+	newNostrSecretKey := nostr.GeneratePrivateKey()
+	nostrSigner, err := signer.NewKeySigner(newNostrSecretKey)
+	if err != nil {
+		return err
+	}
+
+	// the new key is saved last, so the current key is kept if saving fails
+	previousNostrKeyExpiresAt := time.Now().Add(gracePeriod)
+	err = cfg.SetUpdate(config.PreviousNostrSecretKeyKey, nostrSecretKey, encryptionKey)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to save previous nostr secret key")
+		return err
+	}
+	err = cfg.SetUpdate(config.PreviousNostrKeyExpiresAtKey, previousNostrKeyExpiresAt.Format(time.RFC3339), "")
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to save previous nostr key expiry")
+		return err
+	}
+	err = cfg.SetUpdate("NostrSecretKey", newNostrSecretKey, encryptionKey)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to save rotated nostr secret key")
+		return err
+	}
+
+	keys.nostrSigner = nostrSigner
+	keys.nostrPublicKey = nostrSigner.GetPublicKey()
+	keys.previousNostrSigner = previousNostrSigner
+	keys.previousNostrKeyExpiresAt = &previousNostrKeyExpiresAt
+	return nil
+}
+
+func (keys *keys) RetirePreviousNostrKey(cfg config.Config) error {
+	keys.nostrKeyMu.Lock()
+	defer keys.nostrKeyMu.Unlock()
+
+	err := cfg.SetUpdate(config.PreviousNostrSecretKeyKey, "", "")
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to remove previous nostr secret key")
+		return err
+	}
+	err = cfg.SetUpdate(config.PreviousNostrKeyExpiresAtKey, "", "")
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to remove previous nostr key expiry")
+		return err
+	}
+
+	keys.previousNostrSigner = nil
+	keys.previousNostrKeyExpiresAt = nil
+	return nil
+}
+
+func loadPreviousNostrKey(cfg config.Config, encryptionKey string) (signer.Signer, *time.Time, error) {
+	expiresAtValue, err := cfg.Get(config.PreviousNostrKeyExpiresAtKey, "")
+	if err != nil || expiresAtValue == "" {
+		return nil, nil, err
+	}
+	expiresAt, err := time.Parse(time.RFC3339, expiresAtValue)
+	if err != nil {
+		return nil, nil, err
+	}
+	previousNostrSecretKey, err := cfg.Get(config.PreviousNostrSecretKeyKey, encryptionKey)
+	if err != nil || previousNostrSecretKey == "" {
+		return nil, nil, err
+	}
+	previousNostrSigner, err := signer.NewKeySigner(previousNostrSecretKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return previousNostrSigner, &expiresAt, nil
 }
 
 func (keys *keys) GetAppWalletKey(appID uint) (string, error) {
+	return keys.GetAppWalletKeyGeneration(appID, 0)
+}
+
+func (keys *keys) GetAppWalletKeyGeneration(appID uint, generation uint) (string, error) {
 	path := []uint32{bip32.FirstHardenedChild + 1, bip32.FirstHardenedChild + uint32(appID)}
+	if generation > 0 {
+		path = append(path, bip32.FirstHardenedChild+uint32(generation))
+	}
 	key, err := keys.DeriveKey(path)
 	if err != nil {
 		return "", err
@@ -1,9 +1,11 @@
 package keys
 
 import (
+	"encoding/hex"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -61,6 +63,21 @@ func TestUseExistingMnemonic(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, "dd9e304d24f29f3481d5cf18a76c85ca3e95931aee3c997a27f267e975e72976", appWalletPubkey)
+
+	// generation 0 is the original app wallet key
+	appWalletPrivateKeyGeneration0, err := keys.GetAppWalletKeyGeneration(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, appWalletPrivateKey, appWalletPrivateKeyGeneration0)
+
+	// rotated keys are derived from the original app wallet key
+	appWalletKey, err := keys.DeriveKey([]uint32{bip32.FirstHardenedChild + 1, bip32.FirstHardenedChild + 2})
+	require.NoError(t, err)
+	rotatedKey, err := appWalletKey.NewChildKey(bip32.FirstHardenedChild + 1)
+	require.NoError(t, err)
+	appWalletPrivateKeyGeneration1, err := keys.GetAppWalletKeyGeneration(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(rotatedKey.Key), appWalletPrivateKeyGeneration1)
+	assert.NotEqual(t, appWalletPrivateKey, appWalletPrivateKeyGeneration1)
 }
 
 func TestGenerateNewMnemonic(t *testing.T) {
@@ -142,3 +159,66 @@ func TestGenerateSwapMnemonic(t *testing.T) {
 	expectedSwapMnemonic := "truth cargo pluck prefer mosquito symptom review kitchen exile fit corn vault"
 	assert.Equal(t, expectedSwapMnemonic, swapMnemonic)
 }
+
+func TestRotateNostrKey(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	gormDb, err := db.NewDB(t)
+	require.NoError(t, err)
+	defer db.CloseDB(gormDb)
+
+	unlockPassword := "123"
+
+	cfg, err := config.NewConfig(&config.AppConfig{}, gormDb)
+	require.NoError(t, err)
+
+	keys := NewKeys()
+	err = keys.Init(cfg, unlockPassword)
+	require.NoError(t, err)
+	previousPubkey := keys.GetNostrPublicKey()
+	assert.Nil(t, keys.GetPreviousNostrSigner())
+
+	err = keys.RotateNostrKey(cfg, unlockPassword, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, previousPubkey, keys.GetNostrPublicKey())
+	assert.Equal(t, keys.GetNostrPublicKey(), keys.GetNostrSigner().GetPublicKey())
+	require.NotNil(t, keys.GetPreviousNostrSigner())
+	assert.Equal(t, previousPubkey, keys.GetPreviousNostrSigner().GetPublicKey())
+	require.NotNil(t, keys.GetPreviousNostrKeyExpiresAt())
+
+	// the previous key is served after a restart
+	restartedKeys := NewKeys()
+	err = restartedKeys.Init(cfg, unlockPassword)
+	require.NoError(t, err)
+	assert.Equal(t, keys.GetNostrPublicKey(), restartedKeys.GetNostrPublicKey())
+	require.NotNil(t, restartedKeys.GetPreviousNostrSigner())
+	assert.Equal(t, previousPubkey, restartedKeys.GetPreviousNostrSigner().GetPublicKey())
+
+	err = restartedKeys.RetirePreviousNostrKey(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, restartedKeys.GetPreviousNostrSigner())
+	assert.Nil(t, restartedKeys.GetPreviousNostrKeyExpiresAt())
+
+	err = restartedKeys.Init(cfg, unlockPassword)
+	require.NoError(t, err)
+	assert.Nil(t, restartedKeys.GetPreviousNostrSigner())
+}
+
+func TestRotateNostrKey_Expired(t *testing.T) {
+	logger.Init(strconv.Itoa(int(logrus.DebugLevel)))
+	gormDb, err := db.NewDB(t)
+	require.NoError(t, err)
+	defer db.CloseDB(gormDb)
+
+	cfg, err := config.NewConfig(&config.AppConfig{}, gormDb)
+	require.NoError(t, err)
+
+	keys := NewKeys()
+	err = keys.Init(cfg, "123")
+	require.NoError(t, err)
+
+	// the previous key is not served once the grace period ended
+	err = keys.RotateNostrKey(cfg, "123", 0)
+	require.NoError(t, err)
+	assert.Nil(t, keys.GetPreviousNostrSigner())
+	assert.NotNil(t, keys.GetPreviousNostrKeyExpiresAt())
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/transactions/metadataindex"
+)
+
+// bump when metadataindex.Extract changes to reindex existing transactions on the next start
+const transactionMetadataIndexVersion = "1"
+
+// reindexTransactionMetadata indexes the metadata of existing transactions once per
+// index version. New transactions are indexed when they are saved.
+func reindexTransactionMetadata(db *gorm.DB, cfg config.Config) error {
+	indexVersion, err := cfg.Get(config.TransactionMetadataIndexKey, "")
+	if err != nil {
+		return err
+	}
+	if indexVersion == transactionMetadataIndexVersion {
+		return nil
+	}
+
+	logger.Logger.WithField("version", transactionMetadataIndexVersion).Info("Indexing transaction metadata")
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return metadataindex.Reindex(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	return cfg.SetUpdate(config.TransactionMetadataIndexKey, transactionMetadataIndexVersion, "")
+}
@@ -4,9 +4,13 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/backups"
+	"github.com/getAlby/hub/channels"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/lsp"
+	"github.com/getAlby/hub/retention"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/getAlby/hub/swaps"
 	"github.com/getAlby/hub/transactions"
@@ -24,6 +28,10 @@ type Service interface {
 	GetLNClient() lnclient.LNClient
 	GetTransactionsService() transactions.TransactionsService
 	GetSwapsService() swaps.SwapsService
+	GetChannelsService() channels.ChannelsService
+	GetLSPService() lsp.LSPService
+	GetBackupsService() backups.BackupsService
+	GetRetentionService() retention.RetentionService
 	GetDB() *gorm.DB
 	GetConfig() config.Config
 	GetKeys() keys.Keys
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
+)
+
+type rotateNostrKeyConsumer struct {
+	events.EventSubscriber
+	svc   *service
+	relay *nostr.Relay
+}
+
+// When the hub Nostr key is rotated, publish the legacy info event for the new key
+// and subscribe to it on the relay. The subscription for the previous key
+// continues until the grace period ends.
+func (s *rotateNostrKeyConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	if event.Event != "nwc_nostr_key_rotated" {
+		return
+	}
+
+	nostrSigner := s.svc.keys.GetNostrSigner()
+	legacyAppCount, err := s.svc.countLegacyApps(nostrSigner.GetPublicKey())
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to count legacy apps")
+		return
+	}
+	if legacyAppCount > 0 {
+		s.svc.nip47Service.EnqueueNip47InfoPublishRequest(0 /* unused */, nostrSigner)
+		s.svc.startLegacyAppSubscription(ctx, s.relay, nostrSigner)
+	}
+
+	s.svc.schedulePreviousNostrKeyRetirement(s.relay)
+}
+
+// startLegacyAppSubscriptions subscribes to the hub Nostr key, which is the wallet key
+// shared by all legacy apps, and to the hub key replaced by the last hub key rotation
+// until its grace period ends
+func (svc *service) startLegacyAppSubscriptions(ctx context.Context, relay *nostr.Relay) {
+	for _, hubNostrSigner := range svc.getHubNostrSigners() {
+		legacyAppCount, err := svc.countLegacyApps(hubNostrSigner.GetPublicKey())
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to count Legacy Apps")
+			continue
+		}
+		if legacyAppCount > 0 {
+			logger.Logger.WithFields(logrus.Fields{
+				"legacy_app_count": legacyAppCount,
+				"wallet_pubkey":    hubNostrSigner.GetPublicKey(),
+			}).Info("Starting legacy app subscription")
+			svc.startLegacyAppSubscription(ctx, relay, hubNostrSigner)
+		}
+	}
+
+	if svc.keys.GetPreviousNostrKeyExpiresAt() != nil {
+		svc.schedulePreviousNostrKeyRetirement(relay)
+	}
+}
+
+// legacy single wallet subscription - only subscribe once per hub key for all legacy apps
+// to ensure we do not get duplicate events
+func (svc *service) startLegacyAppSubscription(ctx context.Context, relay *nostr.Relay, hubNostrSigner signer.Signer) {
+	go func() {
+		err := svc.startAppWalletSubscription(ctx, relay, 0 /* not a single app */, hubNostrSigner)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			// err being non-nil means that we have an error on the websocket error channel. In this case we just try to reconnect.
+			logger.Logger.WithError(err).Error("Got an error from the relay while listening to legacy subscription.")
+		}
+	}()
+}
+
+// getHubNostrSigners returns the signer of the hub Nostr key and of the hub key
+// replaced by the last hub key rotation, until its grace period ends
+func (svc *service) getHubNostrSigners() []signer.Signer {
+	hubNostrSigners := []signer.Signer{svc.keys.GetNostrSigner()}
+	if previousNostrSigner := svc.keys.GetPreviousNostrSigner(); previousNostrSigner != nil {
+		hubNostrSigners = append(hubNostrSigners, previousNostrSigner)
+	}
+	return hubNostrSigners
+}
+
+// countLegacyApps counts the apps which use the hub key with the given pubkey as wallet key,
+// including legacy apps which were rotated to a new key but still serve the legacy key
+func (svc *service) countLegacyApps(hubPubkey string) (int64, error) {
+	query := svc.db.Model(&db.App{}).
+		Where("previous_wallet_pubkey = ? AND previous_keys_expire_at > ?", hubPubkey, time.Now())
+	// apps without a wallet key use the current hub key, and the previous
+	// hub key until the grace period of the hub key rotation ends
+	if apps.GetHubNostrSigner(svc.keys, hubPubkey) != nil {
+		query = query.Or("wallet_pubkey IS NULL")
+	}
+
+	var legacyAppCount int64
+	err := query.Count(&legacyAppCount).Error
+	return legacyAppCount, err
+}
+
+// checkNostrSigner refuses to switch to a remote signer which holds a different key
+// than the hub key stored in the database while legacy apps use the stored key as wallet key
+func (svc *service) checkNostrSigner(encryptionKey string) error {
+	if svc.cfg.GetEnv().NostrSignerUrl == "" {
+		return nil
+	}
+
+	nostrSecretKey, err := svc.cfg.Get("NostrSecretKey", encryptionKey)
+	if err != nil {
+		return err
+	}
+	storedPubkey, err := nostr.GetPublicKey(nostrSecretKey)
+	if err != nil {
+		return err
+	}
+	if storedPubkey == svc.keys.GetNostrPublicKey() {
+		return nil
+	}
+
+	var legacyAppCount int64
+	err = svc.db.Model(&db.App{}).
+		Where("wallet_pubkey IS NULL").
+		Or("previous_wallet_pubkey = ? AND previous_keys_expire_at > ?", storedPubkey, time.Now()).
+		Count(&legacyAppCount).Error
+	if err != nil {
+		return err
+	}
+	if legacyAppCount > 0 {
+		return fmt.Errorf("%d legacy app connections use the hub nostr key as wallet key, rotate their keys before setting NOSTR_SIGNER_URL", legacyAppCount)
+	}
+	return nil
+}
+
+func (svc *service) schedulePreviousNostrKeyRetirement(relay *nostr.Relay) {
+	previousNostrKeyExpiresAt := svc.keys.GetPreviousNostrKeyExpiresAt()
+	if previousNostrKeyExpiresAt == nil {
+		return
+	}
+	previousNostrPubkey := ""
+	if previousNostrSigner := svc.keys.GetPreviousNostrSigner(); previousNostrSigner != nil {
+		previousNostrPubkey = previousNostrSigner.GetPublicKey()
+	}
+
+	go func() {
+		select {
+		case <-relay.Context().Done():
+			// rescheduled when reconnecting to the relay
+			return
+		case <-time.After(time.Until(*previousNostrKeyExpiresAt)):
+		}
+
+		// the hub key may have been rotated again in the meantime
+		currentExpiresAt := svc.keys.GetPreviousNostrKeyExpiresAt()
+		if currentExpiresAt == nil || !currentExpiresAt.Equal(*previousNostrKeyExpiresAt) {
+			return
+		}
+
+		err := svc.keys.RetirePreviousNostrKey(svc.cfg)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to retire previous nostr key")
+			return
+		}
+		logger.Logger.WithField("pubkey", previousNostrPubkey).Info("Retired previous nostr key")
+
+		svc.eventPublisher.Publish(&events.Event{
+			Event: "nwc_nostr_key_retired",
+			Properties: map[string]interface{}{
+				"pubkey": previousNostrPubkey,
+			},
+		})
+	}()
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/tests/mocks"
+)
+
+func TestCountLegacyApps(t *testing.T) {
+	testSvc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer testSvc.Remove()
+
+	svc := &service{
+		db:   testSvc.DB,
+		keys: testSvc.Keys,
+	}
+
+	_, _, err = tests.CreateAppWithSharedWalletPubkey(testSvc, nostr.GeneratePrivateKey(), constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+	_, _, err = tests.CreateApp(testSvc)
+	require.NoError(t, err)
+
+	previousPubkey := testSvc.Keys.GetNostrPublicKey()
+	count, err := svc.countLegacyApps(previousPubkey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// not a hub key
+	count, err = svc.countLegacyApps(nostr.GeneratePrivateKey())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, testSvc.Keys.RotateNostrKey(testSvc.Cfg, "", time.Hour))
+
+	// a legacy app which was rotated to its own key still serves the previous hub key
+	expiresAt := time.Now().Add(time.Hour)
+	rotatedApp := db.App{Name: "rotated", AppPubkey: "rotated-app-pubkey", WalletPubkey: ptr("rotated-wallet-pubkey"), PreviousWalletPubkey: previousPubkey, PreviousKeysExpireAt: &expiresAt}
+	require.NoError(t, testSvc.DB.Create(&rotatedApp).Error)
+
+	count, err = svc.countLegacyApps(testSvc.Keys.GetNostrPublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = svc.countLegacyApps(previousPubkey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// the legacy app does not use the previous hub key anymore after it was retired
+	require.NoError(t, testSvc.Keys.RetirePreviousNostrKey(testSvc.Cfg))
+	count, err = svc.countLegacyApps(previousPubkey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCheckNostrSigner(t *testing.T) {
+	testSvc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer testSvc.Remove()
+
+	remoteSignerKeys := mocks.NewMockKeys(t)
+	remoteSignerKeys.On("GetNostrPublicKey").Return(nostr.GeneratePrivateKey())
+	svc := &service{
+		db:   testSvc.DB,
+		cfg:  testSvc.Cfg,
+		keys: remoteSignerKeys,
+	}
+
+	// no remote signer configured
+	require.NoError(t, svc.checkNostrSigner(""))
+
+	testSvc.Cfg.GetEnv().NostrSignerUrl = "bunker://remote-signer"
+	_, _, err = tests.CreateApp(testSvc)
+	require.NoError(t, err)
+	require.NoError(t, svc.checkNostrSigner(""))
+
+	_, _, err = tests.CreateAppWithSharedWalletPubkey(testSvc, nostr.GeneratePrivateKey(), constants.ENCRYPTION_TYPE_NIP44_V2)
+	require.NoError(t, err)
+	assert.EqualError(t, svc.checkNostrSigner(""), "1 legacy app connections use the hub nostr key as wallet key, rotate their keys before setting NOSTR_SIGNER_URL")
+
+	// the remote signer holds the stored hub key
+	svc.keys = testSvc.Keys
+	require.NoError(t, svc.checkNostrSigner(""))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/logger"
+)
+
+const onchainTransactionsPollInterval = 1 * time.Minute
+
+// Publishes an event once an incoming on-chain transaction is confirmed.
+// The notified transactions are stored by txid so that transactions which confirm
+// while the hub is offline are notified after the next start. Transactions which
+// were already confirmed when the watcher first ran are ignored.
+func (svc *service) watchOnchainTransactions(ctx context.Context, lnClient lnclient.LNClient) {
+	go func() {
+		ticker := time.NewTicker(onchainTransactionsPollInterval)
+		defer ticker.Stop()
+		for {
+			err := svc.checkOnchainTransactions(ctx, lnClient)
+			if errors.Is(err, errors.ErrUnsupported) {
+				return
+			}
+			if err != nil {
+				logger.Logger.WithError(err).Error("Failed to check onchain transactions")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (svc *service) checkOnchainTransactions(ctx context.Context, lnClient lnclient.LNClient) error {
+	transactions, err := lnClient.ListOnchainTransactions(ctx)
+	if err != nil {
+		return err
+	}
+
+	initializedValue, err := svc.cfg.Get(config.OnchainWatcherInitializedKey, "")
+	if err != nil {
+		return err
+	}
+	// on the first run all confirmed transactions are stored without notifying
+	initialized := initializedValue != ""
+
+	confirmedTransactions := map[string]lnclient.OnchainTransaction{}
+	confirmedTxIds := []string{}
+	for _, transaction := range transactions {
+		if transaction.Type != "incoming" || transaction.State != "confirmed" {
+			continue
+		}
+		confirmedTransactions[transaction.TxId] = transaction
+		confirmedTxIds = append(confirmedTxIds, transaction.TxId)
+	}
+
+	var notifiedTxIds []string
+	if len(confirmedTxIds) > 0 {
+		err = svc.db.Model(&db.OnchainNotifiedTransaction{}).
+			Where("tx_id IN ?", confirmedTxIds).
+			Pluck("tx_id", &notifiedTxIds).Error
+		if err != nil {
+			return err
+		}
+	}
+	for _, txId := range notifiedTxIds {
+		delete(confirmedTransactions, txId)
+	}
+
+	newTransactions := []lnclient.OnchainTransaction{}
+	notifiedTransactions := []db.OnchainNotifiedTransaction{}
+	for _, txId := range confirmedTxIds {
+		if transaction, ok := confirmedTransactions[txId]; ok {
+			newTransactions = append(newTransactions, transaction)
+			notifiedTransactions = append(notifiedTransactions, db.OnchainNotifiedTransaction{TxId: txId})
+		}
+	}
+
+	// persist before publishing so events are not published twice
+	if len(notifiedTransactions) > 0 {
+		err = svc.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notifiedTransactions).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if !initialized {
+		return svc.cfg.SetUpdate(config.OnchainWatcherInitializedKey, "true", "")
+	}
+	for _, transaction := range newTransactions {
+		svc.eventPublisher.Publish(&events.Event{
+			Event:      "nwc_onchain_received",
+			Properties: &transaction,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/tests"
+	"github.com/getAlby/hub/tests/mocks"
+)
+
+func TestCheckOnchainTransactions(t *testing.T) {
+	testSvc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer testSvc.Remove()
+
+	eventPublisher := mocks.NewMockEventPublisher(t)
+	lnClient := mocks.NewMockLNClient(t)
+	svc := &service{
+		db:             testSvc.DB,
+		cfg:            testSvc.Cfg,
+		eventPublisher: eventPublisher,
+	}
+
+	existingTransaction := lnclient.OnchainTransaction{TxId: "existing", Type: "incoming", State: "confirmed"}
+	unconfirmedTransaction := lnclient.OnchainTransaction{TxId: "new", Type: "incoming", State: "unconfirmed"}
+	outgoingTransaction := lnclient.OnchainTransaction{TxId: "outgoing", Type: "outgoing", State: "confirmed"}
+
+	// transactions which are confirmed on the first run are not notified
+	lnClient.On("ListOnchainTransactions", mock.Anything).Return([]lnclient.OnchainTransaction{existingTransaction, unconfirmedTransaction, outgoingTransaction}, nil).Once()
+	require.NoError(t, svc.checkOnchainTransactions(context.TODO(), lnClient))
+
+	// the transaction confirmed while the hub was offline: the notified
+	// transactions are persisted so a fresh service still publishes it
+	svc = &service{
+		db:             testSvc.DB,
+		cfg:            testSvc.Cfg,
+		eventPublisher: eventPublisher,
+	}
+	confirmedTransaction := unconfirmedTransaction
+	confirmedTransaction.State = "confirmed"
+	lnClient.On("ListOnchainTransactions", mock.Anything).Return([]lnclient.OnchainTransaction{existingTransaction, confirmedTransaction, outgoingTransaction}, nil).Twice()
+	eventPublisher.On("Publish", mock.MatchedBy(func(event *events.Event) bool {
+		return event.Event == "nwc_onchain_received" && event.Properties.(*lnclient.OnchainTransaction).TxId == "new"
+	})).Once()
+	require.NoError(t, svc.checkOnchainTransactions(context.TODO(), lnClient))
+
+	// already notified
+	require.NoError(t, svc.checkOnchainTransactions(context.TODO(), lnClient))
+	eventPublisher.AssertNumberOfCalls(t, "Publish", 1)
+}
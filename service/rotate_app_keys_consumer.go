@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+)
+
+type rotateAppKeysConsumer struct {
+	events.EventSubscriber
+	svc   *service
+	relay *nostr.Relay
+}
+
+// When the keys of an app are rotated, publish the info event for the new
+// wallet key and subscribe to it on the relay. The subscription for the
+// previous wallet key continues until the grace period ends.
+func (s *rotateAppKeysConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	if event.Event != "nwc_app_keys_rotated" {
+		return
+	}
+
+	properties, ok := event.Properties.(map[string]interface{})
+	if !ok {
+		logger.Logger.WithField("event", event).Error("Failed to cast event.Properties to map")
+		return
+	}
+	id, ok := properties["id"].(uint)
+	if !ok {
+		logger.Logger.WithField("event", event).Error("Failed to get app id")
+		return
+	}
+
+	app := db.App{}
+	err := s.svc.db.First(&app, &db.App{
+		ID: id,
+	}).Error
+	if err != nil {
+		logger.Logger.WithFields(logrus.Fields{
+			"id": id,
+		}).WithError(err).Error("Failed to find app for id")
+		return
+	}
+
+	walletSigner, err := apps.GetAppWalletSigner(s.svc.keys, &app)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to calculate app wallet priv key")
+		return
+	}
+	s.svc.nip47Service.EnqueueNip47InfoPublishRequest(id, walletSigner)
+
+	go func() {
+		err = s.svc.startAppWalletSubscription(ctx, s.relay, id, walletSigner)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id": id}).Error("Failed to subscribe to wallet")
+		}
+		logger.Logger.WithFields(logrus.Fields{
+			"app_id": id}).Info("App Nostr Subscription ended")
+	}()
+
+	// the previous wallet key is already subscribed, only its retirement is scheduled
+	if app.PreviousKeysExpireAt != nil {
+		s.svc.schedulePreviousAppKeysRetirement(s.relay, app)
+	}
+}
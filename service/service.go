@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,11 +19,17 @@ import (
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/backups"
+	"github.com/getAlby/hub/channels"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/lsp"
+	"github.com/getAlby/hub/retention"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/getAlby/hub/swaps"
+	"github.com/getAlby/hub/tracing"
 	"github.com/getAlby/hub/transactions"
+	"github.com/getAlby/hub/utils"
 	"github.com/getAlby/hub/version"
 
 	"github.com/getAlby/hub/config"
@@ -39,6 +46,10 @@ type service struct {
 	lnClient            lnclient.LNClient
 	transactionsService transactions.TransactionsService
 	swapsService        swaps.SwapsService
+	channelsService     channels.ChannelsService
+	lspService          lsp.LSPService
+	backupsService      backups.BackupsService
+	retentionService    retention.RetentionService
 	albySvc             alby.AlbyService
 	albyOAuthSvc        alby.AlbyOAuthService
 	eventPublisher      events.EventPublisher
@@ -49,6 +60,8 @@ type service struct {
 	keys                keys.Keys
 	isRelayReady        atomic.Bool
 	startupState        string
+	shutdownTracing     func(context.Context) error
+	lockFile            *os.File
 }
 
 func NewService(ctx context.Context) (*service, error) {
@@ -70,6 +83,15 @@ func NewService(ctx context.Context) (*service, error) {
 	// make sure workdir exists
 	os.MkdirAll(appConfig.Workdir, os.ModePerm)
 
+	// the lock is checked by hubctl before changing the database of a stopped hub
+	lockFile, err := utils.LockFile(filepath.Join(appConfig.Workdir, config.LockFileName))
+	if errors.Is(err, utils.ErrFileLocked) {
+		return nil, errors.New("another Alby Hub is already running with this work directory")
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if appConfig.LogToFile {
 		err = logger.AddFileLogger(appConfig.Workdir)
 		if err != nil {
@@ -97,11 +119,23 @@ func NewService(ctx context.Context) (*service, error) {
 		return nil, err
 	}
 
+	err = backups.FinishDatabaseRestore(gormDB, appConfig.Workdir)
+	if err != nil {
+		logger.Logger.WithError(err).Error("failed to import restored database")
+		return nil, err
+	}
+
 	cfg, err := config.NewConfig(appConfig, gormDB)
 	if err != nil {
 		return nil, err
 	}
 
+	err = reindexTransactionMetadata(gormDB, cfg)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to index transaction metadata")
+		return nil, err
+	}
+
 	// write auto unlock password from env to user config
 	if appConfig.AutoUnlockPassword != "" {
 		err = cfg.SetUpdate("AutoUnlockPassword", appConfig.AutoUnlockPassword, "")
@@ -135,11 +169,18 @@ func NewService(ctx context.Context) (*service, error) {
 		transactionsService: transactionsSvc,
 		db:                  gormDB,
 		keys:                keys,
+		backupsService:      backups.NewBackupsService(gormDB, cfg),
+		retentionService:    retention.NewRetentionService(gormDB, cfg),
+		lockFile:            lockFile,
 	}
 
 	eventPublisher.RegisterSubscriber(svc.transactionsService)
 	eventPublisher.RegisterSubscriber(svc.nip47Service)
 	eventPublisher.RegisterSubscriber(svc.albyOAuthSvc)
+	eventPublisher.RegisterSubscriber(svc.backupsService)
+	eventPublisher.RegisterSubscriber(&walletAuthConsumer{
+		svc: svc,
+	})
 	eventPublisher.RegisterSubscriber(&paymentForwardedConsumer{
 		db: gormDB,
 	})
@@ -155,6 +196,14 @@ func NewService(ctx context.Context) (*service, error) {
 		startProfiler(ctx, appConfig.GoProfilerAddr)
 	}
 
+	if appConfig.TracingOtlpEndpoint != "" {
+		svc.shutdownTracing, err = tracing.Init(ctx, appConfig.TracingOtlpEndpoint)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to start tracing")
+			return nil, err
+		}
+	}
+
 	if autoUnlockPassword != "" {
 		nodeLastStartTime, _ := cfg.Get("NodeLastStartTime", "")
 		if nodeLastStartTime != "" {
@@ -240,6 +289,16 @@ func (svc *service) Shutdown() {
 		Event: "nwc_stopped",
 	})
 	db.Stop(svc.db)
+	svc.lockFile.Close()
+	if svc.shutdownTracing != nil {
+		// flush the remaining spans
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := svc.shutdownTracing(ctx)
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to shut down tracing")
+		}
+	}
 }
 
 func (svc *service) GetDB() *gorm.DB {
@@ -278,6 +337,22 @@ func (svc *service) GetSwapsService() swaps.SwapsService {
 	return svc.swapsService
 }
 
+func (svc *service) GetChannelsService() channels.ChannelsService {
+	return svc.channelsService
+}
+
+func (svc *service) GetLSPService() lsp.LSPService {
+	return svc.lspService
+}
+
+func (svc *service) GetBackupsService() backups.BackupsService {
+	return svc.backupsService
+}
+
+func (svc *service) GetRetentionService() retention.RetentionService {
+	return svc.retentionService
+}
+
 func (svc *service) GetKeys() keys.Keys {
 	return svc.keys
 }
@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/channels"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/swaps"
 	"github.com/getAlby/hub/version"
@@ -25,6 +27,9 @@ import (
 	"github.com/getAlby/hub/lnclient/lnd"
 	"github.com/getAlby/hub/lnclient/phoenixd"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/lsp"
+	"github.com/getAlby/hub/nip47/archive"
+	"github.com/getAlby/hub/nostr/signer"
 )
 
 func (svc *service) startNostr(ctx context.Context) error {
@@ -50,6 +55,8 @@ func (svc *service) startNostr(ctx context.Context) error {
 		waitToReconnectSeconds := 0
 		var createAppEventListener events.EventSubscriber
 		var updateAppEventListener events.EventSubscriber
+		var rotateAppKeysEventListener events.EventSubscriber
+		var rotateNostrKeyEventListener events.EventSubscriber
 		for i := 0; ; i++ {
 			// wait for a delay if any before retrying
 			contextCancelled := false
@@ -114,28 +121,25 @@ func (svc *service) startNostr(ctx context.Context) error {
 			updateAppEventListener = &updateAppConsumer{svc: svc, relay: relay}
 			svc.eventPublisher.RegisterSubscriber(updateAppEventListener)
 
+			// register a subscriber for events of "nwc_app_keys_rotated" which handles subscribing to the new app wallet key
+			if rotateAppKeysEventListener != nil {
+				svc.eventPublisher.RemoveSubscriber(rotateAppKeysEventListener)
+			}
+			rotateAppKeysEventListener = &rotateAppKeysConsumer{svc: svc, relay: relay}
+			svc.eventPublisher.RegisterSubscriber(rotateAppKeysEventListener)
+
+			// register a subscriber for events of "nwc_nostr_key_rotated" which handles subscribing to the new hub key
+			if rotateNostrKeyEventListener != nil {
+				svc.eventPublisher.RemoveSubscriber(rotateNostrKeyEventListener)
+			}
+			rotateNostrKeyEventListener = &rotateNostrKeyConsumer{svc: svc, relay: relay}
+			svc.eventPublisher.RegisterSubscriber(rotateNostrKeyEventListener)
+
 			// start each app wallet subscription which have a child derived wallet key
 			svc.startAllExistingAppsWalletSubscriptions(ctx, relay)
 
 			// check if there are still legacy apps in DB
-			var legacyAppCount int64
-			result := svc.db.Model(&db.App{}).Where("wallet_pubkey IS NULL").Count(&legacyAppCount)
-			if result.Error != nil {
-				logger.Logger.WithError(result.Error).Error("Failed to count Legacy Apps")
-				return
-			}
-			if legacyAppCount > 0 {
-				go func() {
-					logger.Logger.WithField("legacy_app_count", legacyAppCount).Info("Starting legacy app subscription")
-					// legacy single wallet subscription - only subscribe once for all legacy apps
-					// to ensure we do not get duplicate events
-					err = svc.startAppWalletSubscription(ctx, relay, svc.keys.GetNostrPublicKey())
-					if err != nil && !errors.Is(err, context.Canceled) {
-						// err being non-nil means that we have an error on the websocket error channel. In this case we just try to reconnect.
-						logger.Logger.WithError(err).Error("Got an error from the relay while listening to legacy subscription.")
-					}
-				}()
-			}
+			svc.startLegacyAppSubscriptions(ctx, relay)
 
 			svc.setRelayReady(true)
 
@@ -161,71 +165,122 @@ func (svc *service) startNostr(ctx context.Context) error {
 // new capabilities, we re-publish info events for all apps on startup
 // to ensure that they are retrievable for all connections
 func (svc *service) publishAllAppInfoEvents() {
-	func() {
-		var legacyAppCount int64
-		result := svc.db.Model(&db.App{}).Where("wallet_pubkey IS NULL").Count(&legacyAppCount)
-		if result.Error != nil {
-			logger.Logger.WithError(result.Error).Error("Failed to fetch App records with empty WalletPubkey")
-			return
+	// legacy apps use the hub key, and the previous hub key during the grace period of a hub key rotation
+	for _, hubNostrSigner := range svc.getHubNostrSigners() {
+		legacyAppCount, err := svc.countLegacyApps(hubNostrSigner.GetPublicKey())
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to fetch App records with empty WalletPubkey")
+			continue
 		}
 		if legacyAppCount > 0 {
 			logger.Logger.WithField("legacy_app_count", legacyAppCount).Debug("Enqueuing publish of legacy info event")
-			svc.nip47Service.EnqueueNip47InfoPublishRequest(0 /* unused */, svc.keys.GetNostrPublicKey(), svc.keys.GetNostrSecretKey())
+			svc.nip47Service.EnqueueNip47InfoPublishRequest(0 /* unused */, hubNostrSigner)
 		}
-	}()
+	}
 
-	var apps []db.App
-	result := svc.db.Where("wallet_pubkey IS NOT NULL").Find(&apps)
+	var dbApps []db.App
+	result := svc.db.Where("wallet_pubkey IS NOT NULL").Find(&dbApps)
 	if result.Error != nil {
 		logger.Logger.WithError(result.Error).Error("Failed to fetch App records with non-empty WalletPubkey")
 		return
 	}
 
-	for _, app := range apps {
+	for _, app := range dbApps {
 		func(app db.App) {
 			// queue info event publish request for all existing apps
-			walletPrivKey, err := svc.keys.GetAppWalletKey(app.ID)
+			walletSigner, err := apps.GetAppWalletSigner(svc.keys, &app)
 			if err != nil {
 				logger.Logger.WithError(err).WithFields(logrus.Fields{
 					"app_id": app.ID}).Error("Could not get app wallet key")
 				return
 			}
 			logger.Logger.WithField("app_id", app.ID).Debug("Enqueuing publish of app info event")
-			svc.nip47Service.EnqueueNip47InfoPublishRequest(app.ID, *app.WalletPubkey, walletPrivKey)
+			svc.nip47Service.EnqueueNip47InfoPublishRequest(app.ID, walletSigner)
 		}(app)
 	}
 }
 
 func (svc *service) startAllExistingAppsWalletSubscriptions(ctx context.Context, relay *nostr.Relay) {
-	var apps []db.App
-	result := svc.db.Where("wallet_pubkey IS NOT NULL").Find(&apps)
+	var dbApps []db.App
+	result := svc.db.Where("wallet_pubkey IS NOT NULL").Find(&dbApps)
 	if result.Error != nil {
 		logger.Logger.WithError(result.Error).Error("Failed to fetch App records with non-empty WalletPubkey")
 		return
 	}
 
-	for _, app := range apps {
+	for _, app := range dbApps {
+		walletSigner, err := apps.GetAppWalletSigner(svc.keys, &app)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id": app.ID}).Error("Could not get app wallet key")
+			continue
+		}
 		go func(app db.App) {
-			err := svc.startAppWalletSubscription(ctx, relay, *app.WalletPubkey)
+			err := svc.startAppWalletSubscription(ctx, relay, app.ID, walletSigner)
 			if err != nil && !errors.Is(err, context.Canceled) {
 				logger.Logger.WithError(err).WithFields(logrus.Fields{
 					"app_id": app.ID}).Error("Subscription error")
 				return
 			}
 		}(app)
+
+		if app.PreviousWalletPubkey != "" {
+			svc.startPreviousAppWalletSubscription(ctx, relay, app)
+		}
+	}
+}
+
+// startPreviousAppWalletSubscription keeps serving the wallet key replaced by a key
+// rotation until the grace period ends, and then retires the previous keys
+func (svc *service) startPreviousAppWalletSubscription(ctx context.Context, relay *nostr.Relay, app db.App) {
+	// the legacy wallet key is served by the legacy subscription
+	if apps.HasPreviousKeys(&app) && apps.GetHubNostrSigner(svc.keys, app.PreviousWalletPubkey) == nil {
+		previousWalletSigner, err := apps.GetPreviousAppWalletSigner(svc.keys, &app)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id": app.ID}).Error("Could not get previous app wallet key")
+		} else {
+			go func() {
+				err := svc.startAppWalletSubscription(ctx, relay, app.ID, previousWalletSigner)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					logger.Logger.WithError(err).WithFields(logrus.Fields{
+						"app_id": app.ID}).Error("Previous wallet key subscription error")
+				}
+			}()
+		}
 	}
+
+	svc.schedulePreviousAppKeysRetirement(relay, app)
 }
 
-func (svc *service) startAppWalletSubscription(ctx context.Context, relay *nostr.Relay, appWalletPubKey string) error {
+func (svc *service) schedulePreviousAppKeysRetirement(relay *nostr.Relay, app db.App) {
+	go func() {
+		if app.PreviousKeysExpireAt != nil {
+			select {
+			case <-relay.Context().Done():
+				// rescheduled when reconnecting to the relay
+				return
+			case <-time.After(time.Until(*app.PreviousKeysExpireAt)):
+			}
+		}
+		err := apps.NewAppsService(svc.db, svc.eventPublisher, svc.keys, svc.cfg).RetirePreviousAppKeys(&app)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id": app.ID}).Error("Failed to retire previous app keys")
+		}
+	}()
+}
 
+func (svc *service) startAppWalletSubscription(ctx context.Context, relay *nostr.Relay, appId uint, appWalletSigner signer.Signer) error {
+	appWalletPubKey := appWalletSigner.GetPublicKey()
 	logger.Logger.Info("Subscribing to events for wallet ", appWalletPubKey)
 	sub, err := relay.Subscribe(ctx, svc.createFilters(appWalletPubKey))
 	if err != nil {
 		return fmt.Errorf("failed to subscribe to events: %w", err)
 	}
 
-	// register a subscriber for "nwc_app_deleted" events, which handles nostr subscription cancel and nip47 info event deletion
-	deleteEventSubscriber := deleteAppConsumer{nostrSubscription: sub, walletPubkey: appWalletPubKey, svc: svc, relay: relay}
+	// register a subscriber for "nwc_app_deleted" and "nwc_app_keys_retired" events, which handles nostr subscription cancel and nip47 info event deletion
+	deleteEventSubscriber := deleteAppConsumer{nostrSubscription: sub, appId: appId, walletSigner: appWalletSigner, svc: svc, relay: relay}
 	svc.eventPublisher.RegisterSubscriber(&deleteEventSubscriber)
 
 	err = svc.StartSubscription(sub.Context, sub)
@@ -285,6 +340,13 @@ func (svc *service) StartApp(encryptionKey string) error {
 		return err
 	}
 
+	err = svc.checkNostrSigner(encryptionKey)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to switch to the remote nostr signer")
+		cancelFn()
+		return err
+	}
+
 	svc.startupState = "Launching Node"
 	err = svc.launchLNBackend(ctx, encryptionKey)
 	if err != nil {
@@ -297,6 +359,13 @@ func (svc *service) StartApp(encryptionKey string) error {
 	}
 
 	svc.swapsService = swaps.NewSwapsService(ctx, svc.db, svc.cfg, svc.keys, svc.eventPublisher, svc.lnClient, svc.transactionsService)
+	svc.channelsService = channels.NewChannelsService(ctx, svc.db, svc.cfg, svc.GetLNClient)
+	svc.lspService = lsp.NewLSPService(ctx, svc.db, svc.albyOAuthSvc)
+	archive.NewRequestArchiveService(svc.db, svc.cfg, svc.keys).StartPruner(ctx)
+	svc.watchOnchainTransactions(ctx, svc.lnClient)
+	svc.watchHoldInvoices(ctx, svc.lnClient)
+	svc.backupsService.Start(ctx, encryptionKey)
+	svc.retentionService.Start(ctx)
 
 	svc.publishAllAppInfoEvents()
 
@@ -355,7 +424,13 @@ func (svc *service) launchLNBackend(ctx context.Context, encryptionKey string) e
 		setStartupState := func(startupState string) {
 			svc.startupState = startupState
 		}
-		lnClient, err = ldk.NewLDKService(ctx, svc.cfg, svc.eventPublisher, mnemonic, ldkWorkdir, svc.cfg.GetNetwork(), vssToken, setStartupState)
+		var trustedPeers0conf []string
+		trustedPeers0conf, err = lsp.ListTrustedPubkeys(svc.db, svc.cfg.GetNetwork())
+		if err != nil {
+			logger.Logger.WithError(err).Error("Failed to list trusted LSP pubkeys")
+			return err
+		}
+		lnClient, err = ldk.NewLDKService(ctx, svc.cfg, svc.eventPublisher, mnemonic, ldkWorkdir, svc.cfg.GetNetwork(), vssToken, trustedPeers0conf, setStartupState)
 	case config.PhoenixBackendType:
 		PhoenixdAddress, _ := svc.cfg.Get("PhoenixdAddress", encryptionKey)
 		PhoenixdAuthorization, _ := svc.cfg.Get("PhoenixdAuthorization", encryptionKey)
@@ -380,6 +455,10 @@ func (svc *service) launchLNBackend(ctx context.Context, encryptionKey string) e
 	// (e.g. lnClient.CheckConnection()) Rather than it being a side-effect
 	// in the LNClient init function
 
+	if svc.cfg.GetEnv().TracingOtlpEndpoint != "" {
+		lnClient = lnclient.NewTracingLNClient(lnClient)
+	}
+
 	svc.lnClient = lnClient
 	info, err := lnClient.GetInfo(ctx)
 	if err != nil {
@@ -3,9 +3,12 @@ package service
 import (
 	"context"
 
+	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/logger"
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
 )
 
 type updateAppConsumer struct {
@@ -30,19 +33,27 @@ func (s *updateAppConsumer) ConsumeEvent(ctx context.Context, event *events.Even
 		logger.Logger.WithField("event", event).Error("Failed to get app id")
 		return
 	}
-	walletPrivKey, err := s.svc.keys.GetAppWalletKey(id)
+
+	app := db.App{}
+	err := s.svc.db.First(&app, &db.App{
+		ID: id,
+	}).Error
 	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to calculate app wallet priv key")
+		logger.Logger.WithFields(logrus.Fields{
+			"id": id,
+		}).WithError(err).Error("Failed to find app for id")
 		return
 	}
-	walletPubKey, err := nostr.GetPublicKey(walletPrivKey)
-	if err != nil {
-		logger.Logger.WithError(err).Error("Failed to calculate app wallet pub key")
+
+	// only need to re-publish the nip47 event info if it is not a legacy wallet
+	if app.WalletPubkey == nil {
 		return
 	}
 
-	if s.svc.keys.GetNostrPublicKey() != walletPubKey {
-		// only need to re-publish the nip47 event info if it is not a legacy wallet
-		s.svc.nip47Service.EnqueueNip47InfoPublishRequest(id, walletPubKey, walletPrivKey)
+	walletSigner, err := apps.GetAppWalletSigner(s.svc.keys, &app)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to calculate app wallet priv key")
+		return
 	}
+	s.svc.nip47Service.EnqueueNip47InfoPublishRequest(id, walletSigner)
 }
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/nostr/signer"
+	"github.com/getAlby/hub/version"
+)
+
+const walletAuthPublishTimeout = 30 * time.Second
+
+type walletAuthConsumer struct {
+	events.EventSubscriber
+	svc *service
+}
+
+// When a wallet auth request is accepted, publish the nip47 info event to the relays
+// requested by the app so that it receives the wallet pubkey. The hub relay is
+// handled by the info publisher of the created app
+func (s *walletAuthConsumer) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	if event.Event != "nwc_wallet_auth_accepted" {
+		return
+	}
+
+	properties, ok := event.Properties.(map[string]interface{})
+	if !ok {
+		logger.Logger.WithField("event", event).Error("Failed to cast event.Properties to map")
+		return
+	}
+	id, ok := properties["id"].(uint)
+	if !ok {
+		logger.Logger.WithField("event", event).Error("Failed to get app id")
+		return
+	}
+	relayUrls, _ := properties["relays"].([]string)
+
+	lnClient := s.svc.GetLNClient()
+	if lnClient == nil {
+		logger.Logger.WithField("app_id", id).Error("Cannot publish wallet auth info event: LNClient not started")
+		return
+	}
+
+	walletPrivKey, err := s.svc.keys.GetAppWalletKey(id)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to calculate app wallet priv key")
+		return
+	}
+	walletSigner, err := signer.NewKeySigner(walletPrivKey)
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to calculate app wallet pub key")
+		return
+	}
+
+	hubRelayUrl := strings.TrimSuffix(s.svc.cfg.GetRelayUrl(), "/")
+	for _, relayUrl := range relayUrls {
+		if strings.TrimSuffix(relayUrl, "/") == hubRelayUrl {
+			continue
+		}
+		err := s.publishNip47Info(relayUrl, id, walletSigner)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"app_id":    id,
+				"relay_url": relayUrl,
+			}).Error("Failed to publish wallet auth info event")
+		}
+	}
+}
+
+func (s *walletAuthConsumer) publishNip47Info(relayUrl string, appId uint, walletSigner signer.Signer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), walletAuthPublishTimeout)
+	defer cancel()
+
+	relay, err := nostr.RelayConnect(
+		ctx,
+		relayUrl,
+		nostr.WithRequestHeader(http.Header{
+			"User-Agent": {"AlbyHub/" + version.Tag},
+		}))
+	if err != nil {
+		return err
+	}
+	defer closeRelay(relay)
+
+	_, err = s.svc.nip47Service.PublishNip47Info(ctx, relay, appId, walletSigner, s.svc.GetLNClient())
+	return err
+}
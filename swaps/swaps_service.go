@@ -8,24 +8,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/BoltzExchange/boltz-client/v2/pkg/boltz"
 	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
-	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/mempool"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/getAlby/hub/transactions"
 	decodepay "github.com/nbd-wtf/ln-decodepay"
@@ -49,6 +45,8 @@ type swapsService struct {
 	boltzWs             *boltz.Websocket
 	swapListeners       map[string]chan boltz.SwapUpdate
 	swapListenersLock   sync.Mutex
+	mempool             *mempool.Client
+	esplora             *mempool.Client
 }
 
 type SwapsService interface {
@@ -67,26 +65,6 @@ const (
 	AlbySwapServiceFee = 1.0
 )
 
-type FeeRates struct {
-	FastestFee  uint64 `json:"fastestFee"`
-	HalfHourFee uint64 `json:"halfHourFee"`
-	HourFee     uint64 `json:"hourFee"`
-	EconomyFee  uint64 `json:"economyFee"`
-	MinimumFee  uint64 `json:"minimumFee"`
-}
-
-type TxStatusInfo struct {
-	Confirmed   bool   `json:"confirmed"`
-	BlockHeight uint32 `json:"block_height"`
-	BlockHash   string `json:"block_hash"`
-	BlockTime   uint64 `json:"block_time"`
-}
-
-type MempoolTx struct {
-	TxId   string       `json:"txid"`
-	Status TxStatusInfo `json:"status"`
-}
-
 type SwapInfo struct {
 	AlbyServiceFee  float64 `json:"albyServiceFee"`
 	BoltzServiceFee float64 `json:"boltzServiceFee"`
@@ -126,6 +104,8 @@ func NewSwapsService(ctx context.Context, db *gorm.DB, cfg config.Config, keys k
 		transactionsService: transactionsService,
 		lnClient:            lnClient,
 		boltzApi:            boltzApi,
+		mempool:             mempool.NewClient(cfg),
+		esplora:             mempool.NewEsploraClient(cfg),
 		boltzWs:             boltzWs,
 		swapListeners:       make(map[string]chan boltz.SwapUpdate),
 	}
@@ -580,6 +560,17 @@ func (svc *swapsService) markSwapState(dbSwap *db.Swap, state string) {
 	}).Error
 	if dbErr != nil {
 		logger.Logger.WithError(dbErr).WithField("swapId", dbSwap.SwapId).Error("Failed to update swap state")
+		return
+	}
+
+	if state == constants.SWAP_STATE_FAILED {
+		svc.eventPublisher.Publish(&events.Event{
+			Event: "nwc_swap_failed",
+			Properties: map[string]interface{}{
+				"swapType": dbSwap.Type,
+				"swapId":   dbSwap.SwapId,
+			},
+		})
 	}
 }
 
@@ -963,6 +954,7 @@ func (svc *swapsService) startSwapInListener(swap *db.Swap) {
 					Event: "nwc_swap_succeeded",
 					Properties: map[string]interface{}{
 						"swapType": constants.SWAP_TYPE_IN,
+						"swapId":   swap.SwapId,
 					},
 				})
 				return
@@ -1106,6 +1098,7 @@ func (svc *swapsService) startSwapOutListener(swap *db.Swap) {
 						Event: "nwc_swap_succeeded",
 						Properties: map[string]interface{}{
 							"swapType": constants.SWAP_TYPE_OUT,
+							"swapId":   swap.SwapId,
 						},
 					})
 					return
@@ -1132,7 +1125,7 @@ func (svc *swapsService) startSwapOutListener(swap *db.Swap) {
 						"swap_id": swap.SwapId,
 					}
 					logger.Logger.WithField("swapId", swap.SwapId).Info("Initiating swap invoice payment")
-					_, err = svc.transactionsService.SendPaymentSync(swap.Invoice, nil, metadata, svc.lnClient, nil, nil)
+					_, err = svc.transactionsService.SendPaymentSync(svc.ctx, swap.Invoice, nil, metadata, svc.lnClient, nil, nil)
 					if err != nil {
 						logger.Logger.WithError(err).WithFields(logrus.Fields{
 							"swapId": swap.SwapId,
@@ -1206,7 +1199,7 @@ func (svc *swapsService) startSwapOutListener(swap *db.Swap) {
 					fee := lockupAmount - swap.ReceiveAmount
 					boltzFee.Sats = &fee
 				} else {
-					var feeRates *FeeRates
+					var feeRates *mempool.FeeRates
 					feeRates, err = svc.getFeeRates()
 					if err != nil {
 						logger.Logger.WithError(err).WithFields(logrus.Fields{
@@ -1290,8 +1283,8 @@ func (svc *swapsService) startSwapOutListener(swap *db.Swap) {
 	}
 }
 
-func (svc *swapsService) getMempoolTx(txId string) (*MempoolTx, error) {
-	var transaction MempoolTx
+func (svc *swapsService) getMempoolTx(txId string) (*mempool.Tx, error) {
+	var transaction mempool.Tx
 	endpoint := fmt.Sprintf("/tx/%s", txId)
 	if err := svc.requestMempoolApi(endpoint, &transaction); err != nil {
 		return nil, err
@@ -1299,8 +1292,8 @@ func (svc *swapsService) getMempoolTx(txId string) (*MempoolTx, error) {
 	return &transaction, nil
 }
 
-func (svc *swapsService) getFeeRates() (*FeeRates, error) {
-	var rates FeeRates
+func (svc *swapsService) getFeeRates() (*mempool.FeeRates, error) {
+	var rates mempool.FeeRates
 	if err := svc.requestMempoolApi("/v1/fees/recommended", &rates); err != nil {
 		return nil, err
 	}
@@ -1309,7 +1302,7 @@ func (svc *swapsService) getFeeRates() (*FeeRates, error) {
 
 func (svc *swapsService) requestMempoolApi(endpoint string, result interface{}) error {
 	for attempt := 1; attempt <= 10; attempt++ {
-		err := svc.doMempoolRequest(endpoint, result)
+		err := svc.mempool.Request(svc.ctx, endpoint, result)
 		if err != nil {
 			logger.Logger.WithError(err).WithFields(logrus.Fields{
 				"attempt":  attempt,
@@ -1324,65 +1317,15 @@ func (svc *swapsService) requestMempoolApi(endpoint string, result interface{})
 	return fmt.Errorf("ran out of attempts to request %s", endpoint)
 }
 
-func (svc *swapsService) doMempoolRequest(endpoint string, result interface{}) error {
-	url := svc.cfg.GetEnv().MempoolApi + endpoint
-	url = strings.ReplaceAll(url, "testnet/", "")
-
-	client := http.Client{
-		Timeout: time.Second * 10,
-	}
-
-	req, err := http.NewRequestWithContext(svc.ctx, http.MethodGet, url, nil)
-	if err != nil {
-		logger.Logger.WithError(err).WithFields(logrus.Fields{
-			"url": url,
-		}).Error("Failed to create http request")
-		return err
-	}
-	res, err := client.Do(req)
-	if err != nil {
-		logger.Logger.WithError(err).WithFields(logrus.Fields{
-			"url": url,
-		}).Error("Failed to send request")
-		return err
-	}
-
-	defer res.Body.Close()
-
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		logger.Logger.WithError(err).WithFields(logrus.Fields{
-			"url": url,
-		}).Error("Failed to read response body")
-		return errors.New("failed to read response body")
-	}
-
-	jsonErr := json.Unmarshal(body, &result)
-	if jsonErr != nil {
-		logger.Logger.WithError(jsonErr).WithFields(logrus.Fields{
-			"url": url,
-		}).Error("Failed to deserialize json")
-		return fmt.Errorf("failed to deserialize json %s %s", url, string(body))
-	}
-	return nil
-}
-
 func (svc *swapsService) bumpAutoswapXpubIndex(swapId uint) {
-	indexStr, err := svc.cfg.Get(config.AutoSwapXpubIndexStart, "")
-	if err != nil {
-		logger.Logger.Error("failed to get auto swap xpub index")
-		return
-	}
-	if indexStr == "" {
-		indexStr = "0"
-	}
-	index, err := strconv.ParseUint(indexStr, 10, 32)
+	destination, _ := svc.cfg.Get(config.AutoSwapDestinationKey, "")
+	index, err := mempool.GetXpubIndexStart(svc.cfg, destination)
 	if err != nil {
-		logger.Logger.Error("failed to parse auto swap xpub index")
+		logger.Logger.WithError(err).Error("failed to get auto swap xpub index")
 		return
 	}
 
-	err = svc.cfg.SetUpdate(config.AutoSwapXpubIndexStart, strconv.FormatUint(uint64(index+1), 10), "")
+	err = mempool.SetXpubIndexStart(svc.cfg, destination, index+1)
 	if err != nil {
 		logger.Logger.WithError(err).Error("Failed to update auto swap xpub index")
 	}
@@ -1392,64 +1335,6 @@ func (svc *swapsService) bumpAutoswapXpubIndex(swapId uint) {
 	}).Info("Updated xpub index start for swap address")
 }
 
-func (svc *swapsService) deriveAddressFromXpub(xpub string, index uint32) (string, error) {
-	var netParams *chaincfg.Params
-	switch svc.cfg.GetNetwork() {
-	case "bitcoin", "mainnet":
-		netParams = &chaincfg.MainNetParams
-	case "testnet":
-		netParams = &chaincfg.TestNet3Params
-	case "regtest":
-		netParams = &chaincfg.RegressionNetParams
-	case "signet":
-		netParams = &chaincfg.SigNetParams
-	default:
-		return "", fmt.Errorf("unsupported network: %s", svc.cfg.GetNetwork())
-	}
-
-	extPubKey, err := hdkeychain.NewKeyFromString(xpub)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse xpub: %w", err)
-	}
-
-	externalChain, err := extPubKey.Derive(0)
-	if err != nil {
-		return "", fmt.Errorf("failed to derive external chain: %w", err)
-	}
-
-	addressKey, err := externalChain.Derive(index)
-	if err != nil {
-		return "", fmt.Errorf("failed to derive address key at index %d: %w", index, err)
-	}
-
-	pubKey, err := addressKey.ECPubKey()
-	if err != nil {
-		return "", fmt.Errorf("failed to get public key: %w", err)
-	}
-
-	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
-	address, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, netParams)
-	if err != nil {
-		return "", fmt.Errorf("failed to create address: %w", err)
-	}
-
-	return address.EncodeAddress(), nil
-}
-
-func (svc *swapsService) checkAddressHasTransactions(address string, esploraApiRequester func(endpoint string) (interface{}, error)) (bool, error) {
-	response, err := esploraApiRequester("/address/" + address + "/txs")
-	if err != nil {
-		return false, fmt.Errorf("failed to get address transactions: %w", err)
-	}
-
-	transactions, ok := response.([]interface{})
-	if !ok {
-		return false, fmt.Errorf("unexpected response format from esplora API")
-	}
-
-	return len(transactions) > 0, nil
-}
-
 func (svc *swapsService) getNextUnusedAddressFromXpub() (string, error) {
 	destination, _ := svc.cfg.Get(config.AutoSwapDestinationKey, "")
 	if destination == "" {
@@ -1460,67 +1345,13 @@ func (svc *swapsService) getNextUnusedAddressFromXpub() (string, error) {
 		return "", errors.New("destination is not a valid XPUB")
 	}
 
-	indexStr, err := svc.cfg.Get(config.AutoSwapXpubIndexStart, "")
+	index, err := mempool.GetXpubIndexStart(svc.cfg, destination)
 	if err != nil {
 		return "", err
 	}
-	if indexStr == "" {
-		indexStr = "0"
-	}
-	index, err := strconv.ParseUint(indexStr, 10, 32)
-	if err != nil {
-		return "", err
-	}
-
-	esploraApiRequester := func(endpoint string) (interface{}, error) {
-		url := svc.cfg.GetEnv().LDKEsploraServer + endpoint
-
-		client := http.Client{
-			Timeout: time.Second * 10,
-		}
-
-		req, err := http.NewRequestWithContext(svc.ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return nil, err
-		}
-		res, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer res.Body.Close()
-
-		body, err := io.ReadAll(res.Body)
-		if err != nil {
-			return nil, err
-		}
-
-		var jsonContent interface{}
-		err = json.Unmarshal(body, &jsonContent)
-		if err != nil {
-			return nil, err
-		}
-		return jsonContent, nil
-	}
-
-	const addressLookAheadLimit = 100
-
-	for i := uint32(index); i < uint32(index)+addressLookAheadLimit; i++ {
-		address, err := svc.deriveAddressFromXpub(destination, i)
-		if err != nil {
-			return "", fmt.Errorf("failed to derive address at index %d: %w", i, err)
-		}
-
-		hasTransactions, err := svc.checkAddressHasTransactions(address, esploraApiRequester)
-		if err != nil {
-			return "", fmt.Errorf("failed to check address for transactions at index %d: %w", i, err)
-		}
-
-		if !hasTransactions {
-			return address, nil
-		}
-	}
 
-	return "", fmt.Errorf("could not find unused address within %d addresses starting from index %d", addressLookAheadLimit, index)
+	address, _, err := svc.esplora.GetNextUnusedAddressFromXpub(svc.ctx, svc.cfg.GetNetwork(), destination, index)
+	return address, err
 }
 
 func (svc *swapsService) validateXpub(xpub string) error {
@@ -89,7 +89,7 @@ func NewMockLn() (*MockLn, error) {
 	return &MockLn{}, nil
 }
 
-func (mln *MockLn) SendPaymentSync(payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
+func (mln *MockLn) SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
 	if len(mln.PayInvoiceResponses) > 0 {
 		response := mln.PayInvoiceResponses[0]
 		err := mln.PayInvoiceErrors[0]
@@ -106,7 +106,7 @@ func (mln *MockLn) SendPaymentSync(payReq string, amount *uint64) (*lnclient.Pay
 	}, nil
 }
 
-func (mln *MockLn) SendKeysend(amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+func (mln *MockLn) SendKeysend(ctx context.Context, amount uint64, destination string, custom_records []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
 	return &lnclient.PayKeysendResponse{
 		Fee: 1,
 	}, nil
@@ -158,6 +158,9 @@ func (mln *MockLn) ConnectPeer(ctx context.Context, connectPeerRequest *lnclient
 func (mln *MockLn) OpenChannel(ctx context.Context, openChannelRequest *lnclient.OpenChannelRequest) (*lnclient.OpenChannelResponse, error) {
 	return nil, nil
 }
+func (mln *MockLn) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	return nil, errors.ErrUnsupported
+}
 func (mln *MockLn) CloseChannel(ctx context.Context, closeChannelRequest *lnclient.CloseChannelRequest) (*lnclient.CloseChannelResponse, error) {
 	return nil, nil
 }
@@ -246,3 +249,15 @@ func (mln *MockLn) MakeOffer(ctx context.Context, description string) (string, e
 func (mln *MockLn) ListOnchainTransactions(ctx context.Context) ([]lnclient.OnchainTransaction, error) {
 	return nil, errors.ErrUnsupported
 }
+
+func (mln *MockLn) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (mln *MockLn) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	return errors.ErrUnsupported
+}
+
+func (mln *MockLn) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	return errors.ErrUnsupported
+}
@@ -444,6 +444,65 @@ func (_c *MockAlbyOAuthService_GetLSPInfo_Call) RunAndReturn(run func(ctx contex
 	return _c
 }
 
+// GetLSPOrder provides a mock function for the type MockAlbyOAuthService
+func (_mock *MockAlbyOAuthService) GetLSPOrder(ctx context.Context, lsp string, network string, orderId string) (*alby.LSPChannelResponse, error) {
+	ret := _mock.Called(ctx, lsp, network, orderId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLSPOrder")
+	}
+
+	var r0 *alby.LSPChannelResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) (*alby.LSPChannelResponse, error)); ok {
+		return returnFunc(ctx, lsp, network, orderId)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) *alby.LSPChannelResponse); ok {
+		r0 = returnFunc(ctx, lsp, network, orderId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*alby.LSPChannelResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = returnFunc(ctx, lsp, network, orderId)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAlbyOAuthService_GetLSPOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLSPOrder'
+type MockAlbyOAuthService_GetLSPOrder_Call struct {
+	*mock.Call
+}
+
+// GetLSPOrder is a helper method to define mock.On call
+//   - ctx
+//   - lsp
+//   - network
+//   - orderId
+func (_e *MockAlbyOAuthService_Expecter) GetLSPOrder(ctx interface{}, lsp interface{}, network interface{}, orderId interface{}) *MockAlbyOAuthService_GetLSPOrder_Call {
+	return &MockAlbyOAuthService_GetLSPOrder_Call{Call: _e.mock.On("GetLSPOrder", ctx, lsp, network, orderId)}
+}
+
+func (_c *MockAlbyOAuthService_GetLSPOrder_Call) Run(run func(ctx context.Context, lsp string, network string, orderId string)) *MockAlbyOAuthService_GetLSPOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockAlbyOAuthService_GetLSPOrder_Call) Return(lSPChannelResponse *alby.LSPChannelResponse, err error) *MockAlbyOAuthService_GetLSPOrder_Call {
+	_c.Call.Return(lSPChannelResponse, err)
+	return _c
+}
+
+func (_c *MockAlbyOAuthService_GetLSPOrder_Call) RunAndReturn(run func(ctx context.Context, lsp string, network string, orderId string) (*alby.LSPChannelResponse, error)) *MockAlbyOAuthService_GetLSPOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetLightningAddress provides a mock function for the type MockAlbyOAuthService
 func (_mock *MockAlbyOAuthService) GetLightningAddress() (string, error) {
 	ret := _mock.Called()
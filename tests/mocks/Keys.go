@@ -5,8 +5,11 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/getAlby/hub/config"
+	"github.com/getAlby/hub/nostr/signer"
 	mock "github.com/stretchr/testify/mock"
 	"github.com/tyler-smith/go-bip32"
 )
@@ -148,6 +151,61 @@ func (_c *MockKeys_GetAppWalletKey_Call) RunAndReturn(run func(childIndex uint)
 	return _c
 }
 
+// GetAppWalletKeyGeneration provides a mock function for the type MockKeys
+func (_mock *MockKeys) GetAppWalletKeyGeneration(childIndex uint, generation uint) (string, error) {
+	ret := _mock.Called(childIndex, generation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAppWalletKeyGeneration")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(uint, uint) (string, error)); ok {
+		return returnFunc(childIndex, generation)
+	}
+	if returnFunc, ok := ret.Get(0).(func(uint, uint) string); ok {
+		r0 = returnFunc(childIndex, generation)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(uint, uint) error); ok {
+		r1 = returnFunc(childIndex, generation)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeys_GetAppWalletKeyGeneration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAppWalletKeyGeneration'
+type MockKeys_GetAppWalletKeyGeneration_Call struct {
+	*mock.Call
+}
+
+// GetAppWalletKeyGeneration is a helper method to define mock.On call
+//   - childIndex
+//   - generation
+func (_e *MockKeys_Expecter) GetAppWalletKeyGeneration(childIndex interface{}, generation interface{}) *MockKeys_GetAppWalletKeyGeneration_Call {
+	return &MockKeys_GetAppWalletKeyGeneration_Call{Call: _e.mock.On("GetAppWalletKeyGeneration", childIndex, generation)}
+}
+
+func (_c *MockKeys_GetAppWalletKeyGeneration_Call) Run(run func(childIndex uint, generation uint)) *MockKeys_GetAppWalletKeyGeneration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *MockKeys_GetAppWalletKeyGeneration_Call) Return(s string, err error) *MockKeys_GetAppWalletKeyGeneration_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockKeys_GetAppWalletKeyGeneration_Call) RunAndReturn(run func(childIndex uint, generation uint) (string, error)) *MockKeys_GetAppWalletKeyGeneration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetNostrPublicKey provides a mock function for the type MockKeys
 func (_mock *MockKeys) GetNostrPublicKey() string {
 	ret := _mock.Called()
@@ -192,46 +250,140 @@ func (_c *MockKeys_GetNostrPublicKey_Call) RunAndReturn(run func() string) *Mock
 	return _c
 }
 
-// GetNostrSecretKey provides a mock function for the type MockKeys
-func (_mock *MockKeys) GetNostrSecretKey() string {
+// GetNostrSigner provides a mock function for the type MockKeys
+func (_mock *MockKeys) GetNostrSigner() signer.Signer {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetNostrSecretKey")
+		panic("no return value specified for GetNostrSigner")
 	}
 
-	var r0 string
-	if returnFunc, ok := ret.Get(0).(func() string); ok {
+	var r0 signer.Signer
+	if returnFunc, ok := ret.Get(0).(func() signer.Signer); ok {
 		r0 = returnFunc()
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(signer.Signer)
+		}
 	}
 	return r0
 }
 
-// MockKeys_GetNostrSecretKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNostrSecretKey'
-type MockKeys_GetNostrSecretKey_Call struct {
+// MockKeys_GetNostrSigner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNostrSigner'
+type MockKeys_GetNostrSigner_Call struct {
 	*mock.Call
 }
 
-// GetNostrSecretKey is a helper method to define mock.On call
-func (_e *MockKeys_Expecter) GetNostrSecretKey() *MockKeys_GetNostrSecretKey_Call {
-	return &MockKeys_GetNostrSecretKey_Call{Call: _e.mock.On("GetNostrSecretKey")}
+// GetNostrSigner is a helper method to define mock.On call
+func (_e *MockKeys_Expecter) GetNostrSigner() *MockKeys_GetNostrSigner_Call {
+	return &MockKeys_GetNostrSigner_Call{Call: _e.mock.On("GetNostrSigner")}
 }
 
-func (_c *MockKeys_GetNostrSecretKey_Call) Run(run func()) *MockKeys_GetNostrSecretKey_Call {
+func (_c *MockKeys_GetNostrSigner_Call) Run(run func()) *MockKeys_GetNostrSigner_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *MockKeys_GetNostrSecretKey_Call) Return(s string) *MockKeys_GetNostrSecretKey_Call {
-	_c.Call.Return(s)
+func (_c *MockKeys_GetNostrSigner_Call) Return(signer1 signer.Signer) *MockKeys_GetNostrSigner_Call {
+	_c.Call.Return(signer1)
 	return _c
 }
 
-func (_c *MockKeys_GetNostrSecretKey_Call) RunAndReturn(run func() string) *MockKeys_GetNostrSecretKey_Call {
+func (_c *MockKeys_GetNostrSigner_Call) RunAndReturn(run func() signer.Signer) *MockKeys_GetNostrSigner_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPreviousNostrKeyExpiresAt provides a mock function for the type MockKeys
+func (_mock *MockKeys) GetPreviousNostrKeyExpiresAt() *time.Time {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreviousNostrKeyExpiresAt")
+	}
+
+	var r0 *time.Time
+	if returnFunc, ok := ret.Get(0).(func() *time.Time); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*time.Time)
+		}
+	}
+	return r0
+}
+
+// MockKeys_GetPreviousNostrKeyExpiresAt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPreviousNostrKeyExpiresAt'
+type MockKeys_GetPreviousNostrKeyExpiresAt_Call struct {
+	*mock.Call
+}
+
+// GetPreviousNostrKeyExpiresAt is a helper method to define mock.On call
+func (_e *MockKeys_Expecter) GetPreviousNostrKeyExpiresAt() *MockKeys_GetPreviousNostrKeyExpiresAt_Call {
+	return &MockKeys_GetPreviousNostrKeyExpiresAt_Call{Call: _e.mock.On("GetPreviousNostrKeyExpiresAt")}
+}
+
+func (_c *MockKeys_GetPreviousNostrKeyExpiresAt_Call) Run(run func()) *MockKeys_GetPreviousNostrKeyExpiresAt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockKeys_GetPreviousNostrKeyExpiresAt_Call) Return(time1 *time.Time) *MockKeys_GetPreviousNostrKeyExpiresAt_Call {
+	_c.Call.Return(time1)
+	return _c
+}
+
+func (_c *MockKeys_GetPreviousNostrKeyExpiresAt_Call) RunAndReturn(run func() *time.Time) *MockKeys_GetPreviousNostrKeyExpiresAt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPreviousNostrSigner provides a mock function for the type MockKeys
+func (_mock *MockKeys) GetPreviousNostrSigner() signer.Signer {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPreviousNostrSigner")
+	}
+
+	var r0 signer.Signer
+	if returnFunc, ok := ret.Get(0).(func() signer.Signer); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(signer.Signer)
+		}
+	}
+	return r0
+}
+
+// MockKeys_GetPreviousNostrSigner_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPreviousNostrSigner'
+type MockKeys_GetPreviousNostrSigner_Call struct {
+	*mock.Call
+}
+
+// GetPreviousNostrSigner is a helper method to define mock.On call
+func (_e *MockKeys_Expecter) GetPreviousNostrSigner() *MockKeys_GetPreviousNostrSigner_Call {
+	return &MockKeys_GetPreviousNostrSigner_Call{Call: _e.mock.On("GetPreviousNostrSigner")}
+}
+
+func (_c *MockKeys_GetPreviousNostrSigner_Call) Run(run func()) *MockKeys_GetPreviousNostrSigner_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockKeys_GetPreviousNostrSigner_Call) Return(signer1 signer.Signer) *MockKeys_GetPreviousNostrSigner_Call {
+	_c.Call.Return(signer1)
+	return _c
+}
+
+func (_c *MockKeys_GetPreviousNostrSigner_Call) RunAndReturn(run func() signer.Signer) *MockKeys_GetPreviousNostrSigner_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -381,3 +533,95 @@ func (_c *MockKeys_Init_Call) RunAndReturn(run func(cfg config.Config, encryptio
 	_c.Call.Return(run)
 	return _c
 }
+
+// RetirePreviousNostrKey provides a mock function for the type MockKeys
+func (_mock *MockKeys) RetirePreviousNostrKey(cfg config.Config) error {
+	ret := _mock.Called(cfg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetirePreviousNostrKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(config.Config) error); ok {
+		r0 = returnFunc(cfg)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockKeys_RetirePreviousNostrKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetirePreviousNostrKey'
+type MockKeys_RetirePreviousNostrKey_Call struct {
+	*mock.Call
+}
+
+// RetirePreviousNostrKey is a helper method to define mock.On call
+//   - cfg
+func (_e *MockKeys_Expecter) RetirePreviousNostrKey(cfg interface{}) *MockKeys_RetirePreviousNostrKey_Call {
+	return &MockKeys_RetirePreviousNostrKey_Call{Call: _e.mock.On("RetirePreviousNostrKey", cfg)}
+}
+
+func (_c *MockKeys_RetirePreviousNostrKey_Call) Run(run func(cfg config.Config)) *MockKeys_RetirePreviousNostrKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(config.Config))
+	})
+	return _c
+}
+
+func (_c *MockKeys_RetirePreviousNostrKey_Call) Return(err error) *MockKeys_RetirePreviousNostrKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockKeys_RetirePreviousNostrKey_Call) RunAndReturn(run func(cfg config.Config) error) *MockKeys_RetirePreviousNostrKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateNostrKey provides a mock function for the type MockKeys
+func (_mock *MockKeys) RotateNostrKey(cfg config.Config, encryptionKey string, gracePeriod time.Duration) error {
+	ret := _mock.Called(cfg, encryptionKey, gracePeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateNostrKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(config.Config, string, time.Duration) error); ok {
+		r0 = returnFunc(cfg, encryptionKey, gracePeriod)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockKeys_RotateNostrKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateNostrKey'
+type MockKeys_RotateNostrKey_Call struct {
+	*mock.Call
+}
+
+// RotateNostrKey is a helper method to define mock.On call
+//   - cfg
+//   - encryptionKey
+//   - gracePeriod
+func (_e *MockKeys_Expecter) RotateNostrKey(cfg interface{}, encryptionKey interface{}, gracePeriod interface{}) *MockKeys_RotateNostrKey_Call {
+	return &MockKeys_RotateNostrKey_Call{Call: _e.mock.On("RotateNostrKey", cfg, encryptionKey, gracePeriod)}
+}
+
+func (_c *MockKeys_RotateNostrKey_Call) Run(run func(cfg config.Config, encryptionKey string, gracePeriod time.Duration)) *MockKeys_RotateNostrKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(config.Config), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockKeys_RotateNostrKey_Call) Return(err error) *MockKeys_RotateNostrKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockKeys_RotateNostrKey_Call) RunAndReturn(run func(cfg config.Config, encryptionKey string, gracePeriod time.Duration) error) *MockKeys_RotateNostrKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
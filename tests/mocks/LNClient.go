@@ -38,6 +38,52 @@ func (_m *MockLNClient) EXPECT() *MockLNClient_Expecter {
 	return &MockLNClient_Expecter{mock: &_m.Mock}
 }
 
+// AddWatchtower provides a mock function for the type MockLNClient
+func (_mock *MockLNClient) AddWatchtower(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error {
+	ret := _mock.Called(ctx, addWatchtowerRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddWatchtower")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *lnclient.AddWatchtowerRequest) error); ok {
+		r0 = returnFunc(ctx, addWatchtowerRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLNClient_AddWatchtower_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddWatchtower'
+type MockLNClient_AddWatchtower_Call struct {
+	*mock.Call
+}
+
+// AddWatchtower is a helper method to define mock.On call
+//   - ctx
+//   - addWatchtowerRequest
+func (_e *MockLNClient_Expecter) AddWatchtower(ctx interface{}, addWatchtowerRequest interface{}) *MockLNClient_AddWatchtower_Call {
+	return &MockLNClient_AddWatchtower_Call{Call: _e.mock.On("AddWatchtower", ctx, addWatchtowerRequest)}
+}
+
+func (_c *MockLNClient_AddWatchtower_Call) Run(run func(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest)) *MockLNClient_AddWatchtower_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*lnclient.AddWatchtowerRequest))
+	})
+	return _c
+}
+
+func (_c *MockLNClient_AddWatchtower_Call) Return(err error) *MockLNClient_AddWatchtower_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLNClient_AddWatchtower_Call) RunAndReturn(run func(ctx context.Context, addWatchtowerRequest *lnclient.AddWatchtowerRequest) error) *MockLNClient_AddWatchtower_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CancelHoldInvoice provides a mock function for the type MockLNClient
 func (_mock *MockLNClient) CancelHoldInvoice(ctx context.Context, paymentHash string) error {
 	ret := _mock.Called(ctx, paymentHash)
@@ -974,6 +1020,62 @@ func (_c *MockLNClient_GetSupportedNIP47NotificationTypes_Call) RunAndReturn(run
 	return _c
 }
 
+// GetWatchtowerStatus provides a mock function for the type MockLNClient
+func (_mock *MockLNClient) GetWatchtowerStatus(ctx context.Context) (*lnclient.WatchtowerStatus, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWatchtowerStatus")
+	}
+
+	var r0 *lnclient.WatchtowerStatus
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*lnclient.WatchtowerStatus, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *lnclient.WatchtowerStatus); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lnclient.WatchtowerStatus)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLNClient_GetWatchtowerStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWatchtowerStatus'
+type MockLNClient_GetWatchtowerStatus_Call struct {
+	*mock.Call
+}
+
+// GetWatchtowerStatus is a helper method to define mock.On call
+//   - ctx
+func (_e *MockLNClient_Expecter) GetWatchtowerStatus(ctx interface{}) *MockLNClient_GetWatchtowerStatus_Call {
+	return &MockLNClient_GetWatchtowerStatus_Call{Call: _e.mock.On("GetWatchtowerStatus", ctx)}
+}
+
+func (_c *MockLNClient_GetWatchtowerStatus_Call) Run(run func(ctx context.Context)) *MockLNClient_GetWatchtowerStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockLNClient_GetWatchtowerStatus_Call) Return(watchtowerStatus *lnclient.WatchtowerStatus, err error) *MockLNClient_GetWatchtowerStatus_Call {
+	_c.Call.Return(watchtowerStatus, err)
+	return _c
+}
+
+func (_c *MockLNClient_GetWatchtowerStatus_Call) RunAndReturn(run func(ctx context.Context) (*lnclient.WatchtowerStatus, error)) *MockLNClient_GetWatchtowerStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ListChannels provides a mock function for the type MockLNClient
 func (_mock *MockLNClient) ListChannels(ctx context.Context) ([]lnclient.Channel, error) {
 	ret := _mock.Called(ctx)
@@ -1495,6 +1597,63 @@ func (_c *MockLNClient_OpenChannel_Call) RunAndReturn(run func(ctx context.Conte
 	return _c
 }
 
+// OpenChannels provides a mock function for the type MockLNClient
+func (_mock *MockLNClient) OpenChannels(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error) {
+	ret := _mock.Called(ctx, openChannelsRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OpenChannels")
+	}
+
+	var r0 *lnclient.OpenChannelsResponse
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error)); ok {
+		return returnFunc(ctx, openChannelsRequest)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *lnclient.OpenChannelsRequest) *lnclient.OpenChannelsResponse); ok {
+		r0 = returnFunc(ctx, openChannelsRequest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*lnclient.OpenChannelsResponse)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *lnclient.OpenChannelsRequest) error); ok {
+		r1 = returnFunc(ctx, openChannelsRequest)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLNClient_OpenChannels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OpenChannels'
+type MockLNClient_OpenChannels_Call struct {
+	*mock.Call
+}
+
+// OpenChannels is a helper method to define mock.On call
+//   - ctx
+//   - openChannelsRequest
+func (_e *MockLNClient_Expecter) OpenChannels(ctx interface{}, openChannelsRequest interface{}) *MockLNClient_OpenChannels_Call {
+	return &MockLNClient_OpenChannels_Call{Call: _e.mock.On("OpenChannels", ctx, openChannelsRequest)}
+}
+
+func (_c *MockLNClient_OpenChannels_Call) Run(run func(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest)) *MockLNClient_OpenChannels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*lnclient.OpenChannelsRequest))
+	})
+	return _c
+}
+
+func (_c *MockLNClient_OpenChannels_Call) Return(openChannelsResponse *lnclient.OpenChannelsResponse, err error) *MockLNClient_OpenChannels_Call {
+	_c.Call.Return(openChannelsResponse, err)
+	return _c
+}
+
+func (_c *MockLNClient_OpenChannels_Call) RunAndReturn(run func(ctx context.Context, openChannelsRequest *lnclient.OpenChannelsRequest) (*lnclient.OpenChannelsResponse, error)) *MockLNClient_OpenChannels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RedeemOnchainFunds provides a mock function for the type MockLNClient
 func (_mock *MockLNClient) RedeemOnchainFunds(ctx context.Context, toAddress string, amount uint64, feeRate *uint64, sendAll bool) (string, error) {
 	ret := _mock.Called(ctx, toAddress, amount, feeRate, sendAll)
@@ -1553,6 +1712,52 @@ func (_c *MockLNClient_RedeemOnchainFunds_Call) RunAndReturn(run func(ctx contex
 	return _c
 }
 
+// RemoveWatchtower provides a mock function for the type MockLNClient
+func (_mock *MockLNClient) RemoveWatchtower(ctx context.Context, pubkey string) error {
+	ret := _mock.Called(ctx, pubkey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveWatchtower")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, pubkey)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLNClient_RemoveWatchtower_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveWatchtower'
+type MockLNClient_RemoveWatchtower_Call struct {
+	*mock.Call
+}
+
+// RemoveWatchtower is a helper method to define mock.On call
+//   - ctx
+//   - pubkey
+func (_e *MockLNClient_Expecter) RemoveWatchtower(ctx interface{}, pubkey interface{}) *MockLNClient_RemoveWatchtower_Call {
+	return &MockLNClient_RemoveWatchtower_Call{Call: _e.mock.On("RemoveWatchtower", ctx, pubkey)}
+}
+
+func (_c *MockLNClient_RemoveWatchtower_Call) Run(run func(ctx context.Context, pubkey string)) *MockLNClient_RemoveWatchtower_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLNClient_RemoveWatchtower_Call) Return(err error) *MockLNClient_RemoveWatchtower_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLNClient_RemoveWatchtower_Call) RunAndReturn(run func(ctx context.Context, pubkey string) error) *MockLNClient_RemoveWatchtower_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ResetRouter provides a mock function for the type MockLNClient
 func (_mock *MockLNClient) ResetRouter(key string) error {
 	ret := _mock.Called(key)
@@ -1599,8 +1804,8 @@ func (_c *MockLNClient_ResetRouter_Call) RunAndReturn(run func(key string) error
 }
 
 // SendKeysend provides a mock function for the type MockLNClient
-func (_mock *MockLNClient) SendKeysend(amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
-	ret := _mock.Called(amount, destination, customRecords, preimage)
+func (_mock *MockLNClient) SendKeysend(ctx context.Context, amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error) {
+	ret := _mock.Called(ctx, amount, destination, customRecords, preimage)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendKeysend")
@@ -1608,18 +1813,18 @@ func (_mock *MockLNClient) SendKeysend(amount uint64, destination string, custom
 
 	var r0 *lnclient.PayKeysendResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(uint64, string, []lnclient.TLVRecord, string) (*lnclient.PayKeysendResponse, error)); ok {
-		return returnFunc(amount, destination, customRecords, preimage)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, string, []lnclient.TLVRecord, string) (*lnclient.PayKeysendResponse, error)); ok {
+		return returnFunc(ctx, amount, destination, customRecords, preimage)
 	}
-	if returnFunc, ok := ret.Get(0).(func(uint64, string, []lnclient.TLVRecord, string) *lnclient.PayKeysendResponse); ok {
-		r0 = returnFunc(amount, destination, customRecords, preimage)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, string, []lnclient.TLVRecord, string) *lnclient.PayKeysendResponse); ok {
+		r0 = returnFunc(ctx, amount, destination, customRecords, preimage)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*lnclient.PayKeysendResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(uint64, string, []lnclient.TLVRecord, string) error); ok {
-		r1 = returnFunc(amount, destination, customRecords, preimage)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint64, string, []lnclient.TLVRecord, string) error); ok {
+		r1 = returnFunc(ctx, amount, destination, customRecords, preimage)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1632,17 +1837,18 @@ type MockLNClient_SendKeysend_Call struct {
 }
 
 // SendKeysend is a helper method to define mock.On call
+//   - ctx
 //   - amount
 //   - destination
 //   - customRecords
 //   - preimage
-func (_e *MockLNClient_Expecter) SendKeysend(amount interface{}, destination interface{}, customRecords interface{}, preimage interface{}) *MockLNClient_SendKeysend_Call {
-	return &MockLNClient_SendKeysend_Call{Call: _e.mock.On("SendKeysend", amount, destination, customRecords, preimage)}
+func (_e *MockLNClient_Expecter) SendKeysend(ctx interface{}, amount interface{}, destination interface{}, customRecords interface{}, preimage interface{}) *MockLNClient_SendKeysend_Call {
+	return &MockLNClient_SendKeysend_Call{Call: _e.mock.On("SendKeysend", ctx, amount, destination, customRecords, preimage)}
 }
 
-func (_c *MockLNClient_SendKeysend_Call) Run(run func(amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string)) *MockLNClient_SendKeysend_Call {
+func (_c *MockLNClient_SendKeysend_Call) Run(run func(ctx context.Context, amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string)) *MockLNClient_SendKeysend_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(uint64), args[1].(string), args[2].([]lnclient.TLVRecord), args[3].(string))
+		run(args[0].(context.Context), args[1].(uint64), args[2].(string), args[3].([]lnclient.TLVRecord), args[4].(string))
 	})
 	return _c
 }
@@ -1652,7 +1858,7 @@ func (_c *MockLNClient_SendKeysend_Call) Return(payKeysendResponse *lnclient.Pay
 	return _c
 }
 
-func (_c *MockLNClient_SendKeysend_Call) RunAndReturn(run func(amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error)) *MockLNClient_SendKeysend_Call {
+func (_c *MockLNClient_SendKeysend_Call) RunAndReturn(run func(ctx context.Context, amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string) (*lnclient.PayKeysendResponse, error)) *MockLNClient_SendKeysend_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1704,8 +1910,8 @@ func (_c *MockLNClient_SendPaymentProbes_Call) RunAndReturn(run func(ctx context
 }
 
 // SendPaymentSync provides a mock function for the type MockLNClient
-func (_mock *MockLNClient) SendPaymentSync(payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
-	ret := _mock.Called(payReq, amount)
+func (_mock *MockLNClient) SendPaymentSync(ctx context.Context, payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error) {
+	ret := _mock.Called(ctx, payReq, amount)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SendPaymentSync")
@@ -1713,18 +1919,18 @@ func (_mock *MockLNClient) SendPaymentSync(payReq string, amount *uint64) (*lncl
 
 	var r0 *lnclient.PayInvoiceResponse
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string, *uint64) (*lnclient.PayInvoiceResponse, error)); ok {
-		return returnFunc(payReq, amount)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *uint64) (*lnclient.PayInvoiceResponse, error)); ok {
+		return returnFunc(ctx, payReq, amount)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string, *uint64) *lnclient.PayInvoiceResponse); ok {
-		r0 = returnFunc(payReq, amount)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *uint64) *lnclient.PayInvoiceResponse); ok {
+		r0 = returnFunc(ctx, payReq, amount)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*lnclient.PayInvoiceResponse)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(string, *uint64) error); ok {
-		r1 = returnFunc(payReq, amount)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, *uint64) error); ok {
+		r1 = returnFunc(ctx, payReq, amount)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1737,15 +1943,16 @@ type MockLNClient_SendPaymentSync_Call struct {
 }
 
 // SendPaymentSync is a helper method to define mock.On call
+//   - ctx
 //   - payReq
 //   - amount
-func (_e *MockLNClient_Expecter) SendPaymentSync(payReq interface{}, amount interface{}) *MockLNClient_SendPaymentSync_Call {
-	return &MockLNClient_SendPaymentSync_Call{Call: _e.mock.On("SendPaymentSync", payReq, amount)}
+func (_e *MockLNClient_Expecter) SendPaymentSync(ctx interface{}, payReq interface{}, amount interface{}) *MockLNClient_SendPaymentSync_Call {
+	return &MockLNClient_SendPaymentSync_Call{Call: _e.mock.On("SendPaymentSync", ctx, payReq, amount)}
 }
 
-func (_c *MockLNClient_SendPaymentSync_Call) Run(run func(payReq string, amount *uint64)) *MockLNClient_SendPaymentSync_Call {
+func (_c *MockLNClient_SendPaymentSync_Call) Run(run func(ctx context.Context, payReq string, amount *uint64)) *MockLNClient_SendPaymentSync_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(*uint64))
+		run(args[0].(context.Context), args[1].(string), args[2].(*uint64))
 	})
 	return _c
 }
@@ -1755,7 +1962,7 @@ func (_c *MockLNClient_SendPaymentSync_Call) Return(payInvoiceResponse *lnclient
 	return _c
 }
 
-func (_c *MockLNClient_SendPaymentSync_Call) RunAndReturn(run func(payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error)) *MockLNClient_SendPaymentSync_Call {
+func (_c *MockLNClient_SendPaymentSync_Call) RunAndReturn(run func(ctx context.Context, payReq string, amount *uint64) (*lnclient.PayInvoiceResponse, error)) *MockLNClient_SendPaymentSync_Call {
 	_c.Call.Return(run)
 	return _c
 }
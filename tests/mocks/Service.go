@@ -6,9 +6,13 @@ package mocks
 
 import (
 	"github.com/getAlby/hub/alby"
+	"github.com/getAlby/hub/backups"
+	"github.com/getAlby/hub/channels"
 	"github.com/getAlby/hub/config"
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/lsp"
+	"github.com/getAlby/hub/retention"
 	"github.com/getAlby/hub/service/keys"
 	"github.com/getAlby/hub/swaps"
 	"github.com/getAlby/hub/transactions"
@@ -135,6 +139,98 @@ func (_c *MockService_GetAlbySvc_Call) RunAndReturn(run func() alby.AlbyService)
 	return _c
 }
 
+// GetBackupsService provides a mock function for the type MockService
+func (_mock *MockService) GetBackupsService() backups.BackupsService {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBackupsService")
+	}
+
+	var r0 backups.BackupsService
+	if returnFunc, ok := ret.Get(0).(func() backups.BackupsService); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(backups.BackupsService)
+		}
+	}
+	return r0
+}
+
+// MockService_GetBackupsService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBackupsService'
+type MockService_GetBackupsService_Call struct {
+	*mock.Call
+}
+
+// GetBackupsService is a helper method to define mock.On call
+func (_e *MockService_Expecter) GetBackupsService() *MockService_GetBackupsService_Call {
+	return &MockService_GetBackupsService_Call{Call: _e.mock.On("GetBackupsService")}
+}
+
+func (_c *MockService_GetBackupsService_Call) Run(run func()) *MockService_GetBackupsService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockService_GetBackupsService_Call) Return(backupsService backups.BackupsService) *MockService_GetBackupsService_Call {
+	_c.Call.Return(backupsService)
+	return _c
+}
+
+func (_c *MockService_GetBackupsService_Call) RunAndReturn(run func() backups.BackupsService) *MockService_GetBackupsService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChannelsService provides a mock function for the type MockService
+func (_mock *MockService) GetChannelsService() channels.ChannelsService {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChannelsService")
+	}
+
+	var r0 channels.ChannelsService
+	if returnFunc, ok := ret.Get(0).(func() channels.ChannelsService); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(channels.ChannelsService)
+		}
+	}
+	return r0
+}
+
+// MockService_GetChannelsService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChannelsService'
+type MockService_GetChannelsService_Call struct {
+	*mock.Call
+}
+
+// GetChannelsService is a helper method to define mock.On call
+func (_e *MockService_Expecter) GetChannelsService() *MockService_GetChannelsService_Call {
+	return &MockService_GetChannelsService_Call{Call: _e.mock.On("GetChannelsService")}
+}
+
+func (_c *MockService_GetChannelsService_Call) Run(run func()) *MockService_GetChannelsService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockService_GetChannelsService_Call) Return(channelsService channels.ChannelsService) *MockService_GetChannelsService_Call {
+	_c.Call.Return(channelsService)
+	return _c
+}
+
+func (_c *MockService_GetChannelsService_Call) RunAndReturn(run func() channels.ChannelsService) *MockService_GetChannelsService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetConfig provides a mock function for the type MockService
 func (_mock *MockService) GetConfig() config.Config {
 	ret := _mock.Called()
@@ -365,6 +461,98 @@ func (_c *MockService_GetLNClient_Call) RunAndReturn(run func() lnclient.LNClien
 	return _c
 }
 
+// GetLSPService provides a mock function for the type MockService
+func (_mock *MockService) GetLSPService() lsp.LSPService {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLSPService")
+	}
+
+	var r0 lsp.LSPService
+	if returnFunc, ok := ret.Get(0).(func() lsp.LSPService); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(lsp.LSPService)
+		}
+	}
+	return r0
+}
+
+// MockService_GetLSPService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLSPService'
+type MockService_GetLSPService_Call struct {
+	*mock.Call
+}
+
+// GetLSPService is a helper method to define mock.On call
+func (_e *MockService_Expecter) GetLSPService() *MockService_GetLSPService_Call {
+	return &MockService_GetLSPService_Call{Call: _e.mock.On("GetLSPService")}
+}
+
+func (_c *MockService_GetLSPService_Call) Run(run func()) *MockService_GetLSPService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockService_GetLSPService_Call) Return(lSPService lsp.LSPService) *MockService_GetLSPService_Call {
+	_c.Call.Return(lSPService)
+	return _c
+}
+
+func (_c *MockService_GetLSPService_Call) RunAndReturn(run func() lsp.LSPService) *MockService_GetLSPService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRetentionService provides a mock function for the type MockService
+func (_mock *MockService) GetRetentionService() retention.RetentionService {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRetentionService")
+	}
+
+	var r0 retention.RetentionService
+	if returnFunc, ok := ret.Get(0).(func() retention.RetentionService); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(retention.RetentionService)
+		}
+	}
+	return r0
+}
+
+// MockService_GetRetentionService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRetentionService'
+type MockService_GetRetentionService_Call struct {
+	*mock.Call
+}
+
+// GetRetentionService is a helper method to define mock.On call
+func (_e *MockService_Expecter) GetRetentionService() *MockService_GetRetentionService_Call {
+	return &MockService_GetRetentionService_Call{Call: _e.mock.On("GetRetentionService")}
+}
+
+func (_c *MockService_GetRetentionService_Call) Run(run func()) *MockService_GetRetentionService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockService_GetRetentionService_Call) Return(retentionService retention.RetentionService) *MockService_GetRetentionService_Call {
+	_c.Call.Return(retentionService)
+	return _c
+}
+
+func (_c *MockService_GetRetentionService_Call) RunAndReturn(run func() retention.RetentionService) *MockService_GetRetentionService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetStartupState provides a mock function for the type MockService
 func (_mock *MockService) GetStartupState() string {
 	ret := _mock.Called()
@@ -0,0 +1,109 @@
+package transactions
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/events"
+	"github.com/getAlby/hub/lnclient"
+	"github.com/getAlby/hub/logger"
+)
+
+type HoldInvoicePolicy struct {
+	// accepted hold invoices are canceled this many blocks before their settle deadline.
+	// 0 disables auto-cancel.
+	AutoCancelBlocks uint32
+	// the app is notified this many blocks before the hold invoice is auto-canceled
+	ExpiryWarningBlocks uint32
+}
+
+// GetAutoCancelHeight returns the block height at which the accepted hold invoice
+// will be canceled by the hub, or nil if it will not be auto-canceled.
+// If the settle deadline is not above AutoCancelBlocks it returns 0, so the
+// invoice is canceled on the next check.
+func (policy HoldInvoicePolicy) GetAutoCancelHeight(transaction *Transaction) *uint32 {
+	if policy.AutoCancelBlocks == 0 || transaction.SettleDeadline == nil {
+		return nil
+	}
+	autoCancelHeight := uint32(0)
+	if *transaction.SettleDeadline > policy.AutoCancelBlocks {
+		autoCancelHeight = *transaction.SettleDeadline - policy.AutoCancelBlocks
+	}
+	return &autoCancelHeight
+}
+
+// ListAcceptedHoldInvoices returns the hold invoices which were paid but not yet settled or canceled,
+// the closest to their settle deadline first
+func (svc *transactionsService) ListAcceptedHoldInvoices(ctx context.Context) ([]Transaction, error) {
+	var transactions []Transaction
+	err := svc.db.
+		Where("type = ? AND state = ? AND hold = ?", constants.TRANSACTION_TYPE_INCOMING, constants.TRANSACTION_STATE_ACCEPTED, true).
+		Order("settle_deadline ASC, id ASC").
+		Find(&transactions).Error
+	if err != nil {
+		logger.Logger.WithError(err).Error("Failed to list accepted hold invoices")
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ProcessExpiringHoldInvoices notifies apps about accepted hold invoices that are about to be
+// auto-canceled and cancels those that reached their auto-cancel height, so that an app which
+// disappeared does not leave an HTLC pending until it risks a force close
+func (svc *transactionsService) ProcessExpiringHoldInvoices(ctx context.Context, lnClient lnclient.LNClient, blockHeight uint32, policy HoldInvoicePolicy) error {
+	if policy.AutoCancelBlocks == 0 {
+		return nil
+	}
+
+	holdInvoices, err := svc.ListAcceptedHoldInvoices(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, holdInvoice := range holdInvoices {
+		autoCancelHeight := policy.GetAutoCancelHeight(&holdInvoice)
+		if autoCancelHeight == nil {
+			continue
+		}
+
+		logFields := logrus.Fields{
+			"payment_hash":       holdInvoice.PaymentHash,
+			"settle_deadline":    *holdInvoice.SettleDeadline,
+			"auto_cancel_height": *autoCancelHeight,
+			"block_height":       blockHeight,
+		}
+
+		if blockHeight >= *autoCancelHeight {
+			logger.Logger.WithFields(logFields).Info("Auto-canceling accepted hold invoice before its settle deadline")
+			err := svc.CancelHoldInvoice(ctx, holdInvoice.PaymentHash, lnClient)
+			if err != nil {
+				// keep going, the cancellation will be retried on the next block
+				logger.Logger.WithFields(logFields).WithError(err).Error("Failed to auto-cancel hold invoice")
+			}
+			continue
+		}
+
+		if holdInvoice.HoldExpiryNotifiedAt == nil && blockHeight+policy.ExpiryWarningBlocks >= *autoCancelHeight {
+			now := time.Now()
+			err := svc.db.Model(&holdInvoice).Update("hold_expiry_notified_at", &now).Error
+			if err != nil {
+				logger.Logger.WithFields(logFields).WithError(err).Error("Failed to mark hold invoice expiry as notified")
+				continue
+			}
+
+			logger.Logger.WithFields(logFields).Info("Accepted hold invoice will be auto-canceled soon")
+			svc.eventPublisher.Publish(&events.Event{
+				Event: "nwc_hold_invoice_expiring",
+				Properties: map[string]interface{}{
+					"transaction":        &holdInvoice,
+					"auto_cancel_height": *autoCancelHeight,
+				},
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package transactions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/getAlby/hub/constants"
+	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/tests"
+)
+
+func TestProcessExpiringHoldInvoices(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	mockEventConsumer := tests.NewMockEventConsumer()
+	svc.EventPublisher.RegisterSubscriber(mockEventConsumer)
+
+	createAcceptedHoldInvoice := func(paymentHash string, settleDeadline uint32) {
+		err := svc.DB.Create(&db.Transaction{
+			Type:           constants.TRANSACTION_TYPE_INCOMING,
+			State:          constants.TRANSACTION_STATE_ACCEPTED,
+			PaymentHash:    paymentHash,
+			AmountMsat:     1000,
+			Hold:           true,
+			SettleDeadline: &settleDeadline,
+		}).Error
+		require.NoError(t, err)
+	}
+	createAcceptedHoldInvoice("hash1", 200)
+	createAcceptedHoldInvoice("hash2", 112)
+	createAcceptedHoldInvoice("hash3", 105)
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+	policy := HoldInvoicePolicy{AutoCancelBlocks: 10, ExpiryWarningBlocks: 6}
+
+	holdInvoices, err := transactionsService.ListAcceptedHoldInvoices(ctx)
+	require.NoError(t, err)
+	require.Len(t, holdInvoices, 3)
+	assert.Equal(t, "hash3", holdInvoices[0].PaymentHash)
+
+	err = transactionsService.ProcessExpiringHoldInvoices(ctx, svc.LNClient, 97, policy)
+	require.NoError(t, err)
+
+	holdInvoices, err = transactionsService.ListAcceptedHoldInvoices(ctx)
+	require.NoError(t, err)
+	require.Len(t, holdInvoices, 2)
+	// hash3 was canceled at its auto-cancel height (95)
+	assert.Equal(t, "hash2", holdInvoices[0].PaymentHash)
+	// hash2 will be canceled at block 102
+	assert.NotNil(t, holdInvoices[0].HoldExpiryNotifiedAt)
+	assert.Nil(t, holdInvoices[1].HoldExpiryNotifiedAt)
+
+	var canceledTransaction db.Transaction
+	require.NoError(t, svc.DB.Where("payment_hash = ?", "hash3").First(&canceledTransaction).Error)
+	assert.Equal(t, constants.TRANSACTION_STATE_FAILED, canceledTransaction.State)
+
+	// the app is only notified once
+	err = transactionsService.ProcessExpiringHoldInvoices(ctx, svc.LNClient, 98, policy)
+	require.NoError(t, err)
+
+	expiringEvents := 0
+	for _, event := range mockEventConsumer.GetConsumedEvents() {
+		if event.Event == "nwc_hold_invoice_expiring" {
+			expiringEvents++
+			properties := event.Properties.(map[string]interface{})
+			assert.Equal(t, "hash2", properties["transaction"].(*db.Transaction).PaymentHash)
+			assert.Equal(t, uint32(102), properties["auto_cancel_height"])
+		}
+	}
+	assert.Equal(t, 1, expiringEvents)
+}
+
+func TestProcessExpiringHoldInvoices_Disabled(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	settleDeadline := uint32(100)
+	err = svc.DB.Create(&db.Transaction{
+		Type:           constants.TRANSACTION_TYPE_INCOMING,
+		State:          constants.TRANSACTION_STATE_ACCEPTED,
+		PaymentHash:    "hash1",
+		Hold:           true,
+		SettleDeadline: &settleDeadline,
+	}).Error
+	require.NoError(t, err)
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+	err = transactionsService.ProcessExpiringHoldInvoices(ctx, svc.LNClient, 99, HoldInvoicePolicy{})
+	require.NoError(t, err)
+
+	holdInvoices, err := transactionsService.ListAcceptedHoldInvoices(ctx)
+	require.NoError(t, err)
+	assert.Len(t, holdInvoices, 1)
+}
@@ -2,14 +2,17 @@ package transactions
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 
 	"github.com/getAlby/hub/constants"
 	"github.com/getAlby/hub/db"
+	"github.com/getAlby/hub/labels"
 	"github.com/getAlby/hub/tests"
 )
 
@@ -48,7 +51,7 @@ func TestListTransactions_Paid(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, false, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), totalCount)
 	assert.Equal(t, 1, len(incomingTransactions))
@@ -114,7 +117,7 @@ func TestListTransactions_UnpaidIncoming(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, true, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, true, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(3), totalCount)
 	assert.Equal(t, 3, len(incomingTransactions))
@@ -182,7 +185,7 @@ func TestListTransactions_UnpaidOutgoing(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	outgoingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, true, false, nil, svc.LNClient, nil, false)
+	outgoingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, true, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(3), totalCount)
 	assert.Equal(t, 3, len(outgoingTransactions))
@@ -250,7 +253,7 @@ func TestListTransactions_Unpaid(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	outgoingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, true, true, nil, svc.LNClient, nil, false)
+	outgoingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, true, true, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(5), totalCount)
 	assert.Equal(t, 5, len(outgoingTransactions))
@@ -286,7 +289,7 @@ func TestListTransactions_Limit(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 1, 0, false, false, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 1, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(2), totalCount)
 	assert.Equal(t, 1, len(incomingTransactions))
@@ -343,7 +346,7 @@ func TestListTransactions_Offset(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 1, 2, false, false, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 1, 2, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(4), totalCount)
 	assert.Equal(t, 1, len(incomingTransactions))
@@ -394,7 +397,7 @@ func TestListTransactions_FromUntil(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, false, false, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), totalCount)
 	assert.Equal(t, 1, len(incomingTransactions))
@@ -456,7 +459,7 @@ func TestListTransactions_FromUntilUnpaidOutgoing(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, true, false, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, true, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), totalCount)
 	assert.Equal(t, "second", incomingTransactions[0].Description)
@@ -518,9 +521,207 @@ func TestListTransactions_FromUntilUnpaidIncoming(t *testing.T) {
 
 	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
 
-	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, false, true, nil, svc.LNClient, nil, false)
+	incomingTransactions, totalCount, err := transactionsService.ListTransactions(ctx, uint64(time.Now().Add(4*time.Minute).Unix()), uint64(time.Now().Add(6*time.Minute).Unix()), 0, 0, false, true, nil, svc.LNClient, nil, false, ListTransactionsFilters{})
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), totalCount)
 	assert.Equal(t, "second", incomingTransactions[0].Description)
 	assert.Equal(t, constants.TRANSACTION_TYPE_INCOMING, incomingTransactions[0].Type)
 }
+
+func TestListTransactions_Cursor(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	now := time.Now()
+	for i := range 5 {
+		err = svc.DB.Create(&db.Transaction{
+			State:      constants.TRANSACTION_STATE_SETTLED,
+			Type:       constants.TRANSACTION_TYPE_INCOMING,
+			AmountMsat: uint64(i+1) * 1000,
+			CreatedAt:  now.Add(time.Duration(-i) * time.Minute),
+			UpdatedAt:  now.Add(time.Duration(-i) * time.Minute),
+		}).Error
+		require.NoError(t, err)
+	}
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+
+	// an empty cursor starts paging by creation time
+	firstPage, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 2, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{
+		Cursor: new(string),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), totalCount)
+	require.Len(t, firstPage, 2)
+	assert.Equal(t, uint64(1000), firstPage[0].AmountMsat)
+	assert.Equal(t, uint64(2000), firstPage[1].AmountMsat)
+
+	// a new transaction arriving while paging must not shift the next page
+	err = svc.DB.Create(&db.Transaction{
+		State:      constants.TRANSACTION_STATE_SETTLED,
+		Type:       constants.TRANSACTION_TYPE_INCOMING,
+		AmountMsat: 99000,
+	}).Error
+	require.NoError(t, err)
+
+	// neither must a transaction of a later page which is updated while paging
+	err = svc.DB.Model(&db.Transaction{}).Where("amount_msat = ?", 5000).Update("description", "updated").Error
+	require.NoError(t, err)
+
+	secondPageCursor := NewTransactionsCursor(&firstPage[1])
+	secondPage, _, err := transactionsService.ListTransactions(ctx, 0, 0, 2, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{
+		Cursor: &secondPageCursor,
+	})
+	assert.NoError(t, err)
+	require.Len(t, secondPage, 2)
+	assert.Equal(t, uint64(3000), secondPage[0].AmountMsat)
+	assert.Equal(t, uint64(4000), secondPage[1].AmountMsat)
+
+	lastPageCursor := NewTransactionsCursor(&secondPage[1])
+	lastPage, _, err := transactionsService.ListTransactions(ctx, 0, 0, 2, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{
+		Cursor: &lastPageCursor,
+	})
+	assert.NoError(t, err)
+	require.Len(t, lastPage, 1)
+	assert.Equal(t, uint64(5000), lastPage[0].AmountMsat)
+	assert.Equal(t, "updated", lastPage[0].Description)
+}
+
+func TestListTransactions_InvalidCursor(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+	cursor := "not-a-cursor"
+	_, _, err = transactionsService.ListTransactions(ctx, 0, 0, 2, 0, false, false, nil, svc.LNClient, nil, false, ListTransactionsFilters{
+		Cursor: &cursor,
+	})
+	assert.ErrorIs(t, err, NewInvalidCursorError())
+}
+
+func TestListTransactions_Filters(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+	for _, transaction := range []db.Transaction{
+		{State: constants.TRANSACTION_STATE_SETTLED, AmountMsat: 1000, Description: "Coffee", Metadata: datatypes.JSON(`{"comment":"thanks!","tags":["food"],"nostr":{"pubkey":"abc","tags":[["e","event123"]]}}`)},
+		{State: constants.TRANSACTION_STATE_SETTLED, AmountMsat: 5000, Description: "100% tip", Metadata: datatypes.JSON(`{"payer_data":{"name":"Alice"}}`)},
+		{State: constants.TRANSACTION_STATE_SETTLED, AmountMsat: 10000, Description: "Boost", Boostagram: datatypes.JSON(`{"app_name":"Fountain"}`)},
+		{State: constants.TRANSACTION_STATE_PENDING, AmountMsat: 2000, Description: "coffee beans"},
+		{State: constants.TRANSACTION_STATE_FAILED, AmountMsat: 3000, Description: "Failed coffee"},
+	} {
+		transaction.Type = constants.TRANSACTION_TYPE_INCOMING
+		err = svc.DB.Create(&transaction).Error
+		require.NoError(t, err)
+		transactionsService.indexTransactionMetadata(&transaction)
+	}
+
+	listTransactions := func(filters ListTransactionsFilters) []uint64 {
+		transactions, totalCount, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, false, nil, svc.LNClient, nil, false, filters)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(transactions)), totalCount)
+		amounts := []uint64{}
+		for _, transaction := range transactions {
+			amounts = append(amounts, transaction.AmountMsat)
+		}
+		return amounts
+	}
+
+	assert.ElementsMatch(t, []uint64{2000, 3000}, listTransactions(ListTransactionsFilters{
+		States: []string{constants.TRANSACTION_STATE_PENDING, constants.TRANSACTION_STATE_FAILED},
+	}))
+	assert.ElementsMatch(t, []uint64{5000, 10000}, listTransactions(ListTransactionsFilters{
+		MinAmountMsat: 4000,
+	}))
+	assert.ElementsMatch(t, []uint64{1000, 5000}, listTransactions(ListTransactionsFilters{
+		MaxAmountMsat: 5000,
+	}))
+	assert.ElementsMatch(t, []uint64{1000}, listTransactions(ListTransactionsFilters{
+		Metadata: map[string]string{"comment": ""},
+	}))
+	assert.ElementsMatch(t, []uint64{5000}, listTransactions(ListTransactionsFilters{
+		Metadata: map[string]string{"payer_data.name": "Alice"},
+	}))
+	assert.Empty(t, listTransactions(ListTransactionsFilters{
+		Metadata: map[string]string{"payer_data.name": "Bob"},
+	}))
+	assert.ElementsMatch(t, []uint64{10000}, listTransactions(ListTransactionsFilters{
+		Metadata: map[string]string{"boostagram.app_name": "Fountain"},
+	}))
+	assert.ElementsMatch(t, []uint64{1000, 2000, 3000}, listTransactions(ListTransactionsFilters{
+		States: []string{constants.TRANSACTION_STATE_SETTLED, constants.TRANSACTION_STATE_PENDING, constants.TRANSACTION_STATE_FAILED},
+		Search: "COFFEE",
+	}))
+	// searches comments and payer names, words are matched by prefix
+	assert.ElementsMatch(t, []uint64{1000}, listTransactions(ListTransactionsFilters{
+		Search: "thank",
+	}))
+	assert.ElementsMatch(t, []uint64{5000}, listTransactions(ListTransactionsFilters{
+		Search: "alice tip",
+	}))
+	assert.Empty(t, listTransactions(ListTransactionsFilters{
+		Search: "alice coffee",
+	}))
+	assert.Empty(t, listTransactions(ListTransactionsFilters{
+		Search: "%",
+	}))
+	assert.ElementsMatch(t, []uint64{5000}, listTransactions(ListTransactionsFilters{
+		PayerName: "Alice",
+	}))
+	assert.ElementsMatch(t, []uint64{1000}, listTransactions(ListTransactionsFilters{
+		Tag: "food",
+	}))
+	assert.ElementsMatch(t, []uint64{1000}, listTransactions(ListTransactionsFilters{
+		NostrEventId: "event123",
+	}))
+}
+
+func TestListTransactions_LabelFilters(t *testing.T) {
+	ctx := context.TODO()
+
+	svc, err := tests.CreateTestService(t)
+	require.NoError(t, err)
+	defer svc.Remove()
+
+	transactionsService := NewTransactionsService(svc.DB, svc.EventPublisher)
+	labelsService := labels.NewLabelsService(svc.DB)
+	for i, amountMsat := range []uint64{1000, 2000, 3000} {
+		transaction := db.Transaction{Type: constants.TRANSACTION_TYPE_INCOMING, State: constants.TRANSACTION_STATE_SETTLED, AmountMsat: amountMsat}
+		err = svc.DB.Create(&transaction).Error
+		require.NoError(t, err)
+
+		switch i {
+		case 0:
+			_, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_TRANSACTION, strconv.FormatUint(uint64(transaction.ID), 10), "Office coffee", "", []string{"expenses"})
+		case 1:
+			_, err = labelsService.SetLabel(constants.LABEL_ENTITY_TYPE_TRANSACTION, strconv.FormatUint(uint64(transaction.ID), 10), "", "paid back by 50%", []string{"personal"})
+		}
+		require.NoError(t, err)
+	}
+
+	listTransactions := func(filters ListTransactionsFilters) []uint64 {
+		transactions, _, err := transactionsService.ListTransactions(ctx, 0, 0, 0, 0, false, false, nil, svc.LNClient, nil, false, filters)
+		require.NoError(t, err)
+		amounts := []uint64{}
+		for _, transaction := range transactions {
+			amounts = append(amounts, transaction.AmountMsat)
+		}
+		return amounts
+	}
+
+	assert.ElementsMatch(t, []uint64{1000}, listTransactions(ListTransactionsFilters{Label: "coffee"}))
+	assert.ElementsMatch(t, []uint64{2000}, listTransactions(ListTransactionsFilters{Label: "50%"}))
+	assert.Empty(t, listTransactions(ListTransactionsFilters{Label: "_"}))
+	assert.ElementsMatch(t, []uint64{2000}, listTransactions(ListTransactionsFilters{LabelTag: "personal"}))
+	assert.Empty(t, listTransactions(ListTransactionsFilters{Label: "coffee", LabelTag: "personal"}))
+}
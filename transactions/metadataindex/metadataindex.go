@@ -0,0 +1,203 @@
+// Package metadataindex extracts well-known fields from the freeform
+// transaction metadata and boostagram JSON into the indexed
+// transaction_metadata_entries table and the transaction_search full-text index.
+//
+// It does not depend on the db package so that it can also be used on databases
+// which are being copied or imported.
+package metadataindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PAYER_NAME_KEY     = "payer_name"
+	COMMENT_KEY        = "comment"
+	RECIPIENT_KEY      = "recipient"
+	NOSTR_EVENT_ID_KEY = "nostr_event_id"
+	TAG_KEY            = "tag"
+	BOOSTAGRAM_APP_KEY = "boostagram_app"
+)
+
+type Entry struct {
+	Key   string
+	Value string
+}
+
+type transactionMetadata struct {
+	Comment   string `json:"comment"` // LUD-12
+	PayerData *struct {
+		Name string `json:"name"`
+	} `json:"payer_data"` // LUD-18
+	RecipientData *struct {
+		Identifier string `json:"identifier"`
+	} `json:"recipient_data"` // LUD-18
+	Nostr *struct {
+		Id   string     `json:"id"`
+		Tags [][]string `json:"tags"`
+	} `json:"nostr"` // NIP-57
+	Offer *struct {
+		PayerNote string `json:"payer_note"`
+	} `json:"offer"` // BOLT-12
+	Tags []string `json:"tags"`
+}
+
+type transactionBoostagram struct {
+	AppName    string `json:"app_name"`
+	SenderName string `json:"sender_name"`
+	Message    string `json:"message"`
+}
+
+// Extract returns the normalized metadata entries and the text to index for full-text search
+func Extract(description string, metadataJSON, boostagramJSON []byte) (entries []Entry, searchContent string) {
+	addEntry := func(key, value string) {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return
+		}
+		entry := Entry{Key: key, Value: value}
+		if !slices.Contains(entries, entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(metadataJSON) > 0 {
+		var metadata transactionMetadata
+		// metadata is freeform so fields of unexpected types are ignored
+		if err := json.Unmarshal(metadataJSON, &metadata); err == nil {
+			addEntry(COMMENT_KEY, metadata.Comment)
+			if metadata.PayerData != nil {
+				addEntry(PAYER_NAME_KEY, metadata.PayerData.Name)
+			}
+			if metadata.RecipientData != nil {
+				addEntry(RECIPIENT_KEY, metadata.RecipientData.Identifier)
+			}
+			if metadata.Nostr != nil {
+				addEntry(NOSTR_EVENT_ID_KEY, metadata.Nostr.Id)
+				for _, tag := range metadata.Nostr.Tags {
+					if len(tag) > 1 && tag[0] == "e" {
+						addEntry(NOSTR_EVENT_ID_KEY, tag[1])
+					}
+				}
+			}
+			if metadata.Offer != nil {
+				addEntry(COMMENT_KEY, metadata.Offer.PayerNote)
+			}
+			for _, tag := range metadata.Tags {
+				addEntry(TAG_KEY, tag)
+			}
+		}
+	}
+
+	if len(boostagramJSON) > 0 {
+		var boostagram transactionBoostagram
+		if err := json.Unmarshal(boostagramJSON, &boostagram); err == nil {
+			addEntry(BOOSTAGRAM_APP_KEY, boostagram.AppName)
+			addEntry(PAYER_NAME_KEY, boostagram.SenderName)
+			addEntry(COMMENT_KEY, boostagram.Message)
+		}
+	}
+
+	searchParts := []string{}
+	if description != "" {
+		searchParts = append(searchParts, description)
+	}
+	for _, entry := range entries {
+		switch entry.Key {
+		case PAYER_NAME_KEY, COMMENT_KEY, RECIPIENT_KEY, TAG_KEY:
+			searchParts = append(searchParts, entry.Value)
+		}
+	}
+
+	return entries, strings.Join(searchParts, "\n")
+}
+
+// Index replaces the metadata entries and search document of a transaction
+func Index(tx *gorm.DB, transactionId uint, description string, metadataJSON, boostagramJSON []byte) error {
+	entries, searchContent := Extract(description, metadataJSON, boostagramJSON)
+
+	err := tx.Exec("DELETE FROM transaction_metadata_entries WHERE transaction_id = ?", transactionId).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction metadata entries: %w", err)
+	}
+	for _, entry := range entries {
+		err = tx.Exec("INSERT INTO transaction_metadata_entries (transaction_id, key, value) VALUES (?, ?, ?)", transactionId, entry.Key, entry.Value).Error
+		if err != nil {
+			return fmt.Errorf("failed to save transaction metadata entry: %w", err)
+		}
+	}
+
+	err = tx.Exec("DELETE FROM transaction_search WHERE docid = ?", transactionId).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete transaction search document: %w", err)
+	}
+	if searchContent != "" {
+		err = tx.Exec("INSERT INTO transaction_search (docid, content) VALUES (?, ?)", transactionId, searchContent).Error
+		if err != nil {
+			return fmt.Errorf("failed to save transaction search document: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reindex rebuilds the index for all transactions, e.g. after they were copied into a new database
+func Reindex(tx *gorm.DB) error {
+	type transaction struct {
+		ID          uint
+		Description string
+		Metadata    []byte
+		Boostagram  []byte
+	}
+
+	batchSize := 1000
+	lastId := uint(0)
+	for {
+		transactions := []transaction{}
+		err := tx.Raw("SELECT id, description, metadata, boostagram FROM transactions WHERE id > ? ORDER BY id LIMIT ?", lastId, batchSize).
+			Scan(&transactions).Error
+		if err != nil {
+			return fmt.Errorf("failed to list transactions: %w", err)
+		}
+		for _, transaction := range transactions {
+			err = Index(tx, transaction.ID, transaction.Description, transaction.Metadata, transaction.Boostagram)
+			if err != nil {
+				return err
+			}
+			lastId = transaction.ID
+		}
+		if len(transactions) < batchSize {
+			return nil
+		}
+	}
+}
+
+// SearchCondition returns a condition matching transactions whose description,
+// comments, payer names, recipient or tags contain all words of the search query.
+// Words are matched by prefix.
+func SearchCondition(tx *gorm.DB, search string) (string, []interface{}) {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		// nothing searchable, e.g. only punctuation
+		return "1 = 0", nil
+	}
+
+	if tx.Dialector.Name() == "postgres" {
+		for i := range words {
+			words[i] += ":*"
+		}
+		return "id IN (SELECT docid FROM transaction_search WHERE to_tsvector('simple', content) @@ to_tsquery('simple', ?))", []interface{}{strings.Join(words, " & ")}
+	}
+
+	for i := range words {
+		words[i] += "*"
+	}
+	return "id IN (SELECT docid FROM transaction_search WHERE content MATCH ?)", []interface{}{strings.Join(words, " ")}
+}
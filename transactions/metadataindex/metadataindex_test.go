@@ -0,0 +1,43 @@
+package metadataindex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtract(t *testing.T) {
+	metadata := []byte(`{
+		"comment": "thanks for the coffee",
+		"payer_data": {"name": "Alice"},
+		"recipient_data": {"identifier": "bob@getalby.com"},
+		"nostr": {"id": "event1", "tags": [["e", "event2"], ["p", "pubkey"]]},
+		"tags": ["coffee", "coffee"]
+	}`)
+	boostagram := []byte(`{"app_name": "Fountain", "sender_name": "Carol", "message": "great episode"}`)
+
+	entries, searchContent := Extract("lunch", metadata, boostagram)
+
+	assert.ElementsMatch(t, []Entry{
+		{Key: COMMENT_KEY, Value: "thanks for the coffee"},
+		{Key: PAYER_NAME_KEY, Value: "Alice"},
+		{Key: RECIPIENT_KEY, Value: "bob@getalby.com"},
+		{Key: NOSTR_EVENT_ID_KEY, Value: "event1"},
+		{Key: NOSTR_EVENT_ID_KEY, Value: "event2"},
+		{Key: TAG_KEY, Value: "coffee"},
+		{Key: BOOSTAGRAM_APP_KEY, Value: "Fountain"},
+		{Key: PAYER_NAME_KEY, Value: "Carol"},
+		{Key: COMMENT_KEY, Value: "great episode"},
+	}, entries)
+
+	assert.Contains(t, searchContent, "lunch")
+	assert.Contains(t, searchContent, "Alice")
+	assert.Contains(t, searchContent, "great episode")
+	assert.NotContains(t, searchContent, "event1")
+}
+
+func TestExtract_InvalidMetadata(t *testing.T) {
+	entries, searchContent := Extract("lunch", []byte(`"not an object"`), nil)
+	assert.Empty(t, entries)
+	assert.Equal(t, "lunch", searchContent)
+}
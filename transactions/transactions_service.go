@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -17,6 +18,7 @@ import (
 
 	decodepay "github.com/nbd-wtf/ln-decodepay"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
@@ -26,6 +28,8 @@ import (
 	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/lnclient"
 	"github.com/getAlby/hub/logger"
+	"github.com/getAlby/hub/tracing"
+	"github.com/getAlby/hub/transactions/metadataindex"
 )
 
 type transactionsService struct {
@@ -37,12 +41,14 @@ type TransactionsService interface {
 	events.EventSubscriber
 	MakeInvoice(ctx context.Context, amount uint64, description string, descriptionHash string, expiry uint64, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint, throughNodePubkey *string) (*Transaction, error)
 	LookupTransaction(ctx context.Context, paymentHash string, transactionType *string, lnClient lnclient.LNClient, appId *uint) (*Transaction, error)
-	ListTransactions(ctx context.Context, from, until, limit, offset uint64, unpaidOutgoing bool, unpaidIncoming bool, transactionType *string, lnClient lnclient.LNClient, appId *uint, forceFilterByAppId bool) (transactions []Transaction, totalCount uint64, err error)
-	SendPaymentSync(payReq string, amountMsat *uint64, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error)
-	SendKeysend(amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error)
+	ListTransactions(ctx context.Context, from, until, limit, offset uint64, unpaidOutgoing bool, unpaidIncoming bool, transactionType *string, lnClient lnclient.LNClient, appId *uint, forceFilterByAppId bool, filters ListTransactionsFilters) (transactions []Transaction, totalCount uint64, err error)
+	SendPaymentSync(ctx context.Context, payReq string, amountMsat *uint64, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error)
+	SendKeysend(ctx context.Context, amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error)
 	MakeHoldInvoice(ctx context.Context, amount uint64, description string, descriptionHash string, expiry uint64, paymentHash string, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error)
 	SettleHoldInvoice(ctx context.Context, preimage string, lnClient lnclient.LNClient) (*Transaction, error)
 	CancelHoldInvoice(ctx context.Context, paymentHash string, lnClient lnclient.LNClient) error
+	ListAcceptedHoldInvoices(ctx context.Context) ([]Transaction, error)
+	ProcessExpiringHoldInvoices(ctx context.Context, lnClient lnclient.LNClient, blockHeight uint32, policy HoldInvoicePolicy) error
 	SetTransactionMetadata(ctx context.Context, id uint, metadata map[string]interface{}) error
 }
 
@@ -58,6 +64,33 @@ var balanceValidationLock = &sync.Mutex{}
 
 type Transaction = db.Transaction
 
+type ListTransactionsFilters struct {
+	// Cursor returned by NewTransactionsCursor for the last transaction of the previous page,
+	// or empty for the first page. When set, transactions are ordered by creation time instead
+	// of by their last update so that pages are not affected by transactions created or
+	// updated while paging. Offset is ignored.
+	Cursor *string
+	// States overrides the unpaid filters when set
+	States        []string
+	MinAmountMsat uint64
+	MaxAmountMsat uint64
+	// Metadata filters by dot-separated metadata keys (e.g. "payer_data.name").
+	// An empty value only requires the key to exist.
+	// Keys prefixed with "boostagram." filter on the boostagram instead.
+	Metadata map[string]string
+	// normalized metadata, see metadataindex.Extract
+	PayerName    string
+	Recipient    string
+	NostrEventId string
+	Tag          string
+	// owner-defined labels, see labels.LabelsService
+	Label    string // case-insensitive match on the label or note
+	LabelTag string
+	// Search does a full-text search over the description, comments,
+	// payer names, recipient and tags. Words are matched by prefix.
+	Search string
+}
+
 type Boostagram struct {
 	AppName        string         `json:"app_name"`
 	Name           string         `json:"name"`
@@ -121,6 +154,17 @@ func (err *insufficientBalanceError) Error() string {
 	return "Insufficient balance remaining to make the requested payment"
 }
 
+type invalidCursorError struct {
+}
+
+func NewInvalidCursorError() error {
+	return &invalidCursorError{}
+}
+
+func (err *invalidCursorError) Error() string {
+	return "The provided cursor is invalid"
+}
+
 type quotaExceededError struct {
 }
 
@@ -209,6 +253,7 @@ func (svc *transactionsService) MakeInvoice(ctx context.Context, amount uint64,
 		logger.Logger.WithError(err).Error("Failed to create DB transaction")
 		return nil, err
 	}
+	svc.indexTransactionMetadata(&dbTransaction)
 	return &dbTransaction, nil
 }
 
@@ -263,13 +308,18 @@ func (svc *transactionsService) MakeHoldInvoice(ctx context.Context, amount uint
 		logger.Logger.WithError(err).Error("Failed to create hold invoice DB transaction")
 		return nil, err
 	}
+	svc.indexTransactionMetadata(&dbTransaction)
 	return &dbTransaction, nil
 }
 
-func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint64, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error) {
+func (svc *transactionsService) SendPaymentSync(ctx context.Context, payReq string, amountMsat *uint64, metadata map[string]interface{}, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (transaction *Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "transactions.SendPaymentSync", appIdAttributes(appId)...)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	var metadataBytes []byte
 	if metadata != nil {
-		var err error
 		metadataBytes, err = json.Marshal(metadata)
 		if err != nil {
 			logger.Logger.WithError(err).Error("Failed to serialize metadata")
@@ -308,9 +358,21 @@ func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint6
 		paymentAmount = *amountMsat
 	}
 
-	err = func() error {
+	span.SetAttributes(
+		attribute.String("payment_hash", paymentRequest.PaymentHash),
+		attribute.Int64("amount_msat", int64(paymentAmount)),
+		attribute.Bool("self_payment", selfPayment),
+	)
+
+	err = func() (err error) {
+		// includes waiting for the balance validation lock
+		_, validateSpan := tracing.StartSpan(ctx, "transactions.validateCanPay")
+		defer func() {
+			tracing.EndSpan(validateSpan, err)
+		}()
 		balanceValidationLock.Lock()
 		defer balanceValidationLock.Unlock()
+		validateSpan.AddEvent("balance validation lock acquired")
 		return svc.db.Transaction(func(tx *gorm.DB) error {
 			var existingSettledTransaction db.Transaction
 			if tx.Limit(1).Find(&existingSettledTransaction, &db.Transaction{
@@ -330,7 +392,12 @@ func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint6
 				return errors.New("there is already a payment pending for this invoice")
 			}
 
-			err := svc.validateCanPay(tx, appId, paymentAmount, paymentRequest.Description, selfPayment)
+			budgetApproved, err := isBudgetApproved(tx, requestEventId, map[string]interface{}{"payment_hash": paymentRequest.PaymentHash})
+			if err != nil {
+				return err
+			}
+
+			err = svc.validateCanPay(tx, appId, paymentAmount, paymentRequest.Description, selfPayment, budgetApproved)
 			if err != nil {
 				return err
 			}
@@ -366,6 +433,7 @@ func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint6
 		}).WithError(err).Error("Failed to create DB transaction")
 		return nil, err
 	}
+	svc.indexTransactionMetadata(&dbTransaction)
 
 	logger.Logger.WithFields(logrus.Fields{
 		"app_id":           appId,
@@ -380,9 +448,9 @@ func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint6
 
 	var response *lnclient.PayInvoiceResponse
 	if selfPayment {
-		response, err = svc.interceptSelfPayment(paymentRequest.PaymentHash, lnClient)
+		response, err = svc.interceptSelfPayment(ctx, paymentRequest.PaymentHash, lnClient)
 	} else {
-		response, err = lnClient.SendPaymentSync(payReq, amountMsat)
+		response, err = lnClient.SendPaymentSync(ctx, payReq, amountMsat)
 	}
 
 	if err != nil {
@@ -410,7 +478,12 @@ func (svc *transactionsService) SendPaymentSync(payReq string, amountMsat *uint6
 	return settledTransaction, nil
 }
 
-func (svc *transactionsService) SendKeysend(amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (*Transaction, error) {
+func (svc *transactionsService) SendKeysend(ctx context.Context, amount uint64, destination string, customRecords []lnclient.TLVRecord, preimage string, lnClient lnclient.LNClient, appId *uint, requestEventId *uint) (transaction *Transaction, err error) {
+	ctx, span := tracing.StartSpan(ctx, "transactions.SendKeysend", appIdAttributes(appId)...)
+	defer func() {
+		tracing.EndSpan(span, err)
+	}()
+
 	if preimage == "" {
 		preImageBytes, err := makePreimageHex()
 		if err != nil {
@@ -448,11 +521,28 @@ func (svc *transactionsService) SendKeysend(amount uint64, destination string, c
 
 	selfPayment := destination == lnClient.GetPubkey()
 
-	err = func() error {
+	span.SetAttributes(
+		attribute.String("payment_hash", paymentHash),
+		attribute.Int64("amount_msat", int64(amount)),
+		attribute.Bool("self_payment", selfPayment),
+	)
+
+	err = func() (err error) {
+		// includes waiting for the balance validation lock
+		_, validateSpan := tracing.StartSpan(ctx, "transactions.validateCanPay")
+		defer func() {
+			tracing.EndSpan(validateSpan, err)
+		}()
 		balanceValidationLock.Lock()
 		defer balanceValidationLock.Unlock()
+		validateSpan.AddEvent("balance validation lock acquired")
 		return svc.db.Transaction(func(tx *gorm.DB) error {
-			err := svc.validateCanPay(tx, appId, amount, "", selfPayment)
+			budgetApproved, err := isBudgetApproved(tx, requestEventId, map[string]interface{}{"destination": destination, "amount_msat": amount})
+			if err != nil {
+				return err
+			}
+
+			err = svc.validateCanPay(tx, appId, amount, "", selfPayment, budgetApproved)
 			if err != nil {
 				return err
 			}
@@ -484,6 +574,7 @@ func (svc *transactionsService) SendKeysend(amount uint64, destination string, c
 		}).WithError(err).Error("Failed to create DB transaction")
 		return nil, err
 	}
+	svc.indexTransactionMetadata(&dbTransaction)
 
 	var payKeysendResponse *lnclient.PayKeysendResponse
 
@@ -508,15 +599,16 @@ func (svc *transactionsService) SendKeysend(amount uint64, destination string, c
 			logger.Logger.WithError(err).Error("Failed to create DB transaction")
 			return nil, err
 		}
+		svc.indexTransactionMetadata(&dbTransaction)
 
-		_, err = svc.interceptSelfPayment(paymentHash, lnClient)
+		_, err = svc.interceptSelfPayment(ctx, paymentHash, lnClient)
 		if err == nil {
 			payKeysendResponse = &lnclient.PayKeysendResponse{
 				Fee: 0,
 			}
 		}
 	} else {
-		payKeysendResponse, err = lnClient.SendKeysend(amount, destination, customRecords, preimage)
+		payKeysendResponse, err = lnClient.SendKeysend(ctx, amount, destination, customRecords, preimage)
 	}
 
 	if err != nil {
@@ -608,7 +700,7 @@ func (svc *transactionsService) LookupTransaction(ctx context.Context, paymentHa
 	return &transaction, nil
 }
 
-func (svc *transactionsService) ListTransactions(ctx context.Context, from, until, limit, offset uint64, unpaidOutgoing bool, unpaidIncoming bool, transactionType *string, lnClient lnclient.LNClient, appId *uint, forceFilterByAppId bool) (transactions []Transaction, totalCount uint64, err error) {
+func (svc *transactionsService) ListTransactions(ctx context.Context, from, until, limit, offset uint64, unpaidOutgoing bool, unpaidIncoming bool, transactionType *string, lnClient lnclient.LNClient, appId *uint, forceFilterByAppId bool, filters ListTransactionsFilters) (transactions []Transaction, totalCount uint64, err error) {
 	svc.checkUnsettledTransactions(ctx, lnClient)
 
 	var isIsolatedApp bool
@@ -632,7 +724,9 @@ func (svc *transactionsService) ListTransactions(ctx context.Context, from, unti
 		tx = tx.Where("app_id = ?", *appId)
 	}
 
-	if !unpaidOutgoing && !unpaidIncoming {
+	if len(filters.States) > 0 {
+		tx = tx.Where("state IN ?", filters.States)
+	} else if !unpaidOutgoing && !unpaidIncoming {
 		tx = tx.Where("state = ?", constants.TRANSACTION_STATE_SETTLED)
 	} else if unpaidOutgoing && !unpaidIncoming {
 		tx = tx.Where("state = ? OR type = ?", constants.TRANSACTION_STATE_SETTLED, constants.TRANSACTION_TYPE_OUTGOING)
@@ -651,6 +745,55 @@ func (svc *transactionsService) ListTransactions(ctx context.Context, from, unti
 		tx = tx.Where("updated_at <= ?", time.Unix(int64(until), 0))
 	}
 
+	if filters.MinAmountMsat > 0 {
+		tx = tx.Where("amount_msat >= ?", filters.MinAmountMsat)
+	}
+	if filters.MaxAmountMsat > 0 {
+		tx = tx.Where("amount_msat <= ?", filters.MaxAmountMsat)
+	}
+
+	for key, value := range filters.Metadata {
+		column := "metadata"
+		if boostagramKey, ok := strings.CutPrefix(key, "boostagram."); ok {
+			column = "boostagram"
+			key = boostagramKey
+		}
+		keys := strings.Split(key, ".")
+		if value == "" {
+			tx = tx.Where(datatypes.JSONQuery(column).HasKey(keys...))
+		} else {
+			tx = tx.Where(datatypes.JSONQuery(column).Equals(value, keys...))
+		}
+	}
+
+	for key, value := range map[string]string{
+		metadataindex.PAYER_NAME_KEY:     filters.PayerName,
+		metadataindex.RECIPIENT_KEY:      filters.Recipient,
+		metadataindex.NOSTR_EVENT_ID_KEY: filters.NostrEventId,
+		metadataindex.TAG_KEY:            filters.Tag,
+	} {
+		if value != "" {
+			tx = tx.Where("id IN (SELECT transaction_id FROM transaction_metadata_entries WHERE key = ? AND value = ?)", key, value)
+		}
+	}
+
+	if filters.Label != "" || filters.LabelTag != "" {
+		labelQuery := svc.db.Model(&db.Label{}).Select("transaction_id").Where("entity_type = ?", constants.LABEL_ENTITY_TYPE_TRANSACTION)
+		if filters.Label != "" {
+			pattern := "%" + escapeLikePattern(strings.ToLower(filters.Label)) + "%"
+			labelQuery = labelQuery.Where("LOWER(label) LIKE ? ESCAPE '\\' OR LOWER(note) LIKE ? ESCAPE '\\'", pattern, pattern)
+		}
+		if filters.LabelTag != "" {
+			labelQuery = labelQuery.Where(datatypes.JSONArrayQuery("tags").Contains(filters.LabelTag))
+		}
+		tx = tx.Where("id IN (?)", labelQuery)
+	}
+
+	if filters.Search != "" {
+		searchCondition, searchArgs := metadataindex.SearchCondition(svc.db, filters.Search)
+		tx = tx.Where(searchCondition, searchArgs...)
+	}
+
 	var totalCount64 int64
 	result := tx.Model(&db.Transaction{}).Count(&totalCount64)
 	if result.Error != nil {
@@ -659,14 +802,27 @@ func (svc *transactionsService) ListTransactions(ctx context.Context, from, unti
 	}
 	totalCount = uint64(totalCount64)
 
-	tx = tx.Order("updated_at desc")
+	if filters.Cursor != nil {
+		if *filters.Cursor != "" {
+			cursorCreatedAt, cursorId, err := parseTransactionsCursor(*filters.Cursor)
+			if err != nil {
+				logger.Logger.WithField("cursor", *filters.Cursor).WithError(err).Error("Failed to parse transactions cursor")
+				return nil, 0, NewInvalidCursorError()
+			}
+			tx = tx.Where("created_at < ? OR (created_at = ? AND id < ?)", cursorCreatedAt, cursorCreatedAt, cursorId)
+		}
+		// the creation time never changes so transactions cannot move between pages
+		tx = tx.Order("created_at desc, id desc")
+	} else {
+		tx = tx.Order("updated_at desc")
+		if offset > 0 {
+			tx = tx.Offset(int(offset))
+		}
+	}
 
 	if limit > 0 {
 		tx = tx.Limit(int(limit))
 	}
-	if offset > 0 {
-		tx = tx.Offset(int(offset))
-	}
 
 	result = tx.Find(&transactions)
 	if result.Error != nil {
@@ -677,6 +833,32 @@ func (svc *transactionsService) ListTransactions(ctx context.Context, from, unti
 	return transactions, totalCount, nil
 }
 
+// NewTransactionsCursor returns an opaque cursor which can be passed to ListTransactions
+// to list the transactions after the given one
+func NewTransactionsCursor(transaction *Transaction) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", transaction.CreatedAt.UnixNano(), transaction.ID))
+}
+
+func parseTransactionsCursor(cursor string) (time.Time, uint, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	createdAtString, idString, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return time.Time{}, 0, errors.New("missing cursor separator")
+	}
+	createdAt, err := strconv.ParseInt(createdAtString, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseUint(idString, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return time.Unix(0, createdAt), uint(id), nil
+}
+
 func (svc *transactionsService) checkUnsettledTransactions(ctx context.Context, lnClient lnclient.LNClient) {
 	// Only check unsettled transactions for clients that don't support async events
 	// checkUnsettledTransactions does not work for keysend payments!
@@ -730,6 +912,7 @@ func (svc *transactionsService) ConsumeEvent(ctx context.Context, event *events.
 		}
 
 		var dbTransaction db.Transaction
+		createdTransaction := false
 		err := svc.db.Transaction(func(tx *gorm.DB) error {
 
 			result := tx.Limit(1).Find(&dbTransaction, &db.Transaction{
@@ -784,6 +967,7 @@ func (svc *transactionsService) ConsumeEvent(ctx context.Context, event *events.
 					}).WithError(err).Error("Failed to create transaction")
 					return err
 				}
+				createdTransaction = true
 			}
 
 			_, err := svc.markTransactionSettled(tx, &dbTransaction, lnClientTransaction.Preimage, uint64(lnClientTransaction.FeesPaid), false)
@@ -797,6 +981,10 @@ func (svc *transactionsService) ConsumeEvent(ctx context.Context, event *events.
 			return
 		}
 
+		if createdTransaction {
+			svc.indexTransactionMetadata(&dbTransaction)
+		}
+
 	case "nwc_lnclient_hold_invoice_accepted":
 		lnClientTransaction, ok := event.Properties.(*lnclient.Transaction)
 		if !ok {
@@ -940,7 +1128,7 @@ func (svc *transactionsService) markHoldInvoiceAccepted(paymentHash string, sett
 	}
 }
 
-func (svc *transactionsService) interceptSelfPayment(paymentHash string, lnClient lnclient.LNClient) (*lnclient.PayInvoiceResponse, error) {
+func (svc *transactionsService) interceptSelfPayment(ctx context.Context, paymentHash string, lnClient lnclient.LNClient) (*lnclient.PayInvoiceResponse, error) {
 	logger.Logger.WithField("payment_hash", paymentHash).Debug("Intercepting self payment")
 	incomingTransaction := db.Transaction{}
 	result := svc.db.Limit(1).Find(&incomingTransaction, &db.Transaction{
@@ -957,7 +1145,7 @@ func (svc *transactionsService) interceptSelfPayment(paymentHash string, lnClien
 	}
 
 	if incomingTransaction.Hold {
-		return svc.interceptSelfHoldPayment(paymentHash, lnClient)
+		return svc.interceptSelfHoldPayment(ctx, paymentHash, lnClient)
 	}
 
 	if incomingTransaction.Preimage == nil {
@@ -979,7 +1167,7 @@ func (svc *transactionsService) interceptSelfPayment(paymentHash string, lnClien
 	}, nil
 }
 
-func (svc *transactionsService) interceptSelfHoldPayment(paymentHash string, lnClient lnclient.LNClient) (*lnclient.PayInvoiceResponse, error) {
+func (svc *transactionsService) interceptSelfHoldPayment(ctx context.Context, paymentHash string, lnClient lnclient.LNClient) (*lnclient.PayInvoiceResponse, error) {
 	settledChannel := make(chan *db.Transaction)
 	canceledChannel := make(chan *db.Transaction)
 
@@ -988,7 +1176,7 @@ func (svc *transactionsService) interceptSelfHoldPayment(paymentHash string, lnC
 	svc.eventPublisher.RegisterSubscriber(holdInvoiceUpdatedConsumer)
 	defer svc.eventPublisher.RemoveSubscriber(holdInvoiceUpdatedConsumer)
 
-	clientInfo, err := lnClient.GetInfo(context.Background())
+	clientInfo, err := lnClient.GetInfo(ctx)
 	if err != nil {
 		return nil, errors.New("failed to get client info")
 	}
@@ -1017,7 +1205,31 @@ func (svc *transactionsService) interceptSelfHoldPayment(paymentHash string, lnC
 	}
 }
 
-func (svc *transactionsService) validateCanPay(tx *gorm.DB, appId *uint, amount uint64, description string, selfPayment bool) error {
+func appIdAttributes(appId *uint) []attribute.KeyValue {
+	if appId == nil {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.Int64("app.id", int64(*appId))}
+}
+
+// isBudgetApproved returns true if the owner approved the payment of this request,
+// in which case it may exceed the app budget
+func isBudgetApproved(tx *gorm.DB, requestEventId *uint, paymentConditions map[string]interface{}) (bool, error) {
+	if requestEventId == nil {
+		return false, nil
+	}
+	var approvedCount int64
+	err := tx.Model(&db.PaymentApproval{}).
+		Where("request_event_id = ? AND state = ?", *requestEventId, constants.PAYMENT_APPROVAL_STATE_USED).
+		Where(paymentConditions).
+		Count(&approvedCount).Error
+	if err != nil {
+		return false, err
+	}
+	return approvedCount > 0, nil
+}
+
+func (svc *transactionsService) validateCanPay(tx *gorm.DB, appId *uint, amount uint64, description string, selfPayment bool, budgetApproved bool) error {
 	amountWithFeeReserve := amount
 	if !selfPayment {
 		amountWithFeeReserve += CalculateFeeReserveMsat(amount)
@@ -1069,7 +1281,7 @@ func (svc *transactionsService) validateCanPay(tx *gorm.DB, appId *uint, amount
 			}
 		}
 
-		if appPermission.MaxAmountSat > 0 {
+		if appPermission.MaxAmountSat > 0 && !budgetApproved {
 			budgetUsageSat := queries.GetBudgetUsageSat(tx, &appPermission)
 			if int(amountWithFeeReserve/1000) > appPermission.MaxAmountSat-int(budgetUsageSat) {
 				message := NewQuotaExceededError().Error()
@@ -1323,9 +1535,26 @@ func (svc *transactionsService) SetTransactionMetadata(ctx context.Context, id u
 		return err
 	}
 
+	var dbTransaction db.Transaction
+	err = svc.db.First(&dbTransaction, id).Error
+	if err != nil {
+		logger.Logger.WithError(err).WithField("id", id).Error("Failed to find updated transaction")
+		return err
+	}
+	svc.indexTransactionMetadata(&dbTransaction)
+
 	return nil
 }
 
+// keeps the normalized metadata and search index in sync with the transaction.
+// Failing to index should not fail the payment, so errors are only logged.
+func (svc *transactionsService) indexTransactionMetadata(dbTransaction *db.Transaction) {
+	err := metadataindex.Index(svc.db, dbTransaction.ID, dbTransaction.Description, dbTransaction.Metadata, dbTransaction.Boostagram)
+	if err != nil {
+		logger.Logger.WithError(err).WithField("id", dbTransaction.ID).Error("Failed to index transaction metadata")
+	}
+}
+
 func (svc *transactionsService) markTransactionSettled(tx *gorm.DB, dbTransaction *db.Transaction, preimage string, fee uint64, selfPayment bool) (*db.Transaction, error) {
 	// TODO: it would be better to have a database constraint so we cannot have two pending payments
 	var existingSettledTransaction db.Transaction
@@ -1409,8 +1638,11 @@ func (svc *transactionsService) checkBudgetUsage(dbTransaction *db.Transaction,
 		svc.eventPublisher.Publish(&events.Event{
 			Event: "nwc_budget_warning",
 			Properties: map[string]interface{}{
-				"name": app.Name,
-				"id":   app.ID,
+				"name":           app.Name,
+				"id":             app.ID,
+				"used_budget":    budgetUsage,
+				"total_budget":   appPermission.MaxAmountSat,
+				"renewal_period": appPermission.BudgetRenewal,
 			},
 		})
 	}
@@ -1451,3 +1683,7 @@ func (svc *transactionsService) markPaymentFailed(tx *gorm.DB, dbTransaction *db
 	})
 	return nil
 }
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
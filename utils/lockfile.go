@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrFileLocked is returned by LockFile if the file is locked by another process
+var ErrFileLocked = errors.New("file is locked by another process")
+
+// LockFile creates the file if needed, takes an exclusive lock on it and writes the
+// current process ID to it. The lock is held until the file is closed or the process exits.
+func LockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	err = lockFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	err = f.Truncate(0)
+	if err == nil {
+		_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return f, nil
+}
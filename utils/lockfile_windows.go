@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func lockFile(f *os.File) error {
+	// lock a byte range past the end of the file, so the process ID stays readable
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, &windows.Overlapped{Offset: 0xffffffff})
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return ErrFileLocked
+	}
+	return err
+}
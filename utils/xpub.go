@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// derives a native segwit address from the external chain of an xpub
+func DeriveAddressFromXpub(network string, xpub string, index uint32) (string, error) {
+	var netParams *chaincfg.Params
+	switch network {
+	case "bitcoin", "mainnet":
+		netParams = &chaincfg.MainNetParams
+	case "testnet":
+		netParams = &chaincfg.TestNet3Params
+	case "regtest":
+		netParams = &chaincfg.RegressionNetParams
+	case "signet":
+		netParams = &chaincfg.SigNetParams
+	default:
+		return "", fmt.Errorf("unsupported network: %s", network)
+	}
+
+	extPubKey, err := hdkeychain.NewKeyFromString(xpub)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse xpub: %w", err)
+	}
+
+	externalChain, err := extPubKey.Derive(0)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive external chain: %w", err)
+	}
+
+	addressKey, err := externalChain.Derive(index)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive address key at index %d: %w", index, err)
+	}
+
+	pubKey, err := addressKey.ECPubKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key: %w", err)
+	}
+
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	address, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, netParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to create address: %w", err)
+	}
+
+	return address.EncodeAddress(), nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/getAlby/hub/api"
 	"github.com/getAlby/hub/apps"
+	"github.com/getAlby/hub/events"
 	"github.com/getAlby/hub/logger"
 	"github.com/getAlby/hub/service"
 	"github.com/wailsapp/wails/v2"
@@ -38,6 +39,20 @@ func NewApp(svc service.Service) *WailsApp {
 // so we can call the runtime methods
 func (app *WailsApp) startup(ctx context.Context) {
 	app.ctx = ctx
+	app.svc.GetEventPublisher().RegisterSubscriber(&wailsEventForwarder{ctx: ctx})
+}
+
+// wailsEventForwarder emits events the user needs to act on to the frontend.
+// It is kept separate from WailsApp so it is not bound to the frontend.
+type wailsEventForwarder struct {
+	ctx context.Context
+}
+
+func (forwarder *wailsEventForwarder) ConsumeEvent(ctx context.Context, event *events.Event, globalProperties map[string]interface{}) {
+	switch event.Event {
+	case "nwc_payment_approval_required":
+		runtime.EventsEmit(forwarder.ctx, "payment_approval_required", event.Properties)
+	}
 }
 
 func (app *WailsApp) onBeforeClose(ctx context.Context) bool {
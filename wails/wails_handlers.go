@@ -117,6 +117,25 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 			}
 			return WailsRequestRouterResponse{Body: nil, Error: ""}
+		case "POST":
+			if !strings.HasSuffix(route, "/rotate-keys") {
+				break
+			}
+			rotateAppKeysRequest := &api.RotateAppKeysRequest{}
+			err := json.Unmarshal([]byte(body), rotateAppKeysRequest)
+			if err != nil {
+				logger.Logger.WithFields(logrus.Fields{
+					"route":  route,
+					"method": method,
+					"body":   body,
+				}).WithError(err).Error("Failed to decode request to wails router")
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			rotateAppKeysResponse, err := app.api.RotateAppKeys(dbApp, rotateAppKeysRequest)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: rotateAppKeysResponse, Error: ""}
 		}
 	}
 
@@ -165,6 +184,171 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 		return WailsRequestRouterResponse{Body: apps, Error: ""}
 	}
 
+	pendingChannelCloseRegex := regexp.MustCompile(
+		`/api/channels/pending-closes/([0-9]+)`,
+	)
+
+	pendingChannelCloseMatch := pendingChannelCloseRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(pendingChannelCloseMatch) > 1 && method == "DELETE":
+		id, err := strconv.ParseUint(pendingChannelCloseMatch[1], 10, 64)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		err = app.api.CancelPendingChannelClose(uint(id))
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: nil, Error: ""}
+	}
+
+	lspRegex := regexp.MustCompile(
+		`/api/lsps/([0-9]+)`,
+	)
+
+	lspMatch := lspRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(lspMatch) > 1 && method == "DELETE":
+		id, err := strconv.ParseUint(lspMatch[1], 10, 64)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		err = app.api.DeleteLSP(uint(id))
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: nil, Error: ""}
+	}
+
+	paymentApprovalRegex := regexp.MustCompile(
+		`/api/payment-approvals/([0-9]+)/(approve|deny)`,
+	)
+
+	paymentApprovalMatch := paymentApprovalRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(paymentApprovalMatch) > 2 && method == "POST":
+		id, err := strconv.ParseUint(paymentApprovalMatch[1], 10, 64)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		if paymentApprovalMatch[2] == "approve" {
+			err = app.api.ApprovePayment(uint(id))
+		} else {
+			err = app.api.DenyPayment(uint(id))
+		}
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: nil, Error: ""}
+	}
+
+	archivedRequestRegex := regexp.MustCompile(
+		`/api/archived-requests/([0-9a-f]+)(/replay)?$`,
+	)
+
+	archivedRequestMatch := archivedRequestRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(archivedRequestMatch) > 2 && archivedRequestMatch[2] == "" && method == "GET":
+		archivedRequest, err := app.api.GetArchivedRequest(archivedRequestMatch[1])
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: archivedRequest, Error: ""}
+	case len(archivedRequestMatch) > 2 && archivedRequestMatch[2] == "/replay" && method == "POST":
+		replayResponse, err := app.api.ReplayArchivedRequest(ctx, archivedRequestMatch[1])
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: replayResponse, Error: ""}
+	}
+
+	setLabelRegex := regexp.MustCompile(
+		`^/api/labels/([a-z_]+)/([^/?]+)$`,
+	)
+	listLabelsRegex := regexp.MustCompile(
+		`^/api/labels(\?entityType=([a-z_]+))?$`,
+	)
+
+	setLabelMatch := setLabelRegex.FindStringSubmatch(route)
+	listLabelsMatch := listLabelsRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(setLabelMatch) > 2 && method == "PUT":
+		setLabelRequest := &api.SetLabelRequest{}
+		err := json.Unmarshal([]byte(body), setLabelRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		label, err := app.api.SetLabel(setLabelMatch[1], setLabelMatch[2], setLabelRequest)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: label, Error: ""}
+	case len(listLabelsMatch) > 2 && method == "GET":
+		entityLabels, err := app.api.ListLabels(listLabelsMatch[2])
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: entityLabels, Error: ""}
+	}
+
+	// channels, peers and on-chain transactions can be filtered by their labels
+	labelFilteredListRegex := regexp.MustCompile(
+		`^(/api/channels|/api/peers|/api/node/transactions)\?filters=([^&]+)`,
+	)
+
+	labelFilteredListMatch := labelFilteredListRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(labelFilteredListMatch) > 2 && method == "GET":
+		var filters api.LabelFilters
+		err := json.Unmarshal([]byte(labelFilteredListMatch[2]), &filters)
+		if err != nil {
+			logger.Logger.WithError(err).WithFields(logrus.Fields{
+				"filters": labelFilteredListMatch[2],
+			}).Error("Failed to deserialize label filters")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+
+		var list interface{}
+		switch labelFilteredListMatch[1] {
+		case "/api/channels":
+			list, err = app.api.ListChannels(ctx, filters)
+		case "/api/peers":
+			list, err = app.api.ListPeers(ctx, filters)
+		case "/api/node/transactions":
+			list, err = app.api.ListOnchainTransactions(ctx, filters)
+		}
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: list, Error: ""}
+	}
+
+	watchtowerRegex := regexp.MustCompile(
+		`/api/watchtowers/([0-9a-f]+)`,
+	)
+
+	watchtowerMatch := watchtowerRegex.FindStringSubmatch(route)
+
+	switch {
+	case len(watchtowerMatch) > 1 && method == "DELETE":
+		err := app.api.RemoveWatchtower(ctx, watchtowerMatch[1])
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: nil, Error: ""}
+	}
+
 	peerChannelRegex := regexp.MustCompile(
 		`/api/peers/([^/]+)/channels/([^/]+)`,
 	)
@@ -198,14 +382,32 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 		case "DELETE":
 			channelIdParts := strings.SplitN(channelId, "?", 2)
 
-			var force bool
+			closeChannelRequest := &api.CloseChannelRequest{}
 			if len(channelIdParts) == 2 {
 				channelId = channelIdParts[0]
-				queryParams := channelIdParts[1]
-				force = strings.Contains(queryParams, "force=true")
+				queryParams, err := url.ParseQuery(channelIdParts[1])
+				if err != nil {
+					return WailsRequestRouterResponse{Body: nil, Error: "Failed to parse query parameters"}
+				}
+				closeChannelRequest.Force = queryParams.Get("force") == "true"
+				closeChannelRequest.Address = queryParams.Get("address")
+				if feeRateParam := queryParams.Get("feeRate"); feeRateParam != "" {
+					feeRate, err := strconv.ParseUint(feeRateParam, 10, 64)
+					if err != nil {
+						return WailsRequestRouterResponse{Body: nil, Error: "Invalid fee rate"}
+					}
+					closeChannelRequest.FeeRate = &feeRate
+				}
+				if maxFeeRateParam := queryParams.Get("maxFeeRate"); maxFeeRateParam != "" {
+					maxFeeRate, err := strconv.ParseUint(maxFeeRateParam, 10, 64)
+					if err != nil {
+						return WailsRequestRouterResponse{Body: nil, Error: "Invalid max fee rate"}
+					}
+					closeChannelRequest.MaxFeeRate = &maxFeeRate
+				}
 			}
 
-			closeChannelResponse, err := app.api.CloseChannel(ctx, peerId, channelId, force)
+			closeChannelResponse, err := app.api.CloseChannel(ctx, peerId, channelId, closeChannelRequest)
 			if err != nil {
 				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 			}
@@ -289,9 +491,10 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 		limit := uint64(20)
 		offset := uint64(0)
 		var appId *uint
+		var filtersJSON string
 
 		// Extract limit and offset parameters
-		paramRegex := regexp.MustCompile(`[?&](limit|offset|appId)=([^&]+)`)
+		paramRegex := regexp.MustCompile(`[?&](limit|offset|appId|filters)=([^&]+)`)
 		paramMatches := paramRegex.FindAllStringSubmatch(route, -1)
 		for _, match := range paramMatches {
 			switch match[1] {
@@ -308,10 +511,23 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 					var unsignedAppId = uint(parsedAppId)
 					appId = &unsignedAppId
 				}
+			case "filters":
+				filtersJSON = match[2]
+			}
+		}
+
+		var filters api.ListTransactionsFilters
+		if filtersJSON != "" {
+			err := json.Unmarshal([]byte(filtersJSON), &filters)
+			if err != nil {
+				logger.Logger.WithError(err).WithFields(logrus.Fields{
+					"filters": filtersJSON,
+				}).Error("Failed to deserialize transaction filters")
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 			}
 		}
 
-		transactions, err := app.api.ListTransactions(ctx, appId, limit, offset)
+		transactions, err := app.api.ListTransactions(ctx, appId, limit, offset, filters)
 		if err != nil {
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
@@ -402,6 +618,38 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
 		return WailsRequestRouterResponse{Body: rate, Error: ""}
+	case "/api/apps/rotate-keys":
+		rotateAppKeysRequest := &api.RotateAppKeysRequest{}
+		err := json.Unmarshal([]byte(body), rotateAppKeysRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		rotateAllAppKeysResponse, err := app.api.RotateAllAppKeys(rotateAppKeysRequest)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: rotateAllAppKeysResponse, Error: ""}
+	case "/api/nostr-key/rotate":
+		rotateNostrKeyRequest := &api.RotateNostrKeyRequest{}
+		err := json.Unmarshal([]byte(body), rotateNostrKeyRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		rotateNostrKeyResponse, err := app.api.RotateNostrKey(rotateNostrKeyRequest)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: rotateNostrKeyResponse, Error: ""}
 	case "/api/apps":
 		switch method {
 		case "POST":
@@ -422,6 +670,37 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 			}
 			return WailsRequestRouterResponse{Body: createAppResponse, Error: ""}
 		}
+	case "/api/wallet-auth/parse":
+		parseWalletAuthUriRequest := &api.ParseWalletAuthUriRequest{}
+		err := json.Unmarshal([]byte(body), parseWalletAuthUriRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		walletAuthRequest, err := app.api.ParseWalletAuthUri(parseWalletAuthUriRequest.Uri)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: walletAuthRequest, Error: ""}
+	case "/api/wallet-auth":
+		acceptWalletAuthRequest := &api.AcceptWalletAuthRequest{}
+		err := json.Unmarshal([]byte(body), acceptWalletAuthRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		createAppResponse, err := app.api.AcceptWalletAuthRequest(acceptWalletAuthRequest)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: createAppResponse, Error: ""}
 	case "/api/reset-router":
 		resetRouterRequest := &api.ResetRouterRequest{}
 		err := json.Unmarshal([]byte(body), resetRouterRequest)
@@ -450,7 +729,7 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 	case "/api/channels":
 		switch method {
 		case "GET":
-			channels, err := app.api.ListChannels(ctx)
+			channels, err := app.api.ListChannels(ctx, api.LabelFilters{})
 			if err != nil {
 				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 			}
@@ -473,6 +752,53 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 			}
 			return WailsRequestRouterResponse{Body: openChannelResponse, Error: ""}
 		}
+	case "/api/watchtowers":
+		switch method {
+		case "GET":
+			watchtowerStatus, err := app.api.GetWatchtowerStatus(ctx)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: watchtowerStatus, Error: ""}
+		case "POST":
+			addWatchtowerRequest := &api.AddWatchtowerRequest{}
+			err := json.Unmarshal([]byte(body), addWatchtowerRequest)
+			if err != nil {
+				logger.Logger.WithFields(logrus.Fields{
+					"route":  route,
+					"method": method,
+					"body":   body,
+				}).WithError(err).Error("Failed to decode request to wails router")
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			err = app.api.AddWatchtower(ctx, addWatchtowerRequest)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: nil, Error: ""}
+		}
+	case "/api/channels/pending-closes":
+		pendingChannelCloses, err := app.api.ListPendingChannelCloses()
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: pendingChannelCloses, Error: ""}
+	case "/api/channels/batch":
+		openChannelsRequest := &api.OpenChannelsRequest{}
+		err := json.Unmarshal([]byte(body), openChannelsRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		openChannelsResponse, err := app.api.OpenChannels(ctx, openChannelsRequest)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: openChannelsResponse, Error: ""}
 	case "/api/channel-offer":
 		offer, err := app.api.GetLSPChannelOffer(ctx)
 		if err != nil {
@@ -577,20 +903,76 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 		}
 		return WailsRequestRouterResponse{Body: *capabilitiesResponse, Error: ""}
 	case "/api/lsp-orders":
-		newInstantChannelRequest := &api.LSPOrderRequest{}
-		err := json.Unmarshal([]byte(body), newInstantChannelRequest)
+		switch method {
+		case "GET":
+			lspOrders, err := app.api.ListLSPOrders()
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: lspOrders, Error: ""}
+		case "POST":
+			newInstantChannelRequest := &api.LSPOrderRequest{}
+			err := json.Unmarshal([]byte(body), newInstantChannelRequest)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			newInstantChannelResponse, err := app.api.RequestLSPOrder(ctx, newInstantChannelRequest)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: *newInstantChannelResponse, Error: ""}
+		}
+	case "/api/lsps":
+		switch method {
+		case "GET":
+			lsps, err := app.api.ListLSPs()
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: lsps, Error: ""}
+		case "POST":
+			addLSPRequest := &api.AddLSPRequest{}
+			err := json.Unmarshal([]byte(body), addLSPRequest)
+			if err != nil {
+				logger.Logger.WithFields(logrus.Fields{
+					"route":  route,
+					"method": method,
+					"body":   body,
+				}).WithError(err).Error("Failed to decode request to wails router")
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			lsp, err := app.api.AddLSP(addLSPRequest)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: lsp, Error: ""}
+		}
+	case "/api/payment-approvals":
+		paymentApprovals, err := app.api.ListPendingPaymentApprovals()
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: paymentApprovals, Error: ""}
+	case "/api/lsp-quotes":
+		lspQuotesRequest := &api.LSPQuotesRequest{}
+		err := json.Unmarshal([]byte(body), lspQuotesRequest)
 		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
-		newInstantChannelResponse, err := app.api.RequestLSPOrder(ctx, newInstantChannelRequest)
+		lspQuotesResponse, err := app.api.GetLSPQuotes(ctx, lspQuotesRequest)
 		if err != nil {
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
-		return WailsRequestRouterResponse{Body: *newInstantChannelResponse, Error: ""}
+		return WailsRequestRouterResponse{Body: lspQuotesResponse, Error: ""}
 	case "/api/peers":
 		switch method {
 		case "GET":
-			peers, err := app.api.ListPeers(ctx)
+			peers, err := app.api.ListPeers(ctx, api.LabelFilters{})
 			if err != nil {
 				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 			}
@@ -627,8 +1009,37 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 			return WailsRequestRouterResponse{Body: nil, Error: ""}
 		}
 		return WailsRequestRouterResponse{Body: *nodeStatus, Error: ""}
+	case "/api/auto-backups":
+		switch method {
+		case "GET":
+			autoBackups, err := app.api.ListAutoBackups(ctx)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: autoBackups, Error: ""}
+		case "POST":
+			autoBackup, err := app.api.CreateAutoBackup(ctx)
+			if err != nil {
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+			return WailsRequestRouterResponse{Body: autoBackup, Error: ""}
+		}
+	case "/api/retention":
+		return WailsRequestRouterResponse{Body: app.api.GetRetention(), Error: ""}
+	case "/api/retention/prune":
+		report, err := app.api.PruneData(ctx)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: report, Error: ""}
+	case "/api/hold-invoices":
+		holdInvoices, err := app.api.ListHoldInvoices(ctx)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: holdInvoices, Error: ""}
 	case "/api/node/transactions":
-		transactions, err := app.api.ListOnchainTransactions(ctx)
+		transactions, err := app.api.ListOnchainTransactions(ctx, api.LabelFilters{})
 		if err != nil {
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
@@ -978,6 +1389,48 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
 		}
 		return WailsRequestRouterResponse{Body: nil, Error: ""}
+	case "/api/backup/verify":
+		verifyRequest := &api.BasicRestoreWailsRequest{}
+		err := json.Unmarshal([]byte(body), verifyRequest)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to decode request to wails router")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+
+		backupFilePath, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
+			Title:           "Select Backup File",
+			DefaultFilename: "albyhub.bkp",
+		})
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to open file dialog")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+
+		backupFile, err := os.Open(backupFilePath)
+		if err != nil {
+			logger.Logger.WithFields(logrus.Fields{
+				"route":  route,
+				"method": method,
+				"body":   body,
+			}).WithError(err).Error("Failed to open backup file")
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+
+		defer backupFile.Close()
+
+		report, err := app.api.VerifyBackup(verifyRequest.UnlockPassword, backupFile)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: report, Error: ""}
 	case "/api/health":
 		nodeHealth, err := app.api.Health(ctx)
 		if err != nil {
@@ -1298,6 +1751,35 @@ func (app *WailsApp) WailsRequestRouter(route string, method string, body string
 		return WailsRequestRouterResponse{Body: swaps, Error: ""}
 	}
 
+	if route == "/api/logs" || strings.HasPrefix(route, "/api/logs?") {
+		parsedUrl, err := url.Parse(route)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: "Failed to parse route URL"}
+		}
+		queryParams := parsedUrl.Query()
+		limit := uint64(0)
+		if limitParam := queryParams.Get("limit"); limitParam != "" {
+			if parsedLimit, err := strconv.ParseUint(limitParam, 10, 64); err == nil {
+				limit = parsedLimit
+			}
+		}
+		var filters api.LogFilters
+		if filtersJSON := queryParams.Get("filters"); filtersJSON != "" {
+			err := json.Unmarshal([]byte(filtersJSON), &filters)
+			if err != nil {
+				logger.Logger.WithError(err).WithFields(logrus.Fields{
+					"filters": filtersJSON,
+				}).Error("Failed to deserialize log filters")
+				return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+			}
+		}
+		queryLogsResponse, err := app.api.QueryLogs(ctx, limit, filters)
+		if err != nil {
+			return WailsRequestRouterResponse{Body: nil, Error: err.Error()}
+		}
+		return WailsRequestRouterResponse{Body: queryLogsResponse, Error: ""}
+	}
+
 	if strings.HasPrefix(route, "/api/log/") {
 		logType := strings.TrimPrefix(route, "/api/log/")
 		logType = strings.Split(logType, "?")[0]